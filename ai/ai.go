@@ -1,11 +1,11 @@
 package ai
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"errors"
-	"io"
+	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -13,45 +13,106 @@ import (
 const (
 	ProviderOpenRouter = "OpenRouter.ai"
 	ProviderOpenAI     = "OpenAI"
+	ProviderAnthropic  = "Anthropic"
+	ProviderOllama     = "Ollama"
+	ProviderLlamaCpp   = "llama.cpp"
 	ProviderCustom     = "Custom (e.g. Local)"
 )
 
-var SupportedProviders = []string{ProviderOpenRouter, ProviderOpenAI, ProviderCustom}
+var SupportedProviders = []string{ProviderOpenRouter, ProviderOpenAI, ProviderAnthropic, ProviderOllama, ProviderLlamaCpp, ProviderCustom}
+
+// Provider is the api of an AI provider. Each provider handles its own authentication,
+// token limits and error format.
+type Provider interface {
+	// RunPrompt runs the prompt and returns the complete response.
+	RunPrompt(ctx context.Context, client *http.Client, config ProviderConfig, prompt Prompt) (Response, error)
+
+	// StreamPrompt runs the prompt and calls onChunk for every part of the response as soon
+	// as it arrives. The complete response is returned at the end.
+	StreamPrompt(ctx context.Context, client *http.Client, config ProviderConfig, prompt Prompt, onChunk func(chunk string) error) (Response, error)
+
+	// ListModels returns the models that can be used.
+	ListModels(ctx context.Context, client *http.Client, config ProviderConfig) ([]Model, error)
+}
+
+// Embedder is implemented by providers that can create embeddings of texts.
+type Embedder interface {
+	// Embed returns the embedding of every text.
+	Embed(ctx context.Context, client *http.Client, config ProviderConfig, model string, texts []string) ([][]float64, Usage, error)
+}
+
+// Tracker is informed about every prompt that a client runs. It can refuse prompts before
+// they are sent, e.g. to enforce budgets or rate limits.
+type Tracker interface {
+	// Allow is called before the prompt is sent. If an error is returned the prompt is not sent.
+	Allow(config ProviderConfig, prompt Prompt) error
+
+	// Track is called with the response of every successful prompt.
+	Track(config ProviderConfig, prompt Prompt, response Response)
+}
+
+var providers = map[string]Provider{
+	ProviderOpenRouter: openAIProvider{referer: true, streamUsage: true},
+	ProviderOpenAI:     openAIProvider{requireKey: true, completionTokens: true, streamUsage: true},
+	ProviderAnthropic:  anthropicProvider{},
+	ProviderOllama:     ollamaProvider{},
+	ProviderLlamaCpp:   openAIProvider{optionalModel: true},
+	ProviderCustom:     openAIProvider{referer: true, optionalModel: true},
+}
+
+// ProviderFor returns the implementation of the provider.
+func ProviderFor(provider string) (Provider, error) {
+	if p, ok := providers[provider]; ok {
+		return p, nil
+	}
+	return nil, errors.New("unknown provider")
+}
 
 type Client struct {
 	httpClient *http.Client
+	tracker    Tracker
 }
 
 type ProviderConfig struct {
 	Provider string
 	Endpoint string
 	APIKey   string
+	// Timeout limits every attempt of a request. Zero uses DefaultTimeout. Streams are not limited,
+	// use the context instead.
+	Timeout time.Duration
 }
 
 type Prompt struct {
-	Model     string
+	Model string
+	// MaxTokens is the maximum number of tokens that the response can have.
 	MaxTokens int
 	System    string
 	User      string
+	// JSON asks the model to respond with a json object. Providers that support it use their
+	// json mode, the prompt itself should still describe the expected format.
+	JSON bool
 }
 
-type Message struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
+// Usage is the number of tokens that a prompt used.
+type Usage struct {
+	PromptTokens     int `json:"promptTokens"`
+	CompletionTokens int `json:"completionTokens"`
+	// Estimated is true if the provider didn't report the usage and it was estimated from the length of the texts.
+	Estimated bool `json:"estimated"`
 }
 
-type PromptRequest struct {
-	Model     string    `json:"model"`
-	MaxTokens int       `json:"max_tokens"`
-	Messages  []Message `json:"messages"`
+// Response is the response of a prompt.
+type Response struct {
+	Text string
+	// Model is the model that generated the response as reported by the provider. It can differ
+	// from the model of the prompt, e.g. if the prompt uses an alias, or be empty.
+	Model string
+	Usage Usage
 }
 
-type PromptResponse struct {
-	ID      string `json:"id"`
-	Model   string `json:"model"`
-	Choices []struct {
-		Message Message `json:"message"`
-	} `json:"choices"`
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
 }
 
 type Model struct {
@@ -72,22 +133,74 @@ type ModelsList struct {
 	Data []Model `json:"data"`
 }
 
+// Cost returns the cost of the usage in USD based on the pricing of the model. False is returned if
+// the model has no pricing.
+func (m Model) Cost(usage Usage) (float64, bool) {
+	if m.Pricing == nil {
+		return 0, false
+	}
+
+	prompt, ok := parsePrice(m.Pricing.Prompt)
+	if !ok {
+		return 0, false
+	}
+
+	completion, ok := parsePrice(m.Pricing.Completion)
+	if !ok {
+		return 0, false
+	}
+
+	return prompt*float64(usage.PromptTokens) + completion*float64(usage.CompletionTokens), true
+}
+
+// parsePrice parses the price per token, which is a string in the OpenRouter api.
+func parsePrice(price any) (float64, bool) {
+	switch val := price.(type) {
+	case float64:
+		return val, true
+	case string:
+		res, err := strconv.ParseFloat(val, 64)
+		return res, err == nil
+	}
+	return 0, false
+}
+
+// EstimateTokens roughly estimates the number of tokens of a text. It is used if the provider
+// doesn't report the usage.
+func EstimateTokens(text string) int {
+	return (len(text) + 3) / 4
+}
+
+// NewClient creates a client. The timeout of requests is set with ProviderConfig.Timeout, a
+// timeout of the http client additionally limits every attempt.
 func NewClient(httpClient *http.Client) *Client {
 	if httpClient == nil {
-		httpClient = &http.Client{
-			Timeout: time.Second * 60,
-		}
+		httpClient = &http.Client{}
 	}
 
 	return &Client{httpClient: httpClient}
 }
 
+// EndpointForProvider returns the base url of the provider. The custom url is required
+// for the custom provider and overrides the default address of local providers.
 func EndpointForProvider(provider string, customURL string) (string, error) {
 	switch provider {
 	case ProviderOpenRouter:
 		return "https://openrouter.ai/api", nil
 	case ProviderOpenAI:
 		return "https://api.openai.com", nil
+	case ProviderAnthropic:
+		return "https://api.anthropic.com", nil
+	case ProviderOllama:
+		if len(customURL) > 0 {
+			return customURL, nil
+		}
+		return "http://127.0.0.1:11434", nil
+	case ProviderLlamaCpp:
+		if len(customURL) > 0 {
+			return customURL, nil
+		}
+		return "http://127.0.0.1:8080", nil
 	case ProviderCustom:
 		if len(customURL) == 0 {
 			return "", errors.New("custom AI provider URL is not set")
@@ -98,142 +211,199 @@ func EndpointForProvider(provider string, customURL string) (string, error) {
 	}
 }
 
-func (c *Client) RunPrompt(config ProviderConfig, prompt Prompt) (string, error) {
-	if strings.TrimSpace(prompt.Model) == "" {
-		return "", errors.New("AI model is not set")
-	}
+// IsLocalProvider checks if the provider runs on the machine of the user, so that the url can be changed.
+func IsLocalProvider(provider string) bool {
+	return provider == ProviderOllama || provider == ProviderLlamaCpp || provider == ProviderCustom
+}
 
-	request := PromptRequest{
-		Model:     prompt.Model,
-		MaxTokens: prompt.MaxTokens,
-		Messages: []Message{
-			{Role: "system", Content: prompt.System},
-			{Role: "user", Content: prompt.User},
-		},
-	}
+// SetTracker sets the tracker that is informed about every prompt.
+func (c *Client) SetTracker(tracker Tracker) {
+	c.tracker = tracker
+}
 
-	if config.Provider == ProviderOpenAI {
-		request.MaxTokens -= len(prompt.System) + len(prompt.User)
-	}
+// RunPrompt runs the prompt and returns the complete response.
+func (c *Client) RunPrompt(config ProviderConfig, prompt Prompt) (string, error) {
+	return c.RunPromptContext(context.Background(), config, prompt)
+}
 
-	body, err := json.Marshal(request)
+// RunPromptContext runs the prompt and returns the complete response. Failed requests are retried
+// if the error is temporary, e.g. a rate limit, until the context is cancelled. Errors of the
+// provider are returned as *Error.
+func (c *Client) RunPromptContext(ctx context.Context, config ProviderConfig, prompt Prompt) (string, error) {
+	provider, err := ProviderFor(config.Provider)
 	if err != nil {
 		return "", err
 	}
 
-	req, err := http.NewRequest("POST", config.Endpoint+"/v1/chat/completions", bytes.NewBuffer(body))
-	if err != nil {
+	// Retries are not checked again, as they belong to the same prompt
+	if err := c.allow(config, prompt); err != nil {
 		return "", err
 	}
 
-	setOpenAICompatibleHeaders(req, config.APIKey)
-
-	resp, err := c.httpClient.Do(req)
+	var resp Response
+	err = retry(ctx, nil, func(ctx context.Context) error {
+		return withTimeout(ctx, config, func(ctx context.Context) error {
+			resp, err = provider.RunPrompt(ctx, c.httpClient, config, prompt)
+			return err
+		})
+	})
 	if err != nil {
 		return "", err
 	}
-	defer resp.Body.Close()
 
-	respBody, err := io.ReadAll(resp.Body)
+	c.track(config, prompt, resp)
+
+	return resp.Text, nil
+}
+
+// StreamPrompt runs the prompt and calls onChunk for every part of the completion as soon as
+// it arrives. The request is stopped when the context is cancelled or onChunk returns an error.
+// The complete response is returned at the end.
+//
+// The timeout of the http client is not applied, as it would limit the whole stream. Use
+// the context to limit the duration instead. Failed requests are only retried as long as
+// no chunk was received.
+func (c *Client) StreamPrompt(ctx context.Context, config ProviderConfig, prompt Prompt, onChunk func(chunk string) error) (string, error) {
+	provider, err := ProviderFor(config.Provider)
 	if err != nil {
 		return "", err
 	}
 
-	if strings.HasPrefix(string(respBody), "error code:") {
-		return "", errors.New(string(respBody))
+	if err := c.allow(config, prompt); err != nil {
+		return "", err
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		return "", responseError(respBody)
-	}
+	streamClient := *c.httpClient
+	streamClient.Timeout = 0
 
-	var aiResp PromptResponse
-	err = json.Unmarshal(respBody, &aiResp)
+	started := false
+	var resp Response
+	err = retry(ctx, func() bool { return !started }, func(ctx context.Context) error {
+		resp, err = provider.StreamPrompt(ctx, &streamClient, config, prompt, func(chunk string) error {
+			started = true
+			return onChunk(chunk)
+		})
+		return err
+	})
 	if err != nil {
 		return "", err
 	}
 
-	if len(aiResp.Choices) == 0 {
-		return "", errors.New("no response from AI")
-	}
+	c.track(config, prompt, resp)
 
-	return aiResp.Choices[0].Message.Content, nil
+	return resp.Text, nil
 }
 
+// ListModels returns the ids of the models that can be used.
 func (c *Client) ListModels(config ProviderConfig) ([]string, error) {
-	req, err := http.NewRequest("GET", config.Endpoint+"/v1/models", nil)
+	models, err := c.Models(config)
 	if err != nil {
 		return nil, err
 	}
 
-	if config.Provider == ProviderOpenAI {
-		if config.APIKey == "" {
-			return nil, errors.New("OpenAI API key not set")
-		}
-
-		req.Header.Set("Authorization", "Bearer "+config.APIKey)
+	modelIDs := make([]string, 0, len(models))
+	for _, model := range models {
+		modelIDs = append(modelIDs, model.ID)
 	}
 
-	resp, err := c.httpClient.Do(req)
+	return modelIDs, nil
+}
+
+// Models returns the models that can be used together with the pricing and limits, if the
+// provider offers them.
+func (c *Client) Models(config ProviderConfig) ([]Model, error) {
+	provider, err := ProviderFor(config.Provider)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
 
-	res, err := io.ReadAll(resp.Body)
+	var models []Model
+	err = retry(context.Background(), nil, func(ctx context.Context) error {
+		return withTimeout(ctx, config, func(ctx context.Context) error {
+			models, err = provider.ListModels(ctx, c.httpClient, config)
+			return err
+		})
+	})
+	return models, err
+}
+
+// Embed returns the embeddings of the texts created by the model. Not every provider supports embeddings.
+// The embeddings count towards the usage like prompts.
+func (c *Client) Embed(config ProviderConfig, model string, texts []string) ([][]float64, error) {
+	return c.EmbedContext(context.Background(), config, model, texts)
+}
+
+// EmbedContext is the same as Embed, but stops when the context is cancelled.
+func (c *Client) EmbedContext(ctx context.Context, config ProviderConfig, model string, texts []string) ([][]float64, error) {
+	provider, err := ProviderFor(config.Provider)
 	if err != nil {
 		return nil, err
 	}
 
-	if strings.Contains(string(res), "invalid_api_key") {
-		return nil, errors.New("invalid API key")
+	embedder, ok := provider.(Embedder)
+	if !ok {
+		return nil, fmt.Errorf("%s doesn't support embeddings", config.Provider)
+	}
+
+	if strings.TrimSpace(model) == "" {
+		return nil, errors.New("embedding model is not set")
 	}
 
-	var models ModelsList
-	err = json.Unmarshal(res, &models)
-	if err != nil {
+	// The prompt is only used for tracking
+	prompt := Prompt{Model: model, User: strings.Join(texts, "\n")}
+	if err := c.allow(config, prompt); err != nil {
 		return nil, err
 	}
 
-	if models.Data == nil {
-		return nil, errors.New("no models found")
+	var embeddings [][]float64
+	var usage Usage
+	err = retry(ctx, nil, func(ctx context.Context) error {
+		return withTimeout(ctx, config, func(ctx context.Context) error {
+			embeddings, usage, err = embedder.Embed(ctx, c.httpClient, config, model, texts)
+			return err
+		})
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	modelIDs := make([]string, 0, len(models.Data))
-	for _, model := range models.Data {
-		modelIDs = append(modelIDs, model.ID)
+	if len(embeddings) != len(texts) {
+		return nil, errors.New("number of embeddings doesn't match the number of texts")
 	}
 
-	return modelIDs, nil
-}
+	c.track(config, prompt, Response{Model: model, Usage: usage})
 
-func setOpenAICompatibleHeaders(req *http.Request, apiKey string) {
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+apiKey)
-	req.Header.Set("HTTP-Referer", "https://sales-and-dungeons.app/")
-	req.Header.Set("X-Title", "Sales & Dungeons")
+	return embeddings, nil
 }
 
-func responseError(respBody []byte) error {
-	var errorResponse map[string]map[string]interface{}
-	err := json.Unmarshal(respBody, &errorResponse)
-	if err != nil {
-		return err
+func (c *Client) allow(config ProviderConfig, prompt Prompt) error {
+	if c.tracker == nil {
+		return nil
 	}
+	return c.tracker.Allow(config, prompt)
+}
 
-	errMsg, ok := errorResponse["error"]["message"].(string)
-	if !ok {
-		return errors.New("AI request failed")
+// track informs the tracker about the response. If the provider didn't report the usage it is estimated.
+func (c *Client) track(config ProviderConfig, prompt Prompt, resp Response) {
+	if c.tracker == nil {
+		return
 	}
 
-	if len(errMsg) > 0 && errMsg[0] == '{' {
-		if err := json.Unmarshal([]byte(errMsg), &errorResponse); err != nil {
-			return err
-		}
-		if nestedErrMsg, ok := errorResponse["error"]["message"].(string); ok {
-			errMsg = nestedErrMsg
+	if resp.Usage.PromptTokens == 0 && resp.Usage.CompletionTokens == 0 {
+		resp.Usage = Usage{
+			PromptTokens:     EstimateTokens(prompt.System) + EstimateTokens(prompt.User),
+			CompletionTokens: EstimateTokens(resp.Text),
+			Estimated:        true,
 		}
 	}
 
-	return errors.New(errMsg)
+	c.tracker.Track(config, prompt, resp)
+}
+
+// checkModel returns an error if the model of the prompt is empty.
+func checkModel(prompt Prompt) error {
+	if strings.TrimSpace(prompt.Model) == "" {
+		return errors.New("AI model is not set")
+	}
+	return nil
 }
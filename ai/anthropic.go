@@ -0,0 +1,240 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// anthropicVersion is the version of the Anthropic Messages api.
+const anthropicVersion = "2023-06-01"
+
+// anthropicDefaultMaxTokens is used if no token limit is set, as the api requires one.
+const anthropicDefaultMaxTokens = 4096
+
+// anthropicJSONPrefill starts the response of the model for json prompts, as the api has no json mode.
+const anthropicJSONPrefill = "{"
+
+// anthropicProvider implements the Anthropic Messages api.
+type anthropicProvider struct{}
+
+type anthropicRequest struct {
+	Model     string    `json:"model"`
+	MaxTokens int       `json:"max_tokens"`
+	System    string    `json:"system,omitempty"`
+	Messages  []Message `json:"messages"`
+	Stream    bool      `json:"stream,omitempty"`
+}
+
+type anthropicUsage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+type anthropicResponse struct {
+	Model   string `json:"model"`
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	Usage anthropicUsage `json:"usage"`
+}
+
+type anthropicEvent struct {
+	Type  string `json:"type"`
+	Delta struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"delta"`
+	// Message is sent with the "message_start" event and contains the usage of the prompt.
+	Message *anthropicResponse `json:"message"`
+	// Usage is sent with the "message_delta" event and contains the usage of the response.
+	Usage *anthropicUsage `json:"usage"`
+	Error *struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (p anthropicProvider) setHeaders(req *http.Request, apiKey string) {
+	req.Header.Set("x-api-key", apiKey)
+	req.Header.Set("anthropic-version", anthropicVersion)
+}
+
+func (p anthropicProvider) newRequest(ctx context.Context, config ProviderConfig, prompt Prompt, stream bool) (*http.Request, error) {
+	if err := checkModel(prompt); err != nil {
+		return nil, err
+	}
+
+	if config.APIKey == "" {
+		return nil, errorf(ErrorAuth, "API key not set")
+	}
+
+	request := anthropicRequest{
+		Model:     prompt.Model,
+		MaxTokens: prompt.MaxTokens,
+		System:    prompt.System,
+		Messages: []Message{
+			{Role: "user", Content: prompt.User},
+		},
+		Stream: stream,
+	}
+
+	if prompt.JSON {
+		request.Messages = append(request.Messages, Message{Role: "assistant", Content: anthropicJSONPrefill})
+	}
+
+	if request.MaxTokens <= 0 {
+		request.MaxTokens = anthropicDefaultMaxTokens
+	}
+
+	req, err := newJSONRequest(ctx, "POST", config.Endpoint+"/v1/messages", request)
+	if err != nil {
+		return nil, err
+	}
+
+	p.setHeaders(req, config.APIKey)
+
+	return req, nil
+}
+
+func (p anthropicProvider) RunPrompt(ctx context.Context, client *http.Client, config ProviderConfig, prompt Prompt) (Response, error) {
+	req, err := p.newRequest(ctx, config, prompt, false)
+	if err != nil {
+		return Response{}, err
+	}
+
+	respBody, err := doRequest(client, req, responseError)
+	if err != nil {
+		return Response{}, err
+	}
+
+	var aiResp anthropicResponse
+	if err := json.Unmarshal(respBody, &aiResp); err != nil {
+		return Response{}, err
+	}
+
+	var sb strings.Builder
+	for _, content := range aiResp.Content {
+		if content.Type == "text" {
+			sb.WriteString(content.Text)
+		}
+	}
+
+	if sb.Len() == 0 {
+		return Response{}, errors.New("no response from AI")
+	}
+
+	resp := Response{
+		Text:  sb.String(),
+		Model: aiResp.Model,
+		Usage: Usage{PromptTokens: aiResp.Usage.InputTokens, CompletionTokens: aiResp.Usage.OutputTokens},
+	}
+
+	// The prefill is not part of the response
+	if prompt.JSON {
+		resp.Text = anthropicJSONPrefill + resp.Text
+	}
+
+	return resp, nil
+}
+
+func (p anthropicProvider) StreamPrompt(ctx context.Context, client *http.Client, config ProviderConfig, prompt Prompt, onChunk func(chunk string) error) (Response, error) {
+	req, err := p.newRequest(ctx, config, prompt, true)
+	if err != nil {
+		return Response{}, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	body, err := openResponse(client, req, responseError)
+	if err != nil {
+		return Response{}, err
+	}
+	defer body.Close()
+
+	var sb strings.Builder
+	var resp Response
+	if prompt.JSON {
+		sb.WriteString(anthropicJSONPrefill)
+		if err := onChunk(anthropicJSONPrefill); err != nil {
+			return Response{}, err
+		}
+	}
+
+	err = readEvents(body, func(data string) error {
+		var event anthropicEvent
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			return err
+		}
+
+		switch event.Type {
+		case "message_start":
+			if event.Message != nil {
+				resp.Model = event.Message.Model
+				resp.Usage.PromptTokens = event.Message.Usage.InputTokens
+			}
+		case "message_delta":
+			if event.Usage != nil {
+				resp.Usage.CompletionTokens = event.Usage.OutputTokens
+			}
+		case "error":
+			if event.Error == nil {
+				return newError(0, "")
+			}
+			return newError(0, event.Error.Message)
+		case "content_block_delta":
+			if event.Delta.Type != "text_delta" || len(event.Delta.Text) == 0 {
+				return nil
+			}
+
+			sb.WriteString(event.Delta.Text)
+			return onChunk(event.Delta.Text)
+		case "message_stop":
+			return io.EOF
+		}
+
+		return nil
+	})
+	if err != nil {
+		return Response{}, err
+	}
+
+	if sb.Len() == 0 || (prompt.JSON && sb.Len() == len(anthropicJSONPrefill)) {
+		return Response{}, errors.New("no response from AI")
+	}
+
+	resp.Text = sb.String()
+	return resp, nil
+}
+
+func (p anthropicProvider) ListModels(ctx context.Context, client *http.Client, config ProviderConfig) ([]Model, error) {
+	if config.APIKey == "" {
+		return nil, errorf(ErrorAuth, "API key not set")
+	}
+
+	req, err := newJSONRequest(ctx, "GET", config.Endpoint+"/v1/models?limit=1000", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	p.setHeaders(req, config.APIKey)
+
+	res, err := doRequest(client, req, responseError)
+	if err != nil {
+		return nil, err
+	}
+
+	var models ModelsList
+	if err := json.Unmarshal(res, &models); err != nil {
+		return nil, err
+	}
+
+	if len(models.Data) == 0 {
+		return nil, errors.New("no models found")
+	}
+
+	return models.Data, nil
+}
@@ -0,0 +1,132 @@
+package ai
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestAnthropicRunPrompt(t *testing.T) {
+	server, req := newTestServer(t, 200, nil, `{"id": "msg_1", "type": "message", "role": "assistant", "model": "claude-3-5-haiku-20241022", "content": [{"type": "text", "text": "\"name\": \"Goblin\"}"}], "usage": {"input_tokens": 20, "output_tokens": 8}}`)
+
+	provider, _ := ProviderFor(ProviderAnthropic)
+	resp, err := provider.RunPrompt(context.Background(), server.Client(), ProviderConfig{Provider: ProviderAnthropic, Endpoint: server.URL, APIKey: "key"}, Prompt{
+		Model:  "claude-3-5-haiku-latest",
+		System: "You are a game master.",
+		User:   "Create a monster.",
+		JSON:   true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if req.Method != "POST" || req.Path != "/v1/messages" || req.Header.Get("x-api-key") != "key" || req.Header.Get("anthropic-version") != anthropicVersion || req.Header.Get("Authorization") != "" {
+		t.Fatalf("unexpected request %s %s %v", req.Method, req.Path, req.Header)
+	}
+
+	// The system prompt is a separate field, the token limit is required and json is prefilled
+	expected := map[string]any{
+		"model":      "claude-3-5-haiku-latest",
+		"max_tokens": float64(anthropicDefaultMaxTokens),
+		"system":     "You are a game master.",
+		"messages": []any{
+			map[string]any{"role": "user", "content": "Create a monster."},
+			map[string]any{"role": "assistant", "content": "{"},
+		},
+	}
+	if !reflect.DeepEqual(req.Body, expected) {
+		t.Fatalf("expected body %v, got %v", expected, req.Body)
+	}
+
+	if resp.Text != `{"name": "Goblin"}` || resp.Model != "claude-3-5-haiku-20241022" || resp.Usage != (Usage{PromptTokens: 20, CompletionTokens: 8}) {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+}
+
+func TestAnthropicRequiresKey(t *testing.T) {
+	server, req := newTestServer(t, 200, nil, `{}`)
+
+	provider, _ := ProviderFor(ProviderAnthropic)
+	if _, err := provider.RunPrompt(context.Background(), server.Client(), ProviderConfig{Provider: ProviderAnthropic, Endpoint: server.URL}, Prompt{Model: "model", User: "Hello"}); Kind(err) != ErrorAuth {
+		t.Fatalf("expected auth error, got %v", err)
+	}
+	if _, err := provider.ListModels(context.Background(), server.Client(), ProviderConfig{Provider: ProviderAnthropic, Endpoint: server.URL}); Kind(err) != ErrorAuth {
+		t.Fatalf("expected auth error, got %v", err)
+	}
+	if len(req.Path) > 0 {
+		t.Fatalf("expected no request, got %s", req.Path)
+	}
+}
+
+func TestAnthropicStreamPrompt(t *testing.T) {
+	body := "event: message_start\n" + sse(`{"type": "message_start", "message": {"model": "claude-3-5-haiku-20241022", "content": [], "usage": {"input_tokens": 25, "output_tokens": 1}}}`) +
+		"event: ping\n" + sse(`{"type": "ping"}`) +
+		"event: content_block_start\n" + sse(`{"type": "content_block_start", "index": 0, "content_block": {"type": "text", "text": ""}}`) +
+		"event: content_block_delta\n" + sse(`{"type": "content_block_delta", "index": 0, "delta": {"type": "text_delta", "text": "Hello"}}`) +
+		"event: content_block_delta\n" + sse(`{"type": "content_block_delta", "index": 0, "delta": {"type": "text_delta", "text": " adventurer"}}`) +
+		"event: content_block_stop\n" + sse(`{"type": "content_block_stop", "index": 0}`) +
+		"event: message_delta\n" + sse(`{"type": "message_delta", "delta": {"stop_reason": "end_turn"}, "usage": {"output_tokens": 15}}`) +
+		"event: message_stop\n" + sse(`{"type": "message_stop"}`)
+	server, req := newTestServer(t, 200, map[string]string{"Content-Type": "text/event-stream"}, body)
+
+	var chunks []string
+	provider, _ := ProviderFor(ProviderAnthropic)
+	resp, err := provider.StreamPrompt(context.Background(), server.Client(), ProviderConfig{Provider: ProviderAnthropic, Endpoint: server.URL, APIKey: "key"}, Prompt{Model: "claude-3-5-haiku-latest", MaxTokens: 200, User: "Hello"}, collectChunks(&chunks))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if req.Body["stream"] != true || req.Body["max_tokens"] != 200.0 || req.Header.Get("Accept") != "text/event-stream" {
+		t.Fatalf("unexpected request %v %v", req.Header, req.Body)
+	}
+
+	if !reflect.DeepEqual(chunks, []string{"Hello", " adventurer"}) {
+		t.Fatalf("unexpected chunks %v", chunks)
+	}
+	if resp.Text != "Hello adventurer" || resp.Model != "claude-3-5-haiku-20241022" || resp.Usage != (Usage{PromptTokens: 25, CompletionTokens: 15}) {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+}
+
+func TestAnthropicStreamJSONPrefill(t *testing.T) {
+	body := sse(
+		`{"type": "content_block_delta", "index": 0, "delta": {"type": "text_delta", "text": "\"hp\": 7}"}}`,
+		`{"type": "message_stop"}`,
+	)
+	server, _ := newTestServer(t, 200, nil, body)
+
+	var chunks []string
+	provider, _ := ProviderFor(ProviderAnthropic)
+	resp, err := provider.StreamPrompt(context.Background(), server.Client(), ProviderConfig{Provider: ProviderAnthropic, Endpoint: server.URL, APIKey: "key"}, Prompt{Model: "model", User: "Hello", JSON: true}, collectChunks(&chunks))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(chunks, []string{"{", `"hp": 7}`}) || resp.Text != `{"hp": 7}` {
+		t.Fatalf("unexpected stream %v %+v", chunks, resp)
+	}
+}
+
+func TestAnthropicStreamError(t *testing.T) {
+	body := "event: error\n" + sse(`{"type": "error", "error": {"type": "overloaded_error", "message": "Overloaded"}}`)
+	server, _ := newTestServer(t, 200, nil, body)
+
+	provider, _ := ProviderFor(ProviderAnthropic)
+	_, err := provider.StreamPrompt(context.Background(), server.Client(), ProviderConfig{Provider: ProviderAnthropic, Endpoint: server.URL, APIKey: "key"}, Prompt{Model: "model", User: "Hello"}, func(string) error { return nil })
+	if Kind(err) != ErrorServer {
+		t.Fatalf("expected server error, got %v", err)
+	}
+}
+
+func TestAnthropicListModels(t *testing.T) {
+	server, req := newTestServer(t, 200, nil, `{"data": [{"type": "model", "id": "claude-3-5-haiku-20241022", "display_name": "Claude 3.5 Haiku"}], "has_more": false}`)
+
+	provider, _ := ProviderFor(ProviderAnthropic)
+	models, err := provider.ListModels(context.Background(), server.Client(), ProviderConfig{Provider: ProviderAnthropic, Endpoint: server.URL, APIKey: "key"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Path != "/v1/models?limit=1000" || req.Header.Get("x-api-key") != "key" || len(models) != 1 || models[0].ID != "claude-3-5-haiku-20241022" {
+		t.Fatalf("unexpected models %+v from %s", models, req.Path)
+	}
+}
@@ -0,0 +1,201 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrorKind classifies why an AI request failed.
+type ErrorKind string
+
+const (
+	// ErrorAuth means the API key is missing, invalid or has no access.
+	ErrorAuth ErrorKind = "auth"
+	// ErrorQuota means the credits or quota of the account are used up.
+	ErrorQuota ErrorKind = "quota"
+	// ErrorRateLimit means too many requests were sent in a short time.
+	ErrorRateLimit ErrorKind = "rate_limit"
+	// ErrorContextLength means the prompt and response don't fit into the context of the model.
+	ErrorContextLength ErrorKind = "context_length"
+	// ErrorModelNotFound means the model doesn't exist or isn't available.
+	ErrorModelNotFound ErrorKind = "model_not_found"
+	// ErrorServer means the provider failed or is overloaded.
+	ErrorServer ErrorKind = "server"
+	// ErrorUnavailable means the provider couldn't be reached, e.g. because a local server isn't running.
+	ErrorUnavailable ErrorKind = "unavailable"
+	// ErrorTimeout means the provider didn't respond in time.
+	ErrorTimeout ErrorKind = "timeout"
+	// ErrorUnknown is used for all other errors.
+	ErrorUnknown ErrorKind = "unknown"
+)
+
+var errorTitles = map[ErrorKind]string{
+	ErrorAuth:          "Authentication failed",
+	ErrorQuota:         "Quota exceeded",
+	ErrorRateLimit:     "Rate limited",
+	ErrorContextLength: "Prompt too long",
+	ErrorModelNotFound: "Model not found",
+	ErrorServer:        "Provider error",
+	ErrorUnavailable:   "Provider unavailable",
+	ErrorTimeout:       "Request timed out",
+	ErrorUnknown:       "AI request failed",
+}
+
+var errorHints = map[ErrorKind]string{
+	ErrorAuth:          "Check the API key in the settings.",
+	ErrorQuota:         "Check the credits and billing of your account.",
+	ErrorRateLimit:     "Wait a moment and try again.",
+	ErrorContextLength: "Lower the context window or max tokens in the settings.",
+	ErrorModelNotFound: "Select another model in the settings.",
+	ErrorUnavailable:   "Check that the provider is running and the URL in the settings is correct.",
+	ErrorTimeout:       "Increase the timeout in the settings or try a smaller prompt.",
+}
+
+// Sentinel errors to check the kind of an error with errors.Is.
+var (
+	ErrAuth          = &Error{Kind: ErrorAuth}
+	ErrQuota         = &Error{Kind: ErrorQuota}
+	ErrRateLimit     = &Error{Kind: ErrorRateLimit}
+	ErrContextLength = &Error{Kind: ErrorContextLength}
+	ErrModelNotFound = &Error{Kind: ErrorModelNotFound}
+	ErrServer        = &Error{Kind: ErrorServer}
+	ErrUnavailable   = &Error{Kind: ErrorUnavailable}
+	ErrTimeout       = &Error{Kind: ErrorTimeout}
+)
+
+// Error is a failed AI request.
+type Error struct {
+	Kind ErrorKind
+	// Status is the http status of the response. Zero if there was no response.
+	Status int
+	// Message is the message of the provider.
+	Message string
+	// RetryAfter is the time the provider asked to wait before the next request. Zero if not given.
+	RetryAfter time.Duration
+}
+
+func (e *Error) Error() string {
+	var sb strings.Builder
+	sb.WriteString(errorTitles[e.Kind])
+	if len(e.Message) > 0 {
+		sb.WriteString(": ")
+		sb.WriteString(e.Message)
+	}
+	if hint, ok := errorHints[e.Kind]; ok {
+		sb.WriteString(" (")
+		sb.WriteString(hint)
+		sb.WriteString(")")
+	}
+	return sb.String()
+}
+
+// Is reports if the target is an error of the same kind, so that errors.Is(err, ai.ErrAuth) works.
+func (e *Error) Is(target error) bool {
+	var other *Error
+	return errors.As(target, &other) && other.Kind == e.Kind
+}
+
+// Retryable reports if sending the same request again might succeed.
+func (e *Error) Retryable() bool {
+	return e.Kind == ErrorRateLimit || e.Kind == ErrorServer || e.Kind == ErrorUnavailable
+}
+
+// Kind returns the kind of the error or ErrorUnknown if it isn't an AI error.
+func Kind(err error) ErrorKind {
+	var aiErr *Error
+	if errors.As(err, &aiErr) {
+		return aiErr.Kind
+	}
+	return ErrorUnknown
+}
+
+// newError creates the error of a failed response and classifies it by the status and message.
+func newError(status int, message string) *Error {
+	return &Error{
+		Kind:    classify(status, message),
+		Status:  status,
+		Message: message,
+	}
+}
+
+// classify guesses the kind of the error. The providers don't use common error codes, so the
+// message is checked for the typical phrases of OpenAI, OpenRouter, Anthropic, Ollama and llama.cpp.
+func classify(status int, message string) ErrorKind {
+	msg := strings.ToLower(message)
+	contains := func(phrases ...string) bool {
+		for _, phrase := range phrases {
+			if strings.Contains(msg, phrase) {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch {
+	case contains("context length", "context_length", "context size", "context window", "prompt is too long", "too many tokens", "maximum context"):
+		return ErrorContextLength
+	case status == http.StatusPaymentRequired || contains("insufficient_quota", "exceeded your current quota", "credit balance", "insufficient credits", "billing"):
+		return ErrorQuota
+	case status == http.StatusUnauthorized || status == http.StatusForbidden || contains("api key", "api_key", "unauthorized", "authentication"):
+		return ErrorAuth
+	case status == http.StatusTooManyRequests || contains("rate limit", "rate_limit"):
+		return ErrorRateLimit
+	case status == http.StatusNotFound && contains("model") || contains("model_not_found", "model not found", "no such model") || (contains("model") && contains("does not exist", "not found")):
+		return ErrorModelNotFound
+	case status == http.StatusRequestTimeout || status == http.StatusGatewayTimeout:
+		return ErrorTimeout
+	case status >= 500 || contains("overloaded"):
+		return ErrorServer
+	}
+
+	return ErrorUnknown
+}
+
+// requestError converts the error of a request that got no response. Cancelled requests return
+// the error of the context, so that they are not retried.
+func requestError(ctx context.Context, err error) error {
+	if ctx.Err() == context.Canceled {
+		return ctx.Err()
+	}
+
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return &Error{Kind: ErrorTimeout, Message: "no response in time"}
+	}
+
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return &Error{Kind: ErrorUnavailable, Message: err.Error()}
+	}
+
+	return err
+}
+
+// parseRetryAfter parses the Retry-After header, which is either a number of seconds or a date.
+func parseRetryAfter(header http.Header) time.Duration {
+	value := strings.TrimSpace(header.Get("Retry-After"))
+	if len(value) == 0 {
+		return 0
+	}
+
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds > 0 {
+		return time.Duration(seconds * float64(time.Second))
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0)
+	}
+
+	return 0
+}
+
+// errorf creates an error of the kind.
+func errorf(kind ErrorKind, format string, args ...any) *Error {
+	return &Error{Kind: kind, Message: fmt.Sprintf(format, args...)}
+}
@@ -0,0 +1,230 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// ollamaProvider implements the native api of Ollama.
+type ollamaProvider struct{}
+
+type ollamaRequest struct {
+	Model    string         `json:"model"`
+	Messages []Message      `json:"messages"`
+	Stream   bool           `json:"stream"`
+	Format   string         `json:"format,omitempty"`
+	Options  map[string]any `json:"options,omitempty"`
+}
+
+type ollamaResponse struct {
+	Model           string  `json:"model"`
+	Message         Message `json:"message"`
+	Done            bool    `json:"done"`
+	Error           string  `json:"error"`
+	PromptEvalCount int     `json:"prompt_eval_count"`
+	EvalCount       int     `json:"eval_count"`
+}
+
+func (r ollamaResponse) usage() Usage {
+	return Usage{PromptTokens: r.PromptEvalCount, CompletionTokens: r.EvalCount}
+}
+
+type ollamaEmbedRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+type ollamaEmbedResponse struct {
+	Embeddings      [][]float64 `json:"embeddings"`
+	PromptEvalCount int         `json:"prompt_eval_count"`
+}
+
+type ollamaTags struct {
+	Models []struct {
+		Name string `json:"name"`
+	} `json:"models"`
+}
+
+// ollamaError parses errors in the format {"error": "..."}.
+func ollamaError(body []byte) error {
+	var resp ollamaResponse
+	if err := json.Unmarshal(body, &resp); err != nil || len(resp.Error) == 0 {
+		return nil
+	}
+	return errors.New(resp.Error)
+}
+
+func (p ollamaProvider) newRequest(ctx context.Context, config ProviderConfig, prompt Prompt, stream bool) (*http.Request, error) {
+	if err := checkModel(prompt); err != nil {
+		return nil, err
+	}
+
+	request := ollamaRequest{
+		Model: prompt.Model,
+		Messages: []Message{
+			{Role: "system", Content: prompt.System},
+			{Role: "user", Content: prompt.User},
+		},
+		Stream: stream,
+	}
+
+	if prompt.JSON {
+		request.Format = "json"
+	}
+
+	if prompt.MaxTokens > 0 {
+		request.Options = map[string]any{"num_predict": prompt.MaxTokens}
+	}
+
+	req, err := newJSONRequest(ctx, "POST", config.Endpoint+"/api/chat", request)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ollama can be run behind a proxy that requires authentication
+	if len(config.APIKey) > 0 {
+		req.Header.Set("Authorization", "Bearer "+config.APIKey)
+	}
+
+	return req, nil
+}
+
+func (p ollamaProvider) RunPrompt(ctx context.Context, client *http.Client, config ProviderConfig, prompt Prompt) (Response, error) {
+	req, err := p.newRequest(ctx, config, prompt, false)
+	if err != nil {
+		return Response{}, err
+	}
+
+	respBody, err := doRequest(client, req, ollamaError)
+	if err != nil {
+		return Response{}, err
+	}
+
+	var aiResp ollamaResponse
+	if err := json.Unmarshal(respBody, &aiResp); err != nil {
+		return Response{}, err
+	}
+
+	if len(aiResp.Error) > 0 {
+		return Response{}, newError(0, aiResp.Error)
+	}
+
+	if len(aiResp.Message.Content) == 0 {
+		return Response{}, errors.New("no response from AI")
+	}
+
+	return Response{
+		Text:  aiResp.Message.Content,
+		Model: aiResp.Model,
+		Usage: aiResp.usage(),
+	}, nil
+}
+
+func (p ollamaProvider) StreamPrompt(ctx context.Context, client *http.Client, config ProviderConfig, prompt Prompt, onChunk func(chunk string) error) (Response, error) {
+	req, err := p.newRequest(ctx, config, prompt, true)
+	if err != nil {
+		return Response{}, err
+	}
+
+	body, err := openResponse(client, req, ollamaError)
+	if err != nil {
+		return Response{}, err
+	}
+	defer body.Close()
+
+	var sb strings.Builder
+	var resp Response
+	err = readLines(body, func(line string) error {
+		var chunk ollamaResponse
+		if err := json.Unmarshal([]byte(line), &chunk); err != nil {
+			return err
+		}
+
+		if len(chunk.Error) > 0 {
+			return newError(0, chunk.Error)
+		}
+
+		if len(chunk.Message.Content) > 0 {
+			sb.WriteString(chunk.Message.Content)
+			if err := onChunk(chunk.Message.Content); err != nil {
+				return err
+			}
+		}
+
+		// The last chunk contains the usage
+		if chunk.Done {
+			resp.Model = chunk.Model
+			resp.Usage = chunk.usage()
+			return io.EOF
+		}
+		return nil
+	})
+	if err != nil {
+		return Response{}, err
+	}
+
+	if sb.Len() == 0 {
+		return Response{}, errors.New("no response from AI")
+	}
+
+	resp.Text = sb.String()
+	return resp, nil
+}
+
+func (p ollamaProvider) ListModels(ctx context.Context, client *http.Client, config ProviderConfig) ([]Model, error) {
+	req, err := newJSONRequest(ctx, "GET", config.Endpoint+"/api/tags", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := doRequest(client, req, ollamaError)
+	if err != nil {
+		return nil, err
+	}
+
+	var tags ollamaTags
+	if err := json.Unmarshal(res, &tags); err != nil {
+		return nil, err
+	}
+
+	if len(tags.Models) == 0 {
+		return nil, errors.New("no models found, pull a model with 'ollama pull <model>' first")
+	}
+
+	models := make([]Model, 0, len(tags.Models))
+	for _, model := range tags.Models {
+		models = append(models, Model{ID: model.Name, Name: model.Name})
+	}
+
+	return models, nil
+}
+
+func (p ollamaProvider) Embed(ctx context.Context, client *http.Client, config ProviderConfig, model string, texts []string) ([][]float64, Usage, error) {
+	req, err := newJSONRequest(ctx, "POST", config.Endpoint+"/api/embed", ollamaEmbedRequest{
+		Model: model,
+		Input: texts,
+	})
+	if err != nil {
+		return nil, Usage{}, err
+	}
+
+	if len(config.APIKey) > 0 {
+		req.Header.Set("Authorization", "Bearer "+config.APIKey)
+	}
+
+	respBody, err := doRequest(client, req, ollamaError)
+	if err != nil {
+		return nil, Usage{}, err
+	}
+
+	var resp ollamaEmbedResponse
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return nil, Usage{}, err
+	}
+
+	return resp.Embeddings, Usage{PromptTokens: resp.PromptEvalCount}, nil
+}
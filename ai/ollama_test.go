@@ -0,0 +1,134 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestOllamaRunPrompt(t *testing.T) {
+	server, req := newTestServer(t, 200, nil, `{"model": "llama3.2", "created_at": "2024-09-25T12:00:00Z", "message": {"role": "assistant", "content": "{\"name\": \"Goblin\"}"}, "done": true, "prompt_eval_count": 26, "eval_count": 10}`)
+
+	provider, _ := ProviderFor(ProviderOllama)
+	resp, err := provider.RunPrompt(context.Background(), server.Client(), ProviderConfig{Provider: ProviderOllama, Endpoint: server.URL}, Prompt{
+		Model:     "llama3.2",
+		MaxTokens: 100,
+		System:    "You are a game master.",
+		User:      "Create a monster.",
+		JSON:      true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if req.Method != "POST" || req.Path != "/api/chat" || req.Header.Get("Authorization") != "" {
+		t.Fatalf("unexpected request %s %s %v", req.Method, req.Path, req.Header)
+	}
+
+	expected := map[string]any{
+		"model":  "llama3.2",
+		"stream": false,
+		"format": "json",
+		"messages": []any{
+			map[string]any{"role": "system", "content": "You are a game master."},
+			map[string]any{"role": "user", "content": "Create a monster."},
+		},
+		"options": map[string]any{"num_predict": 100.0},
+	}
+	if !reflect.DeepEqual(req.Body, expected) {
+		t.Fatalf("expected body %v, got %v", expected, req.Body)
+	}
+
+	if resp.Text != `{"name": "Goblin"}` || resp.Model != "llama3.2" || resp.Usage != (Usage{PromptTokens: 26, CompletionTokens: 10}) {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+}
+
+func TestOllamaProxyAuthentication(t *testing.T) {
+	server, req := newTestServer(t, 200, nil, `{"message": {"role": "assistant", "content": "Hi"}, "done": true}`)
+
+	provider, _ := ProviderFor(ProviderOllama)
+	if _, err := provider.RunPrompt(context.Background(), server.Client(), ProviderConfig{Provider: ProviderOllama, Endpoint: server.URL, APIKey: "key"}, Prompt{Model: "llama3.2", User: "Hello"}); err != nil {
+		t.Fatal(err)
+	}
+	if req.Header.Get("Authorization") != "Bearer key" || req.Body["options"] != nil || req.Body["format"] != nil {
+		t.Fatalf("unexpected request %v %v", req.Header, req.Body)
+	}
+}
+
+func TestOllamaStreamPrompt(t *testing.T) {
+	body := `{"model": "llama3.2", "message": {"role": "assistant", "content": "Hello"}, "done": false}
+{"model": "llama3.2", "message": {"role": "assistant", "content": " adventurer"}, "done": false}
+
+{"model": "llama3.2", "message": {"role": "assistant", "content": ""}, "done": true, "prompt_eval_count": 26, "eval_count": 2}
+`
+	server, req := newTestServer(t, 200, map[string]string{"Content-Type": "application/x-ndjson"}, body)
+
+	var chunks []string
+	provider, _ := ProviderFor(ProviderOllama)
+	resp, err := provider.StreamPrompt(context.Background(), server.Client(), ProviderConfig{Provider: ProviderOllama, Endpoint: server.URL}, Prompt{Model: "llama3.2", User: "Hello"}, collectChunks(&chunks))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if req.Body["stream"] != true {
+		t.Fatalf("unexpected request %v", req.Body)
+	}
+
+	if !reflect.DeepEqual(chunks, []string{"Hello", " adventurer"}) {
+		t.Fatalf("unexpected chunks %v", chunks)
+	}
+	if resp.Text != "Hello adventurer" || resp.Model != "llama3.2" || resp.Usage != (Usage{PromptTokens: 26, CompletionTokens: 2}) {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+}
+
+func TestOllamaStreamError(t *testing.T) {
+	body := `{"model": "llama3.2", "message": {"role": "assistant", "content": "Hel"}, "done": false}
+{"error": "an error was encountered while running the model: unexpected EOF"}
+`
+	server, _ := newTestServer(t, 200, nil, body)
+
+	var chunks []string
+	provider, _ := ProviderFor(ProviderOllama)
+	_, err := provider.StreamPrompt(context.Background(), server.Client(), ProviderConfig{Provider: ProviderOllama, Endpoint: server.URL}, Prompt{Model: "llama3.2", User: "Hello"}, collectChunks(&chunks))
+
+	var aiErr *Error
+	if err == nil || !reflect.DeepEqual(chunks, []string{"Hel"}) {
+		t.Fatalf("expected an error after the first chunk, got %v %v", err, chunks)
+	}
+	if Kind(err) != ErrorUnknown || !errors.As(err, &aiErr) || aiErr.Message != "an error was encountered while running the model: unexpected EOF" {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func TestOllamaListModelsAndEmbed(t *testing.T) {
+	server, req := newTestServer(t, 200, nil, `{"models": [{"name": "llama3.2:latest", "model": "llama3.2:latest", "size": 2019393189}, {"name": "nomic-embed-text:latest"}]}`)
+
+	provider, _ := ProviderFor(ProviderOllama)
+	models, err := provider.ListModels(context.Background(), server.Client(), ProviderConfig{Provider: ProviderOllama, Endpoint: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Method != "GET" || req.Path != "/api/tags" || len(models) != 2 || models[0].ID != "llama3.2:latest" || models[1].Name != "nomic-embed-text:latest" {
+		t.Fatalf("unexpected models %+v from %s %s", models, req.Method, req.Path)
+	}
+
+	server, _ = newTestServer(t, 200, nil, `{"models": []}`)
+	if _, err := provider.ListModels(context.Background(), server.Client(), ProviderConfig{Provider: ProviderOllama, Endpoint: server.URL}); err == nil {
+		t.Fatal("expected an error without models")
+	}
+
+	server, req = newTestServer(t, 200, nil, `{"model": "nomic-embed-text", "embeddings": [[0.1, 0.2], [0.3, 0.4]], "prompt_eval_count": 6}`)
+	embeddings, usage, err := provider.(Embedder).Embed(context.Background(), server.Client(), ProviderConfig{Provider: ProviderOllama, Endpoint: server.URL}, "nomic-embed-text", []string{"a", "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Path != "/api/embed" || !reflect.DeepEqual(req.Body["input"], []any{"a", "b"}) {
+		t.Fatalf("unexpected request %s %v", req.Path, req.Body)
+	}
+	if !reflect.DeepEqual(embeddings, [][]float64{{0.1, 0.2}, {0.3, 0.4}}) || usage.PromptTokens != 6 {
+		t.Fatalf("unexpected embeddings %v %+v", embeddings, usage)
+	}
+}
@@ -0,0 +1,301 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// openAIProvider implements the OpenAI chat completions api that is also offered by
+// OpenRouter, llama.cpp and most other providers.
+type openAIProvider struct {
+	// referer adds the headers that OpenRouter uses to attribute the requests to the app.
+	referer bool
+	// requireKey fails early if no api key is set.
+	requireKey bool
+	// completionTokens sends the token limit as "max_completion_tokens", as newer OpenAI
+	// models don't accept "max_tokens" anymore.
+	completionTokens bool
+	// optionalModel allows prompts without model for servers that only serve a single model.
+	optionalModel bool
+	// streamUsage requests the usage at the end of streams, which not all compatible servers support.
+	streamUsage bool
+}
+
+type PromptRequest struct {
+	Model               string          `json:"model,omitempty"`
+	MaxTokens           int             `json:"max_tokens,omitempty"`
+	MaxCompletionTokens int             `json:"max_completion_tokens,omitempty"`
+	Messages            []Message       `json:"messages"`
+	Stream              bool            `json:"stream,omitempty"`
+	ResponseFormat      *ResponseFormat `json:"response_format,omitempty"`
+	StreamOptions       *StreamOptions  `json:"stream_options,omitempty"`
+}
+
+type StreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+type openAIUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+}
+
+func (u *openAIUsage) usage() Usage {
+	if u == nil {
+		return Usage{}
+	}
+	return Usage{PromptTokens: u.PromptTokens, CompletionTokens: u.CompletionTokens}
+}
+
+type ResponseFormat struct {
+	Type string `json:"type"`
+}
+
+type PromptResponse struct {
+	ID      string `json:"id"`
+	Model   string `json:"model"`
+	Choices []struct {
+		Message Message `json:"message"`
+	} `json:"choices"`
+	Usage *openAIUsage `json:"usage"`
+}
+
+type openAIStreamChunk struct {
+	Model   string `json:"model"`
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+	} `json:"choices"`
+	Usage *openAIUsage `json:"usage"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (p openAIProvider) newRequest(ctx context.Context, config ProviderConfig, prompt Prompt, stream bool) (*http.Request, error) {
+	if !p.optionalModel {
+		if err := checkModel(prompt); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.requireKey && config.APIKey == "" {
+		return nil, errorf(ErrorAuth, "API key not set")
+	}
+
+	request := PromptRequest{
+		Model: prompt.Model,
+		Messages: []Message{
+			{Role: "system", Content: prompt.System},
+			{Role: "user", Content: prompt.User},
+		},
+		Stream: stream,
+	}
+
+	if stream && p.streamUsage {
+		request.StreamOptions = &StreamOptions{IncludeUsage: true}
+	}
+
+	if prompt.JSON {
+		request.ResponseFormat = &ResponseFormat{Type: "json_object"}
+	}
+
+	if p.completionTokens {
+		request.MaxCompletionTokens = prompt.MaxTokens
+	} else {
+		request.MaxTokens = prompt.MaxTokens
+	}
+
+	req, err := newJSONRequest(ctx, "POST", config.Endpoint+"/v1/chat/completions", request)
+	if err != nil {
+		return nil, err
+	}
+
+	p.setHeaders(req, config.APIKey)
+
+	return req, nil
+}
+
+func (p openAIProvider) setHeaders(req *http.Request, apiKey string) {
+	if len(apiKey) > 0 {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+
+	if p.referer {
+		req.Header.Set("HTTP-Referer", "https://sales-and-dungeons.app/")
+		req.Header.Set("X-Title", "Sales & Dungeons")
+	}
+}
+
+func (p openAIProvider) RunPrompt(ctx context.Context, client *http.Client, config ProviderConfig, prompt Prompt) (Response, error) {
+	req, err := p.newRequest(ctx, config, prompt, false)
+	if err != nil {
+		return Response{}, err
+	}
+
+	respBody, err := doRequest(client, req, responseError)
+	if err != nil {
+		return Response{}, err
+	}
+
+	var aiResp PromptResponse
+	if err := json.Unmarshal(respBody, &aiResp); err != nil {
+		return Response{}, err
+	}
+
+	if len(aiResp.Choices) == 0 {
+		return Response{}, errors.New("no response from AI")
+	}
+
+	return Response{
+		Text:  aiResp.Choices[0].Message.Content,
+		Model: aiResp.Model,
+		Usage: aiResp.Usage.usage(),
+	}, nil
+}
+
+func (p openAIProvider) StreamPrompt(ctx context.Context, client *http.Client, config ProviderConfig, prompt Prompt, onChunk func(chunk string) error) (Response, error) {
+	req, err := p.newRequest(ctx, config, prompt, true)
+	if err != nil {
+		return Response{}, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	body, err := openResponse(client, req, responseError)
+	if err != nil {
+		return Response{}, err
+	}
+	defer body.Close()
+
+	var sb strings.Builder
+	var resp Response
+	err = readEvents(body, func(data string) error {
+		var chunk openAIStreamChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return err
+		}
+
+		if len(chunk.Model) > 0 {
+			resp.Model = chunk.Model
+		}
+
+		// The usage is sent with the last chunk
+		if chunk.Usage != nil {
+			resp.Usage = chunk.Usage.usage()
+		}
+
+		if chunk.Error != nil {
+			return newError(0, chunk.Error.Message)
+		}
+
+		if len(chunk.Choices) == 0 || len(chunk.Choices[0].Delta.Content) == 0 {
+			return nil
+		}
+
+		sb.WriteString(chunk.Choices[0].Delta.Content)
+		return onChunk(chunk.Choices[0].Delta.Content)
+	})
+	if err != nil {
+		return Response{}, err
+	}
+
+	if sb.Len() == 0 {
+		return Response{}, errors.New("no response from AI")
+	}
+
+	resp.Text = sb.String()
+	return resp, nil
+}
+
+func (p openAIProvider) ListModels(ctx context.Context, client *http.Client, config ProviderConfig) ([]Model, error) {
+	if p.requireKey && config.APIKey == "" {
+		return nil, errorf(ErrorAuth, "API key not set")
+	}
+
+	req, err := newJSONRequest(ctx, "GET", config.Endpoint+"/v1/models", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	p.setHeaders(req, config.APIKey)
+
+	res, err := doRequest(client, req, func(body []byte) error {
+		if strings.Contains(string(body), "invalid_api_key") {
+			return errors.New("invalid API key")
+		}
+		return responseError(body)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var models ModelsList
+	if err := json.Unmarshal(res, &models); err != nil {
+		return nil, err
+	}
+
+	if models.Data == nil {
+		return nil, errors.New("no models found")
+	}
+
+	return models.Data, nil
+}
+
+type openAIEmbeddingRequest struct {
+	Model string   `json:"model,omitempty"`
+	Input []string `json:"input"`
+}
+
+type openAIEmbeddingResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float64 `json:"embedding"`
+	} `json:"data"`
+	Usage *openAIUsage `json:"usage"`
+}
+
+func (p openAIProvider) Embed(ctx context.Context, client *http.Client, config ProviderConfig, model string, texts []string) ([][]float64, Usage, error) {
+	if p.requireKey && config.APIKey == "" {
+		return nil, Usage{}, errorf(ErrorAuth, "API key not set")
+	}
+
+	req, err := newJSONRequest(ctx, "POST", config.Endpoint+"/v1/embeddings", openAIEmbeddingRequest{
+		Model: model,
+		Input: texts,
+	})
+	if err != nil {
+		return nil, Usage{}, err
+	}
+
+	p.setHeaders(req, config.APIKey)
+
+	respBody, err := doRequest(client, req, responseError)
+	if err != nil {
+		return nil, Usage{}, err
+	}
+
+	var resp openAIEmbeddingResponse
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return nil, Usage{}, err
+	}
+
+	embeddings := make([][]float64, len(texts))
+	for _, data := range resp.Data {
+		if data.Index < 0 || data.Index >= len(embeddings) {
+			return nil, Usage{}, errors.New("invalid embedding index")
+		}
+		embeddings[data.Index] = data.Embedding
+	}
+
+	for i := range embeddings {
+		if embeddings[i] == nil {
+			return nil, Usage{}, errors.New("missing embedding")
+		}
+	}
+
+	return embeddings, resp.Usage.usage(), nil
+}
@@ -0,0 +1,166 @@
+package ai
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestOpenAIRunPrompt(t *testing.T) {
+	server, req := newTestServer(t, 200, nil, `{"id": "1", "model": "gpt-4o-2024-08-06", "choices": [{"message": {"role": "assistant", "content": "{\"name\": \"Goblin\"}"}}], "usage": {"prompt_tokens": 12, "completion_tokens": 5}}`)
+
+	provider, _ := ProviderFor(ProviderOpenAI)
+	resp, err := provider.RunPrompt(context.Background(), server.Client(), ProviderConfig{Provider: ProviderOpenAI, Endpoint: server.URL, APIKey: "key"}, Prompt{
+		Model:     "gpt-4o",
+		MaxTokens: 100,
+		System:    "You are a game master.",
+		User:      "Create a monster.",
+		JSON:      true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if req.Method != "POST" || req.Path != "/v1/chat/completions" || req.Header.Get("Authorization") != "Bearer key" || req.Header.Get("Content-Type") != "application/json" {
+		t.Fatalf("unexpected request %s %s %v", req.Method, req.Path, req.Header)
+	}
+
+	expected := map[string]any{
+		"model":                 "gpt-4o",
+		"max_completion_tokens": 100.0,
+		"messages": []any{
+			map[string]any{"role": "system", "content": "You are a game master."},
+			map[string]any{"role": "user", "content": "Create a monster."},
+		},
+		"response_format": map[string]any{"type": "json_object"},
+	}
+	if !reflect.DeepEqual(req.Body, expected) {
+		t.Fatalf("expected body %v, got %v", expected, req.Body)
+	}
+
+	if resp.Text != `{"name": "Goblin"}` || resp.Model != "gpt-4o-2024-08-06" || resp.Usage != (Usage{PromptTokens: 12, CompletionTokens: 5}) {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+}
+
+func TestOpenAICompatibleRequests(t *testing.T) {
+	server, req := newTestServer(t, 200, nil, `{"choices": [{"message": {"role": "assistant", "content": "Hi"}}]}`)
+
+	// OpenRouter is attributed to the app and uses max_tokens
+	provider, _ := ProviderFor(ProviderOpenRouter)
+	resp, err := provider.RunPrompt(context.Background(), server.Client(), ProviderConfig{Provider: ProviderOpenRouter, Endpoint: server.URL, APIKey: "key"}, Prompt{Model: "openai/gpt-4o", MaxTokens: 50, User: "Hello"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Header.Get("HTTP-Referer") == "" || req.Header.Get("X-Title") != "Sales & Dungeons" || req.Body["max_tokens"] != 50.0 || req.Body["max_completion_tokens"] != nil {
+		t.Fatalf("unexpected request %v %v", req.Header, req.Body)
+	}
+	if resp.Text != "Hi" || resp.Usage != (Usage{}) {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+
+	// llama.cpp serves a single model and needs no key
+	provider, _ = ProviderFor(ProviderLlamaCpp)
+	if _, err := provider.RunPrompt(context.Background(), server.Client(), ProviderConfig{Provider: ProviderLlamaCpp, Endpoint: server.URL}, Prompt{User: "Hello"}); err != nil {
+		t.Fatal(err)
+	}
+	if req.Header.Get("Authorization") != "" || req.Body["model"] != nil {
+		t.Fatalf("unexpected request %v %v", req.Header, req.Body)
+	}
+}
+
+func TestOpenAIRequiresKeyAndModel(t *testing.T) {
+	server, req := newTestServer(t, 200, nil, `{}`)
+
+	provider, _ := ProviderFor(ProviderOpenAI)
+	_, err := provider.RunPrompt(context.Background(), server.Client(), ProviderConfig{Provider: ProviderOpenAI, Endpoint: server.URL}, Prompt{Model: "gpt-4o", User: "Hello"})
+	if Kind(err) != ErrorAuth {
+		t.Fatalf("expected auth error, got %v", err)
+	}
+
+	_, err = provider.RunPrompt(context.Background(), server.Client(), ProviderConfig{Provider: ProviderOpenAI, Endpoint: server.URL, APIKey: "key"}, Prompt{User: "Hello"})
+	if err == nil {
+		t.Fatal("expected an error without model")
+	}
+
+	if len(req.Path) > 0 {
+		t.Fatalf("expected no request, got %s", req.Path)
+	}
+}
+
+func TestOpenAIStreamPrompt(t *testing.T) {
+	body := ": OPENROUTER PROCESSING\n\n" + sse(
+		`{"model": "gpt-4o-2024-08-06", "choices": [{"delta": {"role": "assistant", "content": ""}}]}`,
+		`{"model": "gpt-4o-2024-08-06", "choices": [{"delta": {"content": "Hello"}}]}`,
+		`{"model": "gpt-4o-2024-08-06", "choices": [{"delta": {"content": " adventurer"}}]}`,
+		`{"model": "gpt-4o-2024-08-06", "choices": [], "usage": {"prompt_tokens": 9, "completion_tokens": 2}}`,
+		`[DONE]`,
+	)
+	server, req := newTestServer(t, 200, map[string]string{"Content-Type": "text/event-stream"}, body)
+
+	var chunks []string
+	provider, _ := ProviderFor(ProviderOpenAI)
+	resp, err := provider.StreamPrompt(context.Background(), server.Client(), ProviderConfig{Provider: ProviderOpenAI, Endpoint: server.URL, APIKey: "key"}, Prompt{Model: "gpt-4o", User: "Hello"}, collectChunks(&chunks))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if req.Body["stream"] != true || !reflect.DeepEqual(req.Body["stream_options"], map[string]any{"include_usage": true}) || req.Header.Get("Accept") != "text/event-stream" {
+		t.Fatalf("unexpected request %v %v", req.Header, req.Body)
+	}
+
+	if !reflect.DeepEqual(chunks, []string{"Hello", " adventurer"}) {
+		t.Fatalf("unexpected chunks %v", chunks)
+	}
+	if resp.Text != "Hello adventurer" || resp.Model != "gpt-4o-2024-08-06" || resp.Usage != (Usage{PromptTokens: 9, CompletionTokens: 2}) {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+}
+
+func TestOpenAIStreamError(t *testing.T) {
+	body := sse(
+		`{"choices": [{"delta": {"content": "Hel"}}]}`,
+		`{"error": {"message": "Provider returned error: overloaded"}}`,
+	)
+	server, _ := newTestServer(t, 200, nil, body)
+
+	var chunks []string
+	provider, _ := ProviderFor(ProviderOpenRouter)
+	_, err := provider.StreamPrompt(context.Background(), server.Client(), ProviderConfig{Provider: ProviderOpenRouter, Endpoint: server.URL, APIKey: "key"}, Prompt{Model: "model", User: "Hello"}, collectChunks(&chunks))
+	if Kind(err) != ErrorServer {
+		t.Fatalf("expected server error, got %v", err)
+	}
+	if !reflect.DeepEqual(chunks, []string{"Hel"}) {
+		t.Fatalf("unexpected chunks %v", chunks)
+	}
+}
+
+func TestOpenAIListModelsAndEmbed(t *testing.T) {
+	server, req := newTestServer(t, 200, nil, `{"data": [{"id": "gpt-4o", "name": "GPT-4o", "context_length": 128000, "pricing": {"prompt": "0.0000025", "completion": "0.00001"}}]}`)
+
+	provider, _ := ProviderFor(ProviderOpenRouter)
+	models, err := provider.ListModels(context.Background(), server.Client(), ProviderConfig{Provider: ProviderOpenRouter, Endpoint: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Method != "GET" || req.Path != "/v1/models" || len(models) != 1 || models[0].ID != "gpt-4o" || models[0].ContextLength != 128000 {
+		t.Fatalf("unexpected models %+v from %s %s", models, req.Method, req.Path)
+	}
+	if cost, ok := models[0].Cost(Usage{PromptTokens: 1000, CompletionTokens: 100}); !ok || cost < 0.00349 || cost > 0.00351 {
+		t.Fatalf("unexpected cost %v", cost)
+	}
+
+	// The embeddings can be returned in any order
+	server, req = newTestServer(t, 200, nil, `{"data": [{"index": 1, "embedding": [0, 1]}, {"index": 0, "embedding": [1, 0]}], "usage": {"prompt_tokens": 4}}`)
+
+	embeddings, usage, err := providers[ProviderOpenAI].(Embedder).Embed(context.Background(), server.Client(), ProviderConfig{Provider: ProviderOpenAI, Endpoint: server.URL, APIKey: "key"}, "text-embedding-3-small", []string{"a", "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Path != "/v1/embeddings" || !reflect.DeepEqual(req.Body["input"], []any{"a", "b"}) || req.Body["model"] != "text-embedding-3-small" {
+		t.Fatalf("unexpected request %s %v", req.Path, req.Body)
+	}
+	if !reflect.DeepEqual(embeddings, [][]float64{{1, 0}, {0, 1}}) || usage.PromptTokens != 4 {
+		t.Fatalf("unexpected embeddings %v %+v", embeddings, usage)
+	}
+}
@@ -0,0 +1,80 @@
+package ai
+
+import (
+	"strings"
+)
+
+// listPrice is the price in USD per million tokens.
+type listPrice struct {
+	prompt     float64
+	completion float64
+}
+
+// listPrices are the published prices of the providers that don't include the pricing in their
+// model list. The keys are prefixes of the model ids, so that dated versions like
+// "gpt-4o-2024-08-06" use the price of "gpt-4o". The prices can be outdated, the pricing of
+// the model list is always preferred.
+var listPrices = map[string]map[string]listPrice{
+	ProviderOpenAI: {
+		"gpt-5":                  {1.25, 10},
+		"gpt-5-mini":             {0.25, 2},
+		"gpt-5-nano":             {0.05, 0.4},
+		"gpt-4.1":                {2, 8},
+		"gpt-4.1-mini":           {0.4, 1.6},
+		"gpt-4.1-nano":           {0.1, 0.4},
+		"gpt-4o":                 {2.5, 10},
+		"gpt-4o-mini":            {0.15, 0.6},
+		"gpt-4-turbo":            {10, 30},
+		"gpt-4":                  {30, 60},
+		"gpt-3.5-turbo":          {0.5, 1.5},
+		"o1":                     {15, 60},
+		"o1-mini":                {1.1, 4.4},
+		"o3":                     {2, 8},
+		"o3-mini":                {1.1, 4.4},
+		"o4-mini":                {1.1, 4.4},
+		"text-embedding-3-small": {0.02, 0},
+		"text-embedding-3-large": {0.13, 0},
+		"text-embedding-ada-002": {0.1, 0},
+	},
+	ProviderAnthropic: {
+		"claude-opus-4-5":   {5, 25},
+		"claude-opus-4-1":   {15, 75},
+		"claude-opus-4":     {15, 75},
+		"claude-sonnet-4-5": {3, 15},
+		"claude-sonnet-4":   {3, 15},
+		"claude-haiku-4-5":  {1, 5},
+		"claude-3-7-sonnet": {3, 15},
+		"claude-3-5-sonnet": {3, 15},
+		"claude-3-5-haiku":  {0.8, 4},
+		"claude-3-opus":     {15, 75},
+		"claude-3-sonnet":   {3, 15},
+		"claude-3-haiku":    {0.25, 1.25},
+	},
+}
+
+// ListPrice returns the model with the published pricing of the provider if the provider doesn't
+// report the pricing itself. False is returned if the price of the model is unknown.
+func ListPrice(provider string, model string) (Model, bool) {
+	var match string
+	for prefix := range listPrices[provider] {
+		if (model == prefix || strings.HasPrefix(model, prefix+"-")) && len(prefix) > len(match) {
+			match = prefix
+		}
+	}
+
+	if len(match) == 0 {
+		return Model{}, false
+	}
+
+	price := listPrices[provider][match]
+	res := Model{ID: model}
+	res.Pricing = &struct {
+		Prompt     any `json:"prompt"`
+		Completion any `json:"completion"`
+	}{
+		Prompt:     price.prompt / 1_000_000,
+		Completion: price.completion / 1_000_000,
+	}
+
+	return res, true
+}
@@ -0,0 +1,44 @@
+package ai
+
+import (
+	"math"
+	"testing"
+)
+
+func TestListPrice(t *testing.T) {
+	usage := Usage{PromptTokens: 1_000_000, CompletionTokens: 1_000_000}
+
+	cases := []struct {
+		provider string
+		model    string
+		cost     float64
+		ok       bool
+	}{
+		{provider: ProviderOpenAI, model: "gpt-4o", cost: 12.5, ok: true},
+		{provider: ProviderOpenAI, model: "gpt-4o-2024-08-06", cost: 12.5, ok: true},
+		{provider: ProviderOpenAI, model: "gpt-4o-mini-2024-07-18", cost: 0.75, ok: true},
+		{provider: ProviderOpenAI, model: "text-embedding-3-small", cost: 0.02, ok: true},
+		{provider: ProviderOpenAI, model: "gpt-4.5-preview", ok: false},
+		{provider: ProviderOpenAI, model: "gpt-4oo", ok: false},
+		{provider: ProviderAnthropic, model: "claude-opus-4-5-20251101", cost: 30, ok: true},
+		{provider: ProviderAnthropic, model: "claude-opus-4-20250514", cost: 90, ok: true},
+		{provider: ProviderAnthropic, model: "claude-3-5-haiku-latest", cost: 4.8, ok: true},
+		{provider: ProviderOpenRouter, model: "gpt-4o", ok: false},
+		{provider: ProviderOllama, model: "llama3", ok: false},
+	}
+
+	for _, c := range cases {
+		model, ok := ListPrice(c.provider, c.model)
+		if ok != c.ok {
+			t.Errorf("%s %s: expected %v, got %v", c.provider, c.model, c.ok, ok)
+			continue
+		}
+		if !ok {
+			continue
+		}
+
+		if cost, priced := model.Cost(usage); !priced || math.Abs(cost-c.cost) > 1e-9 {
+			t.Errorf("%s %s: expected $%f, got $%f (%v)", c.provider, c.model, c.cost, cost, priced)
+		}
+	}
+}
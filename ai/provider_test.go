@@ -0,0 +1,208 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// capturedRequest is the last request that the test server received.
+type capturedRequest struct {
+	Method string
+	Path   string
+	Header http.Header
+	Body   map[string]any
+}
+
+// newTestServer starts a server that records the requests and answers every request with
+// the status, headers and body.
+func newTestServer(t *testing.T, status int, header map[string]string, body string) (*httptest.Server, *capturedRequest) {
+	captured := &capturedRequest{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		captured.Method = r.Method
+		captured.Path = r.URL.RequestURI()
+		captured.Header = r.Header.Clone()
+		captured.Body = nil
+
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+		}
+		if len(data) > 0 {
+			if err := json.Unmarshal(data, &captured.Body); err != nil {
+				t.Errorf("request body is not json: %s", data)
+			}
+		}
+
+		for k, v := range header {
+			w.Header().Set(k, v)
+		}
+		w.WriteHeader(status)
+		_, _ = io.WriteString(w, body)
+	}))
+	t.Cleanup(server.Close)
+	return server, captured
+}
+
+// sse formats the data as server-sent events.
+func sse(data ...string) string {
+	var sb strings.Builder
+	for _, d := range data {
+		sb.WriteString("data: ")
+		sb.WriteString(d)
+		sb.WriteString("\n\n")
+	}
+	return sb.String()
+}
+
+// collectChunks returns an onChunk function that appends the chunks.
+func collectChunks(chunks *[]string) func(chunk string) error {
+	return func(chunk string) error {
+		*chunks = append(*chunks, chunk)
+		return nil
+	}
+}
+
+func TestProviderErrorKinds(t *testing.T) {
+	cases := []struct {
+		name       string
+		provider   string
+		status     int
+		header     map[string]string
+		body       string
+		kind       ErrorKind
+		message    string
+		retryAfter time.Duration
+	}{
+		{name: "openai invalid key", provider: ProviderOpenAI, status: 401, body: `{"error": {"message": "Incorrect API key provided: sk-1234.", "type": "invalid_request_error", "code": "invalid_api_key"}}`, kind: ErrorAuth, message: "Incorrect API key provided: sk-1234."},
+		{name: "openai quota", provider: ProviderOpenAI, status: 429, body: `{"error": {"message": "You exceeded your current quota, please check your plan and billing details.", "code": "insufficient_quota"}}`, kind: ErrorQuota},
+		{name: "openai rate limit", provider: ProviderOpenAI, status: 429, header: map[string]string{"Retry-After": "3"}, body: `{"error": {"message": "Rate limit reached for requests"}}`, kind: ErrorRateLimit, retryAfter: 3 * time.Second},
+		{name: "openai context length", provider: ProviderOpenAI, status: 400, body: `{"error": {"message": "This model's maximum context length is 8192 tokens. However, your messages resulted in 9000 tokens."}}`, kind: ErrorContextLength},
+		{name: "openai unknown model", provider: ProviderOpenAI, status: 404, body: `{"error": {"message": "The model 'gpt-9' does not exist or you do not have access to it.", "code": "model_not_found"}}`, kind: ErrorModelNotFound},
+		{name: "openrouter credits", provider: ProviderOpenRouter, status: 402, body: `{"error": {"message": "Insufficient credits", "code": 402}}`, kind: ErrorQuota},
+		{name: "openrouter nested upstream error", provider: ProviderOpenRouter, status: 400, body: `{"error": {"message": "{\"error\": {\"message\": \"prompt is too long\"}}"}}`, kind: ErrorContextLength, message: "prompt is too long"},
+		{name: "openrouter cloudflare", provider: ProviderOpenRouter, status: 502, body: `error code: 502`, kind: ErrorServer, message: "error code: 502"},
+		{name: "llama.cpp plain error", provider: ProviderLlamaCpp, status: 503, body: `Service Unavailable`, kind: ErrorServer, message: "request failed with status 503"},
+		{name: "anthropic invalid key", provider: ProviderAnthropic, status: 401, body: `{"type": "error", "error": {"type": "authentication_error", "message": "invalid x-api-key"}}`, kind: ErrorAuth},
+		{name: "anthropic overloaded", provider: ProviderAnthropic, status: 529, body: `{"type": "error", "error": {"type": "overloaded_error", "message": "Overloaded"}}`, kind: ErrorServer, message: "Overloaded"},
+		{name: "anthropic prompt too long", provider: ProviderAnthropic, status: 400, body: `{"type": "error", "error": {"type": "invalid_request_error", "message": "prompt is too long: 250000 tokens > 200000 maximum"}}`, kind: ErrorContextLength},
+		{name: "anthropic rate limit", provider: ProviderAnthropic, status: 429, header: map[string]string{"Retry-After": "12"}, body: `{"type": "error", "error": {"type": "rate_limit_error", "message": "Number of requests has exceeded your rate limit"}}`, kind: ErrorRateLimit, retryAfter: 12 * time.Second},
+		{name: "ollama unknown model", provider: ProviderOllama, status: 404, body: `{"error": "model \"llama9\" not found, try pulling it first"}`, kind: ErrorModelNotFound, message: `model "llama9" not found, try pulling it first`},
+		{name: "ollama server error", provider: ProviderOllama, status: 500, body: `{"error": "llama runner process has terminated"}`, kind: ErrorServer},
+		{name: "ollama context length", provider: ProviderOllama, status: 400, body: `{"error": "input exceeds the context size"}`, kind: ErrorContextLength},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			server, _ := newTestServer(t, c.status, c.header, c.body)
+			provider, err := ProviderFor(c.provider)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			config := ProviderConfig{Provider: c.provider, Endpoint: server.URL, APIKey: "key"}
+			prompt := Prompt{Model: "model", User: "Hello"}
+
+			for _, stream := range []bool{false, true} {
+				if stream {
+					_, err = provider.StreamPrompt(context.Background(), server.Client(), config, prompt, func(string) error { return nil })
+				} else {
+					_, err = provider.RunPrompt(context.Background(), server.Client(), config, prompt)
+				}
+
+				var aiErr *Error
+				if !errors.As(err, &aiErr) {
+					t.Fatalf("stream %v: expected *Error, got %v", stream, err)
+				}
+				if aiErr.Kind != c.kind || aiErr.Status != c.status || aiErr.RetryAfter != c.retryAfter {
+					t.Fatalf("stream %v: expected %s (%d, %s), got %s (%d, %s): %s", stream, c.kind, c.status, c.retryAfter, aiErr.Kind, aiErr.Status, aiErr.RetryAfter, aiErr)
+				}
+				if len(c.message) > 0 && aiErr.Message != c.message {
+					t.Fatalf("stream %v: expected message '%s', got '%s'", stream, c.message, aiErr.Message)
+				}
+			}
+		})
+	}
+}
+
+func TestProviderUnavailable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	endpoint := server.URL
+	server.Close()
+
+	for _, name := range []string{ProviderOpenAI, ProviderAnthropic, ProviderOllama} {
+		provider, _ := ProviderFor(name)
+		_, err := provider.RunPrompt(context.Background(), http.DefaultClient, ProviderConfig{Provider: name, Endpoint: endpoint, APIKey: "key"}, Prompt{Model: "model", User: "Hello"})
+		if Kind(err) != ErrorUnavailable {
+			t.Fatalf("%s: expected unavailable, got %v", name, err)
+		}
+	}
+}
+
+func TestProviderTimeout(t *testing.T) {
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-done
+	}))
+	defer server.Close()
+	defer close(done)
+
+	client := NewClient(server.Client())
+	_, err := client.RunPromptContext(context.Background(), ProviderConfig{Provider: ProviderOllama, Endpoint: server.URL, Timeout: time.Millisecond * 50}, Prompt{Model: "model", User: "Hello"})
+	if Kind(err) != ErrorTimeout {
+		t.Fatalf("expected timeout, got %v", err)
+	}
+}
+
+func TestClientRetriesRateLimits(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 3 {
+			w.Header().Set("Retry-After", "0.01")
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = io.WriteString(w, `{"error": {"message": "Rate limit reached"}}`)
+			return
+		}
+		_, _ = io.WriteString(w, `{"model": "model", "choices": [{"message": {"role": "assistant", "content": "Hi"}}]}`)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.Client())
+	text, err := client.RunPromptContext(context.Background(), ProviderConfig{Provider: ProviderOpenAI, Endpoint: server.URL, APIKey: "key"}, Prompt{Model: "model", User: "Hello"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text != "Hi" || attempts != 3 {
+		t.Fatalf("expected 'Hi' after 3 attempts, got '%s' after %d", text, attempts)
+	}
+}
+
+func TestClientStopsRetryingWhenCancelled(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.Header().Set("Retry-After", "10")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(time.Millisecond*50, cancel)
+
+	start := time.Now()
+	client := NewClient(server.Client())
+	_, err := client.RunPromptContext(ctx, ProviderConfig{Provider: ProviderOpenAI, Endpoint: server.URL, APIKey: "key"}, Prompt{Model: "model", User: "Hello"})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected cancellation, got %v", err)
+	}
+	if attempts != 1 || time.Since(start) > time.Second*5 {
+		t.Fatalf("expected a single attempt, got %d in %s", attempts, time.Since(start))
+	}
+}
@@ -0,0 +1,111 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// newJSONRequest creates a request with the value as json body. If value is nil no body is sent.
+func newJSONRequest(ctx context.Context, method string, url string, value any) (*http.Request, error) {
+	var body io.Reader
+	if value != nil {
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewBuffer(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, err
+	}
+
+	if value != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	return req, nil
+}
+
+// openResponse sends the request and returns the body of a successful response. The body of
+// a failed response is converted to an error by parseError.
+func openResponse(client *http.Client, req *http.Request, parseError func(body []byte) error) (io.ReadCloser, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, requestError(req.Context(), err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+
+		return nil, statusError(resp.StatusCode, resp.Header, body, parseError)
+	}
+
+	return resp.Body, nil
+}
+
+// doRequest sends the request and returns the complete body of a successful response.
+func doRequest(client *http.Client, req *http.Request, parseError func(body []byte) error) ([]byte, error) {
+	body, err := openResponse(client, req, parseError)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	return io.ReadAll(body)
+}
+
+// statusError converts a failed response to an *Error.
+func statusError(status int, header http.Header, body []byte, parseError func(body []byte) error) error {
+	message := fmt.Sprintf("request failed with status %d", status)
+
+	// Cloudflare errors of OpenRouter
+	if strings.HasPrefix(string(body), "error code:") {
+		message = string(body)
+	} else if err := parseError(body); err != nil {
+		message = err.Error()
+	}
+
+	aiErr := newError(status, message)
+	aiErr.RetryAfter = parseRetryAfter(header)
+	return aiErr
+}
+
+// responseError parses errors in the format {"error": {"message": "..."}} that OpenAI, Anthropic
+// and most compatible apis use. OpenRouter sometimes nests the error of the upstream provider as
+// json in the message. Nil is returned if the body is not in the format.
+func responseError(respBody []byte) error {
+	var errorResponse struct {
+		Error *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(respBody, &errorResponse); err != nil || errorResponse.Error == nil {
+		return nil
+	}
+
+	errMsg := errorResponse.Error.Message
+	if len(errMsg) > 0 && errMsg[0] == '{' {
+		if err := json.Unmarshal([]byte(errMsg), &errorResponse); err == nil && errorResponse.Error != nil && len(errorResponse.Error.Message) > 0 {
+			errMsg = errorResponse.Error.Message
+		}
+	}
+
+	if len(errMsg) == 0 {
+		return errors.New("AI request failed")
+	}
+
+	return errors.New(errMsg)
+}
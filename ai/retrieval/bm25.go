@@ -0,0 +1,99 @@
+package retrieval
+
+import (
+	"math"
+)
+
+const (
+	// bm25K1 controls how fast the score saturates with the frequency of a term.
+	bm25K1 = 1.2
+	// bm25B controls how much long documents are penalized.
+	bm25B = 0.75
+)
+
+// Index is a BM25 full text index over documents.
+type Index struct {
+	docs      []Document
+	terms     []map[string]int
+	lengths   []int
+	avgLength float64
+	// frequency is the number of documents that contain a term.
+	frequency map[string]int
+}
+
+// NewIndex indexes the documents.
+func NewIndex(docs []Document) *Index {
+	index := &Index{
+		docs:      docs,
+		terms:     make([]map[string]int, len(docs)),
+		lengths:   make([]int, len(docs)),
+		frequency: map[string]int{},
+	}
+
+	total := 0
+	for i := range docs {
+		tokens := Tokenize(docs[i].Text)
+
+		terms := map[string]int{}
+		for _, token := range tokens {
+			terms[token]++
+		}
+
+		for term := range terms {
+			index.frequency[term]++
+		}
+
+		index.terms[i] = terms
+		index.lengths[i] = len(tokens)
+		total += len(tokens)
+	}
+
+	if len(docs) > 0 {
+		index.avgLength = float64(total) / float64(len(docs))
+	}
+
+	return index
+}
+
+// Len returns the number of indexed documents.
+func (index *Index) Len() int {
+	return len(index.docs)
+}
+
+// Search returns the documents that are most relevant for the query. Documents that don't
+// contain any term of the query are not returned.
+func (index *Index) Search(query string, limit int) []Result {
+	queryTerms := map[string]bool{}
+	for _, token := range Tokenize(query) {
+		queryTerms[token] = true
+	}
+
+	n := float64(len(index.docs))
+
+	var results []Result
+	for i := range index.docs {
+		score := 0.0
+		for term := range queryTerms {
+			tf := float64(index.terms[i][term])
+			if tf == 0 {
+				continue
+			}
+
+			df := float64(index.frequency[term])
+			idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+
+			norm := 1.0
+			if index.avgLength > 0 {
+				norm = 1 - bm25B + bm25B*float64(index.lengths[i])/index.avgLength
+			}
+
+			score += idf * tf * (bm25K1 + 1) / (tf + bm25K1*norm)
+		}
+
+		if score > 0 {
+			results = append(results, Result{Document: index.docs[i], Score: score})
+		}
+	}
+
+	return top(results, limit)
+}
@@ -0,0 +1,177 @@
+// Package retrieval finds the entries of data sources that are relevant for a query, so that
+// they can be added to AI prompts.
+package retrieval
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/BigJk/snd"
+)
+
+// Document is a searchable entry of a data source.
+type Document struct {
+	Source string    `json:"source"`
+	Entry  snd.Entry `json:"entry"`
+	// Text is the searchable text of the entry.
+	Text string `json:"-"`
+}
+
+// Result is a document found for a query. Higher scores are more relevant.
+type Result struct {
+	Document
+	Score float64 `json:"score"`
+}
+
+// NewDocument creates the document of the entry.
+func NewDocument(source string, entry snd.Entry) Document {
+	return Document{
+		Source: source,
+		Entry:  entry,
+		Text:   EntryText(entry),
+	}
+}
+
+// EntryText converts the entry to plain text containing the name and all values of the data.
+// Keys are included, so that a query for e.g. "rarity" finds entries that have a rarity.
+func EntryText(entry snd.Entry) string {
+	var sb strings.Builder
+	sb.WriteString(entry.Name)
+	writeValue(&sb, "", entry.Data)
+	return sb.String()
+}
+
+func writeValue(sb *strings.Builder, key string, value any) {
+	switch val := value.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			writeValue(sb, k, val[k])
+		}
+	case []any:
+		for i := range val {
+			writeValue(sb, key, val[i])
+		}
+	case nil:
+	default:
+		sb.WriteString("\n")
+		if len(key) > 0 {
+			sb.WriteString(key)
+			sb.WriteString(": ")
+		}
+		sb.WriteString(fmt.Sprint(val))
+	}
+}
+
+// stopWords are common english words that don't help to find relevant entries.
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true, "by": true,
+	"for": true, "from": true, "has": true, "in": true, "is": true, "it": true, "its": true, "of": true,
+	"on": true, "or": true, "that": true, "the": true, "to": true, "was": true, "were": true, "with": true,
+}
+
+// Tokenize splits the text into lower case words without stop words.
+func Tokenize(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	tokens := words[:0]
+	for _, word := range words {
+		if len(word) < 2 || stopWords[word] {
+			continue
+		}
+		tokens = append(tokens, word)
+	}
+
+	return tokens
+}
+
+// Cosine returns the cosine similarity of two vectors.
+func Cosine(a []float64, b []float64) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+
+	var dot, normA, normB float64
+	for i := range a {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// RankByEmbedding returns the documents that are most similar to the query embedding. The embeddings
+// of the documents need to have the same order as the documents.
+func RankByEmbedding(docs []Document, embeddings [][]float64, query []float64, limit int) []Result {
+	results := make([]Result, 0, len(docs))
+	for i := range docs {
+		if i >= len(embeddings) || embeddings[i] == nil {
+			continue
+		}
+
+		results = append(results, Result{Document: docs[i], Score: Cosine(embeddings[i], query)})
+	}
+
+	return top(results, limit)
+}
+
+// rrfK dampens the influence of the top ranks in the reciprocal rank fusion.
+const rrfK = 60
+
+// Fuse combines multiple rankings of the same documents with reciprocal rank fusion. Documents that
+// rank well in multiple rankings are preferred. The scores of the rankings don't need to be comparable.
+func Fuse(limit int, rankings ...[]Result) []Result {
+	scores := map[string]*Result{}
+	var order []string
+
+	for _, ranking := range rankings {
+		for rank := range ranking {
+			key := ranking[rank].Source + "\x00" + ranking[rank].Entry.ID
+
+			res, ok := scores[key]
+			if !ok {
+				res = &Result{Document: ranking[rank].Document}
+				scores[key] = res
+				order = append(order, key)
+			}
+
+			res.Score += 1.0 / float64(rrfK+rank+1)
+		}
+	}
+
+	results := make([]Result, 0, len(order))
+	for _, key := range order {
+		results = append(results, *scores[key])
+	}
+
+	return top(results, limit)
+}
+
+// top sorts the results by score and returns the best ones. If limit is zero or negative all
+// results are returned.
+func top(results []Result, limit int) []Result {
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+
+	return results
+}
@@ -0,0 +1,156 @@
+package retrieval
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/BigJk/snd"
+)
+
+func resultIds(results []Result) []string {
+	var ids []string
+	for i := range results {
+		ids = append(ids, results[i].Entry.ID)
+	}
+	return ids
+}
+
+func TestTokenize(t *testing.T) {
+	cases := []struct {
+		text string
+		want []string
+	}{
+		{text: "The Goblin's Scimitar, and a Shortbow!", want: []string{"goblin", "scimitar", "shortbow"}},
+		{text: "hp: 7 (2d6)", want: []string{"hp", "2d6"}},
+		{text: "Ärger über Öl", want: []string{"ärger", "über", "öl"}},
+		{text: "a the of", want: []string{}},
+	}
+
+	for _, c := range cases {
+		if res := Tokenize(c.text); !reflect.DeepEqual(res, c.want) {
+			t.Errorf("%s: expected %v, got %v", c.text, c.want, res)
+		}
+	}
+}
+
+func TestEntryText(t *testing.T) {
+	entry := snd.Entry{Name: "Goblin", Data: map[string]any{
+		"rarity": "common",
+		"hp":     7.0,
+		"tags":   []any{"small", "humanoid"},
+		"stats":  map[string]any{"str": 8.0},
+		"empty":  nil,
+	}}
+
+	want := "Goblin\nhp: 7\nrarity: common\nstr: 8\ntags: small\ntags: humanoid"
+	if text := EntryText(entry); text != want {
+		t.Fatalf("expected %q, got %q", want, text)
+	}
+}
+
+func testDocuments() []Document {
+	return []Document{
+		NewDocument("ds:monsters", snd.Entry{ID: "goblin", Name: "Goblin", Data: map[string]any{"description": "A small green humanoid that lives in caves."}}),
+		NewDocument("ds:monsters", snd.Entry{ID: "orc", Name: "Orc", Data: map[string]any{"description": "A large humanoid raider."}}),
+		NewDocument("ds:monsters", snd.Entry{ID: "dragon", Name: "Red Dragon", Data: map[string]any{"description": "A huge dragon that breathes fire. Dragon hoards are guarded by the dragon."}}),
+		NewDocument("ds:items", snd.Entry{ID: "goblin", Name: "Goblin Ear", Data: map[string]any{"description": "Proof of a slain goblin."}}),
+	}
+}
+
+func TestSearch(t *testing.T) {
+	index := NewIndex(testDocuments())
+	if index.Len() != 4 {
+		t.Fatalf("expected 4 documents, got %d", index.Len())
+	}
+
+	cases := []struct {
+		query string
+		limit int
+		want  []string
+	}{
+		{query: "dragon", limit: 10, want: []string{"dragon"}},
+		{query: "humanoid", limit: 10, want: []string{"orc", "goblin"}},
+		{query: "green humanoid", limit: 10, want: []string{"goblin", "orc"}},
+		{query: "goblin", limit: 1, want: []string{"goblin"}},
+		{query: "the a of", limit: 10, want: nil},
+		{query: "unicorn", limit: 10, want: nil},
+	}
+
+	for _, c := range cases {
+		if res := index.Search(c.query, c.limit); !reflect.DeepEqual(resultIds(res), c.want) {
+			t.Errorf("%s: expected %v, got %v", c.query, c.want, resultIds(res))
+		}
+	}
+
+	// Both documents with the id "goblin" are found, the shorter document ranks higher
+	res := index.Search("goblin", 10)
+	if len(res) != 2 || res[0].Source != "ds:items" || res[0].Score <= res[1].Score {
+		t.Fatalf("unexpected results %+v", res)
+	}
+
+	if res := NewIndex(nil).Search("goblin", 10); len(res) != 0 {
+		t.Fatalf("expected no results for an empty index, got %+v", res)
+	}
+}
+
+func TestCosine(t *testing.T) {
+	cases := []struct {
+		a, b []float64
+		want float64
+	}{
+		{a: []float64{1, 0}, b: []float64{1, 0}, want: 1},
+		{a: []float64{1, 0}, b: []float64{0, 1}, want: 0},
+		{a: []float64{1, 1}, b: []float64{-1, -1}, want: -1},
+		{a: []float64{1, 2}, b: []float64{1}, want: 0},
+		{a: []float64{0, 0}, b: []float64{1, 1}, want: 0},
+		{a: nil, b: nil, want: 0},
+	}
+
+	for _, c := range cases {
+		if res := Cosine(c.a, c.b); math.Abs(res-c.want) > 1e-9 {
+			t.Errorf("%v %v: expected %f, got %f", c.a, c.b, c.want, res)
+		}
+	}
+}
+
+func TestRankByEmbedding(t *testing.T) {
+	docs := testDocuments()
+	embeddings := [][]float64{{1, 0}, {0.5, 0.5}, nil, {0, 1}}
+
+	res := RankByEmbedding(docs, embeddings, []float64{0, 1}, 2)
+	if ids := resultIds(res); !reflect.DeepEqual(ids, []string{"goblin", "orc"}) || res[0].Source != "ds:items" {
+		t.Fatalf("unexpected ranking %+v", res)
+	}
+
+	// Documents without embedding are skipped
+	if res := RankByEmbedding(docs, embeddings[:1], []float64{1, 0}, 0); len(res) != 1 {
+		t.Fatalf("expected a single result, got %+v", res)
+	}
+}
+
+func TestFuse(t *testing.T) {
+	docs := testDocuments()
+	result := func(i int, score float64) Result {
+		return Result{Document: docs[i], Score: score}
+	}
+
+	// The orc is second in both rankings, so it beats the documents that are only first in one
+	bm25 := []Result{result(0, 10), result(1, 5)}
+	embedding := []Result{result(2, 0.9), result(1, 0.8), result(3, 0.7)}
+
+	res := Fuse(10, bm25, embedding)
+	if ids := resultIds(res); !reflect.DeepEqual(ids, []string{"orc", "goblin", "dragon", "goblin"}) || res[1].Source != "ds:monsters" {
+		t.Fatalf("unexpected fusion %+v", res)
+	}
+	if math.Abs(res[0].Score-2.0/62) > 1e-9 {
+		t.Fatalf("expected the reciprocal rank score, got %f", res[0].Score)
+	}
+
+	if res := Fuse(1, bm25, embedding); len(res) != 1 || res[0].Entry.ID != "orc" {
+		t.Fatalf("expected the limit to be applied, got %+v", res)
+	}
+	if res := Fuse(10); len(res) != 0 {
+		t.Fatalf("expected no results without rankings, got %+v", res)
+	}
+}
@@ -0,0 +1,75 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+)
+
+const (
+	// DefaultTimeout is the timeout of a request if the config doesn't set one.
+	DefaultTimeout = time.Second * 60
+	// maxRetries is the number of times a failed request is retried.
+	maxRetries = 3
+	// retryBaseDelay is the delay before the first retry. It doubles with every retry.
+	retryBaseDelay = time.Second
+	// maxRetryWait is the longest time a request waits for a Retry-After. If the provider asks
+	// to wait longer, e.g. because a daily limit is reached, the error is returned instead.
+	maxRetryWait = time.Second * 30
+)
+
+// backoff returns the delay before the retry with jitter, so that parallel requests don't retry
+// at the same time.
+func backoff(attempt int) time.Duration {
+	delay := retryBaseDelay << attempt
+	return delay/2 + rand.N(delay/2)
+}
+
+// retry calls fn until it succeeds or fails with an error that isn't worth retrying, e.g. an
+// invalid API key. Rate limits, server errors and unreachable providers are retried with
+// exponential backoff or after the time the provider asked for with Retry-After. If canRetry
+// is set and returns false, the error is returned without retry.
+func retry(ctx context.Context, canRetry func() bool, fn func(ctx context.Context) error) error {
+	for attempt := 0; ; attempt++ {
+		err := fn(ctx)
+
+		var aiErr *Error
+		if err == nil || attempt >= maxRetries || !errors.As(err, &aiErr) || !aiErr.Retryable() || (canRetry != nil && !canRetry()) {
+			return err
+		}
+
+		delay := backoff(attempt)
+		if aiErr.RetryAfter > 0 {
+			if aiErr.RetryAfter > maxRetryWait {
+				return err
+			}
+			delay = aiErr.RetryAfter
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			// The last error explains better why the request didn't finish in time
+			if ctx.Err() == context.DeadlineExceeded {
+				return err
+			}
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// withTimeout calls fn with a context that is cancelled after the timeout of the config.
+func withTimeout(ctx context.Context, config ProviderConfig, fn func(ctx context.Context) error) error {
+	timeout := config.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	return fn(ctx)
+}
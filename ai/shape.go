@@ -0,0 +1,324 @@
+package ai
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	ShapeAny     = "any"
+	ShapeObject  = "object"
+	ShapeArray   = "array"
+	ShapeString  = "string"
+	ShapeNumber  = "number"
+	ShapeBoolean = "boolean"
+)
+
+// Shape describes the structure of json values. It is derived from example values, so that
+// generated values can be checked and repaired to have the same structure.
+type Shape struct {
+	Type   string            `json:"type"`
+	Fields map[string]*Shape `json:"fields,omitempty"`
+	Items  *Shape            `json:"items,omitempty"`
+}
+
+// ShapeOf derives the shape that all the values have in common. Fields that are only present in
+// some values are still part of the shape. If values of the same field have different types the
+// field has the type ShapeAny.
+func ShapeOf(values ...any) *Shape {
+	var shape *Shape
+	for i := range values {
+		shape = shape.merge(values[i])
+	}
+
+	if shape == nil {
+		return &Shape{Type: ShapeAny}
+	}
+	return shape
+}
+
+func shapeType(value any) string {
+	switch value.(type) {
+	case map[string]any:
+		return ShapeObject
+	case []any:
+		return ShapeArray
+	case string:
+		return ShapeString
+	case float64, float32, int, int64, int32, json.Number:
+		return ShapeNumber
+	case bool:
+		return ShapeBoolean
+	}
+	return ShapeAny
+}
+
+// merge returns the shape extended by the value. A nil shape is the shape that hasn't seen any value yet.
+func (s *Shape) merge(value any) *Shape {
+	if value == nil {
+		return s
+	}
+
+	valueType := shapeType(value)
+	if s == nil {
+		s = &Shape{Type: valueType}
+	} else if s.Type != valueType {
+		return &Shape{Type: ShapeAny}
+	}
+
+	switch val := value.(type) {
+	case map[string]any:
+		if s.Fields == nil {
+			s.Fields = map[string]*Shape{}
+		}
+		for k, v := range val {
+			if field := s.Fields[k].merge(v); field != nil {
+				s.Fields[k] = field
+			} else if _, ok := s.Fields[k]; !ok {
+				s.Fields[k] = nil
+			}
+		}
+	case []any:
+		for i := range val {
+			s.Items = s.Items.merge(val[i])
+		}
+	}
+
+	return s
+}
+
+// Schema converts the shape to a json schema.
+func (s *Shape) Schema() map[string]any {
+	if s == nil || s.Type == ShapeAny {
+		return map[string]any{}
+	}
+
+	schema := map[string]any{"type": s.Type}
+
+	switch s.Type {
+	case ShapeObject:
+		properties := map[string]any{}
+		required := make([]string, 0, len(s.Fields))
+		for k, field := range s.Fields {
+			properties[k] = field.Schema()
+			required = append(required, k)
+		}
+		sort.Strings(required)
+
+		schema["properties"] = properties
+		schema["required"] = required
+	case ShapeArray:
+		schema["items"] = s.Items.Schema()
+	}
+
+	return schema
+}
+
+// Conform converts the value to the shape. Missing fields are added with empty values, fields
+// that are not part of the shape are removed and values of the wrong type are converted if
+// possible or replaced by empty values.
+func (s *Shape) Conform(value any) any {
+	if s == nil || s.Type == ShapeAny {
+		return value
+	}
+
+	switch s.Type {
+	case ShapeObject:
+		obj, _ := value.(map[string]any)
+		if obj == nil {
+			// Sometimes models return nested objects as json string
+			if str, ok := value.(string); ok {
+				_ = json.Unmarshal([]byte(str), &obj)
+			}
+		}
+
+		res := make(map[string]any, len(s.Fields))
+		for k, field := range s.Fields {
+			res[k] = field.Conform(obj[k])
+		}
+		return res
+	case ShapeArray:
+		var arr []any
+		switch val := value.(type) {
+		case nil:
+		case []any:
+			arr = val
+		default:
+			arr = []any{val}
+		}
+
+		res := make([]any, 0, len(arr))
+		for i := range arr {
+			res = append(res, s.Items.Conform(arr[i]))
+		}
+		return res
+	case ShapeString:
+		switch val := value.(type) {
+		case nil:
+			return ""
+		case string:
+			return val
+		case map[string]any, []any:
+			data, _ := json.Marshal(val)
+			return string(data)
+		default:
+			return fmt.Sprint(val)
+		}
+	case ShapeNumber:
+		switch val := value.(type) {
+		case float64:
+			return val
+		case string:
+			if num, err := strconv.ParseFloat(strings.TrimSpace(val), 64); err == nil {
+				return num
+			}
+		case bool:
+			if val {
+				return 1.0
+			}
+		}
+		return 0.0
+	case ShapeBoolean:
+		switch val := value.(type) {
+		case bool:
+			return val
+		case float64:
+			return val != 0
+		case string:
+			switch strings.ToLower(strings.TrimSpace(val)) {
+			case "true", "yes", "1":
+				return true
+			}
+		}
+		return false
+	}
+
+	return value
+}
+
+// ParseJSON parses json generated by a model. Text around the json like explanations or markdown
+// code blocks is removed and common mistakes are repaired: trailing commas are removed and json
+// that was cut off, because the model reached the token limit, is closed.
+func ParseJSON(text string) (any, error) {
+	var value any
+	if err := json.Unmarshal([]byte(text), &value); err == nil {
+		return value, nil
+	}
+
+	start := strings.IndexAny(text, "{[")
+	if start == -1 {
+		return nil, errors.New("response doesn't contain json")
+	}
+
+	if err := json.Unmarshal([]byte(repairJSON(text[start:])), &value); err != nil {
+		return nil, fmt.Errorf("response contains invalid json: %w", err)
+	}
+
+	return value, nil
+}
+
+// repairJSON reads the json value at the start of the text and returns it without the text that
+// follows it. Trailing commas are removed and unclosed strings, objects and arrays are closed.
+func repairJSON(text string) string {
+	var sb strings.Builder
+	var stack []byte
+	inString := false
+	escaped := false
+
+	trimComma := func() {
+		res := strings.TrimRight(sb.String(), " \t\r\n")
+		res = strings.TrimSuffix(res, ",")
+		sb.Reset()
+		sb.WriteString(res)
+	}
+
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+
+		if inString {
+			sb.WriteByte(c)
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+			continue
+		}
+
+		switch c {
+		case '"':
+			inString = true
+		case '{':
+			stack = append(stack, '}')
+		case '[':
+			stack = append(stack, ']')
+		case '}', ']':
+			if len(stack) == 0 || stack[len(stack)-1] != c {
+				// Unbalanced brackets can't be repaired, let the parser report the error
+				sb.WriteByte(c)
+				continue
+			}
+
+			trimComma()
+			stack = stack[:len(stack)-1]
+			sb.WriteByte(c)
+
+			if len(stack) == 0 {
+				return sb.String()
+			}
+			continue
+		}
+
+		sb.WriteByte(c)
+	}
+
+	// The json was cut off
+	if inString {
+		if escaped {
+			sb.WriteByte('\\')
+		}
+		sb.WriteByte('"')
+	}
+
+	trimComma()
+	res := sb.String()
+	if strings.HasSuffix(res, ":") {
+		res += "null"
+	} else if len(stack) > 0 && stack[len(stack)-1] == '}' && objectKeyPending(res) {
+		res += ":null"
+	}
+
+	for i := len(stack) - 1; i >= 0; i-- {
+		res += string(stack[i])
+	}
+
+	return res
+}
+
+// objectKeyPending checks if the json ends with a key of an object that has no value yet, like `{"a": 1, "b"`.
+func objectKeyPending(text string) bool {
+	if !strings.HasSuffix(text, `"`) {
+		return false
+	}
+
+	// Find the start of the last string
+	start := len(text) - 2
+	for ; start >= 0; start-- {
+		if text[start] == '"' && (start == 0 || text[start-1] != '\\') {
+			break
+		}
+	}
+	if start < 0 {
+		return false
+	}
+
+	before := strings.TrimRight(text[:start], " \t\r\n")
+	return strings.HasSuffix(before, "{") || strings.HasSuffix(before, ",")
+}
@@ -0,0 +1,151 @@
+package ai
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseJSON(t *testing.T) {
+	cases := []struct {
+		name string
+		text string
+		want any
+		err  bool
+	}{
+		{name: "valid", text: `{"name": "Goblin", "hp": 7}`, want: map[string]any{"name": "Goblin", "hp": 7.0}},
+		{name: "surrounding text", text: "Here is the npc:\n{\"name\": \"Goblin\"}\nHave fun!", want: map[string]any{"name": "Goblin"}},
+		{name: "code fence", text: "```json\n[{\"name\": \"Goblin\"}]\n```", want: []any{map[string]any{"name": "Goblin"}}},
+		{name: "trailing commas", text: `{"tags": ["a", "b",], "hp": 7, }`, want: map[string]any{"tags": []any{"a", "b"}, "hp": 7.0}},
+		{name: "text after value", text: `{"a": 1} and {"b": 2}`, want: map[string]any{"a": 1.0}},
+		{name: "brackets in strings", text: `{"text": "a } ] { [ \" b"}`, want: map[string]any{"text": `a } ] { [ " b`}},
+		{name: "truncated string", text: `{"name": "Gob`, want: map[string]any{"name": "Gob"}},
+		{name: "truncated escape", text: `{"name": "Gob\`, want: map[string]any{"name": `Gob\`}},
+		{name: "truncated after colon", text: `{"name": "Goblin", "hp":`, want: map[string]any{"name": "Goblin", "hp": nil}},
+		{name: "truncated key", text: `{"name": "Goblin", "h`, want: map[string]any{"name": "Goblin", "h": nil}},
+		{name: "truncated first key", text: `{"na`, want: map[string]any{"na": nil}},
+		{name: "truncated after comma", text: `{"tags": ["a", "b", `, want: map[string]any{"tags": []any{"a", "b"}}},
+		{name: "truncated nested", text: `[{"name": "Goblin", "items": [{"name": "Scimitar"`, want: []any{map[string]any{"name": "Goblin", "items": []any{map[string]any{"name": "Scimitar"}}}}},
+		{name: "truncated string in list", text: `{"tags": ["sm`, want: map[string]any{"tags": []any{"sm"}}},
+		{name: "no json", text: "I can't do that.", err: true},
+		{name: "unbalanced brackets", text: `{"a": 1]`, err: true},
+		{name: "invalid value", text: `{"a": tru}`, err: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			res, err := ParseJSON(c.text)
+			if c.err {
+				if err == nil {
+					t.Fatalf("expected an error, got %v", res)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(res, c.want) {
+				t.Fatalf("expected %v, got %v", c.want, res)
+			}
+		})
+	}
+}
+
+func TestObjectKeyPending(t *testing.T) {
+	cases := []struct {
+		text string
+		want bool
+	}{
+		{text: `{"a"`, want: true},
+		{text: `{"a": 1, "b"`, want: true},
+		{text: "{\"a\": 1,\n  \"b\"", want: true},
+		{text: `{"a": "b"`, want: false},
+		{text: `{"a": 1`, want: false},
+		{text: `{"a": ["b"`, want: false},
+		{text: `{"a \" b"`, want: true},
+		{text: `"`, want: false},
+	}
+
+	for _, c := range cases {
+		if res := objectKeyPending(c.text); res != c.want {
+			t.Errorf("%s: expected %v, got %v", c.text, c.want, res)
+		}
+	}
+}
+
+func TestShapeConform(t *testing.T) {
+	shape := ShapeOf(
+		map[string]any{"name": "Goblin", "hp": 7.0, "hostile": true, "tags": []any{"small"}, "stats": map[string]any{"str": 8.0}},
+		map[string]any{"name": "Orc", "extra": "only here", "mixed": 1.0},
+		map[string]any{"mixed": "text"},
+	)
+
+	cases := []struct {
+		name  string
+		value any
+		want  map[string]any
+	}{
+		{
+			name:  "matching",
+			value: map[string]any{"name": "Goblin", "hp": 7.0, "hostile": true, "tags": []any{"small"}, "stats": map[string]any{"str": 8.0}, "extra": "x", "mixed": 1.0},
+			want:  map[string]any{"name": "Goblin", "hp": 7.0, "hostile": true, "tags": []any{"small"}, "stats": map[string]any{"str": 8.0}, "extra": "x", "mixed": 1.0},
+		},
+		{
+			name:  "missing and unknown fields",
+			value: map[string]any{"name": "Orc", "unknown": 1.0},
+			want:  map[string]any{"name": "Orc", "hp": 0.0, "hostile": false, "tags": []any{}, "stats": map[string]any{"str": 0.0}, "extra": "", "mixed": nil},
+		},
+		{
+			name:  "coercion",
+			value: map[string]any{"name": 12.0, "hp": " 15 ", "hostile": "Yes", "tags": "large", "stats": `{"str": "16"}`, "extra": []any{1.0}, "mixed": []any{"kept"}},
+			want:  map[string]any{"name": "12", "hp": 15.0, "hostile": true, "tags": []any{"large"}, "stats": map[string]any{"str": 16.0}, "extra": "[1]", "mixed": []any{"kept"}},
+		},
+		{
+			name:  "failed coercion",
+			value: map[string]any{"name": nil, "hp": "many", "hostile": 0.0, "tags": nil, "stats": "not json", "extra": true},
+			want:  map[string]any{"name": "", "hp": 0.0, "hostile": false, "tags": []any{}, "stats": map[string]any{"str": 0.0}, "extra": "true", "mixed": nil},
+		},
+		{
+			name:  "numbers from booleans",
+			value: map[string]any{"hp": true, "hostile": 2.0},
+			want:  map[string]any{"name": "", "hp": 1.0, "hostile": true, "tags": []any{}, "stats": map[string]any{"str": 0.0}, "extra": "", "mixed": nil},
+		},
+		{
+			name:  "not an object",
+			value: []any{"Goblin"},
+			want:  map[string]any{"name": "", "hp": 0.0, "hostile": false, "tags": []any{}, "stats": map[string]any{"str": 0.0}, "extra": "", "mixed": nil},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if res := shape.Conform(c.value); !reflect.DeepEqual(res, c.want) {
+				t.Fatalf("expected %v, got %v", c.want, res)
+			}
+		})
+	}
+}
+
+func TestShapeSchema(t *testing.T) {
+	shape := ShapeOf(map[string]any{"name": "Goblin", "tags": []any{"small"}, "any": nil})
+
+	want := map[string]any{
+		"type": ShapeObject,
+		"properties": map[string]any{
+			"name": map[string]any{"type": ShapeString},
+			"tags": map[string]any{"type": ShapeArray, "items": map[string]any{"type": ShapeString}},
+			"any":  map[string]any{},
+		},
+		"required": []string{"any", "name", "tags"},
+	}
+
+	if schema := shape.Schema(); !reflect.DeepEqual(schema, want) {
+		t.Fatalf("expected %v, got %v", want, schema)
+	}
+
+	if shape := ShapeOf(); shape.Type != ShapeAny {
+		t.Fatalf("expected any without values, got %s", shape.Type)
+	}
+	if shape := ShapeOf("a", 1.0); shape.Type != ShapeAny {
+		t.Fatalf("expected any for mixed values, got %s", shape.Type)
+	}
+}
@@ -0,0 +1,80 @@
+package ai
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// maxLineSize is the maximum size of a single line of a stream.
+const maxLineSize = 1024 * 1024
+
+// readEvents reads server-sent events and calls onData with the data of every event until
+// the "[DONE]" event or the end of the stream. Comments like ": OPENROUTER PROCESSING" that
+// keep the connection alive are skipped. If onData returns io.EOF the reading stops without error.
+func readEvents(reader io.Reader, onData func(data string) error) error {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+
+	var data []string
+	flush := func() error {
+		if len(data) == 0 {
+			return nil
+		}
+
+		event := strings.Join(data, "\n")
+		data = data[:0]
+
+		if event == "[DONE]" {
+			return io.EOF
+		}
+		return onData(event)
+	}
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		switch {
+		case len(line) == 0:
+			if err := flush(); err == io.EOF {
+				return nil
+			} else if err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, ":"):
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	if err := flush(); err != nil && err != io.EOF {
+		return err
+	}
+	return nil
+}
+
+// readLines reads a stream of JSON objects that are separated by new lines and calls onLine for
+// every line. If onLine returns io.EOF the reading stops without error.
+func readLines(reader io.Reader, onLine func(line string) error) error {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 {
+			continue
+		}
+
+		if err := onLine(line); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
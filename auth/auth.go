@@ -0,0 +1,137 @@
+// Package auth contains the optional authentication and role based access control of the http api.
+// Requests are authenticated with API tokens or the session of a user that logged into the UI.
+// Every route belongs to a group and each role can either not access, read or write a group.
+package auth
+
+import (
+	"net/http"
+	"path"
+	"strings"
+	"unicode"
+)
+
+// Role is the role of a user or API token.
+type Role string
+
+const (
+	// RoleAdmin can access everything, including the settings and the access control.
+	RoleAdmin Role = "admin"
+	// RoleGM can manage and print the content, but not change the system.
+	RoleGM Role = "gm"
+	// RolePlayer can only look at the content, e.g. to see handouts.
+	RolePlayer Role = "player"
+	// RoleRenderer is the role of the browser that renders the templates of this process. It can't
+	// be given to users or tokens.
+	RoleRenderer Role = "renderer"
+)
+
+// Roles are all roles.
+var Roles = []Role{RoleAdmin, RoleGM, RolePlayer}
+
+// Valid checks if the role exists.
+func (r Role) Valid() bool {
+	return r == RoleAdmin || r == RoleGM || r == RolePlayer
+}
+
+// Group is a group of routes that share the same permissions.
+type Group string
+
+const (
+	// GroupPublic routes can be accessed without authentication, e.g. the frontend and the login.
+	GroupPublic Group = "public"
+	// GroupContent contains templates, generators, prompts, data sources, entries and fonts.
+	GroupContent Group = "content"
+	// GroupPrint contains printing, the preview images and the proxy. The proxy needs the write access.
+	GroupPrint Group = "print"
+	// GroupAI contains the AI prompts and the AI cache.
+	GroupAI Group = "ai"
+	// GroupSettings contains the settings.
+	GroupSettings Group = "settings"
+	// GroupSystem contains the file browser, git, cloud sync and everything else that accesses the machine.
+	GroupSystem Group = "system"
+	// GroupAuth contains the management of users and tokens. Only admins can access it.
+	GroupAuth Group = "auth"
+)
+
+// Groups are the groups that permissions can be set for.
+var Groups = []Group{GroupContent, GroupPrint, GroupAI, GroupSettings, GroupSystem}
+
+// Access is the permission of a role for a group.
+type Access string
+
+const (
+	AccessNone  Access = "none"
+	AccessRead  Access = "read"
+	AccessWrite Access = "write"
+)
+
+// Valid checks if the access exists.
+func (a Access) Valid() bool {
+	return a == AccessNone || a == AccessRead || a == AccessWrite
+}
+
+// Permissions is the access of a role per group.
+type Permissions map[Group]Access
+
+// DefaultPermissions are the permissions of the roles if they are not changed.
+var DefaultPermissions = map[Role]Permissions{
+	RoleGM: {
+		GroupContent:  AccessWrite,
+		GroupPrint:    AccessWrite,
+		GroupAI:       AccessWrite,
+		GroupSettings: AccessRead,
+		GroupSystem:   AccessNone,
+	},
+	RolePlayer: {
+		GroupContent:  AccessRead,
+		GroupPrint:    AccessRead,
+		GroupAI:       AccessRead,
+		GroupSettings: AccessNone,
+		GroupSystem:   AccessNone,
+	},
+}
+
+// rendererPermissions are the fixed permissions of the rendering browser. Templates can load
+// images through the proxy and use AI prompts while they are rendered. The settings contain the
+// AI API key, so the scripts of templates can't read them.
+var rendererPermissions = Permissions{
+	GroupContent:  AccessRead,
+	GroupPrint:    AccessWrite,
+	GroupAI:       AccessWrite,
+	GroupSettings: AccessNone,
+	GroupSystem:   AccessNone,
+}
+
+// Identity is who sent a request.
+type Identity struct {
+	// Name is the name of the user or token.
+	Name string `json:"name"`
+	Role Role   `json:"role"`
+}
+
+// readPrefixes are the prefixes of rpc names that only read data.
+var readPrefixes = []string{"get", "count", "has", "lint", "render"}
+
+// IsRead checks if the route only reads data. All GET routes and the rpc functions that start
+// with a read prefix like "getTemplates" are reads, everything else is considered a write. Routes
+// that don't follow this rule set their access explicitly, see Routes.Set.
+func IsRead(method string, route string) bool {
+	if method == http.MethodGet || method == http.MethodHead {
+		return true
+	}
+
+	name := path.Base(route)
+	for _, prefix := range readPrefixes {
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+
+		// "getTemplates" is a read but "settings" would not be one, even if "set" was a prefix
+		rest := []rune(strings.TrimPrefix(name, prefix))
+		if len(rest) == 0 || unicode.IsUpper(rest[0]) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,217 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// CookieName is the name of the cookie that contains the session token of the UI.
+	CookieName = "snd_session"
+	// identityKey is the key of the identity in the echo context.
+	identityKey = "auth_identity"
+)
+
+// rendererToken authenticates the rendering browser, see RendererToken.
+var rendererToken = func() string {
+	token, err := randomToken()
+	if err != nil {
+		panic(err)
+	}
+	return token
+}()
+
+// Route is the permission group of a route and whether it changes data.
+type Route struct {
+	Group Group
+	// Write routes need the write access to the group, all others the read access.
+	Write bool
+}
+
+// Routes maps the routes of the server to their groups.
+type Routes struct {
+	sync.RWMutex
+	routes map[string]Route
+}
+
+// NewRoutes creates an empty route mapping.
+func NewRoutes() *Routes {
+	return &Routes{routes: map[string]Route{}}
+}
+
+// Set sets the group and access of the route. The path is the path of the route as registered,
+// e.g. "/api/html/:id".
+func (r *Routes) Set(method string, path string, route Route) {
+	r.Lock()
+	defer r.Unlock()
+	r.routes[method+" "+path] = route
+}
+
+// Get returns the group and access of the route.
+func (r *Routes) Get(method string, path string) (Route, bool) {
+	r.RLock()
+	defer r.RUnlock()
+
+	route, ok := r.routes[method+" "+path]
+	if !ok && method == http.MethodHead {
+		route, ok = r.routes[http.MethodGet+" "+path]
+	}
+	return route, ok
+}
+
+// Middleware authenticates the requests and checks the permissions of the role for the group of
+// the route. Api routes without group can only be accessed by admins. If the authentication is
+// disabled every request has admin rights.
+func Middleware(store *Store, routes *Routes) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if !store.Enabled() {
+				c.Set(identityKey, Identity{Name: "admin", Role: RoleAdmin})
+				return next(c)
+			}
+
+			// CORS preflight requests don't contain credentials
+			if c.Request().Method == http.MethodOptions {
+				return next(c)
+			}
+
+			// Unknown routes outside the api belong to the frontend, e.g. if it is served by the dev server
+			route, ok := routes.Get(c.Request().Method, c.Path())
+			if !ok {
+				route = Route{Group: GroupAuth, Write: !IsRead(c.Request().Method, c.Path())}
+				if !strings.HasPrefix(c.Request().URL.Path, "/api/") {
+					route.Group = GroupPublic
+				}
+			}
+
+			identity, authenticated := identify(store, c)
+			if authenticated {
+				c.Set(identityKey, identity)
+			}
+
+			if route.Group == GroupPublic {
+				return next(c)
+			}
+
+			if !authenticated {
+				return c.JSON(http.StatusUnauthorized, "login required")
+			}
+
+			if !store.Allowed(identity.Role, route.Group, route.Write) {
+				return c.JSON(http.StatusForbidden, "permission denied")
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// IdentityFrom returns who sent the request.
+func IdentityFrom(c echo.Context) (Identity, bool) {
+	identity, ok := c.Get(identityKey).(Identity)
+	return identity, ok
+}
+
+// TokenFrom returns the token of the request. It is taken from the "Authorization: Bearer <token>"
+// header, the session cookie or the "token" query parameter, which is needed for web sockets.
+func TokenFrom(c echo.Context) string {
+	if header := c.Request().Header.Get("Authorization"); strings.HasPrefix(header, "Bearer ") {
+		return strings.TrimSpace(strings.TrimPrefix(header, "Bearer "))
+	}
+
+	if cookie, err := c.Cookie(CookieName); err == nil && len(cookie.Value) > 0 {
+		return cookie.Value
+	}
+
+	return c.QueryParam("token")
+}
+
+// RendererToken returns the token of the browser that renders the templates of this process. It
+// changes on every start and is only accepted from the same machine.
+func RendererToken() string {
+	return rendererToken
+}
+
+func identify(store *Store, c echo.Context) (Identity, bool) {
+	token := TokenFrom(c)
+	if identity, ok := store.Authenticate(token); ok {
+		return identity, true
+	}
+
+	if subtle.ConstantTimeCompare([]byte(token), []byte(rendererToken)) == 1 && isLoopback(c.Request()) {
+		return Identity{Name: "renderer", Role: RoleRenderer}, true
+	}
+
+	if store.TrustLocal() && isLocal(c.Request()) {
+		return Identity{Name: "local", Role: RoleAdmin}, true
+	}
+
+	return Identity{}, false
+}
+
+// isLocal checks if the request comes from the same machine. Requests of browsers from other
+// websites are not local, even if the browser runs on the same machine, as any website could
+// otherwise use the api.
+func isLocal(req *http.Request) bool {
+	if !isLoopback(req) {
+		return false
+	}
+
+	origin := req.Header.Get("Origin")
+	if len(origin) == 0 {
+		return true
+	}
+
+	originURL, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+
+	switch originURL.Hostname() {
+	case "localhost", "127.0.0.1", "::1":
+		return true
+	}
+	return originURL.Host == req.Host
+}
+
+// isLoopback checks if the request was sent from the same machine and not forwarded by a reverse proxy.
+func isLoopback(req *http.Request) bool {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return false
+	}
+
+	if ip := net.ParseIP(host); ip == nil || !ip.IsLoopback() {
+		return false
+	}
+
+	// Requests forwarded by a reverse proxy on the same machine are not local
+	return len(req.Header.Get("X-Forwarded-For")) == 0 && len(req.Header.Get("X-Real-Ip")) == 0
+}
+
+// SessionCookie creates the cookie that stores the session token in the browser. An empty token
+// creates a cookie that removes the session.
+func SessionCookie(token string, secure bool) *http.Cookie {
+	cookie := &http.Cookie{
+		Name:     CookieName,
+		Value:    token,
+		Path:     "/",
+		MaxAge:   int(sessionDuration.Seconds()),
+		HttpOnly: true,
+		Secure:   secure,
+		// Other websites can't use the session, which prevents cross-site request forgery
+		SameSite: http.SameSiteStrictMode,
+	}
+
+	if len(token) == 0 {
+		cookie.MaxAge = -1
+	}
+
+	return cookie
+}
@@ -0,0 +1,116 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func newTestServer(t *testing.T) (*echo.Echo, string) {
+	store, err := Open("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SaveUser("admin", "password123", RoleAdmin); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SetEnabled(true); err != nil {
+		t.Fatal(err)
+	}
+
+	playerToken, err := store.CreateToken("player", RolePlayer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	routes := NewRoutes()
+	routes.Set(http.MethodGet, "/api/html/:id", Route{Group: GroupPrint})
+	routes.Set(http.MethodPost, "/api/aiCached", Route{Group: GroupAI})
+	routes.Set(http.MethodPost, "/api/aiPrompt", Route{Group: GroupAI, Write: true})
+	routes.Set(http.MethodGet, "/proxy/*", Route{Group: GroupPrint, Write: true})
+	routes.Set(http.MethodPost, "/api/gitCommit", Route{Group: GroupSystem, Write: true})
+	routes.Set(http.MethodPost, "/api/getSettings", Route{Group: GroupSettings})
+
+	e := echo.New()
+	e.Use(Middleware(store, routes))
+
+	ok := func(c echo.Context) error { return c.NoContent(http.StatusOK) }
+	e.GET("/api/html/:id", ok)
+	e.POST("/api/aiCached", ok)
+	e.POST("/api/aiPrompt", ok)
+	e.GET("/proxy/*", ok)
+	e.POST("/api/gitCommit", ok)
+	e.POST("/api/getSettings", ok)
+
+	return e, playerToken
+}
+
+func TestMiddlewareAccess(t *testing.T) {
+	e, playerToken := newTestServer(t)
+
+	cases := []struct {
+		name       string
+		method     string
+		target     string
+		remote     string
+		cookie     string
+		bearer     string
+		forwarded  bool
+		wantStatus int
+	}{
+		{name: "anonymous", method: http.MethodGet, target: "/api/html/1", wantStatus: http.StatusUnauthorized},
+		{name: "renderer html", method: http.MethodGet, target: "/api/html/1", cookie: RendererToken(), wantStatus: http.StatusOK},
+		{name: "renderer ai cache", method: http.MethodPost, target: "/api/aiCached", cookie: RendererToken(), wantStatus: http.StatusOK},
+		{name: "renderer ai prompt", method: http.MethodPost, target: "/api/aiPrompt", cookie: RendererToken(), wantStatus: http.StatusOK},
+		{name: "renderer proxy", method: http.MethodGet, target: "/proxy/https://example.com/a.png", cookie: RendererToken(), wantStatus: http.StatusOK},
+		{name: "renderer system", method: http.MethodPost, target: "/api/gitCommit", cookie: RendererToken(), wantStatus: http.StatusForbidden},
+		{name: "renderer settings", method: http.MethodPost, target: "/api/getSettings", cookie: RendererToken(), wantStatus: http.StatusForbidden},
+		{name: "renderer from remote", method: http.MethodGet, target: "/api/html/1", remote: "192.168.1.5:4000", cookie: RendererToken(), wantStatus: http.StatusUnauthorized},
+		{name: "renderer forwarded", method: http.MethodGet, target: "/api/html/1", cookie: RendererToken(), forwarded: true, wantStatus: http.StatusUnauthorized},
+		{name: "player ai cache", method: http.MethodPost, target: "/api/aiCached", bearer: playerToken, wantStatus: http.StatusOK},
+		{name: "player ai prompt", method: http.MethodPost, target: "/api/aiPrompt", bearer: playerToken, wantStatus: http.StatusForbidden},
+		{name: "player proxy", method: http.MethodGet, target: "/proxy/http://192.168.1.1/", bearer: playerToken, wantStatus: http.StatusForbidden},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req := httptest.NewRequest(c.method, c.target, nil)
+			req.RemoteAddr = "127.0.0.1:4000"
+			if len(c.remote) > 0 {
+				req.RemoteAddr = c.remote
+			}
+			if len(c.cookie) > 0 {
+				req.AddCookie(&http.Cookie{Name: CookieName, Value: c.cookie})
+			}
+			if len(c.bearer) > 0 {
+				req.Header.Set("Authorization", "Bearer "+c.bearer)
+			}
+			if c.forwarded {
+				req.Header.Set("X-Forwarded-For", "192.168.1.5")
+			}
+
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if rec.Code != c.wantStatus {
+				t.Fatalf("expected status %d, got %d", c.wantStatus, rec.Code)
+			}
+		})
+	}
+}
+
+func TestRendererRoleCantBeAssigned(t *testing.T) {
+	store, err := Open("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.SaveUser("renderer", "password123", RoleRenderer); err == nil {
+		t.Fatal("expected the renderer role to be rejected for users")
+	}
+	if _, err := store.CreateToken("renderer", RoleRenderer); err == nil {
+		t.Fatal("expected the renderer role to be rejected for tokens")
+	}
+}
@@ -0,0 +1,528 @@
+package auth
+
+import (
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/samber/lo"
+)
+
+const (
+	// tokenPrefix is the prefix of API tokens, so that they are easy to recognize.
+	tokenPrefix = "snd_"
+	// sessionDuration is how long a login of the UI is valid.
+	sessionDuration = time.Hour * 24 * 30
+	// passwordIterations is the number of PBKDF2 iterations used to hash passwords.
+	passwordIterations = 600000
+	// minPasswordLength is the minimum length of passwords.
+	minPasswordLength = 8
+)
+
+// User can log into the UI.
+type User struct {
+	Name string `json:"name"`
+	Role Role   `json:"role"`
+	// PasswordHash is the PBKDF2 hash of the password in the format pbkdf2-sha256$<iterations>$<salt>$<hash>.
+	PasswordHash string `json:"passwordHash,omitempty"`
+}
+
+// Token is an API token for scripts and other programs.
+type Token struct {
+	ID      string    `json:"id"`
+	Name    string    `json:"name"`
+	Role    Role      `json:"role"`
+	Created time.Time `json:"created"`
+	// Hash is the sha256 hash of the token. The token itself is only shown when it is created.
+	Hash string `json:"hash,omitempty"`
+}
+
+type session struct {
+	Hash    string    `json:"hash"`
+	User    string    `json:"user"`
+	Expires time.Time `json:"expires"`
+}
+
+// Config is the configuration of the access control.
+type Config struct {
+	// Enabled turns the authentication on. If disabled every request has admin rights.
+	Enabled bool `json:"enabled"`
+	// TrustLocal gives requests from the same machine admin rights without authentication.
+	TrustLocal  bool                 `json:"trustLocal"`
+	Users       []User               `json:"users"`
+	Tokens      []Token              `json:"tokens"`
+	Permissions map[Role]Permissions `json:"permissions"`
+	Sessions    []session            `json:"sessions,omitempty"`
+}
+
+// Store keeps the configuration of the access control in a file. Users, tokens and sessions are
+// not stored in the database, as the key-value store of the database is accessible over the api.
+type Store struct {
+	sync.RWMutex
+	file   string
+	config Config
+}
+
+// Open loads the configuration from the file. If the file doesn't exist the authentication is
+// disabled. If file is empty the configuration is only kept in memory.
+func Open(file string) (*Store, error) {
+	store := &Store{file: file}
+
+	if len(file) == 0 {
+		return store, nil
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return store, nil
+		}
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &store.config); err != nil {
+		return nil, fmt.Errorf("could not parse %s: %w", file, err)
+	}
+
+	return store, nil
+}
+
+// save writes the configuration. The lock has to be held.
+func (s *Store) save() error {
+	if len(s.file) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	s.config.Sessions = lo.Filter(s.config.Sessions, func(item session, _ int) bool {
+		return item.Expires.After(now)
+	})
+
+	data, err := json.MarshalIndent(s.config, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(s.file, data, 0600)
+}
+
+// Enabled checks if the authentication is enabled.
+func (s *Store) Enabled() bool {
+	s.RLock()
+	defer s.RUnlock()
+	return s.config.Enabled
+}
+
+// TrustLocal checks if requests from the same machine are trusted.
+func (s *Store) TrustLocal() bool {
+	s.RLock()
+	defer s.RUnlock()
+	return s.config.TrustLocal
+}
+
+// Config returns the configuration without password hashes, token hashes and sessions.
+func (s *Store) Config() Config {
+	s.RLock()
+	defer s.RUnlock()
+
+	config := Config{
+		Enabled:    s.config.Enabled,
+		TrustLocal: s.config.TrustLocal,
+		Users: lo.Map(s.config.Users, func(user User, _ int) User {
+			user.PasswordHash = ""
+			return user
+		}),
+		Tokens: lo.Map(s.config.Tokens, func(token Token, _ int) Token {
+			token.Hash = ""
+			return token
+		}),
+		Permissions: map[Role]Permissions{},
+	}
+
+	for _, role := range []Role{RoleGM, RolePlayer} {
+		config.Permissions[role] = s.permissions(role)
+	}
+
+	return config
+}
+
+// permissions returns the permissions of the role. The lock has to be held.
+func (s *Store) permissions(role Role) Permissions {
+	permissions := Permissions{}
+	for _, group := range Groups {
+		if role == RoleAdmin {
+			permissions[group] = AccessWrite
+			continue
+		}
+
+		access, ok := s.config.Permissions[role][group]
+		if !ok {
+			access = DefaultPermissions[role][group]
+		}
+		permissions[group] = access
+	}
+	return permissions
+}
+
+// Permissions returns the permissions of the role.
+func (s *Store) Permissions(role Role) Permissions {
+	s.RLock()
+	defer s.RUnlock()
+	return s.permissions(role)
+}
+
+// Allowed checks if the role can access the group. Public routes are always allowed and only
+// admins can access the access control.
+func (s *Store) Allowed(role Role, group Group, write bool) bool {
+	switch {
+	case group == GroupPublic:
+		return true
+	case role == RoleAdmin:
+		return true
+	case group == GroupAuth:
+		return false
+	}
+
+	access := rendererPermissions[group]
+	if role != RoleRenderer {
+		s.RLock()
+		access = s.permissions(role)[group]
+		s.RUnlock()
+	}
+
+	switch access {
+	case AccessWrite:
+		return true
+	case AccessRead:
+		return !write
+	}
+	return false
+}
+
+// SetEnabled turns the authentication on or off. It can only be turned on if an admin user
+// exists, so that nobody gets locked out.
+func (s *Store) SetEnabled(enabled bool) error {
+	s.Lock()
+	defer s.Unlock()
+
+	if enabled && !hasAdmin(s.config.Users) {
+		return errors.New("create an admin user before enabling authentication")
+	}
+
+	s.config.Enabled = enabled
+	return s.save()
+}
+
+// SetTrustLocal sets if requests from the same machine are trusted.
+func (s *Store) SetTrustLocal(trust bool) error {
+	s.Lock()
+	defer s.Unlock()
+
+	s.config.TrustLocal = trust
+	return s.save()
+}
+
+// SetPermissions changes the permissions of a role. The permissions of admins can't be changed.
+func (s *Store) SetPermissions(role Role, permissions Permissions) error {
+	if !role.Valid() || role == RoleAdmin {
+		return fmt.Errorf("permissions of role '%s' can't be changed", role)
+	}
+
+	for group, access := range permissions {
+		if !lo.Contains(Groups, group) {
+			return fmt.Errorf("unknown group '%s'", group)
+		}
+		if !access.Valid() {
+			return fmt.Errorf("unknown access '%s'", access)
+		}
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	if s.config.Permissions == nil {
+		s.config.Permissions = map[Role]Permissions{}
+	}
+	s.config.Permissions[role] = permissions
+	return s.save()
+}
+
+// SaveUser creates or updates a user. An empty password keeps the password of an existing user.
+func (s *Store) SaveUser(name string, password string, role Role) error {
+	name = strings.TrimSpace(name)
+	if len(name) == 0 {
+		return errors.New("name is empty")
+	}
+
+	if !role.Valid() {
+		return fmt.Errorf("unknown role '%s'", role)
+	}
+
+	if len(password) > 0 && len(password) < minPasswordLength {
+		return fmt.Errorf("password needs at least %d characters", minPasswordLength)
+	}
+
+	// Hashing is slow on purpose, so it is done before locking
+	var passwordHash string
+	if len(password) > 0 {
+		hash, err := hashPassword(password)
+		if err != nil {
+			return err
+		}
+		passwordHash = hash
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	index := slices.IndexFunc(s.config.Users, func(user User) bool { return user.Name == name })
+	exists := index >= 0
+
+	if !exists && len(password) == 0 {
+		return errors.New("password is empty")
+	}
+
+	user := User{Name: name, Role: role, PasswordHash: passwordHash}
+	if exists && len(password) == 0 {
+		user.PasswordHash = s.config.Users[index].PasswordHash
+	}
+
+	users := append([]User{}, s.config.Users...)
+	if exists {
+		users[index] = user
+	} else {
+		users = append(users, user)
+	}
+
+	if s.config.Enabled && !hasAdmin(users) {
+		return errors.New("the last admin can't be changed to another role while authentication is enabled")
+	}
+
+	s.config.Users = users
+
+	// Changing the password logs the user out everywhere
+	if len(password) > 0 {
+		s.config.Sessions = lo.Reject(s.config.Sessions, func(item session, _ int) bool { return item.User == name })
+	}
+
+	return s.save()
+}
+
+// hasAdmin checks if one of the users is an admin.
+func hasAdmin(users []User) bool {
+	return lo.ContainsBy(users, func(user User) bool { return user.Role == RoleAdmin })
+}
+
+// DeleteUser deletes the user and its sessions.
+func (s *Store) DeleteUser(name string) error {
+	s.Lock()
+	defer s.Unlock()
+
+	users := lo.Reject(s.config.Users, func(user User, _ int) bool { return user.Name == name })
+	if len(users) == len(s.config.Users) {
+		return fmt.Errorf("user '%s' not found", name)
+	}
+
+	if s.config.Enabled && !hasAdmin(users) {
+		return errors.New("the last admin can't be deleted while authentication is enabled")
+	}
+
+	s.config.Users = users
+	s.config.Sessions = lo.Reject(s.config.Sessions, func(item session, _ int) bool { return item.User == name })
+	return s.save()
+}
+
+// CreateToken creates a new API token. The token is only returned here, only its hash is stored.
+func (s *Store) CreateToken(name string, role Role) (string, error) {
+	name = strings.TrimSpace(name)
+	if len(name) == 0 {
+		return "", errors.New("name is empty")
+	}
+
+	if !role.Valid() {
+		return "", fmt.Errorf("unknown role '%s'", role)
+	}
+
+	token, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+
+	id, err := randomID()
+	if err != nil {
+		return "", err
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	s.config.Tokens = append(s.config.Tokens, Token{
+		ID:      id,
+		Name:    name,
+		Role:    role,
+		Created: time.Now(),
+		Hash:    hashToken(token),
+	})
+
+	return token, s.save()
+}
+
+// DeleteToken deletes the API token.
+func (s *Store) DeleteToken(id string) error {
+	s.Lock()
+	defer s.Unlock()
+
+	tokens := lo.Reject(s.config.Tokens, func(token Token, _ int) bool { return token.ID == id })
+	if len(tokens) == len(s.config.Tokens) {
+		return fmt.Errorf("token '%s' not found", id)
+	}
+
+	s.config.Tokens = tokens
+	return s.save()
+}
+
+// Login checks the password of the user and starts a session. The session token is returned.
+func (s *Store) Login(name string, password string) (string, Identity, error) {
+	s.RLock()
+	user, ok := lo.Find(s.config.Users, func(user User) bool { return user.Name == name })
+	s.RUnlock()
+
+	if !ok || !checkPassword(user.PasswordHash, password) {
+		return "", Identity{}, errors.New("wrong name or password")
+	}
+
+	token, err := randomToken()
+	if err != nil {
+		return "", Identity{}, err
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	s.config.Sessions = append(s.config.Sessions, session{
+		Hash:    hashToken(token),
+		User:    user.Name,
+		Expires: time.Now().Add(sessionDuration),
+	})
+
+	return token, Identity{Name: user.Name, Role: user.Role}, s.save()
+}
+
+// Logout ends the session of the token.
+func (s *Store) Logout(token string) error {
+	s.Lock()
+	defer s.Unlock()
+
+	hash := hashToken(token)
+	s.config.Sessions = lo.Reject(s.config.Sessions, func(item session, _ int) bool { return item.Hash == hash })
+	return s.save()
+}
+
+// Authenticate returns who the API token or session token belongs to.
+func (s *Store) Authenticate(token string) (Identity, bool) {
+	if len(token) == 0 {
+		return Identity{}, false
+	}
+
+	hash := hashToken(token)
+
+	s.RLock()
+	defer s.RUnlock()
+
+	for _, t := range s.config.Tokens {
+		if subtle.ConstantTimeCompare([]byte(t.Hash), []byte(hash)) == 1 {
+			return Identity{Name: t.Name, Role: t.Role}, true
+		}
+	}
+
+	now := time.Now()
+	for _, item := range s.config.Sessions {
+		if item.Expires.Before(now) || subtle.ConstantTimeCompare([]byte(item.Hash), []byte(hash)) != 1 {
+			continue
+		}
+
+		// The role is taken from the user, so that changes apply to existing sessions
+		if user, ok := lo.Find(s.config.Users, func(user User) bool { return user.Name == item.User }); ok {
+			return Identity{Name: user.Name, Role: user.Role}, true
+		}
+	}
+
+	return Identity{}, false
+}
+
+func randomToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return tokenPrefix + base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func randomID() (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// hashToken hashes API and session tokens. They are random, so a fast hash is enough.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func hashPassword(password string) (string, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key, err := pbkdf2.Key(sha256.New, password, salt, passwordIterations, 32)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("pbkdf2-sha256$%d$%s$%s", passwordIterations, base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+func checkPassword(hash string, password string) bool {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 4 || parts[0] != "pbkdf2-sha256" {
+		return false
+	}
+
+	iterations, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return false
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return false
+	}
+
+	expected, err := base64.RawStdEncoding.DecodeString(parts[3])
+	if err != nil {
+		return false
+	}
+
+	key, err := pbkdf2.Key(sha256.New, password, salt, iterations, len(expected))
+	if err != nil {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare(key, expected) == 1
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/BigJk/snd/imexport"
+	"github.com/BigJk/snd/local"
+	"github.com/BigJk/snd/rpc"
+	"github.com/BigJk/snd/server"
+)
+
+// The command line tools call the RPC functions of the running S&D instance, as the
+// database is locked by it and templates might need its frontend to render.
+
+// cliTimeout is the timeout of a RPC call, linting and rendering all entries can take a while.
+const cliTimeout = time.Minute * 30
+
+// cliClient returns the http client for the running instance at addr. Over https only one
+// certificate is trusted, so that the self-signed certificate of the instance works: the
+// certificate with the fingerprint or, without fingerprint, the certificate in certFile. If
+// certFile doesn't exist the certificate is verified as usual.
+func cliClient(addr string, fingerprint string, certFile string) (*http.Client, error) {
+	if !strings.HasPrefix(addr, "https://") || len(fingerprint) > 0 {
+		return local.PinnedClient(fingerprint, cliTimeout), nil
+	}
+
+	cert, err := server.ReadCertificate(certFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return local.PinnedClient("", cliTimeout), nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return local.PinnedClient(local.Fingerprint(cert), cliTimeout), nil
+}
+
+// callRPC calls the RPC function with the arguments and decodes the result into res. If the
+// authentication is enabled the API token is taken from the SND_API_TOKEN environment variable.
+func callRPC(client *http.Client, addr string, name string, res any, args ...any) error {
+	body, err := json.Marshal(args)
+	if err != nil {
+		return err
+	}
+
+	// The address can contain the scheme, e.g. "https://127.0.0.1:7123" if TLS is enabled
+	if !strings.Contains(addr, "://") {
+		addr = "http://" + addr
+	}
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/api/%s", strings.TrimRight(addr, "/"), name), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token := os.Getenv("SND_API_TOKEN"); len(token) > 0 {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("could not reach Sales & Dungeons. Is it running? (%w)", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return errors.New(string(bytes.TrimSpace(data)))
+	}
+
+	if res == nil {
+		return nil
+	}
+
+	return json.Unmarshal(data, res)
+}
+
+// runLint lints the template, prints the found issues and returns the exit code.
+func runLint(client *http.Client, addr string, id string, options rpc.LintOptions) int {
+	var report rpc.LintReport
+	if err := callRPC(client, addr, "lintTemplate", &report, id, options); err != nil {
+		fmt.Println("ERROR: linting failed:", err)
+		return 2
+	}
+
+	for _, issue := range report.Issues {
+		fmt.Printf("%-7s %s/%s [%s:%s] %s\n", issue.Severity, issue.Source, issue.Entry, issue.Template, issue.Kind, issue.Message)
+	}
+
+	fmt.Printf("\n%s: checked %d entries, %d errors, %d warnings\n", report.Template, report.Entries, report.Errors, report.Warnings)
+
+	if report.Errors > 0 {
+		return 1
+	}
+	return 0
+}
+
+// runGolden compares the template against its reference images, prints the results and
+// returns the exit code. If approve is true the current renderings are stored as references instead.
+// The diff and actual images of failed comparisons are written to diffFolder if it is set.
+func runGolden(client *http.Client, addr string, id string, approve bool, diffFolder string, options rpc.GoldenOptions) int {
+	if approve {
+		var count int
+		if err := callRPC(client, addr, "approveTemplateReferences", &count, id, options); err != nil {
+			fmt.Println("ERROR: approving failed:", err)
+			return 2
+		}
+
+		fmt.Printf("%s: approved %d references\n", id, count)
+		return 0
+	}
+
+	var report rpc.GoldenReport
+	if err := callRPC(client, addr, "testTemplateReferences", &report, id, options); err != nil {
+		fmt.Println("ERROR: comparing failed:", err)
+		return 2
+	}
+
+	for _, res := range report.Results {
+		if res.Status == rpc.GoldenStatusPass {
+			continue
+		}
+
+		fmt.Printf("%-5s %s: %s\n", res.Status, res.Entry, res.Message)
+		if len(diffFolder) > 0 && len(res.DiffImage) > 0 {
+			file, err := writeGoldenImages(diffFolder, report.Template, res)
+			if err != nil {
+				fmt.Println("      diff image could not be written:", err)
+			} else {
+				fmt.Printf("      diff: %s\n", file)
+			}
+		}
+	}
+
+	fmt.Printf("\n%s: %d passed, %d failed\n", report.Template, report.Passed, report.Failed)
+
+	if report.Failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+// writeGoldenImages writes the diff and actual image of a failed comparison to the folder and
+// returns the path of the diff image.
+func writeGoldenImages(folder string, tmplId string, res rpc.GoldenResult) (string, error) {
+	base := filepath.Join(folder, strings.NewReplacer(":", "_", "+", "_").Replace(tmplId))
+	if err := os.MkdirAll(base, 0777); err != nil {
+		return "", err
+	}
+
+	name := strings.TrimSuffix(imexport.ReferenceFileName(res.Entry), ".png")
+	diffFile := filepath.Join(base, name+".diff.png")
+
+	if err := os.WriteFile(diffFile, res.DiffImage, 0666); err != nil {
+		return "", err
+	}
+
+	if err := os.WriteFile(filepath.Join(base, name+".actual.png"), res.ActualImage, 0666); err != nil {
+		return "", err
+	}
+
+	return diffFile, nil
+}
@@ -17,6 +17,7 @@ import (
 	"time"
 
 	"github.com/BigJk/snd/database"
+	"github.com/BigJk/snd/local"
 	"github.com/BigJk/snd/log"
 	"github.com/BigJk/snd/printing/preview"
 	"github.com/BigJk/snd/rendering"
@@ -44,8 +45,18 @@ func init() {
 	onAlreadyRunning = func() {
 		fmt.Println("ERROR: Sales & Dungeons is already running!")
 
+		// The running instance uses the same certificate, if TLS is enabled
+		if tlsConfig != nil {
+			cert, err := server.ReadCertificate(tlsConfig.CertificateFile(sndDataDir))
+			if err != nil {
+				fmt.Println("ERROR: could not read certificate:", err)
+				return
+			}
+			local.Set("https", "7123", cert)
+		}
+
 		// Send GET request to the server to focus the Electron window
-		if _, err := http.Get("http://127.0.0.1:7123/api/focusElectron"); err != nil {
+		if _, err := local.Client().Get(local.URL("/api/focusElectron")); err != nil {
 			fmt.Println("ERROR: could not focus Electron window:", err)
 		}
 	}
@@ -88,6 +99,15 @@ contextBridge.exposeInMainWorld("api", {
 	time.Sleep(time.Millisecond * 500)
 	log.Info("If no window is opening please wait a few seconds for the dependencies to download...")
 
+	// The window has to trust the certificate of the server, which is self-signed by default
+	if !local.Wait(time.Second * 30) {
+		panic("server didn't start")
+	}
+	spki, err := local.SPKIHash()
+	if err != nil {
+		panic(err)
+	}
+
 	opts := astilectron.Options{
 		AppName:            "SND",
 		BaseDirectoryPath:  "./data",
@@ -102,6 +122,10 @@ contextBridge.exposeInMainWorld("api", {
 		},
 	}
 
+	if len(spki) > 0 {
+		opts.ElectronSwitches = append(opts.ElectronSwitches, "--ignore-certificate-errors-spki-list", spki)
+	}
+
 	if isMacAppBundle() {
 		opts.BaseDirectoryPath = filepath.Join(sndDataDir, "/electron")
 		opts.DataDirectoryPath = filepath.Join(sndDataDir, "/electron")
@@ -132,7 +156,7 @@ contextBridge.exposeInMainWorld("api", {
 		panic(err)
 	}
 
-	w, _ := a.NewWindow("http://127.0.0.1:7123", &astilectron.WindowOptions{
+	w, _ := a.NewWindow(local.URL(""), &astilectron.WindowOptions{
 		Center: astikit.BoolPtr(true),
 		Height: astikit.IntPtr(920),
 		Width:  astikit.IntPtr(1600),
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
 	"math/rand"
@@ -15,15 +16,18 @@ import (
 	"github.com/BigJk/snd"
 	"github.com/BigJk/snd/database"
 	"github.com/BigJk/snd/database/badger"
+	"github.com/BigJk/snd/golden"
 	"github.com/BigJk/snd/printing/cups"
 	"github.com/BigJk/snd/printing/dump"
 	"github.com/BigJk/snd/printing/remote"
 	"github.com/BigJk/snd/printing/serial"
 	"github.com/BigJk/snd/rendering"
+	"github.com/BigJk/snd/rpc"
 	"github.com/BigJk/snd/server"
 )
 
 var serverOptions []server.Option
+var tlsConfig *server.TLSConfig
 var startFunc = startServer
 var onAlreadyRunning = func() {
 	fmt.Println("ERROR: Sales & Dungeons is already running!")
@@ -171,8 +175,67 @@ func fixWorkingDir() bool {
 
 func main() {
 	debug := flag.Bool("debug", false, "")
+	lint := flag.String("lint", "", "lint the template with the given id using the running instance and exit")
+	lintAddr := flag.String("lint-addr", "127.0.0.1:7123", "address of the running instance used by -lint and -golden. Prefix it with https:// if TLS is enabled")
+	lintFingerprint := flag.String("lint-fingerprint", "", "SHA-256 fingerprint of the certificate of the running instance used by -lint and -golden over https. Defaults to the certificate in the data dir or -tls-cert")
+	lintConfig := flag.String("lint-config", "", "template config as json used by -lint and -golden")
+	lintLimit := flag.Int("lint-limit", 0, "maximum number of entries per source checked by -lint or approved by -golden-approve (0 = all)")
+	lintSkipBrowser := flag.Bool("lint-skip-browser", false, "skip the browser checks of -lint")
+	goldenTest := flag.String("golden", "", "compare the template with the given id against its reference images using the running instance and exit")
+	goldenApprove := flag.Bool("golden-approve", false, "store the current renderings as reference images instead of comparing them with -golden")
+	goldenOut := flag.String("golden-out", "", "folder the diff images of -golden are written to")
+	goldenThreshold := flag.Int("golden-threshold", golden.DefaultTolerance.Threshold, "maximum difference of a color channel (0-255) for pixels to count as equal with -golden")
+	goldenMaxDiff := flag.Float64("golden-max-diff", golden.DefaultTolerance.MaxDiffRatio, "ratio of pixels (0-1) that are allowed to differ with -golden")
+	tlsEnabled := flag.Bool("tls", false, "serve https instead of http. Uses -tls-cert and -tls-key or a self-signed certificate in the data dir")
+	tlsCert := flag.String("tls-cert", "", "PEM encoded certificate used by -tls")
+	tlsKey := flag.String("tls-key", "", "PEM encoded private key used by -tls")
+	tlsRedirect := flag.String("tls-redirect", "", "address of a http listener that redirects to https with -tls (e.g. :80)")
 	flag.Parse()
 
+	if *tlsEnabled {
+		tlsConfig = &server.TLSConfig{
+			CertFile:     *tlsCert,
+			KeyFile:      *tlsKey,
+			RedirectAddr: *tlsRedirect,
+		}
+		serverOptions = append(serverOptions, server.WithTLS(*tlsConfig))
+	}
+
+	var config map[string]any
+	if len(*lintConfig) > 0 {
+		if err := json.Unmarshal([]byte(*lintConfig), &config); err != nil {
+			fmt.Println("ERROR: invalid -lint-config:", err)
+			os.Exit(2)
+		}
+	}
+
+	if len(*lint) > 0 || len(*goldenTest) > 0 {
+		certFile := server.TLSConfig{CertFile: *tlsCert}.CertificateFile(sndDataDir)
+		client, err := cliClient(*lintAddr, *lintFingerprint, certFile)
+		if err != nil {
+			fmt.Println("ERROR: could not read the certificate:", err)
+			os.Exit(2)
+		}
+
+		if len(*lint) > 0 {
+			os.Exit(runLint(client, *lintAddr, *lint, rpc.LintOptions{
+				Config:      config,
+				Limit:       *lintLimit,
+				SkipBrowser: *lintSkipBrowser,
+			}))
+		}
+
+		os.Exit(runGolden(client, *lintAddr, *goldenTest, *goldenApprove, *goldenOut, rpc.GoldenOptions{
+			Config: config,
+			Tolerance: &golden.Tolerance{
+				Threshold:    *goldenThreshold,
+				MaxDiffRatio: *goldenMaxDiff,
+			},
+			Limit:  *lintLimit,
+			Images: len(*goldenOut) > 0,
+		}))
+	}
+
 	fmt.Println(`
    _____        _____
   / ____| ___  |  __ \
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/BigJk/snd/rpc/bind"
 	"github.com/iancoleman/strcase"
+	"sort"
 	"strings"
 )
 
@@ -21,23 +22,30 @@ const pythonAPIHeader = `import requests
 
 class SndAPI:
 
-    def __init__(self, base_url):
+    def __init__(self, base_url, token=None, verify=True):
         """
         Initialize the Sales & Dungeon API class with the base URL of the API.
 
         Parameters:
-        - base_url (str): The base URL of the API. Most likely "http://127.0.0.1:7123"
+        - base_url (str): The base URL of the API. Most likely "http://127.0.0.1:7123" or "https://127.0.0.1:7123" if TLS is enabled
+        - token (str): The API token if authentication is enabled. Tokens can be created in the settings.
+        - verify (bool or str): Verify the certificate of the server. To pin a self-signed certificate pass
+          the path to its cert.pem, which is located in the "tls" folder of the data directory.
         """
-        self.base_url = base_url
+        self.base_url = base_url.rstrip("/")
+        self.session = requests.Session()
+        self.session.verify = verify
+        if token:
+            self.session.headers["Authorization"] = f"Bearer {token}"
 
     def _make_request(self, endpoint, method='GET', params=None):
         url = f"{self.base_url}/{endpoint}"
         if method == 'GET':
-            response = requests.get(url, params=params)
+            response = self.session.get(url, params=params)
         elif method == 'POST':
-            response = requests.post(url, json=params)
+            response = self.session.post(url, json=params)
         elif method == 'DELETE':
-            response = requests.delete(url, json=params)
+            response = self.session.delete(url, json=params)
         else:
             raise ValueError(f"Unsupported HTTP method: {method}")
         
@@ -110,11 +118,17 @@ func pythonDefineFunction(function bind.Function) string {
 
 func pythonSDK() string {
 	functions := bind.Functions()
-	functionsStr := make([]string, len(functions))
-	i := 0
-	for _, function := range functions {
-		functionsStr[i] = pythonDefineFunction(function)
-		i++
+	names := make([]string, 0, len(functions))
+	for name := range functions {
+		names = append(names, name)
+	}
+
+	// Sorted so that regenerating the SDK only changes the functions that changed
+	sort.Strings(names)
+
+	functionsStr := make([]string, len(names))
+	for i, name := range names {
+		functionsStr[i] = pythonDefineFunction(functions[name])
 	}
 
 	return fmt.Sprintf("%s\n%s", pythonAPIHeader, strings.Join(functionsStr, "\n\n"))
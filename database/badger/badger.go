@@ -177,6 +177,22 @@ func (b *Badger) GetGenerators() ([]snd.Generator, error) {
 	return fetchAll[snd.Generator](b.db, "gen:", nil)
 }
 
+func (b *Badger) GetPrompt(id string) (snd.Prompt, error) {
+	return fetchSingle[snd.Prompt](b.db, id)
+}
+
+func (b *Badger) SavePrompt(prompt snd.Prompt) error {
+	return setSingle[snd.Prompt](b.db, prompt.ID(), prompt)
+}
+
+func (b *Badger) DeletePrompt(id string) error {
+	return dropSingle(b.db, id)
+}
+
+func (b *Badger) GetPrompts() ([]snd.Prompt, error) {
+	return fetchAll[snd.Prompt](b.db, "prompt:", nil)
+}
+
 func (b *Badger) SaveSource(ds snd.DataSource) error {
 	return setSingle[snd.DataSource](b.db, ds.ID(), ds)
 }
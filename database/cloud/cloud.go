@@ -199,6 +199,26 @@ func (c *Cloud) GetGenerators() ([]snd.Generator, error) {
 	return generators, err
 }
 
+func (c *Cloud) GetPrompt(id string) (snd.Prompt, error) {
+	var prompt snd.Prompt
+	_, err := c.request(http.MethodGet, "/api/prompt/"+id, nil, &prompt)
+	return prompt, err
+}
+
+func (c *Cloud) SavePrompt(prompt snd.Prompt) error {
+	return c.requestNoStatus(http.MethodPost, "/api/prompt", prompt, nil)
+}
+
+func (c *Cloud) DeletePrompt(id string) error {
+	return c.requestNoStatus(http.MethodDelete, "/api/prompt/"+id, nil, nil)
+}
+
+func (c *Cloud) GetPrompts() ([]snd.Prompt, error) {
+	var prompts []snd.Prompt
+	_, err := c.request(http.MethodGet, "/api/prompts", nil, &prompts)
+	return prompts, err
+}
+
 func (c *Cloud) SaveSource(ds snd.DataSource) error {
 	return c.requestNoStatus(http.MethodPost, "/api/source/", ds, nil)
 }
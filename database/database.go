@@ -51,6 +51,11 @@ type Database interface {
 	GetSource(id string) (snd.DataSource, error)
 	GetSources() ([]DataSourceEntry, error)
 
+	GetPrompt(id string) (snd.Prompt, error)
+	SavePrompt(prompt snd.Prompt) error
+	DeletePrompt(id string) error
+	GetPrompts() ([]snd.Prompt, error)
+
 	GetKey(key string) (string, error)
 	SetKey(key string, value string) error
 	DeleteKey(key string) error
@@ -11,6 +11,7 @@ type Memory struct {
 	templates  map[string]snd.Template
 	entries    map[string]map[string]snd.Entry
 	generators map[string]snd.Generator
+	prompts    map[string]snd.Prompt
 	sources    map[string]snd.DataSource
 	kv         map[string]string
 }
@@ -20,6 +21,7 @@ func New() *Memory {
 		templates:  map[string]snd.Template{},
 		entries:    map[string]map[string]snd.Entry{},
 		generators: map[string]snd.Generator{},
+		prompts:    map[string]snd.Prompt{},
 		sources:    map[string]snd.DataSource{},
 	}
 }
@@ -136,6 +138,28 @@ func (m *Memory) GetGenerators() ([]snd.Generator, error) {
 	return generators, nil
 }
 
+func (m *Memory) GetPrompt(id string) (snd.Prompt, error) {
+	return m.prompts[id], nil
+}
+
+func (m *Memory) SavePrompt(prompt snd.Prompt) error {
+	m.prompts[prompt.ID()] = prompt
+	return nil
+}
+
+func (m *Memory) DeletePrompt(id string) error {
+	delete(m.prompts, id)
+	return nil
+}
+
+func (m *Memory) GetPrompts() ([]snd.Prompt, error) {
+	var prompts []snd.Prompt
+	for _, p := range m.prompts {
+		prompts = append(prompts, p)
+	}
+	return prompts, nil
+}
+
 func (m *Memory) SaveSource(ds snd.DataSource) error {
 	m.sources[ds.ID()] = ds
 	return nil
@@ -67,5 +67,12 @@ func Migrate(from Database, to Database) error {
 		}
 	}
 
+	// Copy prompts. Older databases might not support prompts yet, so they are skipped on errors.
+	if prompts, err := from.GetPrompts(); err == nil {
+		for i := range prompts {
+			_ = to.SavePrompt(prompts[i])
+		}
+	}
+
 	return nil
 }
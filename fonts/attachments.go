@@ -0,0 +1,41 @@
+package fonts
+
+import (
+	"github.com/BigJk/snd/imexport"
+)
+
+// Attachments returns the fonts used in the templates as attachments for the export.
+func Attachments(templates ...string) (imexport.Attachments, error) {
+	used, err := Used(templates...)
+	if err != nil {
+		return nil, err
+	}
+
+	res := imexport.Attachments{}
+	for i := range used {
+		data, err := Read(used[i])
+		if err != nil {
+			return nil, err
+		}
+		res.Add(imexport.FontsFolder, used[i].File, data)
+	}
+
+	return res, nil
+}
+
+// InstallAttachments adds the fonts contained in the imported attachments to the library. Fonts
+// that already exist are kept, so that an import can't replace a font other templates use.
+func InstallAttachments(attachments imexport.Attachments) error {
+	for file, data := range attachments.Files(imexport.FontsFolder) {
+		name := NameFromFile(file)
+		if _, err := Get(name); err == nil {
+			continue
+		}
+
+		if _, err := Add(name, data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
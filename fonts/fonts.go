@@ -0,0 +1,276 @@
+// Package fonts manages the font library. Fonts are stored in a folder of the data dir and are
+// available to all templates by their name, without having to install them system-wide.
+//
+// Supported are TrueType, OpenType, WOFF and WOFF2 files. The format is detected from
+// the content of the file and not its extension.
+package fonts
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Font represents a font in the library.
+type Font struct {
+	Name   string `json:"name"`
+	File   string `json:"file"`
+	Format string `json:"format"`
+	Size   int64  `json:"size"`
+}
+
+// MimeType returns the mime type of the font.
+func (f Font) MimeType() string {
+	switch f.Format {
+	case "truetype":
+		return "font/ttf"
+	case "opentype":
+		return "font/otf"
+	default:
+		return "font/" + f.Format
+	}
+}
+
+// formats maps the file extensions to the css format names.
+var formats = map[string]string{
+	".ttf":   "truetype",
+	".otf":   "opentype",
+	".woff":  "woff",
+	".woff2": "woff2",
+}
+
+// validName represents valid characters for font names.
+// Valid: a-z, A-Z, 0-9, space, - and _
+var validName = regexp.MustCompile(`^[a-zA-Z0-9 _\-]+$`)
+
+var (
+	dirMtx sync.RWMutex
+	dir    string
+)
+
+// SetDir sets the folder the fonts are stored in.
+func SetDir(folder string) {
+	dirMtx.Lock()
+	defer dirMtx.Unlock()
+	dir = folder
+}
+
+func getDir() (string, error) {
+	dirMtx.RLock()
+	defer dirMtx.RUnlock()
+
+	if dir == "" {
+		return "", errors.New("font folder is not set")
+	}
+	return dir, nil
+}
+
+// detectExtension returns the file extension matching the content of the font file.
+func detectExtension(data []byte) (string, error) {
+	switch {
+	case bytes.HasPrefix(data, []byte{0x00, 0x01, 0x00, 0x00}), bytes.HasPrefix(data, []byte("true")):
+		return ".ttf", nil
+	case bytes.HasPrefix(data, []byte("OTTO")):
+		return ".otf", nil
+	case bytes.HasPrefix(data, []byte("wOFF")):
+		return ".woff", nil
+	case bytes.HasPrefix(data, []byte("wOF2")):
+		return ".woff2", nil
+	}
+	return "", errors.New("unsupported font format (supported: ttf, otf, woff, woff2)")
+}
+
+// NameFromFile returns the font name for a file name (e.g. "Press Start 2P.ttf" => "Press Start 2P").
+func NameFromFile(file string) string {
+	return strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+}
+
+// List returns all fonts in the library sorted by name. If the folder
+// is not set the library is empty.
+func List() ([]Font, error) {
+	folder, err := getDir()
+	if err != nil {
+		return []Font{}, nil
+	}
+
+	files, err := os.ReadDir(folder)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Font{}, nil
+		}
+		return nil, err
+	}
+
+	res := []Font{}
+	for _, file := range files {
+		format, ok := formats[strings.ToLower(filepath.Ext(file.Name()))]
+		if file.IsDir() || !ok {
+			continue
+		}
+
+		info, err := file.Info()
+		if err != nil {
+			continue
+		}
+
+		res = append(res, Font{
+			Name:   NameFromFile(file.Name()),
+			File:   file.Name(),
+			Format: format,
+			Size:   info.Size(),
+		})
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Name < res[j].Name
+	})
+
+	return res, nil
+}
+
+// Get returns the font with the given name.
+func Get(name string) (Font, error) {
+	fonts, err := List()
+	if err != nil {
+		return Font{}, err
+	}
+
+	for i := range fonts {
+		if fonts[i].Name == name {
+			return fonts[i], nil
+		}
+	}
+
+	return Font{}, fmt.Errorf("font '%s' not found", name)
+}
+
+// Read returns the content of the font file.
+func Read(font Font) ([]byte, error) {
+	folder, err := getDir()
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(filepath.Join(folder, filepath.Base(font.File)))
+}
+
+// Add stores the font under the given name. An existing font with the same name is replaced.
+func Add(name string, data []byte) (Font, error) {
+	folder, err := getDir()
+	if err != nil {
+		return Font{}, err
+	}
+
+	name = strings.TrimSpace(name)
+	if !validName.MatchString(name) {
+		return Font{}, errors.New("font name is empty or contains illegal characters")
+	}
+
+	ext, err := detectExtension(data)
+	if err != nil {
+		return Font{}, err
+	}
+
+	if err := os.MkdirAll(folder, 0777); err != nil {
+		return Font{}, err
+	}
+
+	// Remove the font first in case it was stored in a different format before.
+	if err := Remove(name); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return Font{}, err
+	}
+
+	file := name + ext
+	if err := os.WriteFile(filepath.Join(folder, file), data, 0666); err != nil {
+		return Font{}, err
+	}
+
+	return Font{
+		Name:   name,
+		File:   file,
+		Format: formats[ext],
+		Size:   int64(len(data)),
+	}, nil
+}
+
+// Remove deletes the font with the given name.
+func Remove(name string) error {
+	folder, err := getDir()
+	if err != nil {
+		return err
+	}
+
+	font, err := Get(name)
+	if err != nil {
+		return fmt.Errorf("%w: %v", os.ErrNotExist, err)
+	}
+
+	return os.Remove(filepath.Join(folder, font.File))
+}
+
+// fontDeclaration matches the value of font-family and font declarations in CSS and style attributes.
+var fontDeclaration = regexp.MustCompile(`(?i)font(-family)?\s*:\s*([^;}>\n]+)`)
+
+// Used returns the fonts that are used by a font-family or font declaration in one of the templates.
+func Used(templates ...string) ([]Font, error) {
+	fonts, err := List()
+	if err != nil {
+		return nil, err
+	}
+
+	families := map[string]bool{}
+	for _, tmpl := range templates {
+		// Entities are decoded first so quotes in style attributes don't end the declaration
+		for _, match := range fontDeclaration.FindAllStringSubmatch(html.UnescapeString(tmpl), -1) {
+			shorthand := len(match[1]) == 0
+			for i, family := range strings.Split(match[2], ",") {
+				family = strings.ToLower(strings.TrimSpace(strings.NewReplacer(`"`, "", "'", "").Replace(family)))
+
+				// In the font shorthand the first family follows the size, e.g. "bold 12px My Font"
+				if shorthand && i == 0 {
+					families[family] = true
+					for j := range family {
+						if family[j] == ' ' {
+							families[family[j+1:]] = true
+						}
+					}
+					continue
+				}
+
+				families[family] = true
+			}
+		}
+	}
+
+	var res []Font
+	for i := range fonts {
+		if families[strings.ToLower(fonts[i].Name)] {
+			res = append(res, fonts[i])
+		}
+	}
+
+	return res, nil
+}
+
+// FontFaces returns the @font-face rules of all fonts in the library. The font
+// files are expected to be served under baseURL followed by the file name.
+func FontFaces(baseURL string) (string, error) {
+	fonts, err := List()
+	if err != nil {
+		return "", err
+	}
+
+	buf := &strings.Builder{}
+	for i := range fonts {
+		_, _ = fmt.Fprintf(buf, "@font-face { font-family: %q; src: url(%q) format(%q); }\n", fonts[i].Name, strings.TrimRight(baseURL, "/")+"/"+url.PathEscape(fonts[i].File), fonts[i].Format)
+	}
+
+	return buf.String(), nil
+}
@@ -0,0 +1,53 @@
+package fonts
+
+import (
+	"testing"
+)
+
+func TestUsed(t *testing.T) {
+	SetDir(t.TempDir())
+	defer SetDir("")
+
+	ttf := []byte{0x00, 0x01, 0x00, 0x00, 0x00}
+	for _, name := range []string{"Arial", "Arial Narrow", "My Font"} {
+		if _, err := Add(name, ttf); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cases := []struct {
+		name     string
+		template string
+		expected []string
+	}{
+		{"body text", `<p>Arial is a nice font</p>`, nil},
+		{"longer family", `<style>p { font-family: "Arial Narrow", sans-serif; }</style>`, []string{"Arial Narrow"}},
+		{"fallback list", `<style>p { font-family: 'My Font', Arial; }</style>`, []string{"Arial", "My Font"}},
+		{"shorthand", `<style>h1 { font: bold 12px 'My Font'; }</style>`, []string{"My Font"}},
+		{"style attribute", `<div style="font-family: &quot;Arial&quot;">x</div>`, []string{"Arial"}},
+		{"case insensitive", `<style>p { FONT-FAMILY: arial narrow }</style>`, []string{"Arial Narrow"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			used, err := Used(c.template)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var names []string
+			for i := range used {
+				names = append(names, used[i].Name)
+			}
+
+			if len(names) != len(c.expected) {
+				t.Fatalf("expected %v, got %v", c.expected, names)
+			}
+			for i := range names {
+				if names[i] != c.expected[i] {
+					t.Fatalf("expected %v, got %v", c.expected, names)
+				}
+			}
+		})
+	}
+}
@@ -23,6 +23,7 @@ import GeneratorCreate from 'js/ui/views/generator/create';
 import GeneratorEdit from 'js/ui/views/generator/edit';
 import GeneratorSingle from 'js/ui/views/generator/single';
 import Home from 'js/ui/views/home';
+import Login from 'js/ui/views/login';
 import SessionGrid from 'js/ui/views/session-grid';
 import Settings from 'js/ui/views/settings';
 import TemplateAll from 'js/ui/views/template/all';
@@ -37,65 +38,94 @@ import WorkshopSingle from 'js/ui/views/workshop/single';
 import * as Portal from 'js/ui/portal';
 import * as Toast from 'js/ui/toast';
 
+const root = document.getElementById('app') ?? document.body;
+
 // Load all the data from the backend and then start the router.
-store.actions.loadAll().then(() => {
-	store.actions.setRandomAIToken();
-	console.log('Store initialized:', store.value);
+const start = () => {
+	// Users without access to the settings use the defaults.
+	const noSettings = store.value.auth.permissions?.settings === 'none';
 
-	// Save settings when they change.
-	settings.subscribe((state) => {
-		if (!state) return;
+	store.actions.loadAll(noSettings).then(() => {
+		store.actions.setRandomAIToken();
+		console.log('Store initialized:', store.value);
 
-		store.actions
-			.saveSettings()
-			.then(() => Toast.success('Settings saved successfully.'))
-			.catch((e) => {
-				Toast.error(e);
-				console.error('Failed to save settings:', e);
-			});
-	});
+		// Save settings when they change.
+		settings.subscribe((state) => {
+			if (!state) return;
+
+			store.actions
+				.saveSettings()
+				.then(() => Toast.success('Settings saved successfully.'))
+				.catch((e) => {
+					Toast.error(e);
+					console.error('Failed to save settings:', e);
+				});
+		});
 
-	m.route(document.getElementById('app') ?? document.body, '/', {
-		'/': Home,
-		'/devices': Devices,
-		'/settings': Settings,
-		'/template': TemplateAll,
-		'/template/create': TemplateCreate,
-		'/template/create/:id': TemplateCreate,
-		'/template/:id': TemplateSingle,
-		'/template/:id/edit': TemplateEdit,
-		'/template/:id/create': TemplateCreateEntity,
-		'/template/:id/edit/:eid': TemplateCreateEntity,
-		'/generator': GeneratorAll,
-		'/generator/create': GeneratorCreate,
-		'/generator/create/:id': GeneratorCreate,
-		'/generator/:id': GeneratorSingle,
-		'/generator/:id/edit': GeneratorEdit,
-		'/session-grid': SessionGrid,
-		'/data-source': DataSourceAll,
-		'/data-source/:id/edit': DataSourceEdit,
-		'/data-source/:id': DataSourceSingle,
-		'/workshop': WorkshopAll,
-		'/workshop/:id': WorkshopSingle,
-		'/workshop/:id/:repo': WorkshopRepo,
-		'/extern-print/template/:id/:json/:config': ExternPrintTemplate,
-		'/extern-print/generator/:id/:config': ExternPrintGenerator,
+		m.route(root, '/', {
+			'/': Home,
+			'/devices': Devices,
+			'/settings': Settings,
+			'/template': TemplateAll,
+			'/template/create': TemplateCreate,
+			'/template/create/:id': TemplateCreate,
+			'/template/:id': TemplateSingle,
+			'/template/:id/edit': TemplateEdit,
+			'/template/:id/create': TemplateCreateEntity,
+			'/template/:id/edit/:eid': TemplateCreateEntity,
+			'/generator': GeneratorAll,
+			'/generator/create': GeneratorCreate,
+			'/generator/create/:id': GeneratorCreate,
+			'/generator/:id': GeneratorSingle,
+			'/generator/:id/edit': GeneratorEdit,
+			'/session-grid': SessionGrid,
+			'/data-source': DataSourceAll,
+			'/data-source/:id/edit': DataSourceEdit,
+			'/data-source/:id': DataSourceSingle,
+			'/workshop': WorkshopAll,
+			'/workshop/:id': WorkshopSingle,
+			'/workshop/:id/:repo': WorkshopRepo,
+			'/extern-print/template/:id/:json/:config': ExternPrintTemplate,
+			'/extern-print/generator/:id/:config': ExternPrintGenerator,
+		});
+
+		document.addEventListener('keydown', (e) => {
+			// Create CMD+O / CTRL+O shortcut to open spotlight.
+			if (e.key === 'o' && (e.metaKey || e.ctrlKey)) {
+				e.preventDefault();
+				Portal.setPortal(Spotlight, {
+					className: '.mt5',
+					items: 'start',
+				});
+			}
+
+			// Create ESC shortcut to close spotlight.
+			if (Portal.hasPortal() && e.key === 'Escape') {
+				e.preventDefault();
+				Portal.popPortal();
+			}
+		});
 	});
+};
 
-	document.addEventListener('keydown', (e) => {
-		// Create CMD+O / CTRL+O shortcut to open spotlight.
-		if (e.key === 'o' && (e.metaKey || e.ctrlKey)) {
-			e.preventDefault();
-			Portal.setPortal(Spotlight, {
-				className: '.mt5',
-				items: 'start',
+// Ask for a login if the authentication is enabled.
+store.actions
+	.loadAuth()
+	.then(() => {
+		const { auth } = store.value;
+		if (auth.enabled && !auth.identity) {
+			m.mount(root, {
+				view: () =>
+					m(Login, {
+						onLogin: () => {
+							m.mount(root, null);
+							start();
+						},
+					}),
 			});
+			return;
 		}
 
-		// Create ESC shortcut to close spotlight.
-		if (Portal.hasPortal() && e.key === 'Escape') {
-			e.preventDefault();
-			Portal.popPortal();
-		}
-	});
-});
+		start();
+	})
+	.catch(start);
@@ -1,7 +1,7 @@
 import Entry from 'js/types/entry';
 import Template from 'js/types/template';
 import * as API from 'js/core/api';
-import { AI_GENERATE } from 'js/core/api';
+import { AI_GENERATE, AI_GENERATE_GROUNDED, AI_GENERATE_STREAM } from 'js/core/api';
 import { safeCall } from 'js/core/safe';
 import { settings } from 'js/core/store';
 
@@ -21,8 +21,18 @@ function extractJSON(str: string) {
  * @param prompt The prompt to use.
  * @param template The template to use.
  * @param entries The pool of entries to use as possible examples. They will be chosen randomly.
+ * @param onChunk Optional callback that receives the parts of the response while it is generated.
+ * @param signal Optional signal to cancel the generation.
+ * @param sources Optional data sources. The entries that are most relevant for the prompt are given to the AI. The response is not streamed in this case.
  */
-export const generateEntry = (prompt: string, template: Template, entries: Entry[]) => {
+export const generateEntry = (
+	prompt: string,
+	template: Template,
+	entries: Entry[],
+	onChunk?: (chunk: string) => void,
+	signal?: AbortSignal,
+	sources?: string[],
+) => {
 	let system = `
 	You output JSON.
 	You are a helper to generate data in a Software.
@@ -49,7 +59,17 @@ export const generateEntry = (prompt: string, template: Template, entries: Entry
 			${e}`;
 	});
 
-	return API.exec<string>(AI_GENERATE, system, prompt, 'AI_ENTRY' + Math.floor(Math.random() * 50000).toString()).then((data) =>
+	const token = 'AI_ENTRY' + Math.floor(Math.random() * 50000).toString();
+	let response: Promise<string>;
+	if (sources && sources.length > 0) {
+		response = API.exec<string>(AI_GENERATE_GROUNDED, system, prompt, sources, token);
+	} else if (onChunk) {
+		response = API.stream(AI_GENERATE_STREAM, [system, prompt, token], onChunk, signal);
+	} else {
+		response = API.exec<string>(AI_GENERATE, system, prompt, token);
+	}
+
+	return response.then((data) =>
 		safeCall(() => {
 			let resp = {};
 			if (data[0] === '{') resp = JSON.parse(data);
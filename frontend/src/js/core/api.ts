@@ -19,6 +19,55 @@ export function exec<T>(type: String, ...args: any[]): Promise<T> {
 	});
 }
 
+/**
+ * Stream calls a backend function that responds with server-sent events and passes every
+ * chunk of the response to onChunk. The request can be cancelled with the signal.
+ * @param type The function to call on the backend.
+ * @param args The arguments to pass to the backend function.
+ * @param onChunk Called with every part of the response.
+ * @param signal Optional signal to cancel the request.
+ * @returns The complete response. Errors of the AI have a kind like 'auth' or 'rate_limit'.
+ */
+export async function stream(type: String, args: any[], onChunk: (chunk: string) => void, signal?: AbortSignal): Promise<string> {
+	const res = await fetch('/api/' + type, {
+		method: 'POST',
+		headers: { 'Content-Type': 'application/json' },
+		body: JSON.stringify(args),
+		signal,
+	});
+
+	if (!res.ok || !res.body) {
+		throw new Error(await res.text());
+	}
+
+	const reader = res.body.getReader();
+	const decoder = new TextDecoder();
+	let buffer = '';
+
+	while (true) {
+		const { done, value } = await reader.read();
+		if (done) break;
+
+		buffer += decoder.decode(value, { stream: true });
+
+		let end = buffer.indexOf('\n\n');
+		while (end >= 0) {
+			const line = buffer.substring(0, end);
+			buffer = buffer.substring(end + 2);
+			end = buffer.indexOf('\n\n');
+
+			if (!line.startsWith('data: ')) continue;
+
+			const event = JSON.parse(line.substring(6));
+			if (event.error) throw Object.assign(new Error(event.error), { kind: event.kind });
+			if (event.done) return event.response;
+			onChunk(event.chunk);
+		}
+	}
+
+	throw new Error('stream ended without response');
+}
+
 // Settings functions
 export const GET_SETTINGS = 'getSettings';
 export const SAVE_SETTINGS = 'saveSettings';
@@ -54,6 +103,8 @@ export const DELETE_TEMPLATE = 'deleteTemplate';
 export const GET_TEMPLATE_IMPORTS = 'importsTemplate';
 export const GET_TEMPLATE_EXPORTS = 'exportsTemplate';
 export const IMPORT_TEMPLATE = 'importsTemplate';
+export const PREVIEW_IMPORT_TEMPLATE = 'previewImportsTemplate';
+export const MERGE_IMPORT_TEMPLATE = 'mergeImportsTemplate';
 export const EXPORT_TEMPLATE = 'exportsTemplate';
 
 // Generators
@@ -66,6 +117,16 @@ export const GET_GENERATOR_EXPORTS = 'exportsGenerator';
 export const IMPORT_GENERATOR = 'importsGenerator';
 export const EXPORT_GENERATOR = 'exportsGenerator';
 
+// Prompts
+export const GET_PROMPTS = 'getPrompts';
+export const GET_PROMPT = 'getPrompt';
+export const SAVE_PROMPT = 'savePrompt';
+export const DELETE_PROMPT = 'deletePrompt';
+export const GET_PROMPT_IMPORTS = 'importsPrompt';
+export const GET_PROMPT_EXPORTS = 'exportsPrompt';
+export const IMPORT_PROMPT = 'importsPrompt';
+export const EXPORT_PROMPT = 'exportsPrompt';
+
 // Data Sources
 export const GET_SOURCES = 'getSources';
 export const GET_SOURCE = 'getSource';
@@ -74,7 +135,19 @@ export const DELETE_SOURCE = 'deleteSource';
 export const GET_SOURCE_IMPORTS = 'importsSource';
 export const GET_SOURCE_EXPORTS = 'exportsSource';
 export const IMPORT_SOURCE = 'importsSource';
+export const PREVIEW_IMPORT_SOURCE = 'previewImportsSource';
+export const MERGE_IMPORT_SOURCE = 'mergeImportsSource';
+export const GET_IMPORT_STRATEGIES = 'importsSourceStrategies';
+export const GET_IMPORT_MAPPING = 'getImportMapping';
+export const SAVE_IMPORT_MAPPING = 'saveImportMapping';
+export const DELETE_IMPORT_MAPPING = 'deleteImportMapping';
+export const TEST_IMPORT_MAPPING = 'testImportMapping';
 export const EXPORT_SOURCE = 'exportsSource';
+export const EXPORT_SOURCES_XLSX = 'exportSourcesXLSX';
+
+// Hot Folder
+export const HOT_FOLDER = 'hotFolder';
+export const HOT_FOLDER_RESULTS = 'hotFolderResults';
 
 // Packages
 export const GET_PUBLIC_LIST = 'getPublicPackages';
@@ -100,10 +173,34 @@ export const SYNC_LOCAL_TO_CLOUD = 'syncLocalToCloud';
 
 // AI
 export const AI_GENERATE = 'aiPrompt';
+export const AI_GENERATE_STREAM = 'aiPromptStream';
 export const AI_GENERATE_CODING = 'aiCodingPrompt';
+export const AI_GENERATE_ENTRIES = 'aiGenerateEntries';
+export const AI_GENERATE_GROUNDED = 'aiGroundedPrompt';
+export const AI_RETRIEVE = 'aiRetrieve';
+export const AI_RUN_PROMPT = 'aiRunPrompt';
 export const AI_MODELS = 'aiModels';
 export const AI_PROVIDERS = 'aiProviders';
+export const AI_USAGE = 'aiUsage';
 export const AI_INVALIDATE_CACHE = 'aiInvalidateCached';
+export const AI_CACHE_LIST = 'aiCacheList';
+export const AI_CACHE_GET = 'aiCacheGet';
+export const AI_CACHE_PIN = 'aiCachePin';
+export const AI_CACHE_DELETE = 'aiCacheDelete';
+export const AI_CACHE_EVICT = 'aiCacheEvict';
+
+// Authentication
+export const AUTH_STATUS = 'auth/status';
+export const AUTH_LOGIN = 'auth/login';
+export const AUTH_LOGOUT = 'auth/logout';
+export const GET_AUTH_CONFIG = 'getAuthConfig';
+export const SET_AUTH_ENABLED = 'setAuthEnabled';
+export const SET_AUTH_TRUST_LOCAL = 'setAuthTrustLocal';
+export const SET_AUTH_PERMISSIONS = 'setAuthPermissions';
+export const SAVE_AUTH_USER = 'saveAuthUser';
+export const DELETE_AUTH_USER = 'deleteAuthUser';
+export const CREATE_AUTH_TOKEN = 'createAuthToken';
+export const DELETE_AUTH_TOKEN = 'deleteAuthToken';
 
 // File Browser
 export const GET_FILES = 'getFiles';
@@ -4,6 +4,7 @@ import { flatten } from 'lodash-es';
 import Fuse from 'fuse.js';
 import { create } from 'xoid';
 
+import AuthStatus from 'js/types/auth';
 import BasicInfo, { buildId } from 'js/types/basic-info';
 import DataSource from 'js/types/data-source';
 import Generator from 'js/types/generator';
@@ -64,6 +65,7 @@ type Store = {
 		token: string;
 	};
 	dataDir: string;
+	auth: AuthStatus;
 };
 
 const initialState: Store = {
@@ -83,6 +85,11 @@ const initialState: Store = {
 		token: '',
 	},
 	dataDir: '',
+	auth: {
+		enabled: false,
+		identity: null,
+		permissions: null,
+	},
 };
 
 const buildFuzzySearch = (state: Store) =>
@@ -123,6 +130,48 @@ const store = create(initialState, (atom) => ({
 		]);
 	},
 
+	/**
+	 * LoadAuth loads if the authentication is enabled and who is logged in.
+	 */
+	loadAuth() {
+		return m
+			.request<AuthStatus>({
+				method: 'GET',
+				url: '/api/' + API.AUTH_STATUS,
+			})
+			.then((res) =>
+				atom.update((state) => ({
+					...state,
+					auth: res,
+				})),
+			);
+	},
+
+	/**
+	 * Login logs the user into the UI. The session is stored in a cookie by the backend.
+	 */
+	login(name: string, password: string) {
+		return m
+			.request({
+				method: 'POST',
+				url: '/api/' + API.AUTH_LOGIN,
+				body: [name, password],
+			})
+			.then(() => this.loadAuth());
+	},
+
+	/**
+	 * Logout ends the session of the user.
+	 */
+	logout() {
+		return m
+			.request({
+				method: 'POST',
+				url: '/api/' + API.AUTH_LOGOUT,
+			})
+			.then(() => this.loadAuth());
+	},
+
 	/**
 	 * LoadDataDir loads the data directory from the backend.
 	 */
@@ -288,3 +337,4 @@ export const printerTypes = store.focus('printerTypes');
 export const packages = store.focus('publicLists');
 export const ai = store.focus('ai');
 export const dataDir = store.focus('dataDir');
+export const auth = store.focus('auth');
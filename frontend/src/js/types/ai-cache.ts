@@ -0,0 +1,26 @@
+type CacheInfo = {
+	key: string;
+	token: string;
+	promptHash: string;
+	model: string;
+	created: string;
+	lastUsed: string;
+	size: number;
+	pinned: boolean;
+};
+
+type CacheEntry = CacheInfo & {
+	system: string;
+	user: string;
+	response: string;
+};
+
+type CacheStats = {
+	entries: CacheInfo[];
+	size: number;
+	ttl: number;
+	maxSize: number;
+};
+
+export default CacheStats;
+export { CacheInfo, CacheEntry };
@@ -0,0 +1,33 @@
+type UsageTotals = {
+	requests: number;
+	promptTokens: number;
+	completionTokens: number;
+	estimatedTokens: number;
+	unpricedTokens: number;
+	cost: number;
+};
+
+type ModelUsage = UsageTotals & {
+	provider: string;
+	model: string;
+};
+
+type DayUsage = {
+	date: string;
+	models: ModelUsage[];
+	total: UsageTotals;
+};
+
+type UsageReport = {
+	days: DayUsage[] | null;
+	total: UsageTotals;
+	today: UsageTotals;
+	dailyBudget: number;
+	dailyTokenLimit: number;
+	rateLimit: number;
+	recentRequests: number;
+	budgetWarning: string;
+};
+
+export default UsageReport;
+export { UsageTotals, ModelUsage, DayUsage };
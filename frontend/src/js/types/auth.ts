@@ -0,0 +1,45 @@
+type Role = 'admin' | 'gm' | 'player';
+
+type Group = 'content' | 'print' | 'ai' | 'settings' | 'system';
+
+type Access = 'none' | 'read' | 'write';
+
+type Permissions = Record<Group, Access>;
+
+type Identity = {
+	name: string;
+	role: Role;
+};
+
+type AuthStatus = {
+	enabled: boolean;
+	identity: Identity | null;
+	permissions: Permissions | null;
+};
+
+type AuthUser = {
+	name: string;
+	role: Role;
+};
+
+type AuthToken = {
+	id: string;
+	name: string;
+	role: Role;
+	created: string;
+};
+
+type AuthConfig = {
+	enabled: boolean;
+	trustLocal: boolean;
+	users: AuthUser[];
+	tokens: AuthToken[];
+	permissions: Record<Role, Permissions>;
+};
+
+const Roles: Role[] = ['admin', 'gm', 'player'];
+
+const Groups: Group[] = ['content', 'print', 'ai', 'settings', 'system'];
+
+export default AuthStatus;
+export { Role, Group, Access, Permissions, Identity, AuthUser, AuthToken, AuthConfig, Roles, Groups };
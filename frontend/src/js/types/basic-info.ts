@@ -10,10 +10,10 @@ type BasicInfo = {
 export default BasicInfo;
 
 /**
- * Builds an ID for a template, generator or data source.
+ * Builds an ID for a template, generator, data source or prompt.
  * @param type The type of the item.
  * @param info The basic info of the item.
  */
-export function buildId(type: 'template' | 'generator' | 'source', info: BasicInfo): string {
-	return `${{ template: 'tmpl', generator: 'gen', source: 'ds' }[type]}:${info.author}+${info.slug}`;
+export function buildId(type: 'template' | 'generator' | 'source' | 'prompt', info: BasicInfo): string {
+	return `${{ template: 'tmpl', generator: 'gen', source: 'ds', prompt: 'prompt' }[type]}:${info.author}+${info.slug}`;
 }
@@ -11,3 +11,20 @@ export type ImportExport = {
 	description: string;
 	arguments: Argument[];
 };
+
+export type EntryChange = {
+	id: string;
+	name: string;
+	locallyModified: boolean;
+};
+
+export type ImportDiff = {
+	source?: string;
+	template?: string;
+	name: string;
+	exists: boolean;
+	new: EntryChange[];
+	changed: EntryChange[];
+	removed: EntryChange[];
+	unchanged: number;
+};
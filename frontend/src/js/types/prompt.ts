@@ -0,0 +1,42 @@
+import BasicInfo from 'js/types/basic-info';
+
+type PromptVariable = {
+	key: string;
+	name: string;
+	description: string;
+	default: string;
+};
+
+type PromptRevision = {
+	version: string;
+	system: string;
+	user: string;
+	variables: PromptVariable[];
+	date: string;
+};
+
+type Prompt = BasicInfo & {
+	system: string;
+	user: string;
+	variables: PromptVariable[];
+	history?: PromptRevision[];
+};
+
+/**
+ * Creates an empty prompt object.
+ */
+function createEmptyPrompt(): Prompt {
+	return {
+		name: 'Your Prompt Name',
+		description: '',
+		author: 'username',
+		slug: 'your-prompt-name',
+		version: '',
+		system: '',
+		user: '',
+		variables: [],
+	};
+}
+
+export default Prompt;
+export { PromptVariable, PromptRevision, createEmptyPrompt };
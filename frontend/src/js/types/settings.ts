@@ -28,6 +28,15 @@ type Settings = {
 	aiContextWindow: number;
 	aiMaxTokens: number;
 	aiUrl: string;
+	aiEmbeddingModel: string;
+	aiDailyBudget: number;
+	aiDailyTokenLimit: number;
+	aiRateLimit: number;
+	aiTimeout: number;
+	aiCacheTtl: number;
+	aiCacheMaxSize: number;
+	renderPoolSize: number;
+	hotFolderStrategy: string;
 };
 
 /**
@@ -62,6 +71,15 @@ export function createEmptySettings(): Settings {
 		aiContextWindow: 0,
 		aiMaxTokens: 0,
 		aiUrl: '',
+		aiEmbeddingModel: '',
+		aiDailyBudget: 0,
+		aiDailyTokenLimit: 0,
+		aiRateLimit: 0,
+		aiTimeout: 0,
+		aiCacheTtl: 0,
+		aiCacheMaxSize: 0,
+		renderPoolSize: 0,
+		hotFolderStrategy: 'keep-local',
 	};
 }
 
@@ -0,0 +1,253 @@
+import m from 'mithril';
+
+import { Access, AuthConfig, Groups, Permissions, Role, Roles } from 'js/types/auth';
+import * as API from 'js/core/api';
+import store from 'js/core/store';
+
+import Button from 'js/ui/shoelace/button';
+import Checkbox from 'js/ui/shoelace/checkbox';
+import IconButton from 'js/ui/shoelace/icon-button';
+import Select from 'js/ui/shoelace/select';
+
+import HorizontalProperty from 'js/ui/components/horizontal-property';
+import Flex from 'js/ui/components/layout/flex';
+import { openPromptModal } from 'js/ui/components/modals/prompt';
+import PropertyHeader from 'js/ui/components/view-layout/property-header';
+
+import { dialogWarning, error, success } from 'js/ui/toast';
+
+const roleNames: Record<Role, string> = {
+	admin: 'Admin',
+	gm: 'Game Master',
+	player: 'Player',
+};
+
+const groupDescriptions: Record<string, string> = {
+	content: 'Templates, generators, prompts, data sources, entries and fonts',
+	print: 'Printing and preview images. The image proxy requires write access',
+	ai: 'AI prompts and the AI cache',
+	settings: 'The settings',
+	system: 'File browser, git, cloud sync, hot folder and other access to this machine',
+};
+
+/**
+ * AccessControl manages the users, API tokens and the permissions of the roles. Only admins can use it.
+ */
+export default (): m.Component => {
+	let config: AuthConfig | null = null;
+	let tokenRole: Role = 'player';
+
+	const fetchConfig = () => {
+		API.exec<AuthConfig>(API.GET_AUTH_CONFIG)
+			.then((res) => {
+				config = res;
+				m.redraw();
+			})
+			.catch(error);
+	};
+
+	const run = (promise: Promise<unknown>, message: string) =>
+		promise
+			.then(() => success(message))
+			.catch(error)
+			.finally(fetchConfig);
+
+	const setEnabled = (enabled: boolean) => {
+		run(API.exec(API.SET_AUTH_ENABLED, enabled), enabled ? 'Authentication enabled' : 'Authentication disabled');
+	};
+
+	const setTrustLocal = (trust: boolean) => {
+		run(API.exec(API.SET_AUTH_TRUST_LOCAL, trust), 'Saved');
+	};
+
+	const setPermissions = (role: Role, permissions: Permissions) => {
+		run(API.exec(API.SET_AUTH_PERMISSIONS, role, permissions), 'Permissions saved');
+	};
+
+	const addUser = () => {
+		openPromptModal({
+			title: 'Add User',
+			label: 'Name',
+			description: 'The name the user logs in with',
+			buttonText: 'Next',
+			onSuccess: (name) => {
+				// The first user has to be an admin, so that the authentication can be enabled.
+				const role: Role = config?.users.some((user) => user.role === 'admin') ? 'player' : 'admin';
+				setTimeout(() => changePassword(name, role), 0);
+			},
+		});
+	};
+
+	const changePassword = (name: string, role: Role) => {
+		openPromptModal({
+			title: 'Password',
+			label: 'Password',
+			description: `The password of ${name} (at least 8 characters)`,
+			buttonText: 'Save',
+			onSuccess: (password) => run(API.exec(API.SAVE_AUTH_USER, name, password, role), 'User saved'),
+		});
+	};
+
+	const deleteUser = (name: string) => {
+		dialogWarning(`Are you sure you want to delete the user ${name}?`)
+			.then(() => run(API.exec(API.DELETE_AUTH_USER, name), 'User deleted'))
+			.catch(() => {});
+	};
+
+	const createToken = () => {
+		openPromptModal({
+			title: 'Create API Token',
+			label: 'Name',
+			description: 'A name to recognize the token, e.g. the script or device that uses it',
+			buttonText: 'Create',
+			onSuccess: (name) => {
+				API.exec<string>(API.CREATE_AUTH_TOKEN, name, tokenRole)
+					.then((token) => {
+						fetchConfig();
+						openPromptModal({
+							title: 'API Token',
+							label: 'Token',
+							description: 'Copy the token now, it will not be shown again. Send it as "Authorization: Bearer <token>" header.',
+							value: token,
+							buttonText: 'Done',
+							onSuccess: () => {},
+						});
+					})
+					.catch(error);
+			},
+		});
+	};
+
+	const deleteToken = (id: string, name: string) => {
+		dialogWarning(`Are you sure you want to delete the token ${name}?`)
+			.then(() => run(API.exec(API.DELETE_AUTH_TOKEN, id), 'Token deleted'))
+			.catch(() => {});
+	};
+
+	const roleSelect = (selected: Role, onInput: (role: Role) => void) =>
+		m(Select, {
+			keys: Roles,
+			names: Roles.map((role) => roleNames[role]),
+			selected,
+			noDefault: true,
+			onInput: (e) => onInput(e.value as Role),
+		});
+
+	return {
+		oninit() {
+			fetchConfig();
+		},
+		view() {
+			if (!config) return null;
+
+			const current = config;
+			const identity = store.value.auth.identity;
+
+			return m('div', [
+				m(PropertyHeader, {
+					className: '.mt3',
+					title: 'Access Control',
+					description: 'Require a login for the UI and API tokens for scripts. Other websites can only use the API while this is disabled.',
+					icon: 'lock-closed',
+				}),
+				m(
+					HorizontalProperty,
+					{
+						label: 'Enable Authentication',
+						description: 'Require a login or API token for every request. Create an admin user first.',
+						centered: true,
+						bottomBorder: true,
+					},
+					m(Checkbox, { checked: current.enabled, onChange: setEnabled }),
+				),
+				m(
+					HorizontalProperty,
+					{
+						label: 'Trust This Machine',
+						description: 'Requests from the machine S&D runs on are handled as admin without login.',
+						centered: true,
+						bottomBorder: true,
+					},
+					m(Checkbox, { checked: current.trustLocal, onChange: setTrustLocal }),
+				),
+				//
+				// Users
+				...current.users.map((user) =>
+					m(
+						HorizontalProperty,
+						{
+							label: user.name,
+							description: identity?.name === user.name ? 'User (you)' : 'User',
+							centered: true,
+							bottomBorder: true,
+						},
+						m(Flex, { gap: 2, items: 'center' }, [
+							roleSelect(user.role, (role) => run(API.exec(API.SAVE_AUTH_USER, user.name, '', role), 'User saved')),
+							m(IconButton, { icon: 'key', intend: 'primary', onClick: () => changePassword(user.name, user.role) }),
+							m(IconButton, { icon: 'trash', intend: 'error', onClick: () => deleteUser(user.name) }),
+						]),
+					),
+				),
+				m(
+					HorizontalProperty,
+					{
+						label: 'Add User',
+						description: 'Users log into the UI with their name and password.',
+						centered: true,
+						bottomBorder: true,
+					},
+					m(Button, { intend: 'primary', onClick: addUser }, 'Add User'),
+				),
+				//
+				// Tokens
+				...current.tokens.map((token) =>
+					m(
+						HorizontalProperty,
+						{
+							label: token.name,
+							description: `API token, ${roleNames[token.role]}, created ${new Date(token.created).toLocaleDateString()}`,
+							centered: true,
+							bottomBorder: true,
+						},
+						m(IconButton, { icon: 'trash', intend: 'error', onClick: () => deleteToken(token.id, token.name) }),
+					),
+				),
+				m(
+					HorizontalProperty,
+					{
+						label: 'Create API Token',
+						description: 'API tokens are used by scripts, the SDK and the command line (SND_API_TOKEN).',
+						centered: true,
+						bottomBorder: true,
+					},
+					m(Flex, { gap: 2, items: 'center' }, [
+						roleSelect(tokenRole, (role) => (tokenRole = role)),
+						m(Button, { intend: 'primary', onClick: createToken }, 'Create'),
+					]),
+				),
+				//
+				// Permissions
+				...(['gm', 'player'] as Role[]).flatMap((role) =>
+					Groups.map((group) =>
+						m(
+							HorizontalProperty,
+							{
+								label: `${roleNames[role]}: ${group}`,
+								description: groupDescriptions[group],
+								centered: true,
+								bottomBorder: true,
+							},
+							m(Select, {
+								keys: ['none', 'read', 'write'],
+								names: ['No Access', 'Read', 'Read & Write'],
+								selected: current.permissions[role][group],
+								noDefault: true,
+								onInput: (e) => setPermissions(role, { ...current.permissions[role], [group]: e.value as Access }),
+							}),
+						),
+					),
+				),
+			]);
+		},
+	};
+};
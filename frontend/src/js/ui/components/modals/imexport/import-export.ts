@@ -1,6 +1,6 @@
 import m from 'mithril';
 
-import { ImportExport } from 'js/types/import-export';
+import { ImportDiff, ImportExport } from 'js/types/import-export';
 import * as API from 'js/core/api';
 import store from 'js/core/store';
 
@@ -23,6 +23,17 @@ type ImportExportProps = {
 	loadingMessage: string;
 	verb: string;
 	id?: string;
+	/** Endpoint prefix of the dry-run imports. If set together with mergeEndpoint a merge strategy can be chosen. */
+	previewEndpoint?: string;
+	/** Endpoint prefix of the imports that merge the entries with the given strategy. */
+	mergeEndpoint?: string;
+};
+
+const strategyDescriptions: Record<string, string> = {
+	replace: 'Delete all existing entries and save the imported ones.',
+	'keep-local': 'Update and remove entries like the import, but keep entries that were modified or created locally since the last import.',
+	update: 'Save all imported entries, overwriting local modifications, but never remove entries.',
+	'add-only': 'Only save the entries that do not exist yet.',
 };
 
 type ImportExportState = {
@@ -31,6 +42,9 @@ type ImportExportState = {
 	selected: ImportExport | null;
 	args: any[];
 	loading: boolean;
+	strategies: string[];
+	strategy: string;
+	preview: ImportDiff[] | null;
 };
 
 export default (): m.Component<ImportExportProps> => {
@@ -40,6 +54,18 @@ export default (): m.Component<ImportExportProps> => {
 		selected: null,
 		args: [],
 		loading: false,
+		strategies: [],
+		strategy: 'replace',
+		preview: null,
+	};
+
+	const canMerge = () => !!state.attrs?.previewEndpoint && !!state.attrs?.mergeEndpoint && !state.attrs?.id;
+
+	const execImport = (selected: ImportExport) => {
+		if (canMerge()) {
+			return API.exec(state.attrs?.mergeEndpoint + selected.rpcName, state.args, state.strategy);
+		}
+		return API.exec(state.attrs?.endpoint + selected.rpcName, ...(state.attrs?.id ? [state.attrs.id, state.args] : [state.args]));
 	};
 
 	const importData = () => {
@@ -48,7 +74,7 @@ export default (): m.Component<ImportExportProps> => {
 		}
 
 		state.loading = true;
-		API.exec(state.attrs?.endpoint + state.selected.rpcName, ...(state.attrs?.id ? [state.attrs.id, state.args] : [state.args]))
+		execImport(state.selected)
 			.then(() => {
 				success(`${state.attrs?.verb ?? ''} successful`);
 				store.actions.loadAll(true).catch(error);
@@ -91,6 +117,7 @@ export default (): m.Component<ImportExportProps> => {
 						value: state.args[i],
 						onChange: (value: any) => {
 							state.args[i] = value;
+							state.preview = null;
 						},
 					}),
 				);
@@ -98,6 +125,75 @@ export default (): m.Component<ImportExportProps> => {
 		);
 	};
 
+	const previewData = () => {
+		if (!state.selected) {
+			return;
+		}
+
+		state.loading = true;
+		API.exec<ImportDiff[]>(state.attrs?.previewEndpoint + state.selected.rpcName, state.args)
+			.then((diffs) => {
+				state.preview = diffs;
+			})
+			.catch(error)
+			.finally(() => {
+				state.loading = false;
+			});
+	};
+
+	const changeCount = (label: string, changes: { locallyModified: boolean }[]) => {
+		const modified = changes.filter((c) => c.locallyModified).length;
+		return m('span.mr3', [m('span.b', changes.length), ` ${label}`, modified > 0 ? m('span.text-muted', ` (${modified} locally modified)`) : null]);
+	};
+
+	const previewResult = () => {
+		if (!state.preview) {
+			return null;
+		}
+
+		return m(
+			Flex,
+			{ direction: 'column', gap: 2, className: '.mt2' },
+			state.preview.map((diff) =>
+				m('div.pv2.ph3.br2.bg-black-05.f7', [
+					m('div.b.mb1', [diff.name, diff.exists ? null : m('span.text-muted.normal', ' (new)')]),
+					changeCount('new', diff.new),
+					changeCount('changed', diff.changed),
+					changeCount('removed', diff.removed),
+					m('span', [m('span.b', diff.unchanged), ' unchanged']),
+				]),
+			),
+		);
+	};
+
+	const strategySelection = () => {
+		if (!canMerge()) {
+			return null;
+		}
+
+		return [
+			m('div.ttu.f8.b.bb.b--black-05.pb2.mt3', 'Existing Entries'),
+			m(
+				HorizontalProperty,
+				{
+					label: 'Strategy',
+					description: strategyDescriptions[state.strategy] ?? '',
+					bottomBorder: true,
+					centered: true,
+				},
+				m(Select, {
+					keys: state.strategies,
+					selected: state.strategy,
+					onInput: (e) => {
+						state.strategy = e.value;
+					},
+				}),
+			),
+			m('div.mt2', m(Button, { intend: 'link', onClick: previewData }, 'Preview changes')),
+			previewResult(),
+		];
+	};
+
 	const selected = () => {
 		if (!state.selected) {
 			return null;
@@ -108,6 +204,7 @@ export default (): m.Component<ImportExportProps> => {
 			m('div.pv2.ph3.br2.bg-black-05', [m('div.f5.mb1', state.selected.name), m('div.f7.text-muted', state.selected.description)]),
 			m('div.ttu.f8.b.bb.b--black-05.pb2.mt3', 'Arguments'),
 			selectedArguments(),
+			strategySelection(),
 			m('div.mt2', m(Button, { className: '.fr', intend: 'success', onClick: importData }, `${state.attrs?.verb ?? 'Do'} "${state.selected.name}"`)),
 		]);
 	};
@@ -121,6 +218,14 @@ export default (): m.Component<ImportExportProps> => {
 					state.imports = imports;
 				})
 				.catch(error);
+
+			if (canMerge()) {
+				API.exec<string[]>(API.GET_IMPORT_STRATEGIES)
+					.then((strategies) => {
+						state.strategies = strategies;
+					})
+					.catch(error);
+			}
 		},
 		view() {
 			if (state.imports.length === 0) {
@@ -146,6 +251,7 @@ export default (): m.Component<ImportExportProps> => {
 							selected: state.selected?.name ?? '',
 							onInput: (key) => {
 								state.selected = state.imports.find((imp) => imp.name === key.value) ?? null;
+								state.preview = null;
 								if (state.selected) {
 									state.args = (state.selected.arguments ?? []).map((a) => a.default);
 								}
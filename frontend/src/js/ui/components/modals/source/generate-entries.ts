@@ -0,0 +1,105 @@
+import m from 'mithril';
+
+import Entry from 'js/types/entry';
+import * as API from 'js/core/api';
+
+import Button from 'js/ui/shoelace/button';
+import Input from 'js/ui/shoelace/input';
+import Modal from 'js/ui/shoelace/modal';
+import TextArea from 'js/ui/shoelace/text-area';
+
+import HorizontalProperty from 'js/ui/components/horizontal-property';
+import Flex from 'js/ui/components/layout/flex';
+
+import { popPortal, pushPortal } from 'js/ui/portal';
+import { error } from 'js/ui/toast';
+
+type GenerateEntriesProps = {
+	id: string;
+	onGenerated: (entries: Entry[]) => void;
+	onClose: () => void;
+};
+
+const GenerateEntries = (): m.Component<GenerateEntriesProps> => {
+	let instructions = '';
+	let count = '5';
+	let loading = false;
+
+	const generate = (attrs: GenerateEntriesProps) => {
+		loading = true;
+		API.exec<Entry[]>(API.AI_GENERATE_ENTRIES, attrs.id, instructions, parseInt(count))
+			.then(attrs.onGenerated)
+			.catch(error)
+			.finally(() => {
+				loading = false;
+				m.redraw();
+			});
+	};
+
+	return {
+		view({ attrs }) {
+			return m(
+				Modal,
+				{
+					title: 'Generate Entries',
+					icon: 'planet',
+					onClose: () => {
+						if (loading) return;
+						popPortal();
+						attrs.onClose();
+					},
+				},
+				m(Flex, { direction: 'column', gap: 2 }, [
+					m(
+						HorizontalProperty,
+						{
+							label: 'Instructions',
+							description: 'What the AI should generate, e.g. "Taverns in a port city".',
+							bottomBorder: true,
+						},
+						m(TextArea, { value: instructions, rows: 4, onChange: (val: string) => (instructions = val) }),
+					),
+					m(
+						HorizontalProperty,
+						{
+							label: 'Count',
+							description: 'The number of entries to generate (max. 100). The new entries have the same structure as the existing ones.',
+							bottomBorder: true,
+							centered: true,
+						},
+						m(Input, { type: 'number', value: count, onChange: (val: string) => (count = val) }),
+					),
+					m(
+						'div',
+						m(
+							Button,
+							{
+								intend: 'success',
+								loading,
+								onClick: () => generate(attrs),
+								disabled: !(parseInt(count) > 0 && parseInt(count) <= 100),
+							},
+							'Generate',
+						),
+					),
+				]),
+			);
+		},
+	};
+};
+
+export default (id: string): Promise<Entry[]> =>
+	new Promise((resolve, reject) => {
+		pushPortal<GenerateEntriesProps>(GenerateEntries, {
+			attributes: {
+				id,
+				onGenerated: (entries) => {
+					popPortal();
+					resolve(entries);
+				},
+				onClose: () => {
+					reject();
+				},
+			},
+		});
+	});
@@ -97,6 +97,8 @@ export default (): m.Component => {
 									setPortal(ImportExport, {
 										attributes: {
 											endpoint: API.IMPORT_SOURCE,
+											previewEndpoint: API.PREVIEW_IMPORT_SOURCE,
+											mergeEndpoint: API.MERGE_IMPORT_SOURCE,
 											title: 'Import Data Source',
 											loadingMessage: 'Importing... Please wait',
 											verb: 'Import',
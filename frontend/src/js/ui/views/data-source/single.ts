@@ -4,7 +4,7 @@ import { buildId } from 'js/types/basic-info';
 import DataSource from 'js/types/data-source';
 import Entry from 'js/types/entry';
 import * as API from 'js/core/api';
-import store from 'js/core/store';
+import store, { settings } from 'js/core/store';
 
 import Button from 'js/ui/shoelace/button';
 import DividerVert from 'js/ui/shoelace/divider-vert';
@@ -19,6 +19,7 @@ import Flex from 'js/ui/components/layout/flex';
 import { openAdditionalInfosModal } from 'js/ui/components/modals/additional-infos';
 import ImportExport from 'js/ui/components/modals/imexport/import-export';
 import CreateSourceEntry from 'js/ui/components/modals/source/create-edit-entry';
+import GenerateSourceEntries from 'js/ui/components/modals/source/generate-entries';
 import Monaco from 'js/ui/components/monaco';
 import Base from 'js/ui/components/view-layout/base';
 import Breadcrumbs from 'js/ui/components/view-layout/breadcrumbs';
@@ -220,6 +221,25 @@ export default (): m.Component<SingleSourceProps> => {
 							},
 							'Create Entry',
 						), //
+						settings.value.aiEnabled
+							? m(
+									IconButton,
+									{
+										className: '.mr2',
+										icon: 'planet',
+										intend: 'primary',
+										size: 'sm',
+										onClick: () =>
+											GenerateSourceEntries(attrs.id)
+												.then((entries) => {
+													success(`Generated ${entries.length} entries`);
+													fetchData(attrs.id).catch(error);
+												})
+												.catch(() => {}),
+									},
+									'Generate',
+								)
+							: null,
 						m(
 							IconButton,
 							{
@@ -0,0 +1,63 @@
+import m from 'mithril';
+
+import store from 'js/core/store';
+
+import Button from 'js/ui/shoelace/button';
+import Input from 'js/ui/shoelace/input';
+
+import Logo from 'js/ui/components/atomic/logo';
+import Title from 'js/ui/components/atomic/title';
+import HorizontalProperty from 'js/ui/components/horizontal-property';
+import Flex from 'js/ui/components/layout/flex';
+
+import { error } from 'js/ui/toast';
+
+type LoginProps = {
+	onLogin: () => void;
+};
+
+export default (): m.Component<LoginProps> => {
+	let name = '';
+	let password = '';
+	let loading = false;
+
+	return {
+		view({ attrs }) {
+			const login = () => {
+				if (loading) return;
+
+				loading = true;
+				store.actions
+					.login(name, password)
+					.then(attrs.onLogin)
+					.catch((err) => {
+						password = '';
+						error(err.response ?? err);
+					})
+					.finally(() => {
+						loading = false;
+						m.redraw();
+					});
+			};
+
+			return m(
+				Flex,
+				{ justify: 'center', items: 'center', className: '.vh-100.bg-light-gray' },
+				m('div.bg-white.ba.b--black-10.br2.pa3', { style: { width: '400px' } }, [
+					m(Flex, { items: 'center', gap: 2, className: '.mb3' }, [m(Logo, { scale: 0.5 }), m(Title, 'Login')]),
+					m(
+						HorizontalProperty,
+						{ label: 'Name', description: 'Your user name', centered: true, bottomBorder: true },
+						m(Input, { value: name, onChange: (val) => (name = val), onEnter: login }),
+					),
+					m(
+						HorizontalProperty,
+						{ label: 'Password', description: 'Your password', centered: true },
+						m(Input, { type: 'password', value: password, onChange: (val) => (password = val), onEnter: login }),
+					),
+					m(Button, { intend: 'success', className: '.mt2', loading, onClick: login }, 'Login'),
+				]),
+			);
+		},
+	};
+};
@@ -3,6 +3,8 @@ import { isEqual } from 'lodash-es';
 
 import { css } from 'goober';
 
+import CacheStats from 'js/types/ai-cache';
+import UsageReport, { UsageTotals } from 'js/types/ai-usage';
 import Settings, { Commands, createEmptySettings } from 'js/types/settings';
 import * as API from 'js/core/api';
 import store, { printerTypes, settings } from 'js/core/store';
@@ -12,6 +14,7 @@ import IconButton from 'js/ui/shoelace/icon-button';
 import Input from 'js/ui/shoelace/input';
 import Select from 'js/ui/shoelace/select';
 
+import AccessControl from 'js/ui/components/access-control';
 import Title from 'js/ui/components/atomic/title';
 import HorizontalProperty from 'js/ui/components/horizontal-property';
 import Flex from 'js/ui/components/layout/flex';
@@ -36,6 +39,8 @@ export default (): m.Component => {
 	let settingsCopy: Settings = { ...createEmptySettings(), ...settings.value };
 	let aiModels: string[] = [];
 	let aiProviders: string[] = [];
+	let aiUsage: UsageReport | null = null;
+	let aiCache: CacheStats | null = null;
 	let aiModelSearch = '';
 	let printerConfigs: Record<string, PrinterConfigPreset> = {};
 	let selectedPrinterConfig = '';
@@ -175,6 +180,32 @@ export default (): m.Component => {
 			.catch(error);
 	};
 
+	const fetchAiUsage = () => {
+		API.exec<UsageReport>(API.AI_USAGE, 30)
+			.then((usage) => {
+				aiUsage = usage;
+				m.redraw();
+			})
+			.catch(error);
+	};
+
+	const fetchAiCache = () => {
+		API.exec<CacheStats>(API.AI_CACHE_LIST)
+			.then((stats) => {
+				aiCache = stats;
+				m.redraw();
+			})
+			.catch(error);
+	};
+
+	const formatCache = (stats: CacheStats) =>
+		`${stats.entries.length} responses, ${(stats.size / 1024 / 1024).toFixed(2)} MB` +
+		(stats.entries.some((entry) => entry.pinned) ? `, ${stats.entries.filter((entry) => entry.pinned).length} pinned` : '');
+
+	const formatUsage = (usage: UsageTotals) =>
+		`${usage.requests} requests, ${usage.promptTokens + usage.completionTokens} tokens, $${usage.cost.toFixed(4)}` +
+		(usage.unpricedTokens > 0 ? ` (${usage.unpricedTokens} tokens without pricing)` : '');
+
 	const fetchAiModels = () => {
 		if (settingsCopy.aiProvider === '') return;
 
@@ -244,14 +275,24 @@ export default (): m.Component => {
 			.then((num) => {
 				clearPortal();
 				success(`Cleared ${num} AI results from cache!`);
+				fetchAiCache();
 			})
 			.catch(error);
 	};
 
+	const logout = () => {
+		store.actions
+			.logout()
+			.then(() => window.location.reload())
+			.catch(error);
+	};
+
 	return {
 		oninit() {
 			fetchAiProviders();
 			fetchAiModels();
+			fetchAiUsage();
+			fetchAiCache();
 			loadPrinterConfigs();
 		},
 		view() {
@@ -265,6 +306,7 @@ export default (): m.Component => {
 					active: 'settings',
 					classNameContainer: '.pa3',
 					rightElement: m(Flex, { gap: 2 }, [
+						store.value.auth.enabled ? m(IconButton, { icon: 'log-out', onClick: logout }, 'Logout') : null,
 						m(IconButton, { icon: 'print', intend: 'primary', onClick: testPrint, disabled: !isEqual(settingsCopy, settings.value) }, 'Test Print'),
 						m(IconButton, { icon: 'checkmark-circle-outline', intend: 'success', onClick: applySettings }, 'Apply'),
 					]),
@@ -295,8 +337,24 @@ export default (): m.Component => {
 										description: 'Custom repositories besides the default that will be used for installing packages',
 										arrayType: 'string',
 									},
+									renderPoolSize: {
+										label: 'Render Pool Size',
+										description: 'The maximum number of pages that render at the same time. 0 uses the default (number of CPUs, at most 4).',
+									},
+									hotFolderStrategy: {
+										label: 'Hot Folder Strategy',
+										description:
+											'How the entries of files dropped into the hot folder are merged with existing ones. "keep-local" keeps entries that were modified locally since the last import, "replace" deletes all existing entries.',
+										customComponent: m(Select, {
+											keys: ['keep-local', 'update', 'add-only', 'replace'],
+											selected: settingsCopy.hotFolderStrategy || 'keep-local',
+											onInput: (e) => {
+												settingsCopy = { ...settingsCopy, hotFolderStrategy: e.value };
+											},
+										}),
+									},
 								},
-								show: ['spellcheckerLanguages', 'packageRepos'],
+								show: ['spellcheckerLanguages', 'packageRepos', 'renderPoolSize', 'hotFolderStrategy'],
 								onChange: onChangeSettings,
 							} as PropertyEditProps<Settings>),
 							//
@@ -452,10 +510,61 @@ export default (): m.Component => {
 										description:
 											'The max tokens for the AI service. Higher values will allow the AI to generate more content. (common range: 300-10000)',
 									},
+									aiDailyTokenLimit: {
+										label: 'Daily Token Limit',
+										description: 'The maximum number of tokens that can be used per day. 0 means no limit.',
+									},
+									aiRateLimit: {
+										label: 'Rate Limit',
+										description: 'The maximum number of AI requests per minute. 0 means no limit.',
+									},
+									aiTimeout: {
+										label: 'Timeout',
+										description:
+											'The time in seconds after which an AI request is cancelled. Slow local models might need more time. 0 means the default of 60 seconds.',
+									},
+									aiEmbeddingModel: {
+										label: 'Embedding Model',
+										description:
+											'The model used to find the entries of data sources that are relevant for a prompt (e.g. text-embedding-3-small or nomic-embed-text). Leave empty to only use full text search.',
+									},
 								},
-								show: ['aiEnabled', 'aiAlwaysAllow', 'aiApiKey', 'aiContextWindow', 'aiMaxTokens'],
+								show: ['aiEnabled', 'aiAlwaysAllow', 'aiApiKey', 'aiContextWindow', 'aiMaxTokens', 'aiDailyTokenLimit', 'aiRateLimit', 'aiTimeout', 'aiEmbeddingModel'],
 								onChange: onChangeSettings,
 							} as PropertyEditProps<Settings>),
+							m(
+								HorizontalProperty,
+								{
+									label: 'Daily Budget',
+									description:
+										'The maximum amount in USD that can be spent on AI per day, based on the pricing of the provider or the list prices of OpenAI and Anthropic. Models without known pricing are not limited. 0 means no limit.',
+									centered: true,
+									bottomBorder: true,
+								},
+								m(Input, {
+									type: 'number',
+									value: settingsCopy.aiDailyBudget.toString(),
+									onChange: (val) => {
+										settingsCopy = { ...settingsCopy, aiDailyBudget: parseFloat(val) || 0 };
+									},
+								}),
+							),
+							aiUsage
+								? m(
+										HorizontalProperty,
+										{
+											label: 'Usage',
+											description: 'The AI usage of today and the last 30 days. Tokens are estimated if the provider does not report them.',
+											centered: true,
+											bottomBorder: true,
+										},
+										m('div.f7.lh-copy', [
+											m('div', `Today: ${formatUsage(aiUsage.today)}`),
+											m('div', `Last 30 days: ${formatUsage(aiUsage.total)}`),
+											aiUsage.budgetWarning ? m('div.mt1.dark-red', aiUsage.budgetWarning) : null,
+										]),
+									)
+								: null,
 							m(
 								HorizontalProperty,
 								{
@@ -581,6 +690,23 @@ export default (): m.Component => {
 											}),
 										),
 									],
+							settingsCopy.aiProvider !== 'Ollama' && settingsCopy.aiProvider !== 'llama.cpp'
+								? null
+								: m(
+										HorizontalProperty,
+										{
+											label: 'URL',
+											description: 'The URL of the local server. Leave empty for the default (Ollama: http://127.0.0.1:11434, llama.cpp: http://127.0.0.1:8080)',
+											centered: true,
+											bottomBorder: true,
+										},
+										m(Input, {
+											value: settingsCopy.aiUrl,
+											onChange: (val) => {
+												settingsCopy = { ...settingsCopy, aiUrl: val };
+											},
+										}),
+									),
 						]),
 						//
 						// Cache
@@ -590,11 +716,39 @@ export default (): m.Component => {
 							description: 'Control for the cache',
 							icon: 'cube',
 						}), //
+						m(PropertyEdit<Settings>, {
+							properties: settingsCopy,
+							annotations: {
+								aiCacheTtl: {
+									label: 'AI Cache TTL',
+									description: 'The time in hours after which cached AI results expire. Pinned results never expire. 0 means no expiry.',
+								},
+								aiCacheMaxSize: {
+									label: 'AI Cache Size',
+									description: 'The maximum size of the AI cache in MB. The least recently used results are removed first. 0 means no limit.',
+								},
+							},
+							show: ['aiCacheTtl', 'aiCacheMaxSize'],
+							onChange: onChangeSettings,
+						} as PropertyEditProps<Settings>),
+						aiCache
+							? m(
+									HorizontalProperty,
+									{
+										label: 'AI Cache',
+										description: 'The AI results that are currently cached.',
+										centered: true,
+										bottomBorder: true,
+									},
+									m('div.f7.lh-copy', formatCache(aiCache)),
+								)
+							: null,
 						m(
 							HorizontalProperty,
 							{
 								label: 'Clear AI Cache',
-								description: 'Clear the AI cache. This will remove all cached AI data. This can be useful if you encounter issues with the AI.',
+								description:
+									'Clear the AI cache. This will remove all cached AI data except pinned results. This can be useful if you encounter issues with the AI.',
 								bottomBorder: true,
 								centered: true,
 							},
@@ -656,6 +810,9 @@ export default (): m.Component => {
 							},
 							m(Button, { intend: 'error', onClick: syncFromCloud }, 'Start Sync'),
 						),
+						//
+						// Access Control
+						store.value.auth.identity?.role === 'admin' ? m(AccessControl) : null,
 					),
 				),
 			);
@@ -35,6 +35,10 @@ export type EntityListConfig<T extends BasicInfo> = {
 	importEndpoint: string;
 	/** Import modal title */
 	importTitle: string;
+	/** Optional dry-run import endpoint, enables the choice of a merge strategy together with mergeEndpoint */
+	previewEndpoint?: string;
+	/** Optional import endpoint that merges the entries with a strategy */
+	mergeEndpoint?: string;
 	/** Route to navigate to for creating a new entity */
 	createRoute: string;
 	/** Atom holding the list of entities */
@@ -196,6 +200,8 @@ export default <T extends BasicInfo>(config: EntityListConfig<T>): m.Component =
 										attributes: {
 											endpoint: config.importEndpoint,
 											title: config.importTitle,
+											previewEndpoint: config.previewEndpoint,
+											mergeEndpoint: config.mergeEndpoint,
 											loadingMessage: 'Importing... Please wait',
 											verb: 'Import',
 										},
@@ -13,6 +13,8 @@ export default () =>
 		active: 'templates',
 		importEndpoint: API.IMPORT_TEMPLATE,
 		importTitle: 'Import Template',
+		previewEndpoint: API.PREVIEW_IMPORT_TEMPLATE,
+		mergeEndpoint: API.MERGE_IMPORT_TEMPLATE,
 		createRoute: '/template/create',
 		itemsAtom: templates,
 		loadAction: store.actions.loadTemplates,
@@ -10,6 +10,7 @@ import store, { settings } from 'js/core/store';
 import { render } from 'js/core/templating';
 
 import Button from 'js/ui/shoelace/button';
+import Checkbox from 'js/ui/shoelace/checkbox';
 import Divider from 'js/ui/shoelace/divider';
 import DividerVert from 'js/ui/shoelace/divider-vert';
 import IconButton from 'js/ui/shoelace/icon-button';
@@ -61,6 +62,9 @@ type SingleTemplateState = SavedConfigsState & {
 	aiPrompt: string;
 	aiLanguage: string;
 	aiLoading: boolean;
+	aiStream: string;
+	aiAbort: AbortController | null;
+	aiUseSources: boolean;
 	lastRendered: string;
 };
 
@@ -76,6 +80,9 @@ export default (): m.Component<SingleTemplateProps> => {
 		aiPrompt: '',
 		aiLanguage: '',
 		aiLoading: false,
+		aiStream: '',
+		aiAbort: null,
+		aiUseSources: false,
 		lastRendered: '',
 		savedConfigs: {},
 	};
@@ -122,7 +129,19 @@ export default (): m.Component<SingleTemplateProps> => {
 	const generateAIEntry = () => {
 		if (!state.template || state.aiPrompt === '') return;
 		state.aiLoading = true;
-		AI.generateEntry(state.aiPrompt, state.template, state.entries)
+		state.aiStream = '';
+		state.aiAbort = new AbortController();
+		AI.generateEntry(
+			state.aiPrompt,
+			state.template,
+			state.entries,
+			(chunk) => {
+				state.aiStream += chunk;
+				m.redraw();
+			},
+			state.aiAbort.signal,
+			state.aiUseSources ? state.template.dataSources : undefined,
+		)
 			.then((entry) => {
 				if (entry.hasError) {
 					error('AI generation failed. Please try again or test another model.');
@@ -130,12 +149,21 @@ export default (): m.Component<SingleTemplateProps> => {
 				}
 				state.selectedEntry = entry.value;
 			})
-			.catch(error)
+			.catch((err) => {
+				if (err?.name === 'AbortError') return;
+				error(err);
+			})
 			.finally(() => {
 				state.aiLoading = false;
+				state.aiAbort = null;
+				m.redraw();
 			});
 	};
 
+	const cancelAIEntry = () => {
+		state.aiAbort?.abort();
+	};
+
 	const generateAITranslation = () => {
 		if (!state.template || !state.selectedEntry || state.aiLanguage === '') return;
 		state.aiLoading = true;
@@ -361,8 +389,18 @@ export default (): m.Component<SingleTemplateProps> => {
 												value: state.aiPrompt,
 												onChange: (val) => (state.aiPrompt = val),
 											}),
+											state.template?.dataSources?.length
+												? m(
+														Checkbox,
+														{ checked: state.aiUseSources, onChange: (val) => (state.aiUseSources = val) },
+														'Use the most relevant entries of the data sources',
+													)
+												: null,
 											m(Flex, { justify: 'between', className: '.mt2' }, [
-												m(Button, { onClick: generateAIEntry, loading: state.aiLoading, intend: 'primary' }, 'Generate'),
+												m(Flex, { gap: 2 }, [
+													m(Button, { onClick: generateAIEntry, loading: state.aiLoading, intend: 'primary' }, 'Generate'),
+													state.aiAbort ? m(Button, { onClick: cancelAIEntry }, 'Cancel') : null,
+												]),
 												m(
 													Flex,
 													{ items: 'center' },
@@ -405,6 +443,9 @@ export default (): m.Component<SingleTemplateProps> => {
 														: null,
 												),
 											]),
+											state.aiLoading && state.aiStream.length > 0
+												? m('div.f8.text-muted.overflow-auto', { style: { whiteSpace: 'pre-wrap', maxHeight: '200px' } }, state.aiStream)
+												: null,
 											m(Divider),
 											m('div.f5.b', 'AI Translation'),
 											m('div.f7.text-muted.mb2.lh-copy', 'Enter a language to which the selected entry should be converted to.'),
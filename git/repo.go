@@ -57,7 +57,7 @@ func (r Repo) Fetch(tag Tag) ([]Package, error) {
 				FS:     fs,
 			}
 
-			tmpl, entries, err := imexport.ImportTemplate(imFs)
+			tmpl, entries, _, err := imexport.ImportTemplate(imFs)
 			if err == nil {
 				packages = append(packages, Package{
 					Author:   tmpl.Author,
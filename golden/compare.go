@@ -0,0 +1,89 @@
+package golden
+
+import (
+	"image"
+	"image/color"
+)
+
+// Tolerance controls how strict two images are compared.
+type Tolerance struct {
+	// Threshold is the maximum difference of a color channel (0-255) for two pixels to count as equal.
+	Threshold int `json:"threshold"`
+	// MaxDiffRatio is the ratio of pixels (0-1) that are allowed to differ.
+	MaxDiffRatio float64 `json:"maxDiffRatio"`
+}
+
+// DefaultTolerance ignores slight differences from anti-aliasing.
+var DefaultTolerance = Tolerance{
+	Threshold:    24,
+	MaxDiffRatio: 0.001,
+}
+
+// Comparison is the result of comparing a rendering to its reference.
+type Comparison struct {
+	Pass         bool    `json:"pass"`
+	SizeMismatch bool    `json:"sizeMismatch"`
+	DiffPixels   int     `json:"diffPixels"`
+	DiffRatio    float64 `json:"diffRatio"`
+	// Diff shows the actual image faded out with the differing pixels in red.
+	Diff *image.RGBA `json:"-"`
+}
+
+var diffColor = color.RGBA{R: 255, A: 255}
+
+// Compare compares the actual image pixel by pixel with the reference. Images that differ
+// in size never pass, the pixels outside the smaller image count as different.
+func Compare(reference image.Image, actual image.Image, tolerance Tolerance) Comparison {
+	rb := reference.Bounds()
+	ab := actual.Bounds()
+
+	width := max(rb.Dx(), ab.Dx())
+	height := max(rb.Dy(), ab.Dy())
+
+	res := Comparison{
+		SizeMismatch: rb.Dx() != ab.Dx() || rb.Dy() != ab.Dy(),
+		Diff:         image.NewRGBA(image.Rect(0, 0, width, height)),
+	}
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			inRef := x < rb.Dx() && y < rb.Dy()
+			inActual := x < ab.Dx() && y < ab.Dy()
+
+			if !inRef || !inActual {
+				res.DiffPixels++
+				res.Diff.SetRGBA(x, y, diffColor)
+				continue
+			}
+
+			r1, g1, b1, _ := reference.At(rb.Min.X+x, rb.Min.Y+y).RGBA()
+			r2, g2, b2, _ := actual.At(ab.Min.X+x, ab.Min.Y+y).RGBA()
+
+			if channelDiff(r1, r2) > tolerance.Threshold || channelDiff(g1, g2) > tolerance.Threshold || channelDiff(b1, b2) > tolerance.Threshold {
+				res.DiffPixels++
+				res.Diff.SetRGBA(x, y, diffColor)
+				continue
+			}
+
+			// Fade out the equal pixels so that the differences stand out.
+			gray := uint8(((r2+g2+b2)/3)>>8)/4 + 191
+			res.Diff.SetRGBA(x, y, color.RGBA{R: gray, G: gray, B: gray, A: 255})
+		}
+	}
+
+	if width*height > 0 {
+		res.DiffRatio = float64(res.DiffPixels) / float64(width*height)
+	}
+
+	res.Pass = !res.SizeMismatch && res.DiffRatio <= tolerance.MaxDiffRatio
+	return res
+}
+
+// channelDiff returns the difference of two 16-bit color channels on a 0-255 scale.
+func channelDiff(a uint32, b uint32) int {
+	d := int(a>>8) - int(b>>8)
+	if d < 0 {
+		return -d
+	}
+	return d
+}
@@ -0,0 +1,156 @@
+// Package golden implements visual regression tests for templates. Approved renderings of
+// entries are stored as reference images in the database and new renderings are compared
+// pixel by pixel against them.
+//
+// The reference images are exported beside the template in the references folder, so that
+// they travel with the template package.
+package golden
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/png"
+	"strings"
+
+	"github.com/BigJk/snd/database"
+	"github.com/BigJk/snd/imexport"
+)
+
+// keyPrefix is the prefix of the reference images in the key-value store. Template ids can't
+// contain a '_', so everything after the first '_' following the prefix is the entry id.
+const keyPrefix = "GOLDEN_"
+
+// ReferenceID returns the id the reference of an entry is stored by. Entries of different data
+// sources can share an id, so the id of the source is part of it.
+func ReferenceID(sourceId string, entryId string) string {
+	return sourceId + "/" + entryId
+}
+
+func key(tmplId string, entryId string) string {
+	return fmt.Sprintf("%s%s_%s", keyPrefix, tmplId, entryId)
+}
+
+// SaveReference stores the image as reference of the entry.
+func SaveReference(db database.Database, tmplId string, entryId string, img image.Image) error {
+	buf := &bytes.Buffer{}
+	if err := png.Encode(buf, img); err != nil {
+		return err
+	}
+
+	return SaveReferencePNG(db, tmplId, entryId, buf.Bytes())
+}
+
+// SaveReferencePNG stores the png encoded image as reference of the entry.
+func SaveReferencePNG(db database.Database, tmplId string, entryId string, data []byte) error {
+	if _, err := png.DecodeConfig(bytes.NewReader(data)); err != nil {
+		return fmt.Errorf("reference of '%s' is not a valid png: %w", entryId, err)
+	}
+
+	return db.SetKey(key(tmplId, entryId), base64.StdEncoding.EncodeToString(data))
+}
+
+// GetReference returns the reference image of the entry.
+func GetReference(db database.Database, tmplId string, entryId string) (image.Image, error) {
+	data, err := db.GetKey(key(tmplId, entryId))
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return png.Decode(bytes.NewReader(raw))
+}
+
+// GetReferences returns the png encoded reference images of the template by entry id.
+func GetReferences(db database.Database, tmplId string) (map[string][]byte, error) {
+	prefix := key(tmplId, "")
+
+	keys, err := db.GetKeysPrefix(prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	res := map[string][]byte{}
+	for _, k := range keys {
+		data, err := db.GetKey(k)
+		if err != nil {
+			return nil, err
+		}
+
+		raw, err := base64.StdEncoding.DecodeString(data)
+		if err != nil {
+			return nil, err
+		}
+
+		res[strings.TrimPrefix(k, prefix)] = raw
+	}
+
+	return res, nil
+}
+
+// DeleteReference deletes the reference image of the entry.
+func DeleteReference(db database.Database, tmplId string, entryId string) error {
+	return db.DeleteKey(key(tmplId, entryId))
+}
+
+// DeleteReferences deletes all reference images of the template.
+func DeleteReferences(db database.Database, tmplId string) error {
+	keys, err := db.GetKeysPrefix(key(tmplId, ""))
+	if err != nil {
+		return err
+	}
+
+	for _, k := range keys {
+		if err := db.DeleteKey(k); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Attachments returns the reference images of the template as attachments for the export.
+func Attachments(db database.Database, tmplId string) (imexport.Attachments, error) {
+	refs, err := GetReferences(db, tmplId)
+	if err != nil {
+		return nil, err
+	}
+
+	res := imexport.Attachments{}
+	for entryId, data := range refs {
+		res.Add(imexport.ReferencesFolder, imexport.ReferenceFileName(entryId), data)
+	}
+
+	return res, nil
+}
+
+// SaveAttachments replaces the reference images of the template with the ones
+// contained in the imported attachments.
+func SaveAttachments(db database.Database, tmplId string, attachments imexport.Attachments) error {
+	files := attachments.Files(imexport.ReferencesFolder)
+	if len(files) == 0 {
+		return nil
+	}
+
+	if err := DeleteReferences(db, tmplId); err != nil {
+		return err
+	}
+
+	for name, data := range files {
+		entryId, ok := imexport.ReferenceEntryID(name)
+		if !ok {
+			continue
+		}
+
+		if err := SaveReferencePNG(db, tmplId, entryId, data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
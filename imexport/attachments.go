@@ -0,0 +1,72 @@
+package imexport
+
+import (
+	"path"
+	"sort"
+	"strings"
+)
+
+// Attachments are additional files that are stored beside a template, like the reference images of
+// the visual regression tests. The keys are the slash separated paths relative to the template folder.
+type Attachments map[string][]byte
+
+// ImportLister can be implemented by an ImportReader to list the files in a folder. Attachments
+// can only be imported from readers that implement it.
+type ImportLister interface {
+	ListFiles(folder string) ([]string, error)
+}
+
+// FontsFolder is the folder in a template folder that contains the fonts used by the template.
+const FontsFolder = "fonts"
+
+// attachmentFolders are the folders in a template folder that contain attachments.
+var attachmentFolders = []string{ReferencesFolder, FontsFolder}
+
+// Files returns the files in the given folder by their name.
+func (a Attachments) Files(folder string) map[string][]byte {
+	res := map[string][]byte{}
+	for file, data := range a {
+		if path.Dir(file) == folder {
+			res[path.Base(file)] = data
+		}
+	}
+	return res
+}
+
+// Add adds the file to the folder.
+func (a Attachments) Add(folder string, name string, data []byte) {
+	a[path.Join(folder, name)] = data
+}
+
+// readAttachments reads all files in the attachment folders. If the reader
+// can't list files or a folder doesn't exist it is skipped.
+func readAttachments(reader ImportReader) (Attachments, error) {
+	res := Attachments{}
+
+	lister, ok := reader.(ImportLister)
+	if !ok {
+		return res, nil
+	}
+
+	for _, folder := range attachmentFolders {
+		files, err := lister.ListFiles(folder)
+		if err != nil {
+			continue
+		}
+
+		sort.Strings(files)
+		for _, file := range files {
+			if strings.HasPrefix(path.Base(file), ".") {
+				continue
+			}
+
+			data, err := reader.ReadFile(path.Join(folder, file))
+			if err != nil {
+				return nil, err
+			}
+			res.Add(folder, file, data)
+		}
+	}
+
+	return res, nil
+}
@@ -0,0 +1,181 @@
+package fightclub5e
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BigJk/snd"
+)
+
+// Types contains the element types of a compendium.
+var Types = []string{"item", "monster", "race", "background", "spell", "feat", "class"}
+
+var invalidNameRegex = regexp.MustCompile(`[^a-zA-Z0-9_.-]`)
+
+// TypeFromSource guesses the element type of the source based on the slug. Sources
+// imported from a compendium end with the type (e.g. "my-compendium-monster").
+func TypeFromSource(ds snd.DataSource) string {
+	for _, t := range Types {
+		if strings.HasSuffix(ds.Slug, "-"+t) {
+			return t
+		}
+	}
+	return ""
+}
+
+// ExportCompendium writes the entries as a compendium with elements of the given type.
+// The entry data is converted the inverse way of the import: keys starting with "@" become
+// attributes, "#text" becomes the text content and lists become repeated elements.
+func ExportCompendium(entries []snd.Entry, elementType string, writer io.Writer) error {
+	if len(elementType) == 0 {
+		return fmt.Errorf("no element type specified")
+	}
+
+	enc := xml.NewEncoder(writer)
+	enc.Indent("", "  ")
+
+	if _, err := io.WriteString(writer, xml.Header); err != nil {
+		return err
+	}
+
+	root := xml.StartElement{
+		Name: xml.Name{Local: "compendium"},
+		Attr: []xml.Attr{{Name: xml.Name{Local: "version"}, Value: "5"}, {Name: xml.Name{Local: "auto_indent"}, Value: "NO"}},
+	}
+
+	if err := enc.EncodeToken(root); err != nil {
+		return err
+	}
+
+	for i := range entries {
+		data := map[string]any{}
+		for k, v := range entries[i].Data {
+			data[k] = v
+		}
+
+		if _, ok := data["name"]; !ok {
+			data["name"] = entries[i].Name
+		}
+
+		if err := encodeValue(enc, elementType, data); err != nil {
+			return err
+		}
+	}
+
+	if err := enc.EncodeToken(root.End()); err != nil {
+		return err
+	}
+
+	return enc.Flush()
+}
+
+// ExportCompendiumFile writes the entries as a compendium to the folder.
+// The function returns the path of the written file with the pattern "{ds.Author}_{ds.Slug}.xml".
+func ExportCompendiumFile(ds snd.DataSource, entries []snd.Entry, elementType string, folder string) (string, error) {
+	buf := &bytes.Buffer{}
+	if err := ExportCompendium(entries, elementType, buf); err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(folder, fmt.Sprintf("%s_%s.xml", ds.Author, ds.Slug))
+	return path, os.WriteFile(path, buf.Bytes(), 0666)
+}
+
+// elementName converts a key to a valid xml element name.
+func elementName(key string) string {
+	name := invalidNameRegex.ReplaceAllString(key, "_")
+	if len(name) == 0 || !(name[0] == '_' || (name[0] >= 'a' && name[0] <= 'z') || (name[0] >= 'A' && name[0] <= 'Z')) {
+		name = "_" + name
+	}
+	return name
+}
+
+func encodeValue(enc *xml.Encoder, key string, value any) error {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []any:
+		for i := range v {
+			if err := encodeValue(enc, key, v[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	case map[string]any:
+		return encodeObject(enc, key, v)
+	default:
+		start := xml.StartElement{Name: xml.Name{Local: elementName(key)}}
+		return enc.EncodeElement(textValue(v), start)
+	}
+}
+
+func encodeObject(enc *xml.Encoder, key string, obj map[string]any) error {
+	start := xml.StartElement{Name: xml.Name{Local: elementName(key)}}
+
+	var keys []string
+	for k := range obj {
+		keys = append(keys, k)
+	}
+
+	// Keep the name at the top, as it is easier to read and some apps expect it there.
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i] == "name" || keys[j] == "name" {
+			return keys[i] == "name"
+		}
+		return keys[i] < keys[j]
+	})
+
+	var children []string
+	for _, k := range keys {
+		switch {
+		case strings.HasPrefix(k, "@"):
+			start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: elementName(k[1:])}, Value: textValue(obj[k])})
+		case k == "#text":
+		default:
+			children = append(children, k)
+		}
+	}
+
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+
+	if text, ok := obj["#text"]; ok {
+		if err := enc.EncodeToken(xml.CharData(textValue(text))); err != nil {
+			return err
+		}
+	}
+
+	for _, k := range children {
+		if err := encodeValue(enc, k, obj[k]); err != nil {
+			return err
+		}
+	}
+
+	return enc.EncodeToken(start.End())
+}
+
+func textValue(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		// Compendiums use YES and NO for flags like <ritual>
+		if v {
+			return "YES"
+		}
+		return "NO"
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,62 @@
+package fightclub5e
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/BigJk/snd"
+)
+
+func TestExportImportRoundTrip(t *testing.T) {
+	entries := []snd.Entry{
+		{ID: "Goblin", Name: "Goblin", Data: map[string]any{
+			"name":  "Goblin",
+			"size":  "S",
+			"hp":    7.0,
+			"trait": []any{map[string]any{"name": "Nimble Escape", "text": "Disengage or Hide as a bonus action."}, map[string]any{"name": "Darkvision", "text": "60 ft."}},
+			"roll":  map[string]any{"@description": "Attack", "#text": "1d20+4"},
+		}},
+		// The name is taken from the entry if the data doesn't contain it
+		{ID: "Orc", Name: "Orc", Data: map[string]any{"passive": 10.0, "legendary": false}},
+	}
+
+	ds := snd.DataSource{Name: "Monsters", Author: "author", Slug: "monsters-monster"}
+	if elementType := TypeFromSource(ds); elementType != "monster" {
+		t.Fatalf("expected the type from the slug, got '%s'", elementType)
+	}
+
+	file, err := ExportCompendiumFile(ds, entries, "monster", t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sources, imported, err := ImportCompedium(file, "Monsters", "author", "monsters", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sources) != 1 || sources[0].Slug != ds.Slug || len(imported[0]) != 2 {
+		t.Fatalf("unexpected import %+v %+v", sources, imported)
+	}
+
+	// All values are text in the compendium
+	want := []snd.Entry{
+		{ID: "Goblin", Name: "Goblin", Data: map[string]any{
+			"name":  "Goblin",
+			"size":  "S",
+			"hp":    "7",
+			"trait": []any{map[string]any{"name": "Nimble Escape", "text": "Disengage or Hide as a bonus action."}, map[string]any{"name": "Darkvision", "text": "60 ft."}},
+			"roll":  map[string]any{"@description": "Attack", "#text": "1d20+4"},
+		}},
+		{ID: "Orc", Name: "Orc", Data: map[string]any{"name": "Orc", "passive": "10", "legendary": "NO"}},
+	}
+
+	if !reflect.DeepEqual(imported[0], want) {
+		t.Fatalf("expected %+v, got %+v", want, imported[0])
+	}
+}
+
+func TestExportCompendiumWithoutType(t *testing.T) {
+	if _, err := ExportCompendiumFile(snd.DataSource{Slug: "unknown"}, nil, "", t.TempDir()); err == nil {
+		t.Fatal("expected an error without element type")
+	}
+}
@@ -0,0 +1,154 @@
+package imexport
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Flatten converts nested maps and arrays to a single map with dotted paths as keys, e.g.
+//
+//	{"stats": {"str": 10}, "tags": ["a", "b"]}
+//
+// becomes
+//
+//	{"stats.str": 10, "tags.0": "a", "tags.1": "b"}
+//
+// Dots and backslashes in keys are escaped with a backslash, so {"a.b": 1} becomes {"a\.b": 1}.
+// Empty maps and arrays are kept as they are, as there is no path that could represent them.
+func Flatten(data map[string]any) map[string]any {
+	res := map[string]any{}
+	for k, v := range data {
+		flattenValue(res, escapeKey(k), v)
+	}
+	return res
+}
+
+func flattenValue(res map[string]any, path string, value any) {
+	switch v := value.(type) {
+	case map[string]any:
+		if len(v) == 0 {
+			res[path] = v
+			return
+		}
+		for k, child := range v {
+			flattenValue(res, path+"."+escapeKey(k), child)
+		}
+	case []any:
+		if len(v) == 0 {
+			res[path] = v
+			return
+		}
+		for i, child := range v {
+			flattenValue(res, path+"."+strconv.Itoa(i), child)
+		}
+	default:
+		res[path] = v
+	}
+}
+
+var keyEscaper = strings.NewReplacer(`\`, `\\`, `.`, `\.`)
+
+func escapeKey(key string) string {
+	return keyEscaper.Replace(key)
+}
+
+// splitPath splits a flattened path at the unescaped dots and unescapes the keys.
+func splitPath(path string) []string {
+	var keys []string
+	var key strings.Builder
+	for i := 0; i < len(path); i++ {
+		switch {
+		case path[i] == '\\' && i+1 < len(path):
+			i++
+			key.WriteByte(path[i])
+		case path[i] == '.':
+			keys = append(keys, key.String())
+			key.Reset()
+		default:
+			key.WriteByte(path[i])
+		}
+	}
+	return append(keys, key.String())
+}
+
+// Unflatten is the inverse of Flatten. Objects whose keys are exactly the indices 0 to n-1 are
+// converted back to arrays. Paths that conflict, like "a" and "a.b", return an error instead
+// of overwriting each other.
+func Unflatten(data map[string]any) (map[string]any, error) {
+	res := map[string]any{}
+
+	// Sort the paths so that the reported conflict doesn't depend on the map order.
+	var paths []string
+	for k := range data {
+		paths = append(paths, k)
+	}
+	sort.Strings(paths)
+
+	// Objects that were created for a path, all other values are leaves.
+	created := map[string]bool{}
+
+	for _, path := range paths {
+		keys := splitPath(path)
+
+		current := res
+		prefix := ""
+		for i, key := range keys {
+			prefix += "." + escapeKey(key)
+
+			if i == len(keys)-1 {
+				if _, ok := current[key]; ok {
+					return nil, fmt.Errorf("path '%s' conflicts with another path", path)
+				}
+				current[key] = data[path]
+				break
+			}
+
+			next, ok := current[key].(map[string]any)
+			if _, exists := current[key]; exists && (!ok || !created[prefix]) {
+				return nil, fmt.Errorf("path '%s' conflicts with another path", path)
+			}
+			if !ok {
+				next = map[string]any{}
+				current[key] = next
+				created[prefix] = true
+			}
+			current = next
+		}
+	}
+
+	// The top level always stays an object, even if all keys are numbers.
+	for k, v := range res {
+		res[k] = toArrays(v)
+	}
+
+	return res, nil
+}
+
+// toArrays converts all objects that only have index keys to arrays.
+func toArrays(value any) any {
+	obj, ok := value.(map[string]any)
+	if !ok {
+		return value
+	}
+
+	for k, v := range obj {
+		obj[k] = toArrays(v)
+	}
+
+	if len(obj) == 0 {
+		return obj
+	}
+
+	arr := make([]any, len(obj))
+	for k, v := range obj {
+		i, err := strconv.Atoi(k)
+		if err != nil || i < 0 || i >= len(obj) || strconv.Itoa(i) != k {
+			return obj
+		}
+		arr[i] = v
+	}
+
+	return arr
+}
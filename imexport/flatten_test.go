@@ -0,0 +1,48 @@
+package imexport
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFlattenRoundTrip(t *testing.T) {
+	cases := []map[string]any{
+		{"stats": map[string]any{"str": 10.0}, "tags": []any{"a", "b"}},
+		{"a.b": 1.0, "a": map[string]any{"b": 2.0}},
+		{`back\slash`: "x", `dot\.`: map[string]any{"c.d": []any{"e"}}},
+		{"empty": map[string]any{}, "list": []any{}},
+	}
+
+	for _, data := range cases {
+		flat := Flatten(data)
+		res, err := Unflatten(flat)
+		if err != nil {
+			t.Fatalf("%v: %s", data, err)
+		}
+		if !reflect.DeepEqual(res, data) {
+			t.Fatalf("expected %v, got %v (flat %v)", data, res, flat)
+		}
+	}
+}
+
+func TestFlattenEscapesKeys(t *testing.T) {
+	flat := Flatten(map[string]any{"a.b": 1.0, "c": map[string]any{"d": 2.0}})
+	expected := map[string]any{`a\.b`: 1.0, "c.d": 2.0}
+	if !reflect.DeepEqual(flat, expected) {
+		t.Fatalf("expected %v, got %v", expected, flat)
+	}
+}
+
+func TestUnflattenConflicts(t *testing.T) {
+	cases := []map[string]any{
+		{"a": 1.0, "a.b": 2.0},
+		{"a.b": 1.0, "a.b.c": 2.0},
+		{"a": map[string]any{}, "a.b": 1.0},
+	}
+
+	for _, data := range cases {
+		if res, err := Unflatten(data); err == nil {
+			t.Fatalf("expected conflict for %v, got %v", data, res)
+		}
+	}
+}
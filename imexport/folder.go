@@ -2,6 +2,7 @@ package imexport
 
 import (
 	"fmt"
+	"io/fs"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -14,18 +15,47 @@ type FolderImportReader struct {
 }
 
 func (f *FolderImportReader) ReadFile(s string) ([]byte, error) {
-	data, err := ioutil.ReadFile(filepath.Join(f.base, s))
+	s, err := cleanPath(s)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(f.base, filepath.FromSlash(s)))
 	if err != nil {
 		return nil, err
 	}
 	return data, nil
 }
 
+func (f *FolderImportReader) ListFiles(folder string) ([]string, error) {
+	var files []string
+	root := filepath.Join(f.base, folder)
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	return files, err
+}
+
 type FolderExportWriter struct {
 	base string
 }
 
 func (f *FolderExportWriter) WriteFile(file string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(filepath.Join(f.base, file)), 0777); err != nil {
+		return err
+	}
 	return ioutil.WriteFile(filepath.Join(f.base, file), data, 0666)
 }
 
@@ -39,12 +69,12 @@ func (f *FolderExportWriter) WriteFile(file string, data []byte) error {
 // - skeleton.json
 // - entries.json
 //
-// The function returns the name of the created folder.
-func ExportTemplateFolder(tmpl snd.Template, entries []snd.Entry, folder string) (string, error) {
+// The attachments are written beside them. The function returns the name of the created folder.
+func ExportTemplateFolder(tmpl snd.Template, entries []snd.Entry, attachments Attachments, folder string) (string, error) {
 	name := fmt.Sprintf("%s_%s", tmpl.Author, tmpl.Slug)
 	_ = os.MkdirAll(filepath.Join(folder, name), 0777)
 
-	return name, ExportTemplate(tmpl, entries, &FolderExportWriter{base: filepath.Join(folder, name)})
+	return name, ExportTemplate(tmpl, entries, attachments, &FolderExportWriter{base: filepath.Join(folder, name)})
 }
 
 // ImportTemplateFolder will import template and entry data from a given folder.
@@ -55,7 +85,7 @@ func ExportTemplateFolder(tmpl snd.Template, entries []snd.Entry, folder string)
 // - list.html.njk
 // - skeleton.json
 // - entries.json
-func ImportTemplateFolder(folder string) (snd.Template, []snd.Entry, error) {
+func ImportTemplateFolder(folder string) (snd.Template, []snd.Entry, Attachments, error) {
 	reader := &FolderImportReader{
 		base: folder,
 	}
@@ -115,3 +145,32 @@ func ImportGeneratorFolder(folder string) (snd.Generator, error) {
 	}
 	return ImportGenerator(reader)
 }
+
+// ExportPromptFolder exports the prompt to the given folder. A new folder with
+// the pattern prompt_{prompt.Autor}_{prompt.Slug} will be created.
+//
+// Following files will be created:
+// - meta.json
+// - system.txt
+// - user.txt
+//
+// The function returns the name of the created folder.
+func ExportPromptFolder(prompt snd.Prompt, folder string) (string, error) {
+	name := fmt.Sprintf("prompt_%s_%s", prompt.Author, prompt.Slug)
+	_ = os.MkdirAll(filepath.Join(folder, name), 0777)
+
+	return name, ExportPrompt(prompt, &FolderExportWriter{base: filepath.Join(folder, name)})
+}
+
+// ImportPromptFolder will import a prompt from a given folder.
+//
+// Following files are needed:
+// - meta.json
+// - system.txt
+// - user.txt
+func ImportPromptFolder(folder string) (snd.Prompt, error) {
+	reader := &FolderImportReader{
+		base: folder,
+	}
+	return ImportPrompt(reader)
+}
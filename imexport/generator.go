@@ -61,6 +61,11 @@ func ImportGenerator(reader ImportReader) (snd.Generator, error) {
 
 	gen.PrintTemplate = string(files["print.html.njk"])
 
+	gen.Images, err = importImages(gen.Images, reader)
+	if err != nil {
+		return snd.Generator{}, err
+	}
+
 	if len(gen.Slug) == 0 || len(gen.Author) == 0 || len(gen.Name) == 0 {
 		return snd.Generator{}, errors.New("meta data incomplete (e.g. name, author, slug missing)")
 	}
@@ -77,7 +82,15 @@ func ImportGenerator(reader ImportReader) (snd.Generator, error) {
 // Following files will be created:
 // - meta.json
 // - list.html.njk
+//
+// Images are written as files into the images folder and meta.json references them by their path.
 func ExportGenerator(gen snd.Generator, writer ExportWriter) error {
+	images, err := exportImages(gen.Images, writer)
+	if err != nil {
+		return err
+	}
+	gen.Images = images
+
 	metaData := &bytes.Buffer{}
 
 	if err := writeGenMeta(metaData, gen); err != nil {
@@ -0,0 +1,222 @@
+package imexport
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/vincent-petithory/dataurl"
+)
+
+// ImagesFolder is the folder in a template or generator folder that contains the images.
+const ImagesFolder = "images"
+
+// textOnlyWriter is implemented by export writers that can't store binary files.
+// Images are kept as data uris in the meta data for them.
+type textOnlyWriter interface {
+	textOnly()
+}
+
+// imageExtensions maps the image mime types to file extensions. Go's mime package
+// depends on the system for most of them, so the common ones are fixed here.
+var imageExtensions = map[string]string{
+	"image/png":     ".png",
+	"image/jpeg":    ".jpg",
+	"image/gif":     ".gif",
+	"image/webp":    ".webp",
+	"image/svg+xml": ".svg",
+	"image/bmp":     ".bmp",
+	"image/avif":    ".avif",
+}
+
+func imageExtension(mimeType string) string {
+	if ext, ok := imageExtensions[mimeType]; ok {
+		return ext
+	}
+	if exts, err := mime.ExtensionsByType(mimeType); err == nil && len(exts) > 0 {
+		return exts[0]
+	}
+	return ".bin"
+}
+
+func imageMimeType(file string) string {
+	ext := strings.ToLower(path.Ext(file))
+	for mimeType, imageExt := range imageExtensions {
+		if imageExt == ext {
+			return mimeType
+		}
+	}
+	if mimeType := mime.TypeByExtension(ext); len(mimeType) > 0 {
+		return mimeType
+	}
+	return "application/octet-stream"
+}
+
+// imageFile returns the path of the image in the images folder. The name is the hash of the content,
+// so that the same image is only stored once.
+func imageFile(data []byte, mimeType string) string {
+	hash := sha256.Sum256(data)
+	return path.Join(ImagesFolder, hex.EncodeToString(hash[:16])+imageExtension(mimeType))
+}
+
+// exportImages writes the images that are data uris as files into the images folder. The returned map
+// contains the paths of the files instead of the data uris. Other values (e.g. remote urls) are kept.
+func exportImages(images map[string]string, writer ExportWriter) (map[string]string, error) {
+	if images == nil {
+		return nil, nil
+	}
+
+	if _, ok := writer.(textOnlyWriter); ok {
+		return images, nil
+	}
+
+	res := map[string]string{}
+	written := map[string]bool{}
+	for name, val := range images {
+		if !strings.HasPrefix(val, "data:") {
+			res[name] = val
+			continue
+		}
+
+		decoded, err := dataurl.DecodeString(val)
+		if err != nil {
+			return nil, fmt.Errorf("image '%s' is not a valid data uri: %w", name, err)
+		}
+
+		file := imageFile(decoded.Data, decoded.MediaType.ContentType())
+		if !written[file] {
+			if err := writer.WriteFile(file, decoded.Data); err != nil {
+				return nil, fmt.Errorf("can't write file %s (%s)", file, err)
+			}
+			written[file] = true
+		}
+
+		res[name] = file
+	}
+
+	return res, nil
+}
+
+// importImages converts the images that reference files in the images folder back to data uris.
+func importImages(images map[string]string, reader ImportReader) (map[string]string, error) {
+	if images == nil {
+		return nil, nil
+	}
+
+	res := map[string]string{}
+	for name, val := range images {
+		if !strings.HasPrefix(val, ImagesFolder+"/") {
+			res[name] = val
+			continue
+		}
+
+		// The reference has to stay inside the images folder
+		file, err := cleanPath(val)
+		if err != nil || !strings.HasPrefix(file, ImagesFolder+"/") {
+			return nil, fmt.Errorf("image '%s' references a file outside of the images folder", name)
+		}
+
+		data, err := reader.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("can't read image %s (%s)", val, err)
+		}
+
+		res[name] = dataurl.New(data, imageMimeType(file)).String()
+	}
+
+	return res, nil
+}
+
+// remoteImgRegex finds <img> tags with a static remote src.
+var remoteImgRegex = regexp.MustCompile(`(<img\s[^>]*?src=)(["'])(https?://[^"'{}]+)(["'])`)
+
+// LocalizeImages downloads the remote images so that they can be bundled into an export. Remote urls
+// in images are replaced by data uris. Remote <img> src in the template are moved into images and
+// the src is replaced by a reference to it. Images that can't be downloaded are left as they are
+// and the returned error lists them, so that the caller can decide if they are fatal.
+func LocalizeImages(images map[string]string, template string) (map[string]string, string, error) {
+	client := &http.Client{Timeout: time.Second * 30}
+
+	var errs []error
+	cache := map[string]string{}
+	download := func(url string) (string, bool) {
+		if val, ok := cache[url]; ok {
+			return val, true
+		}
+
+		val, err := downloadImage(client, url)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", url, err))
+			return "", false
+		}
+
+		cache[url] = val
+		return val, true
+	}
+
+	res := map[string]string{}
+	for name, val := range images {
+		res[name] = val
+		if strings.HasPrefix(val, "http://") || strings.HasPrefix(val, "https://") {
+			if local, ok := download(val); ok {
+				res[name] = local
+			}
+		}
+	}
+
+	template = remoteImgRegex.ReplaceAllStringFunc(template, func(s string) string {
+		match := remoteImgRegex.FindStringSubmatch(s)
+		if match[2] != match[4] {
+			return s
+		}
+
+		local, ok := download(match[3])
+		if !ok {
+			return s
+		}
+
+		decoded, err := dataurl.DecodeString(local)
+		if err != nil {
+			return s
+		}
+
+		name := path.Base(imageFile(decoded.Data, decoded.MediaType.ContentType()))
+		res[name] = local
+
+		return fmt.Sprintf(`%s%s{{ images['%s'] }}%s`, match[1], match[2], name, match[4])
+	})
+
+	return res, template, errors.Join(errs...)
+}
+
+func downloadImage(client *http.Client, url string) (string, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	mimeType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if !strings.HasPrefix(mimeType, "image/") {
+		return "", errors.New("not a image")
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	return dataurl.New(data, mimeType).String(), nil
+}
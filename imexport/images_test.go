@@ -0,0 +1,78 @@
+package imexport
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type mapImportReader map[string][]byte
+
+func (m mapImportReader) ReadFile(s string) ([]byte, error) {
+	data, ok := m[s]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return data, nil
+}
+
+func TestImportImagesRejectsTraversal(t *testing.T) {
+	reader := mapImportReader{
+		"images/a.png": []byte("png"),
+		"secret.txt":   []byte("secret"),
+	}
+
+	res, err := importImages(map[string]string{"a": "images/./a.png", "b": "https://example.com/b.png"}, reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res["a"] != "data:image/png;base64,cG5n" || res["b"] != "https://example.com/b.png" {
+		t.Fatalf("unexpected images %v", res)
+	}
+
+	for _, val := range []string{"images/../secret.txt", "images/../../etc/passwd", `images/..\secret.txt`} {
+		if _, err := importImages(map[string]string{"a": val}, reader); err == nil {
+			t.Fatalf("expected '%s' to be rejected", val)
+		}
+	}
+}
+
+func TestReadersRejectTraversal(t *testing.T) {
+	base := t.TempDir()
+	if err := os.WriteFile(filepath.Join(base, "secret.txt"), []byte("secret"), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	folder := filepath.Join(base, "import")
+	if err := os.MkdirAll(filepath.Join(folder, "images"), 0777); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := &bytes.Buffer{}
+	writer := zip.NewWriter(buf)
+	if _, err := writer.Create("images/a.png"); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	zipReader, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	readers := map[string]ImportReader{
+		"folder": &FolderImportReader{base: folder},
+		"zip":    &ZipImportReader{reader: zipReader},
+	}
+
+	for name, reader := range readers {
+		for _, file := range []string{"../secret.txt", "images/../../secret.txt", "/etc/passwd"} {
+			if _, err := reader.ReadFile(file); err == nil {
+				t.Fatalf("%s reader: expected '%s' to be rejected", name, file)
+			}
+		}
+	}
+}
@@ -5,7 +5,9 @@ package imexport
 import (
 	"errors"
 	"fmt"
+	"path"
 	"regexp"
+	"strings"
 )
 
 // ImportReader represents an arbitrary file reader that can be used
@@ -14,6 +16,16 @@ type ImportReader interface {
 	ReadFile(string) ([]byte, error)
 }
 
+// cleanPath cleans a slash separated path that is read by an ImportReader. Absolute paths and
+// paths that leave the imported folder are rejected.
+func cleanPath(file string) (string, error) {
+	cleaned := path.Clean(strings.ReplaceAll(file, "\\", "/"))
+	if path.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", fmt.Errorf("invalid path '%s'", file)
+	}
+	return cleaned, nil
+}
+
 func readFiles(reader ImportReader, files []string) (map[string][]byte, error) {
 	res := map[string][]byte{}
 	for i := range files {
@@ -37,11 +37,14 @@ func (f *JSONExportWriter) WriteFile(file string, data []byte) error {
 	return nil
 }
 
+// textOnly keeps the images as data uris, as binary files can't be stored in json.
+func (f *JSONExportWriter) textOnly() {}
+
 func ExportTemplateJSON(tmpl snd.Template, entries []snd.Entry) ([]byte, error) {
 	name := fmt.Sprintf("%s_%s", tmpl.Author, tmpl.Slug)
 	writer := &JSONExportWriter{Name: name, Files: make(map[string]string)}
 
-	err := ExportTemplate(tmpl, entries, writer)
+	err := ExportTemplate(tmpl, entries, nil, writer)
 	if err != nil {
 		return []byte{}, err
 	}
@@ -54,11 +57,13 @@ func ExportTemplateJSON(tmpl snd.Template, entries []snd.Entry) ([]byte, error)
 	return json, nil
 }
 
+// ImportTemplateJSON imports a template from the json. Attachments are not supported in json.
 func ImportTemplateJSON(s string) (snd.Template, []snd.Entry, error) {
 	reader := &JSONImportReader{
 		json: s,
 	}
-	return ImportTemplate(reader)
+	tmpl, entries, _, err := ImportTemplate(reader)
+	return tmpl, entries, err
 }
 
 func ImportSourceJSON(s string) (snd.DataSource, []snd.Entry, error) {
@@ -106,3 +111,26 @@ func ImportGeneratorJSON(s string) (snd.Generator, error) {
 	}
 	return ImportGenerator(reader)
 }
+
+func ExportPromptJSON(prompt snd.Prompt) ([]byte, error) {
+	name := fmt.Sprintf("prompt_%s_%s", prompt.Author, prompt.Slug)
+	writer := &JSONExportWriter{Name: name, Files: make(map[string]string)}
+
+	err := ExportPrompt(prompt, writer)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	json, err := json.Marshal(writer)
+	if err != nil {
+		return []byte{}, err
+	}
+	return json, nil
+}
+
+func ImportPromptJSON(s string) (snd.Prompt, error) {
+	reader := &JSONImportReader{
+		json: s,
+	}
+	return ImportPrompt(reader)
+}
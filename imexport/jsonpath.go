@@ -0,0 +1,455 @@
+package imexport
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// pathSegment is a single step of a JSONPath expression.
+type pathSegment struct {
+	// recursive matches the segment against the value and all its descendants (..).
+	recursive bool
+	wildcard  bool
+	keys      []string
+	indices   []int
+	slice     *[2]*int
+	filter    *pathFilter
+}
+
+// pathFilter is a filter expression like "@.type == 'npc'".
+type pathFilter struct {
+	path  []pathSegment
+	op    string
+	value any
+}
+
+// SelectJSONPath returns all values that match the JSONPath expression. A subset of
+// JSONPath is supported:
+//
+//	$                       the root, can be omitted ("results[*]" is the same as "$.results[*]")
+//	.key ['key']            child
+//	.* [*]                  all children
+//	..key ..*               recursive descent
+//	[0] [-1] [0,2]          list indices
+//	[1:3]                   list slice
+//	['a','b']               multiple children
+//	[?(@.type == 'npc')]    filter with ==, !=, <, <=, >, >= or existence like [?(@.level)]
+func SelectJSONPath(data any, path string) ([]any, error) {
+	segments, err := parseJSONPath(path)
+	if err != nil {
+		return nil, err
+	}
+	return applyJSONPath(data, segments), nil
+}
+
+func applyJSONPath(data any, segments []pathSegment) []any {
+	values := []any{data}
+	for _, s := range segments {
+		var next []any
+		for _, v := range values {
+			if s.recursive {
+				for _, d := range descendants(v) {
+					next = append(next, s.match(d)...)
+				}
+			} else {
+				next = append(next, s.match(v)...)
+			}
+		}
+		values = next
+	}
+	return values
+}
+
+// children returns the values of a map sorted by key or the elements of a list.
+func children(value any) []any {
+	switch v := value.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		res := make([]any, len(keys))
+		for i, k := range keys {
+			res[i] = v[k]
+		}
+		return res
+	case []any:
+		return v
+	}
+	return nil
+}
+
+// descendants returns the value itself and all values that are nested in it.
+func descendants(value any) []any {
+	res := []any{value}
+	for _, c := range children(value) {
+		res = append(res, descendants(c)...)
+	}
+	return res
+}
+
+func (s pathSegment) match(value any) []any {
+	switch {
+	case s.wildcard:
+		return children(value)
+	case s.filter != nil:
+		var res []any
+		for _, c := range children(value) {
+			if s.filter.matches(c) {
+				res = append(res, c)
+			}
+		}
+		return res
+	case s.slice != nil:
+		list, ok := value.([]any)
+		if !ok {
+			return nil
+		}
+
+		start, end := 0, len(list)
+		if s.slice[0] != nil {
+			start = listIndex(*s.slice[0], len(list))
+		}
+		if s.slice[1] != nil {
+			end = listIndex(*s.slice[1], len(list))
+		}
+		start = max(0, min(start, len(list)))
+		end = max(start, min(end, len(list)))
+		return list[start:end]
+	case len(s.indices) > 0:
+		list, ok := value.([]any)
+		if !ok {
+			return nil
+		}
+
+		var res []any
+		for _, i := range s.indices {
+			if i = listIndex(i, len(list)); i >= 0 && i < len(list) {
+				res = append(res, list[i])
+			}
+		}
+		return res
+	default:
+		obj, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+
+		var res []any
+		for _, k := range s.keys {
+			if v, ok := obj[k]; ok {
+				res = append(res, v)
+			}
+		}
+		return res
+	}
+}
+
+// listIndex resolves negative indices from the end of the list.
+func listIndex(i int, length int) int {
+	if i < 0 {
+		return length + i
+	}
+	return i
+}
+
+func (f *pathFilter) matches(value any) bool {
+	values := applyJSONPath(value, f.path)
+	if len(f.op) == 0 {
+		return len(values) > 0
+	}
+	if len(values) == 0 {
+		return f.op == "!="
+	}
+
+	left := values[0]
+	switch f.op {
+	case "==":
+		return compareValues(left, f.value) == 0
+	case "!=":
+		return compareValues(left, f.value) != 0
+	}
+
+	// Ordering is only defined for values of the same type
+	switch left.(type) {
+	case float64:
+		if _, ok := f.value.(float64); !ok {
+			return false
+		}
+	case string:
+		if _, ok := f.value.(string); !ok {
+			return false
+		}
+	default:
+		return false
+	}
+
+	c := compareValues(left, f.value)
+	switch f.op {
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	case ">=":
+		return c >= 0
+	}
+	return false
+}
+
+// compareValues returns 0 if the values are equal and otherwise their order if they are
+// numbers or strings.
+func compareValues(a any, b any) int {
+	switch av := a.(type) {
+	case float64:
+		if bv, ok := b.(float64); ok {
+			switch {
+			case av < bv:
+				return -1
+			case av > bv:
+				return 1
+			}
+			return 0
+		}
+	case string:
+		if bv, ok := b.(string); ok {
+			return strings.Compare(av, bv)
+		}
+	case bool:
+		if bv, ok := b.(bool); ok && av == bv {
+			return 0
+		}
+	case nil:
+		if b == nil {
+			return 0
+		}
+	}
+	return 1
+}
+
+func parseJSONPath(path string) ([]pathSegment, error) {
+	path = strings.TrimSpace(path)
+	switch {
+	case strings.HasPrefix(path, "$"), strings.HasPrefix(path, "@"):
+		path = path[1:]
+	case len(path) > 0 && path[0] != '.' && path[0] != '[':
+		path = "." + path
+	}
+
+	var segments []pathSegment
+	for i := 0; i < len(path); {
+		var segment pathSegment
+
+		switch {
+		case strings.HasPrefix(path[i:], ".."):
+			segment.recursive = true
+			i += 2
+			if i < len(path) && path[i] == '[' {
+				s, n, err := parseBracket(path[i:])
+				if err != nil {
+					return nil, err
+				}
+				s.recursive = true
+				segments = append(segments, s)
+				i += n
+				continue
+			}
+			fallthrough
+		case path[i] == '.':
+			if !segment.recursive {
+				i++
+			}
+
+			n := strings.IndexAny(path[i:], ".[")
+			if n < 0 {
+				n = len(path) - i
+			}
+			if n == 0 {
+				return nil, fmt.Errorf("missing name at position %d", i)
+			}
+
+			name := path[i : i+n]
+			if name == "*" {
+				segment.wildcard = true
+			} else {
+				segment.keys = []string{name}
+			}
+			segments = append(segments, segment)
+			i += n
+		case path[i] == '[':
+			s, n, err := parseBracket(path[i:])
+			if err != nil {
+				return nil, err
+			}
+			segments = append(segments, s)
+			i += n
+		default:
+			return nil, fmt.Errorf("unexpected '%c' at position %d", path[i], i)
+		}
+	}
+
+	return segments, nil
+}
+
+// parseBracket parses a segment like "['key']" or "[?(@.a == 1)]" at the start of the text and
+// returns the length of the segment.
+func parseBracket(text string) (pathSegment, int, error) {
+	end := -1
+	depth := 0
+	var quote byte
+	for i := 1; i < len(text) && end < 0; i++ {
+		c := text[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '(' || c == '[':
+			depth++
+		case c == ')':
+			depth--
+		case c == ']':
+			if depth == 0 {
+				end = i
+			} else {
+				depth--
+			}
+		}
+	}
+	if end < 0 {
+		return pathSegment{}, 0, errors.New("missing ']'")
+	}
+
+	content := strings.TrimSpace(text[1:end])
+	var segment pathSegment
+
+	switch {
+	case content == "*":
+		segment.wildcard = true
+	case strings.HasPrefix(content, "?"):
+		filter, err := parseFilter(content[1:])
+		if err != nil {
+			return pathSegment{}, 0, err
+		}
+		segment.filter = filter
+	case strings.Contains(content, ":") && !strings.ContainsAny(content, `'"`):
+		from, to, _ := strings.Cut(content, ":")
+		segment.slice = &[2]*int{}
+		for i, part := range []string{from, to} {
+			if part = strings.TrimSpace(part); len(part) == 0 {
+				continue
+			}
+
+			n, err := strconv.Atoi(part)
+			if err != nil {
+				return pathSegment{}, 0, fmt.Errorf("invalid slice '%s'", content)
+			}
+			segment.slice[i] = &n
+		}
+	default:
+		for _, part := range splitOutsideQuotes(content, ',') {
+			if part = strings.TrimSpace(part); len(part) == 0 {
+				return pathSegment{}, 0, fmt.Errorf("invalid selector '%s'", content)
+			}
+
+			if key, ok := unquote(part); ok {
+				segment.keys = append(segment.keys, key)
+				continue
+			}
+
+			n, err := strconv.Atoi(part)
+			if err != nil {
+				return pathSegment{}, 0, fmt.Errorf("invalid selector '%s'", part)
+			}
+			segment.indices = append(segment.indices, n)
+		}
+
+		if len(segment.keys) > 0 && len(segment.indices) > 0 {
+			return pathSegment{}, 0, fmt.Errorf("keys and indices can't be mixed in '%s'", content)
+		}
+	}
+
+	return segment, end + 1, nil
+}
+
+func parseFilter(text string) (*pathFilter, error) {
+	text = strings.TrimSpace(text)
+	if strings.HasPrefix(text, "(") && strings.HasSuffix(text, ")") {
+		text = strings.TrimSpace(text[1 : len(text)-1])
+	}
+
+	if !strings.HasPrefix(text, "@") {
+		return nil, fmt.Errorf("filter '%s' has to start with '@'", text)
+	}
+
+	filter := &pathFilter{}
+	left := text
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if i := indexOutsideQuotes(text, op); i >= 0 {
+			filter.op = op
+			left = strings.TrimSpace(text[:i])
+
+			right := strings.TrimSpace(text[i+len(op):])
+			if s, ok := unquote(right); ok {
+				filter.value = s
+			} else if err := json.Unmarshal([]byte(right), &filter.value); err != nil {
+				return nil, fmt.Errorf("invalid value '%s' in filter", right)
+			}
+			break
+		}
+	}
+
+	path, err := parseJSONPath(left)
+	if err != nil {
+		return nil, err
+	}
+	filter.path = path
+
+	return filter, nil
+}
+
+// unquote removes single or double quotes around the text.
+func unquote(text string) (string, bool) {
+	if len(text) >= 2 && (text[0] == '\'' || text[0] == '"') && text[len(text)-1] == text[0] {
+		return text[1 : len(text)-1], true
+	}
+	return "", false
+}
+
+func indexOutsideQuotes(text string, sub string) int {
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		switch {
+		case quote != 0:
+			if text[i] == quote {
+				quote = 0
+			}
+		case text[i] == '\'' || text[i] == '"':
+			quote = text[i]
+		case strings.HasPrefix(text[i:], sub):
+			return i
+		}
+	}
+	return -1
+}
+
+func splitOutsideQuotes(text string, sep byte) []string {
+	var parts []string
+	for {
+		i := indexOutsideQuotes(text, string(sep))
+		if i < 0 {
+			return append(parts, text)
+		}
+		parts = append(parts, text[:i])
+		text = text[i+1:]
+	}
+}
@@ -0,0 +1,95 @@
+package imexport
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+const jsonPathData = `{
+	"results": [
+		{"name": "Goblin", "type": "npc", "level": 1, "tags": ["small", "humanoid"], "stats": {"hp": 7}},
+		{"name": "Orc", "type": "npc", "level": 3, "stats": {"hp": 15}},
+		{"name": "Rope", "type": "item", "price": "1 gp"},
+		{"name": "Dragon", "type": "npc", "level": 10, "boss": true, "stats": {"hp": 200}}
+	],
+	"meta": {"count": 4, "page.size": 10, "it's": "quoted"}
+}`
+
+func TestSelectJSONPath(t *testing.T) {
+	var data any
+	if err := json.Unmarshal([]byte(jsonPathData), &data); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		path string
+		want []any
+	}{
+		{path: "$", want: []any{data}},
+		{path: "$.meta.count", want: []any{4.0}},
+		{path: "meta.count", want: []any{4.0}},
+		{path: "$['meta']['page.size']", want: []any{10.0}},
+		{path: `$.meta["it's"]`, want: []any{"quoted"}},
+		{path: "$.meta[*]", want: []any{4.0, "quoted", 10.0}},
+		{path: "$.meta.*", want: []any{4.0, "quoted", 10.0}},
+		{path: "$.meta['count','missing']", want: []any{4.0}},
+		{path: "$.meta.missing", want: nil},
+		{path: "$.results[0].name", want: []any{"Goblin"}},
+		{path: "$.results[-1].name", want: []any{"Dragon"}},
+		{path: "$.results[0,2].name", want: []any{"Goblin", "Rope"}},
+		{path: "$.results[4].name", want: nil},
+		{path: "$.results[-5].name", want: nil},
+		{path: "$.results[1:3].name", want: []any{"Orc", "Rope"}},
+		{path: "$.results[:2].name", want: []any{"Goblin", "Orc"}},
+		{path: "$.results[-2:].name", want: []any{"Rope", "Dragon"}},
+		{path: "$.results[2:1].name", want: nil},
+		{path: "$.results[1:100].name", want: []any{"Orc", "Rope", "Dragon"}},
+		{path: "$.meta[0:1]", want: nil},
+		{path: "$.results[*].tags[1]", want: []any{"humanoid"}},
+		{path: "$.results[?(@.type == 'npc')].name", want: []any{"Goblin", "Orc", "Dragon"}},
+		{path: `$.results[?(@.type != "npc")].name`, want: []any{"Rope"}},
+		{path: "$.results[?(@.level >= 3)].name", want: []any{"Orc", "Dragon"}},
+		{path: "$.results[?(@.level < 3)].name", want: []any{"Goblin"}},
+		{path: "$.results[?(@.stats.hp > 10)].name", want: []any{"Orc", "Dragon"}},
+		{path: "$.results[?(@.name > 'N')].name", want: []any{"Orc", "Rope"}},
+		{path: "$.results[?(@.level > '1')].name", want: nil},
+		{path: "$.results[?(@.boss == true)].name", want: []any{"Dragon"}},
+		{path: "$.results[?(@.price)].name", want: []any{"Rope"}},
+		{path: "$.results[?(@.name == 'a == b')].name", want: nil},
+		{path: "$..hp", want: []any{7.0, 15.0, 200.0}},
+		{path: "$..tags[0]", want: []any{"small"}},
+		{path: "$.results..stats.hp", want: []any{7.0, 15.0, 200.0}},
+		{path: "$..[?(@.boss)].name", want: []any{"Dragon"}},
+		{path: "$.meta..*", want: []any{4.0, "quoted", 10.0}},
+	}
+
+	for _, c := range cases {
+		res, err := SelectJSONPath(data, c.path)
+		if err != nil {
+			t.Errorf("%s: %s", c.path, err)
+			continue
+		}
+		if !reflect.DeepEqual(res, c.want) {
+			t.Errorf("%s: expected %v, got %v", c.path, c.want, res)
+		}
+	}
+}
+
+func TestSelectJSONPathErrors(t *testing.T) {
+	for _, path := range []string{
+		"$.results[0",
+		"$.results[a]",
+		"$.results[1:b]",
+		"$.results['a',0]",
+		"$.results[0,]",
+		"$.results[?(type == 'npc')]",
+		"$.results[?(@.level > abc)]",
+		"$.results.",
+		"$results",
+	} {
+		if _, err := SelectJSONPath(nil, path); err == nil {
+			t.Errorf("%s: expected an error", path)
+		}
+	}
+}
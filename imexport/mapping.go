@@ -0,0 +1,417 @@
+package imexport
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/BigJk/snd"
+	"github.com/BigJk/snd/templating"
+)
+
+// Operations of mapping rules.
+const (
+	// MappingRename moves the value of the field to another path.
+	MappingRename = "rename"
+	// MappingDrop removes the field.
+	MappingDrop = "drop"
+	// MappingCompute sets the field to the result of a Nunjucks expression like "it.hp.average" or
+	// a template like "{{ it.size }} {{ it.type }}". The data of the entry is available as "it".
+	MappingCompute = "compute"
+	// MappingSplit splits a text field by a separator into a list.
+	MappingSplit = "split"
+	// MappingConvert converts the field to a type.
+	MappingConvert = "convert"
+	// MappingDefault sets the field to a value if it is missing or empty.
+	MappingDefault = "default"
+)
+
+// Types that values can be converted to.
+const (
+	MappingTypeAuto   = "auto"
+	MappingTypeString = "string"
+	MappingTypeNumber = "number"
+	MappingTypeInt    = "int"
+	MappingTypeBool   = "bool"
+	MappingTypeJSON   = "json"
+)
+
+// MappingRule is a single step that transforms the data of an entry. Fields are
+// addressed by dotted paths like "stats.str".
+type MappingRule struct {
+	Op    string `json:"op"`
+	Field string `json:"field"`
+	// To is the target of rename, compute and split. Compute and split write to Field if it is empty.
+	To string `json:"to"`
+	// Expr is the expression of compute.
+	Expr string `json:"expr"`
+	// Separator is the separator of split. If it is empty "," is used.
+	Separator string `json:"separator"`
+	// Type is the type for convert and the optional type of the result of compute and the elements of split.
+	Type string `json:"type"`
+	// Value is the value of default.
+	Value any `json:"value"`
+}
+
+// Mapping is a list of rules that are applied in order to every imported entry. It is used to
+// bring the data of different import formats into the same shape.
+type Mapping struct {
+	Rules []MappingRule `json:"rules"`
+}
+
+// Validate checks if all rules are complete.
+func (m Mapping) Validate() error {
+	for i, rule := range m.Rules {
+		err := func() error {
+			if len(rule.Field) == 0 && !(rule.Op == MappingCompute && len(rule.To) > 0) {
+				return errors.New("field is missing")
+			}
+
+			switch rule.Op {
+			case MappingRename:
+				if len(rule.To) == 0 {
+					return errors.New("target is missing")
+				}
+			case MappingCompute:
+				if len(rule.Expr) == 0 {
+					return errors.New("expression is missing")
+				}
+			case MappingConvert:
+				if len(rule.Type) == 0 {
+					return errors.New("type is missing")
+				}
+			case MappingDrop, MappingSplit, MappingDefault:
+			default:
+				return fmt.Errorf("unknown operation '%s'", rule.Op)
+			}
+
+			switch rule.Type {
+			case "", MappingTypeAuto, MappingTypeString, MappingTypeNumber, MappingTypeInt, MappingTypeBool, MappingTypeJSON:
+			default:
+				return fmt.Errorf("unknown type '%s'", rule.Type)
+			}
+
+			return nil
+		}()
+		if err != nil {
+			return fmt.Errorf("rule %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// Apply transforms the data of all entries. The renderer is used to evaluate the expressions
+// of compute rules. The entries passed in are not modified.
+func (m Mapping) Apply(renderer *templating.Renderer, entries []snd.Entry) ([]snd.Entry, error) {
+	if len(m.Rules) == 0 {
+		return entries, nil
+	}
+
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+
+	res := make([]snd.Entry, len(entries))
+	for i := range entries {
+		data, err := copyData(entries[i].Data)
+		if err != nil {
+			return nil, err
+		}
+
+		for j, rule := range m.Rules {
+			if err := applyRule(renderer, rule, data); err != nil {
+				return nil, fmt.Errorf("rule %d failed for entry '%s': %w", j+1, entries[i].ID, err)
+			}
+		}
+
+		res[i] = snd.Entry{
+			ID:   entries[i].ID,
+			Name: entries[i].Name,
+			Data: data,
+		}
+	}
+
+	return res, nil
+}
+
+// copyData creates a deep copy of the data through json.
+func copyData(data map[string]any) (map[string]any, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	res := map[string]any{}
+	if err := json.Unmarshal(raw, &res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func applyRule(renderer *templating.Renderer, rule MappingRule, data map[string]any) error {
+	target := rule.To
+	if len(target) == 0 {
+		target = rule.Field
+	}
+
+	switch rule.Op {
+	case MappingRename:
+		if value, ok := GetPath(data, rule.Field); ok {
+			DeletePath(data, rule.Field)
+			SetPath(data, rule.To, value)
+		}
+	case MappingDrop:
+		DeletePath(data, rule.Field)
+	case MappingCompute:
+		if renderer == nil {
+			return errors.New("expressions can't be evaluated")
+		}
+
+		template := rule.Expr
+		if !strings.Contains(template, "{{") && !strings.Contains(template, "{%") {
+			template = "{{ " + template + " }}"
+		}
+
+		res, err := renderer.Render(template, templating.State{It: data, Config: map[string]any{"seed": "mapping"}}, templating.Options{Minimal: true, Raw: true})
+		if err != nil {
+			return err
+		}
+
+		value, err := convertValue(strings.TrimSpace(res), rule.Type)
+		if err != nil {
+			return err
+		}
+
+		SetPath(data, target, value)
+	case MappingSplit:
+		value, ok := GetPath(data, rule.Field)
+		if !ok {
+			return nil
+		}
+
+		text, ok := value.(string)
+		if !ok {
+			return nil
+		}
+
+		separator := rule.Separator
+		if len(separator) == 0 {
+			separator = ","
+		}
+
+		list := []any{}
+		for _, part := range strings.Split(text, separator) {
+			if part = strings.TrimSpace(part); len(part) == 0 {
+				continue
+			}
+
+			converted, err := convertValue(part, rule.Type)
+			if err != nil {
+				return err
+			}
+			list = append(list, converted)
+		}
+
+		SetPath(data, target, list)
+	case MappingConvert:
+		value, ok := GetPath(data, rule.Field)
+		if !ok {
+			return nil
+		}
+
+		converted, err := convertValue(value, rule.Type)
+		if err != nil {
+			return err
+		}
+
+		SetPath(data, rule.Field, converted)
+	case MappingDefault:
+		if value, ok := GetPath(data, rule.Field); !ok || value == nil || value == "" {
+			SetPath(data, rule.Field, rule.Value)
+		}
+	}
+
+	return nil
+}
+
+// convertValue converts the value to the type. The auto type converts text that is
+// a plain number or boolean and keeps everything else as it is.
+func convertValue(value any, t string) (any, error) {
+	text, isText := value.(string)
+
+	switch t {
+	case "", MappingTypeAuto:
+		if !isText {
+			return value, nil
+		}
+		if f, err := strconv.ParseFloat(text, 64); err == nil {
+			return f, nil
+		}
+		if b, err := strconv.ParseBool(text); err == nil && (text == "true" || text == "false") {
+			return b, nil
+		}
+		return text, nil
+	case MappingTypeString:
+		if isText {
+			return text, nil
+		}
+		if value == nil {
+			return "", nil
+		}
+		if f, ok := value.(float64); ok {
+			return strconv.FormatFloat(f, 'f', -1, 64), nil
+		}
+		if _, ok := value.(bool); ok {
+			return fmt.Sprint(value), nil
+		}
+		raw, err := json.Marshal(value)
+		return string(raw), err
+	case MappingTypeNumber, MappingTypeInt:
+		var f float64
+		switch v := value.(type) {
+		case float64:
+			f = v
+		case bool:
+			if v {
+				f = 1
+			}
+		case string:
+			// Take the leading number of text like "15 (natural armor)"
+			fields := strings.Fields(v)
+			if len(fields) == 0 {
+				return nil, fmt.Errorf("'%s' is not a number", v)
+			}
+
+			var err error
+			if f, err = strconv.ParseFloat(strings.TrimSuffix(fields[0], ","), 64); err != nil {
+				return nil, fmt.Errorf("'%s' is not a number", v)
+			}
+		default:
+			return nil, fmt.Errorf("%v is not a number", value)
+		}
+
+		if t == MappingTypeInt {
+			return float64(int64(f)), nil
+		}
+		return f, nil
+	case MappingTypeBool:
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case float64:
+			return v != 0, nil
+		case string:
+			switch strings.ToLower(strings.TrimSpace(v)) {
+			case "true", "yes", "y", "1", "x":
+				return true, nil
+			case "false", "no", "n", "0", "":
+				return false, nil
+			}
+			return nil, fmt.Errorf("'%s' is not a boolean", v)
+		case nil:
+			return false, nil
+		}
+		return nil, fmt.Errorf("%v is not a boolean", value)
+	case MappingTypeJSON:
+		if !isText {
+			return value, nil
+		}
+
+		var res any
+		if err := json.Unmarshal([]byte(text), &res); err != nil {
+			return nil, err
+		}
+		return res, nil
+	}
+
+	return nil, fmt.Errorf("unknown type '%s'", t)
+}
+
+// GetPath returns the value at the dotted path. Numbers address elements of lists.
+func GetPath(data map[string]any, path string) (any, bool) {
+	var current any = data
+	for _, key := range strings.Split(path, ".") {
+		switch v := current.(type) {
+		case map[string]any:
+			next, ok := v[key]
+			if !ok {
+				return nil, false
+			}
+			current = next
+		case []any:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil, false
+			}
+			current = v[i]
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// SetPath sets the value at the dotted path and creates missing objects on the way.
+func SetPath(data map[string]any, path string, value any) {
+	keys := strings.Split(path, ".")
+
+	var current any = data
+	for i, key := range keys {
+		last := i == len(keys)-1
+
+		switch v := current.(type) {
+		case map[string]any:
+			if last {
+				v[key] = value
+				return
+			}
+
+			next, ok := v[key]
+			if _, isList := next.([]any); !ok || (!isList && !isMap(next)) {
+				next = map[string]any{}
+				v[key] = next
+			}
+			current = next
+		case []any:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(v) {
+				return
+			}
+
+			if last {
+				v[index] = value
+				return
+			}
+
+			if _, isList := v[index].([]any); !isList && !isMap(v[index]) {
+				v[index] = map[string]any{}
+			}
+			current = v[index]
+		}
+	}
+}
+
+// DeletePath removes the value at the dotted path.
+func DeletePath(data map[string]any, path string) {
+	keys := strings.Split(path, ".")
+
+	parent, ok := GetPath(data, strings.Join(keys[:len(keys)-1], "."))
+	if len(keys) == 1 {
+		parent, ok = data, true
+	}
+	if !ok {
+		return
+	}
+
+	if obj, ok := parent.(map[string]any); ok {
+		delete(obj, keys[len(keys)-1])
+	}
+}
+
+func isMap(value any) bool {
+	_, ok := value.(map[string]any)
+	return ok
+}
@@ -0,0 +1,316 @@
+package imexport
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/BigJk/snd"
+	"github.com/BigJk/snd/templating"
+)
+
+func testData() map[string]any {
+	return map[string]any{
+		"name":  "Goblin",
+		"ac":    "15 (leather armor)",
+		"stats": map[string]any{"str": 8.0, "dex": "14"},
+		"tags":  "small, humanoid,, goblinoid",
+		"items": []any{map[string]any{"name": "Scimitar"}, "Shortbow"},
+		"empty": "",
+	}
+}
+
+func TestMappingRules(t *testing.T) {
+	cases := []struct {
+		name string
+		rule MappingRule
+		want map[string]any
+		err  bool
+	}{
+		{
+			name: "rename",
+			rule: MappingRule{Op: MappingRename, Field: "ac", To: "armor"},
+			want: map[string]any{"armor": "15 (leather armor)", "ac": nil},
+		},
+		{
+			name: "rename dotted paths",
+			rule: MappingRule{Op: MappingRename, Field: "stats.str", To: "abilities.strength"},
+			want: map[string]any{"stats": map[string]any{"dex": "14"}, "abilities": map[string]any{"strength": 8.0}},
+		},
+		{
+			name: "rename missing field",
+			rule: MappingRule{Op: MappingRename, Field: "stats.con", To: "con"},
+			want: map[string]any{},
+		},
+		{
+			name: "drop",
+			rule: MappingRule{Op: MappingDrop, Field: "stats.dex"},
+			want: map[string]any{"stats": map[string]any{"str": 8.0}},
+		},
+		{
+			name: "drop missing field",
+			rule: MappingRule{Op: MappingDrop, Field: "missing.field"},
+			want: map[string]any{},
+		},
+		{
+			name: "compute expression",
+			rule: MappingRule{Op: MappingCompute, Field: "stats.str", To: "str", Expr: "it.stats.str + 2", Type: MappingTypeNumber},
+			want: map[string]any{"str": 10.0},
+		},
+		{
+			name: "compute template",
+			rule: MappingRule{Op: MappingCompute, To: "title", Expr: "{{ it.name }} ({{ it.items.0.name }})"},
+			want: map[string]any{"title": "Goblin (Scimitar)"},
+		},
+		{
+			name: "compute into the field",
+			rule: MappingRule{Op: MappingCompute, Field: "stats.dex", Expr: "it.stats.dex"},
+			want: map[string]any{"stats": map[string]any{"str": 8.0, "dex": 14.0}},
+		},
+		{
+			name: "compute with invalid template",
+			rule: MappingRule{Op: MappingCompute, To: "x", Expr: "{% if %}"},
+			err:  true,
+		},
+		{
+			name: "split",
+			rule: MappingRule{Op: MappingSplit, Field: "tags"},
+			want: map[string]any{"tags": []any{"small", "humanoid", "goblinoid"}},
+		},
+		{
+			name: "split with separator and type",
+			rule: MappingRule{Op: MappingSplit, Field: "ac", To: "parts", Separator: " (", Type: MappingTypeString},
+			want: map[string]any{"parts": []any{"15", "leather armor)"}},
+		},
+		{
+			name: "split ignores non-text",
+			rule: MappingRule{Op: MappingSplit, Field: "stats"},
+			want: map[string]any{},
+		},
+		{
+			name: "convert to int",
+			rule: MappingRule{Op: MappingConvert, Field: "ac", Type: MappingTypeInt},
+			want: map[string]any{"ac": 15.0},
+		},
+		{
+			name: "convert list element",
+			rule: MappingRule{Op: MappingConvert, Field: "items.0", Type: MappingTypeString},
+			want: map[string]any{"items": []any{`{"name":"Scimitar"}`, "Shortbow"}},
+		},
+		{
+			name: "convert invalid number",
+			rule: MappingRule{Op: MappingConvert, Field: "name", Type: MappingTypeNumber},
+			err:  true,
+		},
+		{
+			name: "convert missing field",
+			rule: MappingRule{Op: MappingConvert, Field: "missing", Type: MappingTypeNumber},
+			want: map[string]any{},
+		},
+		{
+			name: "default for empty field",
+			rule: MappingRule{Op: MappingDefault, Field: "empty", Value: "none"},
+			want: map[string]any{"empty": "none"},
+		},
+		{
+			name: "default for missing dotted path",
+			rule: MappingRule{Op: MappingDefault, Field: "stats.con", Value: 10.0},
+			want: map[string]any{"stats": map[string]any{"str": 8.0, "dex": "14", "con": 10.0}},
+		},
+		{
+			name: "default keeps value",
+			rule: MappingRule{Op: MappingDefault, Field: "name", Value: "Unknown"},
+			want: map[string]any{},
+		},
+	}
+
+	renderer := templating.New(nil)
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			entries := []snd.Entry{{ID: "goblin", Name: "Goblin", Data: testData()}}
+
+			res, err := Mapping{Rules: []MappingRule{c.rule}}.Apply(renderer, entries)
+			if c.err {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// The expected data only contains the changed fields, nil marks removed fields
+			want := testData()
+			for key, value := range c.want {
+				if value == nil {
+					delete(want, key)
+				} else {
+					want[key] = value
+				}
+			}
+
+			if !reflect.DeepEqual(res[0].Data, want) {
+				t.Fatalf("expected %v, got %v", want, res[0].Data)
+			}
+			if !reflect.DeepEqual(entries[0].Data, testData()) {
+				t.Fatal("expected the original entry to be unchanged")
+			}
+		})
+	}
+}
+
+func TestMappingValidate(t *testing.T) {
+	cases := []struct {
+		rule MappingRule
+		err  bool
+	}{
+		{rule: MappingRule{Op: MappingDrop, Field: "a"}},
+		{rule: MappingRule{Op: MappingCompute, To: "a", Expr: "1"}},
+		{rule: MappingRule{Op: MappingDrop}, err: true},
+		{rule: MappingRule{Op: MappingRename, Field: "a"}, err: true},
+		{rule: MappingRule{Op: MappingCompute, Field: "a"}, err: true},
+		{rule: MappingRule{Op: MappingConvert, Field: "a"}, err: true},
+		{rule: MappingRule{Op: MappingConvert, Field: "a", Type: "date"}, err: true},
+		{rule: MappingRule{Op: "merge", Field: "a"}, err: true},
+	}
+
+	for _, c := range cases {
+		if err := (Mapping{Rules: []MappingRule{c.rule}}).Validate(); (err != nil) != c.err {
+			t.Errorf("%+v: expected error %v, got %v", c.rule, c.err, err)
+		}
+	}
+
+	if _, err := (Mapping{Rules: []MappingRule{{Op: MappingCompute, To: "a", Expr: "1"}}}).Apply(nil, []snd.Entry{{Data: map[string]any{}}}); err == nil {
+		t.Error("expected an error for compute without renderer")
+	}
+}
+
+func TestConvertValue(t *testing.T) {
+	cases := []struct {
+		value any
+		t     string
+		want  any
+		err   bool
+	}{
+		{value: "12.5", t: MappingTypeAuto, want: 12.5},
+		{value: "true", t: "", want: true},
+		{value: "True", t: MappingTypeAuto, want: "True"},
+		{value: map[string]any{"a": 1.0}, t: MappingTypeAuto, want: map[string]any{"a": 1.0}},
+		{value: 1.5, t: MappingTypeString, want: "1.5"},
+		{value: false, t: MappingTypeString, want: "false"},
+		{value: nil, t: MappingTypeString, want: ""},
+		{value: []any{1.0}, t: MappingTypeString, want: "[1]"},
+		{value: "7, 2d6", t: MappingTypeNumber, want: 7.0},
+		{value: "7.9 hp", t: MappingTypeInt, want: 7.0},
+		{value: true, t: MappingTypeNumber, want: 1.0},
+		{value: "", t: MappingTypeNumber, err: true},
+		{value: []any{}, t: MappingTypeNumber, err: true},
+		{value: "Yes", t: MappingTypeBool, want: true},
+		{value: "x", t: MappingTypeBool, want: true},
+		{value: "", t: MappingTypeBool, want: false},
+		{value: 0.0, t: MappingTypeBool, want: false},
+		{value: nil, t: MappingTypeBool, want: false},
+		{value: "maybe", t: MappingTypeBool, err: true},
+		{value: `{"a": [1]}`, t: MappingTypeJSON, want: map[string]any{"a": []any{1.0}}},
+		{value: 1.0, t: MappingTypeJSON, want: 1.0},
+		{value: `{"a": `, t: MappingTypeJSON, err: true},
+		{value: "a", t: "date", err: true},
+	}
+
+	for _, c := range cases {
+		res, err := convertValue(c.value, c.t)
+		if (err != nil) != c.err || (!c.err && !reflect.DeepEqual(res, c.want)) {
+			t.Errorf("%v as %s: expected %v (error %v), got %v (%v)", c.value, c.t, c.want, c.err, res, err)
+		}
+	}
+}
+
+func TestGetPath(t *testing.T) {
+	data := testData()
+
+	cases := []struct {
+		path string
+		want any
+		ok   bool
+	}{
+		{path: "name", want: "Goblin", ok: true},
+		{path: "stats.str", want: 8.0, ok: true},
+		{path: "items.0.name", want: "Scimitar", ok: true},
+		{path: "items.1", want: "Shortbow", ok: true},
+		{path: "items.2", ok: false},
+		{path: "items.-1", ok: false},
+		{path: "items.first", ok: false},
+		{path: "stats.con", ok: false},
+		{path: "name.first", ok: false},
+		{path: "missing.path", ok: false},
+		{path: "", ok: false},
+	}
+
+	for _, c := range cases {
+		res, ok := GetPath(data, c.path)
+		if ok != c.ok || !reflect.DeepEqual(res, c.want) {
+			t.Errorf("%s: expected %v (%v), got %v (%v)", c.path, c.want, c.ok, res, ok)
+		}
+	}
+}
+
+func TestSetPath(t *testing.T) {
+	cases := []struct {
+		path  string
+		value any
+		want  map[string]any
+	}{
+		{path: "name", value: "Orc", want: map[string]any{"name": "Orc"}},
+		{path: "stats.con", value: 10.0, want: map[string]any{"stats": map[string]any{"str": 8.0, "dex": "14", "con": 10.0}}},
+		{path: "new.nested.key", value: 1.0, want: map[string]any{"new": map[string]any{"nested": map[string]any{"key": 1.0}}}},
+		{path: "name.first", value: "G", want: map[string]any{"name": map[string]any{"first": "G"}}},
+		{path: "items.0.name", value: "Dagger", want: map[string]any{"items": []any{map[string]any{"name": "Dagger"}, "Shortbow"}}},
+		{path: "items.1.range", value: 80.0, want: map[string]any{"items": []any{map[string]any{"name": "Scimitar"}, map[string]any{"range": 80.0}}}},
+		{path: "items.5", value: "Rope", want: map[string]any{}},
+		{path: "items.x", value: "Rope", want: map[string]any{}},
+	}
+
+	for _, c := range cases {
+		data := testData()
+		SetPath(data, c.path, c.value)
+
+		want := testData()
+		for key, value := range c.want {
+			want[key] = value
+		}
+
+		if !reflect.DeepEqual(data, want) {
+			t.Errorf("%s: expected %v, got %v", c.path, want, data)
+		}
+	}
+}
+
+func TestDeletePath(t *testing.T) {
+	cases := []struct {
+		path    string
+		removed string
+		want    map[string]any
+	}{
+		{path: "name", removed: "name"},
+		{path: "stats.dex", want: map[string]any{"stats": map[string]any{"str": 8.0}}},
+		{path: "items.0.name", want: map[string]any{"items": []any{map[string]any{}, "Shortbow"}}},
+		{path: "items.1", want: map[string]any{}},
+		{path: "stats.con", want: map[string]any{}},
+		{path: "missing.path", want: map[string]any{}},
+	}
+
+	for _, c := range cases {
+		data := testData()
+		DeletePath(data, c.path)
+
+		want := testData()
+		delete(want, c.removed)
+		for key, value := range c.want {
+			want[key] = value
+		}
+
+		if !reflect.DeepEqual(data, want) {
+			t.Errorf("%s: expected %v, got %v", c.path, want, data)
+		}
+	}
+}
@@ -0,0 +1,477 @@
+// Package markdown imports Markdown documents, including Homebrewery and GM Binder style
+// stat blocks and tables, as data source entries.
+package markdown
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/BigJk/snd"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	extast "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+// Modes that decide what becomes an entry.
+const (
+	// ModeHeadings creates an entry for each heading of the heading level. Stat block fields,
+	// tables and sub-sections below the heading become the data of the entry.
+	ModeHeadings = "Headings"
+	// ModeTableRows creates an entry for each row of every table in the document.
+	ModeTableRows = "Table Rows"
+)
+
+// Options changes how a document is imported.
+type Options struct {
+	// HeadingLevel is the level of the headings that start a new entry.
+	HeadingLevel int
+	// Mode is either ModeHeadings or ModeTableRows.
+	Mode string
+	// Rules map the labels of fields, columns and sections to keys of the entry data.
+	Rules []Rule
+}
+
+var parser = goldmark.New(goldmark.WithExtensions(extension.Table)).Parser()
+
+// homebreweryRegex matches the lines of Homebrewery and GM Binder specific syntax that only
+// affects the layout, like the start and end of {{monster,frame}} blocks, page breaks,
+// spacers and wrapping divs.
+var homebreweryRegex = regexp.MustCompile(`(?m)^[ \t>]*(\{\{[^}\n]*|\}\}|\\page|\\pagebreak|\\column|\\columnbreak|:+|</?div[^>\n]*>)[ \t]*$`)
+
+// Import imports the Markdown file as a data source.
+func Import(filePath string, name string, author string, slug string, description string, options Options) ([]snd.DataSource, [][]snd.Entry, error) {
+	if len(author) == 0 || len(slug) == 0 {
+		return nil, nil, errors.New("author and slug are required")
+	}
+
+	source, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	entries, err := Convert(source, options)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(entries) == 0 {
+		return nil, nil, errors.New("no entries found in the document")
+	}
+
+	return []snd.DataSource{{
+		Name:        name,
+		Slug:        slug,
+		Author:      author,
+		Description: description,
+		Version:     "",
+	}}, [][]snd.Entry{entries}, nil
+}
+
+// Convert converts the Markdown document to entries.
+func Convert(source []byte, options Options) ([]snd.Entry, error) {
+	if options.HeadingLevel <= 0 {
+		options.HeadingLevel = 2
+	}
+
+	source = homebreweryRegex.ReplaceAll(source, nil)
+	doc := parser.Parse(text.NewReader(source))
+
+	var blocks []ast.Node
+	collectBlocks(doc, &blocks)
+
+	c := &converter{source: source, options: options, ids: map[string]int{}}
+
+	switch options.Mode {
+	case ModeHeadings, "":
+		c.convertHeadings(blocks)
+	case ModeTableRows:
+		c.convertTableRows(blocks)
+	default:
+		return nil, fmt.Errorf("unknown mode '%s'", options.Mode)
+	}
+
+	return c.entries, nil
+}
+
+// collectBlocks flattens the document to headings, paragraphs and tables. Block quotes
+// and lists are only containers, e.g. legacy Homebrewery stat blocks are block quotes.
+func collectBlocks(node ast.Node, blocks *[]ast.Node) {
+	for child := node.FirstChild(); child != nil; child = child.NextSibling() {
+		switch child.(type) {
+		case *ast.Heading, *ast.Paragraph, *ast.TextBlock, *extast.Table:
+			*blocks = append(*blocks, child)
+		case *ast.Blockquote, *ast.List, *ast.ListItem:
+			collectBlocks(child, blocks)
+		}
+	}
+}
+
+type converter struct {
+	source  []byte
+	options Options
+	ids     map[string]int
+	entries []snd.Entry
+
+	// State of the entry that is currently converted
+	current     *snd.Entry
+	description []string
+	section     string
+	last        map[string]any
+	lastField   string
+}
+
+// uniqueId returns the name as id and appends a number if it was already used.
+func (c *converter) uniqueId(name string) string {
+	c.ids[name]++
+	if c.ids[name] == 1 {
+		return name
+	}
+	return fmt.Sprintf("%s-%d", name, c.ids[name])
+}
+
+func (c *converter) key(label string) (string, bool) {
+	return fieldKey(label, c.options.Rules)
+}
+
+func (c *converter) convertHeadings(blocks []ast.Node) {
+	for _, block := range blocks {
+		switch n := block.(type) {
+		case *ast.Heading:
+			switch {
+			case n.Level == c.options.HeadingLevel:
+				c.finish()
+				name := inlineText(n, c.source)
+				c.current = &snd.Entry{ID: c.uniqueId(name), Name: name, Data: map[string]any{"name": name}}
+			case n.Level < c.options.HeadingLevel:
+				c.finish()
+			case c.current != nil:
+				c.section, _ = c.key(inlineText(n, c.source))
+				if c.section == "" {
+					c.section = DropField
+				}
+				c.last = nil
+				c.lastField = ""
+			}
+		case *ast.Paragraph, *ast.TextBlock:
+			if c.current != nil && c.section != DropField {
+				c.addLines(n)
+			}
+		case *extast.Table:
+			if c.current != nil && c.section != DropField {
+				c.addTable(n)
+			}
+		}
+	}
+	c.finish()
+}
+
+// finish adds the current entry to the converted entries.
+func (c *converter) finish() {
+	if c.current == nil {
+		return
+	}
+
+	if len(c.description) > 0 {
+		if key, ok := c.key("Description"); ok {
+			if _, exists := c.current.Data[key]; !exists {
+				c.current.Data[key] = strings.Join(c.description, "\n\n")
+			}
+		}
+	}
+
+	c.entries = append(c.entries, *c.current)
+	c.current = nil
+	c.description = nil
+	c.section = ""
+	c.last = nil
+	c.lastField = ""
+}
+
+// setField sets a field of the current entry.
+func (c *converter) setField(label string, value string) {
+	key, ok := c.key(label)
+	if !ok {
+		c.lastField = ""
+		return
+	}
+
+	c.current.Data[key] = parseValue(value)
+	c.lastField = key
+}
+
+// addItem appends a named item like an action or trait to the list under the key.
+func (c *converter) addItem(key string, name string, value string) {
+	item := map[string]any{"name": name, "text": value}
+
+	list, _ := c.current.Data[key].([]any)
+	c.current.Data[key] = append(list, item)
+	c.last = item
+	c.lastField = ""
+}
+
+func (c *converter) addLines(block ast.Node) {
+	var plain []string
+
+	flushPlain := func() {
+		if len(plain) == 0 {
+			return
+		}
+
+		paragraph := strings.Join(plain, " ")
+		plain = nil
+
+		if c.section == "" {
+			c.description = append(c.description, paragraph)
+		} else {
+			c.addItem(c.section, "", paragraph)
+		}
+	}
+
+	for _, line := range splitLines(block) {
+		kind, label, value := c.classify(line)
+		switch kind {
+		case lineField:
+			flushPlain()
+			if c.section == "" {
+				c.setField(label, value)
+			} else {
+				c.addItem(c.section, label, value)
+			}
+		case lineItem:
+			flushPlain()
+			key := c.section
+			if key == "" {
+				var ok bool
+				if key, ok = c.key("Traits"); !ok {
+					continue
+				}
+			}
+			c.addItem(key, label, value)
+		default:
+			if len(value) == 0 {
+				continue
+			}
+
+			// Lines that continue an item or field in the same paragraph belong to it.
+			if len(plain) == 0 && c.last != nil {
+				c.last["text"] = strings.TrimSpace(fmt.Sprint(c.last["text"]) + " " + value)
+				continue
+			}
+			if len(plain) == 0 && c.lastField != "" {
+				if prev, ok := c.current.Data[c.lastField].(string); ok {
+					c.current.Data[c.lastField] = prev + " " + value
+					continue
+				}
+			}
+
+			plain = append(plain, value)
+		}
+	}
+	flushPlain()
+
+	// A new paragraph doesn't continue the previous item.
+	c.last = nil
+	c.lastField = ""
+}
+
+func (c *converter) addTable(table *extast.Table) {
+	header, rows := tableCells(table, c.source)
+	if len(rows) == 0 {
+		return
+	}
+
+	// A single row like the ability scores of a stat block contains fields of the entry.
+	if len(rows) == 1 {
+		for i := range header {
+			if i < len(rows[0]) {
+				c.setField(header[i], rows[0][i])
+			}
+		}
+		c.lastField = ""
+		return
+	}
+
+	key := c.section
+	if key == "" {
+		var ok bool
+		if key, ok = c.key("Table"); !ok {
+			return
+		}
+	}
+
+	list, _ := c.current.Data[key].([]any)
+	for i := range rows {
+		list = append(list, c.rowData(header, rows[i]))
+	}
+	c.current.Data[key] = list
+	c.last = nil
+}
+
+func (c *converter) rowData(header []string, row []string) map[string]any {
+	data := map[string]any{}
+	for i := range header {
+		if i >= len(row) {
+			break
+		}
+
+		if key, ok := c.key(header[i]); ok {
+			data[key] = parseValue(row[i])
+		}
+	}
+	return data
+}
+
+func (c *converter) convertTableRows(blocks []ast.Node) {
+	for _, block := range blocks {
+		table, ok := block.(*extast.Table)
+		if !ok {
+			continue
+		}
+
+		header, rows := tableCells(table, c.source)
+		for i := range rows {
+			data := c.rowData(header, rows[i])
+
+			name, ok := data["name"].(string)
+			if !ok && len(rows[i]) > 0 {
+				name = rows[i][0]
+			}
+			if len(name) == 0 {
+				name = fmt.Sprint(len(c.entries) + 1)
+			}
+
+			if _, ok := data["name"]; !ok {
+				data["name"] = name
+			}
+
+			c.entries = append(c.entries, snd.Entry{ID: c.uniqueId(name), Name: name, Data: data})
+		}
+	}
+}
+
+const (
+	linePlain = iota
+	lineField
+	lineItem
+)
+
+// classify checks what a line of a paragraph is:
+//
+//	**Armor Class** 15            a field with the label "Armor Class"
+//	**Armor Class** :: 15         a field in Homebrewery V3 syntax
+//	***Multiattack.*** The ...    a named item like a trait or action
+//	Some text                     plain text
+func (c *converter) classify(line []ast.Node) (int, string, string) {
+	if len(line) == 0 {
+		return linePlain, "", ""
+	}
+
+	emphasis, ok := line[0].(*ast.Emphasis)
+	rest := strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(nodesText(line[1:], c.source)), ":"))
+	if !ok || len(rest) == 0 {
+		return linePlain, "", strings.TrimSpace(nodesText(line, c.source))
+	}
+
+	label := strings.TrimSpace(inlineText(emphasis, c.source))
+	_, nested := emphasis.FirstChild().(*ast.Emphasis)
+
+	switch {
+	case nested || strings.HasSuffix(label, "."):
+		return lineItem, strings.TrimSpace(strings.TrimSuffix(label, ".")), rest
+	case emphasis.Level == 2:
+		return lineField, strings.TrimSpace(strings.TrimSuffix(label, ":")), rest
+	}
+
+	return linePlain, "", strings.TrimSpace(nodesText(line, c.source))
+}
+
+// splitLines splits the inline content of a block at the line breaks.
+func splitLines(block ast.Node) [][]ast.Node {
+	var lines [][]ast.Node
+	var line []ast.Node
+
+	for child := block.FirstChild(); child != nil; child = child.NextSibling() {
+		line = append(line, child)
+		if t, ok := child.(*ast.Text); ok && (t.SoftLineBreak() || t.HardLineBreak()) {
+			lines = append(lines, line)
+			line = nil
+		}
+	}
+
+	if len(line) > 0 {
+		lines = append(lines, line)
+	}
+
+	return lines
+}
+
+func tableCells(table *extast.Table, source []byte) ([]string, [][]string) {
+	var header []string
+	var rows [][]string
+
+	for row := table.FirstChild(); row != nil; row = row.NextSibling() {
+		var cells []string
+		for cell := row.FirstChild(); cell != nil; cell = cell.NextSibling() {
+			cells = append(cells, strings.TrimSpace(inlineText(cell, source)))
+		}
+
+		if _, ok := row.(*extast.TableHeader); ok {
+			header = cells
+		} else {
+			rows = append(rows, cells)
+		}
+	}
+
+	return header, rows
+}
+
+func nodesText(nodes []ast.Node, source []byte) string {
+	var sb strings.Builder
+	for _, n := range nodes {
+		writeText(&sb, n, source)
+	}
+	return sb.String()
+}
+
+// inlineText returns the plain text of the inline content of the node.
+func inlineText(node ast.Node, source []byte) string {
+	var sb strings.Builder
+	for child := node.FirstChild(); child != nil; child = child.NextSibling() {
+		writeText(&sb, child, source)
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+func writeText(sb *strings.Builder, node ast.Node, source []byte) {
+	switch n := node.(type) {
+	case *ast.Text:
+		sb.Write(n.Value(source))
+		if n.SoftLineBreak() || n.HardLineBreak() {
+			sb.WriteString(" ")
+		}
+	case *ast.String:
+		sb.Write(n.Value)
+	case *ast.AutoLink:
+		sb.Write(n.Label(source))
+	case *ast.RawHTML:
+	default:
+		for child := node.FirstChild(); child != nil; child = child.NextSibling() {
+			writeText(sb, child, source)
+		}
+	}
+}
+
+// parseValue converts values that are plain numbers to numbers.
+func parseValue(value string) any {
+	value = strings.TrimSpace(value)
+	if f, err := strconv.ParseFloat(value, 64); err == nil {
+		return f
+	}
+	return value
+}
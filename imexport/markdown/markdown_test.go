@@ -0,0 +1,117 @@
+package markdown
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/BigJk/snd"
+)
+
+func TestConvert(t *testing.T) {
+	rules, err := ParseRules(`
+		Armor Class => ac
+		/^Hit Points?$/ => hp
+		Challenge => -
+		Lore => -
+		Description => summary
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		file     string
+		expected string
+		options  Options
+	}{
+		{file: "homebrewery.md", expected: "homebrewery.json", options: Options{Mode: ModeHeadings}},
+		{file: "homebrewery.md", expected: "homebrewery-rules.json", options: Options{Mode: ModeHeadings, Rules: rules}},
+		{file: "tables.md", expected: "tables.json", options: Options{Mode: ModeTableRows}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.expected, func(t *testing.T) {
+			source, err := os.ReadFile(filepath.Join("testdata", c.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			expectedData, err := os.ReadFile(filepath.Join("testdata", c.expected))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var expected []snd.Entry
+			if err := json.Unmarshal(expectedData, &expected); err != nil {
+				t.Fatal(err)
+			}
+
+			entries, err := Convert(source, c.options)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Numbers are compared as they are decoded from the fixture
+			data, err := json.Marshal(entries)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []snd.Entry
+			if err := json.Unmarshal(data, &got); err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(got, expected) {
+				t.Fatalf("expected %s, got %s", expectedData, data)
+			}
+		})
+	}
+}
+
+func TestConvertHeadingLevel(t *testing.T) {
+	source := []byte("# Goblin\n**Armor Class** 15\n## Actions\n***Scimitar.*** Hit\n# Orc\n")
+
+	entries, err := Convert(source, Options{HeadingLevel: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != 2 || entries[0].Data["armor_class"] != 15.0 || entries[1].Name != "Orc" {
+		t.Fatalf("unexpected entries %+v", entries)
+	}
+	if actions, ok := entries[0].Data["actions"].([]any); !ok || len(actions) != 1 {
+		t.Fatalf("expected the sub-heading to be a section, got %+v", entries[0].Data)
+	}
+}
+
+func TestConvertUnknownMode(t *testing.T) {
+	if _, err := Convert([]byte("## Goblin"), Options{Mode: "Lists"}); err == nil {
+		t.Fatal("expected an error for an unknown mode")
+	}
+}
+
+func TestImport(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "monsters.md")
+	if err := os.WriteFile(file, []byte("# Monsters\n\nNo entries here."), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := Import(file, "Monsters", "", "monsters", "", Options{}); err == nil {
+		t.Fatal("expected an error without author")
+	}
+	if _, _, err := Import(file, "Monsters", "author", "monsters", "", Options{}); err == nil {
+		t.Fatal("expected an error without entries")
+	}
+
+	sources, entries, err := Import(filepath.Join("testdata", "tables.md"), "Equipment", "author", "equipment", "Gear", Options{Mode: ModeTableRows})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sources) != 1 || sources[0].Slug != "equipment" || sources[0].Description != "Gear" || len(entries[0]) != 5 {
+		t.Fatalf("unexpected import %+v %+v", sources, entries)
+	}
+}
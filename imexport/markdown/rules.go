@@ -0,0 +1,80 @@
+package markdown
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// DropField is the key of a rule that drops the field instead of renaming it.
+const DropField = "-"
+
+// Rule maps the label of a stat block field, table column or section to a key of the entry data.
+type Rule struct {
+	Label string
+	Regex *regexp.Regexp
+	Key   string
+}
+
+// Matches checks if the rule applies to the label.
+func (r Rule) Matches(label string) bool {
+	if r.Regex != nil {
+		return r.Regex.MatchString(label)
+	}
+	return strings.EqualFold(strings.TrimSpace(r.Label), strings.TrimSpace(label))
+}
+
+// ParseRules parses rules with one rule per line in the form "Label => key". The label can be
+// a regular expression if it is enclosed in slashes and the key "-" drops the field:
+//
+//	Armor Class => ac
+//	/^Hit Points?$/ => hp
+//	Challenge => -
+//
+// Empty lines and lines starting with "#" are ignored.
+func ParseRules(text string) ([]Rule, error) {
+	var rules []Rule
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		label, key, ok := strings.Cut(line, "=>")
+		if !ok {
+			return nil, fmt.Errorf("rule in line %d is missing '=>'", i+1)
+		}
+
+		rule := Rule{Label: strings.TrimSpace(label), Key: strings.TrimSpace(key)}
+		if len(rule.Label) == 0 || len(rule.Key) == 0 {
+			return nil, fmt.Errorf("rule in line %d needs a label and a key", i+1)
+		}
+
+		if len(rule.Label) > 2 && strings.HasPrefix(rule.Label, "/") && strings.HasSuffix(rule.Label, "/") {
+			regex, err := regexp.Compile(rule.Label[1 : len(rule.Label)-1])
+			if err != nil {
+				return nil, fmt.Errorf("rule in line %d: %w", i+1, err)
+			}
+			rule.Regex = regex
+		}
+
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+var keyRegex = regexp.MustCompile(`[^a-z0-9]+`)
+
+// fieldKey returns the key for a label. If no rule matches the label is converted to
+// lower snake case, e.g. "Armor Class" becomes "armor_class". The second return value
+// is false if the field should be dropped.
+func fieldKey(label string, rules []Rule) (string, bool) {
+	for _, rule := range rules {
+		if rule.Matches(label) {
+			return rule.Key, rule.Key != DropField
+		}
+	}
+
+	key := strings.Trim(keyRegex.ReplaceAllString(strings.ToLower(label), "_"), "_")
+	return key, len(key) > 0
+}
@@ -0,0 +1,54 @@
+package markdown
+
+import (
+	"testing"
+)
+
+func TestParseRules(t *testing.T) {
+	rules, err := ParseRules("# Comment\n\nArmor Class => ac\n/^Hit Points?$/ => hp\nChallenge => -\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rules) != 3 || rules[0].Regex != nil || rules[1].Regex == nil || rules[2].Key != DropField {
+		t.Fatalf("unexpected rules %+v", rules)
+	}
+
+	cases := []struct {
+		label string
+		key   string
+		ok    bool
+	}{
+		{label: "armor class", key: "ac", ok: true},
+		{label: "Hit Point", key: "hp", ok: true},
+		{label: "Hit Points", key: "hp", ok: true},
+		{label: "Challenge", key: DropField, ok: false},
+		{label: "Damage Immunities", key: "damage_immunities", ok: true},
+		{label: "STR (+2)", key: "str_2", ok: true},
+		{label: "***", key: "", ok: false},
+	}
+
+	for _, c := range cases {
+		key, ok := fieldKey(c.label, rules)
+		if key != c.key || ok != c.ok {
+			t.Errorf("%s: expected '%s' (%v), got '%s' (%v)", c.label, c.key, c.ok, key, ok)
+		}
+	}
+}
+
+func TestParseRulesErrors(t *testing.T) {
+	cases := []struct {
+		name  string
+		rules string
+	}{
+		{name: "missing arrow", rules: "Armor Class => ac\nHit Points -> hp"},
+		{name: "missing key", rules: "Armor Class =>"},
+		{name: "missing label", rules: " => ac"},
+		{name: "invalid regex", rules: "/^Hit (Points$/ => hp"},
+	}
+
+	for _, c := range cases {
+		if _, err := ParseRules(c.rules); err == nil {
+			t.Errorf("%s: expected an error", c.name)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package imexport
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/BigJk/snd"
+)
+
+// Strategies that decide how imported entries are merged with the existing ones.
+const (
+	// MergeReplace deletes all existing entries and saves the imported ones.
+	MergeReplace = "replace"
+	// MergeKeepLocal updates and removes entries like the import, but keeps entries that
+	// were modified or created locally since the last import.
+	MergeKeepLocal = "keep-local"
+	// MergeUpdate saves all imported entries, overwriting local modifications, but never removes entries.
+	MergeUpdate = "update"
+	// MergeAddOnly only saves the entries that don't exist yet.
+	MergeAddOnly = "add-only"
+)
+
+// MergeStrategies contains all valid merge strategies.
+var MergeStrategies = []string{MergeReplace, MergeKeepLocal, MergeUpdate, MergeAddOnly}
+
+// ImportBase contains the hashes of the entries as they were imported the last time by their id.
+// It is used to find out which entries were modified locally after an import.
+type ImportBase map[string]string
+
+// EntryChange represents a single entry in an import diff.
+type EntryChange struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// LocallyModified is true if the existing entry was modified or created since the
+	// last import. If no import happened before every differing entry counts as modified.
+	LocallyModified bool `json:"locallyModified"`
+}
+
+// EntryDiff represents the difference between the existing and imported entries.
+type EntryDiff struct {
+	New       []EntryChange `json:"new"`
+	Changed   []EntryChange `json:"changed"`
+	Removed   []EntryChange `json:"removed"`
+	Unchanged int           `json:"unchanged"`
+}
+
+// HashEntry returns a hash of the name and data of the entry.
+func HashEntry(entry snd.Entry) string {
+	data, err := json.Marshal([]any{entry.Name, entry.Data})
+	if err != nil {
+		data = []byte(fmt.Sprint(entry))
+	}
+
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:16])
+}
+
+// NewImportBase creates the base of the imported entries.
+func NewImportBase(entries []snd.Entry) ImportBase {
+	base := ImportBase{}
+	for i := range entries {
+		base[entries[i].ID] = HashEntry(entries[i])
+	}
+	return base
+}
+
+// locallyModified checks if the entry changed since the last import.
+func (b ImportBase) locallyModified(entry snd.Entry) bool {
+	hash, ok := b[entry.ID]
+	return !ok || hash != HashEntry(entry)
+}
+
+// DiffEntries compares the existing entries with the imported ones.
+func DiffEntries(existing []snd.Entry, incoming []snd.Entry, base ImportBase) EntryDiff {
+	diff := EntryDiff{
+		New:     []EntryChange{},
+		Changed: []EntryChange{},
+		Removed: []EntryChange{},
+	}
+
+	existingById := map[string]snd.Entry{}
+	for i := range existing {
+		existingById[existing[i].ID] = existing[i]
+	}
+
+	incomingIds := map[string]bool{}
+	for i := range incoming {
+		incomingIds[incoming[i].ID] = true
+
+		local, ok := existingById[incoming[i].ID]
+		switch {
+		case !ok:
+			diff.New = append(diff.New, EntryChange{ID: incoming[i].ID, Name: incoming[i].Name})
+		case HashEntry(local) == HashEntry(incoming[i]):
+			diff.Unchanged++
+		default:
+			diff.Changed = append(diff.Changed, EntryChange{ID: local.ID, Name: local.Name, LocallyModified: base.locallyModified(local)})
+		}
+	}
+
+	for i := range existing {
+		if !incomingIds[existing[i].ID] {
+			diff.Removed = append(diff.Removed, EntryChange{ID: existing[i].ID, Name: existing[i].Name, LocallyModified: base.locallyModified(existing[i])})
+		}
+	}
+
+	for _, changes := range [][]EntryChange{diff.New, diff.Changed, diff.Removed} {
+		sort.Slice(changes, func(i, j int) bool {
+			return changes[i].ID < changes[j].ID
+		})
+	}
+
+	return diff
+}
+
+// MergeEntries decides which entries have to be saved and which have to be deleted to
+// apply the import with the given strategy. If replace is true all existing entries
+// have to be deleted before saving.
+func MergeEntries(existing []snd.Entry, incoming []snd.Entry, base ImportBase, strategy string) (save []snd.Entry, remove []string, replace bool, err error) {
+	if strategy == MergeReplace {
+		return incoming, nil, true, nil
+	}
+
+	diff := DiffEntries(existing, incoming, base)
+
+	skip := map[string]bool{}
+	switch strategy {
+	case MergeKeepLocal:
+		for _, change := range diff.Changed {
+			if change.LocallyModified {
+				skip[change.ID] = true
+			}
+		}
+		for _, change := range diff.Removed {
+			if !change.LocallyModified {
+				remove = append(remove, change.ID)
+			}
+		}
+	case MergeUpdate:
+	case MergeAddOnly:
+		for _, change := range diff.Changed {
+			skip[change.ID] = true
+		}
+	default:
+		return nil, nil, false, fmt.Errorf("unknown merge strategy '%s'", strategy)
+	}
+
+	for i := range incoming {
+		if !skip[incoming[i].ID] {
+			save = append(save, incoming[i])
+		}
+	}
+
+	return save, remove, false, nil
+}
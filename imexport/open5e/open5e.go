@@ -0,0 +1,353 @@
+// Package open5e imports the openly licensed SRD datasets of Open5e (https://open5e.com) and
+// the 5e-SRD-API (https://www.dnd5eapi.co) from local JSON files or their APIs.
+//
+// The following layouts are supported:
+//
+//   - 5e-SRD-API dumps like "5e-SRD-Monsters.json" that contain a plain array of objects
+//   - Open5e API responses like "https://api.open5e.com/v1/monsters/" that contain paginated results
+//   - Open5e fixtures like "Monster.json" with entries of the form {"model": "api.monster", "pk": ..., "fields": {...}}
+package open5e
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/BigJk/snd"
+)
+
+// Category is a kind of content that is imported as own data source.
+type Category struct {
+	Name string
+	Slug string
+	// keywords are the parts of file names, urls and fixture models that identify the category.
+	keywords []string
+}
+
+// Categories contains all categories that can be imported.
+var Categories = []Category{
+	{Name: "Monsters", Slug: "monsters", keywords: []string{"monster", "creature"}},
+	{Name: "Spells", Slug: "spells", keywords: []string{"spell"}},
+	{Name: "Magic Items", Slug: "magic-items", keywords: []string{"magic-item", "magic_item", "magicitem"}},
+	{Name: "Conditions", Slug: "conditions", keywords: []string{"condition"}},
+}
+
+// maxPages limits how many pages of an api are fetched.
+const maxPages = 1000
+
+var client = &http.Client{Timeout: time.Minute}
+
+// ErrUnknownCategory is returned if a file or url doesn't belong to any of the categories.
+var ErrUnknownCategory = errors.New("category could not be detected")
+
+// DetectCategory finds the category that the name (e.g. a file name or url) belongs to.
+func DetectCategory(name string) (Category, bool) {
+	name = strings.ToLower(name)
+	for _, c := range Categories {
+		for _, k := range c.keywords {
+			if strings.Contains(name, k) {
+				return c, true
+			}
+		}
+	}
+	return Category{}, false
+}
+
+type fixture struct {
+	Model  string         `json:"model"`
+	PK     any            `json:"pk"`
+	Fields map[string]any `json:"fields"`
+}
+
+type page struct {
+	Next    *string           `json:"next"`
+	Results []json.RawMessage `json:"results"`
+}
+
+// ParseEntries parses the objects of a dataset file or api response. For fixtures the model
+// of the objects is returned, so that the category can be detected if the file name doesn't
+// contain it. If the data is a api page the url of the next page is returned.
+func ParseEntries(data []byte) (entries []snd.Entry, model string, next string, err error) {
+	var objects []json.RawMessage
+
+	trimmed := strings.TrimSpace(string(data))
+	switch {
+	case strings.HasPrefix(trimmed, "["):
+		if err := json.Unmarshal(data, &objects); err != nil {
+			return nil, "", "", err
+		}
+	case strings.HasPrefix(trimmed, "{"):
+		var p page
+		if err := json.Unmarshal(data, &p); err != nil {
+			return nil, "", "", err
+		}
+
+		if p.Results == nil {
+			return nil, "", "", errors.New("json object doesn't contain results")
+		}
+
+		objects = p.Results
+		if p.Next != nil {
+			next = *p.Next
+		}
+	default:
+		return nil, "", "", errors.New("not a json array or object")
+	}
+
+	for i := range objects {
+		obj := map[string]any{}
+		if err := json.Unmarshal(objects[i], &obj); err != nil {
+			return nil, "", "", err
+		}
+
+		// Open5e fixture
+		if _, ok := obj["fields"].(map[string]any); ok && obj["model"] != nil {
+			var f fixture
+			if err := json.Unmarshal(objects[i], &f); err != nil {
+				return nil, "", "", err
+			}
+
+			model = f.Model
+			obj = f.Fields
+			if _, ok := obj["slug"]; !ok && f.PK != nil {
+				obj["slug"] = fmt.Sprint(f.PK)
+			}
+		}
+
+		name, _ := obj["name"].(string)
+		if len(name) == 0 {
+			continue
+		}
+
+		entries = append(entries, snd.Entry{
+			ID:   entryId(obj, name),
+			Name: name,
+			Data: obj,
+		})
+	}
+
+	return entries, model, next, nil
+}
+
+// entryId returns the identifier that the dataset uses for the object.
+func entryId(obj map[string]any, name string) string {
+	for _, key := range []string{"index", "slug", "key"} {
+		if id, ok := obj[key].(string); ok && len(id) > 0 {
+			return id
+		}
+	}
+	return name
+}
+
+// collection gathers the entries of all categories.
+type collection struct {
+	entries map[string][]snd.Entry
+	ids     map[string]map[string]bool
+}
+
+func newCollection() *collection {
+	return &collection{entries: map[string][]snd.Entry{}, ids: map[string]map[string]bool{}}
+}
+
+// add adds the entries to the category. Datasets that are split into multiple files can
+// contain the same id more than once, so duplicated ids get a suffix.
+func (c *collection) add(category Category, entries []snd.Entry) {
+	if c.ids[category.Slug] == nil {
+		c.ids[category.Slug] = map[string]bool{}
+	}
+
+	ids := c.ids[category.Slug]
+	for _, e := range entries {
+		id := e.ID
+		for i := 2; ids[id]; i++ {
+			id = fmt.Sprintf("%s-%d", e.ID, i)
+		}
+		ids[id] = true
+		e.ID = id
+
+		c.entries[category.Slug] = append(c.entries[category.Slug], e)
+	}
+}
+
+func (c *collection) sources(name string, author string, slug string, description string) ([]snd.DataSource, [][]snd.Entry, error) {
+	var sources []snd.DataSource
+	var allEntries [][]snd.Entry
+
+	for _, category := range Categories {
+		entries := c.entries[category.Slug]
+		if len(entries) == 0 {
+			continue
+		}
+
+		sources = append(sources, snd.DataSource{
+			Name:        name + " - " + category.Name,
+			Slug:        slug + "-" + category.Slug,
+			Author:      author,
+			Description: description,
+			Version:     "",
+		})
+		allEntries = append(allEntries, entries)
+	}
+
+	if len(sources) == 0 {
+		return nil, nil, errors.New("no monsters, spells, magic items or conditions found")
+	}
+
+	return sources, allEntries, nil
+}
+
+func (c *collection) addFile(file string) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	category, ok := DetectCategory(filepath.Base(file))
+
+	entries, model, _, err := ParseEntries(data)
+	if err != nil {
+		if !ok {
+			return fmt.Errorf("%s: %w", filepath.Base(file), ErrUnknownCategory)
+		}
+		return fmt.Errorf("%s: %w", filepath.Base(file), err)
+	}
+
+	if !ok {
+		if category, ok = DetectCategory(model); !ok {
+			return fmt.Errorf("%s: %w", filepath.Base(file), ErrUnknownCategory)
+		}
+	}
+
+	c.add(category, entries)
+	return nil
+}
+
+// ImportFile imports a single dataset file.
+func ImportFile(file string, name string, author string, slug string, description string) ([]snd.DataSource, [][]snd.Entry, error) {
+	c := newCollection()
+	if err := c.addFile(file); err != nil {
+		return nil, nil, err
+	}
+	return c.sources(name, author, slug, description)
+}
+
+// ImportFolder imports all dataset files in the folder and its sub-folders. Files that don't
+// belong to one of the categories are skipped.
+func ImportFolder(folder string, name string, author string, slug string, description string) ([]snd.DataSource, [][]snd.Entry, error) {
+	var files []string
+	err := filepath.Walk(folder, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() || filepath.Ext(path) != ".json" {
+			return nil
+		}
+
+		files = append(files, path)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	sort.Strings(files)
+
+	c := newCollection()
+	for _, file := range files {
+		// Files of other categories (e.g. equipment or classes) are skipped
+		if err := c.addFile(file); err != nil && !errors.Is(err, ErrUnknownCategory) {
+			return nil, nil, err
+		}
+	}
+
+	return c.sources(name, author, slug, description)
+}
+
+// ImportURL imports a dataset from a url. Paginated api responses are followed until the last page.
+func ImportURL(target string, name string, author string, slug string, description string) ([]snd.DataSource, [][]snd.Entry, error) {
+	parsed, err := url.Parse(target)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	category, ok := DetectCategory(parsed.Path)
+
+	var entries []snd.Entry
+	var model string
+	next := target
+	for i := 0; len(next) > 0; i++ {
+		if i >= maxPages {
+			return nil, nil, fmt.Errorf("more than %d pages", maxPages)
+		}
+
+		data, err := fetch(next)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		var pageEntries []snd.Entry
+		pageEntries, model, next, err = ParseEntries(data)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		entries = append(entries, pageEntries...)
+	}
+
+	if !ok {
+		if category, ok = DetectCategory(model); !ok {
+			return nil, nil, ErrUnknownCategory
+		}
+	}
+
+	c := newCollection()
+	c.add(category, entries)
+	return c.sources(name, author, slug, description)
+}
+
+// Import imports from a file, folder or url depending on the location.
+func Import(location string, name string, author string, slug string, description string) ([]snd.DataSource, [][]snd.Entry, error) {
+	location = strings.TrimSpace(location)
+	if strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://") {
+		return ImportURL(location, name, author, slug, description)
+	}
+
+	info, err := os.Stat(location)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if info.IsDir() {
+		return ImportFolder(location, name, author, slug, description)
+	}
+
+	return ImportFile(location, name, author, slug, description)
+}
+
+func fetch(target string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned status %d", target, resp.StatusCode)
+	}
+
+	return io.ReadAll(resp.Body)
+}
@@ -0,0 +1,213 @@
+package open5e
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/BigJk/snd"
+)
+
+func entryIds(entries []snd.Entry) []string {
+	var ids []string
+	for i := range entries {
+		ids = append(ids, entries[i].ID)
+	}
+	return ids
+}
+
+func TestDetectCategory(t *testing.T) {
+	cases := []struct {
+		name     string
+		expected string
+	}{
+		{"5e-SRD-Monsters.json", "monsters"},
+		{"Creature.json", "monsters"},
+		{"https://api.open5e.com/v1/spells/", "spells"},
+		{"/v1/magicitems/", "magic-items"},
+		{"5e-SRD-Magic-Items.json", "magic-items"},
+		{"api.condition", "conditions"},
+		{"5e-SRD-Equipment.json", ""},
+	}
+
+	for _, c := range cases {
+		category, ok := DetectCategory(c.name)
+		if ok != (len(c.expected) > 0) || category.Slug != c.expected {
+			t.Errorf("%s: expected '%s', got '%s' (%v)", c.name, c.expected, category.Slug, ok)
+		}
+	}
+}
+
+func TestParseEntries(t *testing.T) {
+	cases := []struct {
+		file  string
+		data  string
+		ids   []string
+		model string
+		next  string
+		err   bool
+	}{
+		{file: "srd/5e-SRD-Monsters.json", ids: []string{"goblin", "orc", "goblin"}},
+		{file: "srd/5e-SRD-Spells.json", ids: []string{"fire-bolt", "magic-missile"}},
+		{file: "srd/open5e/data.json", ids: []string{"blinded", "prone-condition"}, model: "api.condition"},
+		{file: "api/magicitems-1.json", ids: []string{"bag-of-holding", "cloak-of-elvenkind"}, next: "{{server}}/v1/magicitems/?page=2"},
+		{file: "api/magicitems-2.json", ids: []string{"potion-of-healing"}},
+		{data: `[{"key": "by-key", "name": "Key"}, {"name": "Only Name"}]`, ids: []string{"by-key", "Only Name"}},
+		{data: `{"count": 0}`, err: true},
+		{data: `"monsters"`, err: true},
+		{data: `[{"name": 1}`, err: true},
+	}
+
+	for _, c := range cases {
+		data := []byte(c.data)
+		if len(c.file) > 0 {
+			var err error
+			if data, err = os.ReadFile(filepath.Join("testdata", c.file)); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		entries, model, next, err := ParseEntries(data)
+		if c.err {
+			if err == nil {
+				t.Errorf("%s%s: expected an error", c.file, c.data)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s%s: %s", c.file, c.data, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(entryIds(entries), c.ids) || model != c.model || next != c.next {
+			t.Errorf("%s%s: expected %v '%s' '%s', got %v '%s' '%s'", c.file, c.data, c.ids, c.model, c.next, entryIds(entries), model, next)
+		}
+	}
+}
+
+func TestImport(t *testing.T) {
+	cases := []struct {
+		location string
+		sources  map[string][]string
+		err      error
+	}{
+		{
+			// Equipment is skipped and the conditions are detected by their fixture model
+			location: "testdata/srd",
+			sources: map[string][]string{
+				"prefix-monsters":   {"goblin", "orc", "goblin-2"},
+				"prefix-spells":     {"fire-bolt", "magic-missile"},
+				"prefix-conditions": {"blinded", "prone-condition"},
+			},
+		},
+		{
+			location: "testdata/srd/5e-SRD-Spells.json",
+			sources: map[string][]string{
+				"prefix-spells": {"fire-bolt", "magic-missile"},
+			},
+		},
+		{
+			// Downloaded api pages are imported like any other file
+			location: "testdata/api",
+			sources: map[string][]string{
+				"prefix-magic-items": {"bag-of-holding", "cloak-of-elvenkind", "potion-of-healing"},
+			},
+		},
+		{
+			location: "testdata/srd/5e-SRD-Equipment.json",
+			err:      ErrUnknownCategory,
+		},
+		{
+			location: t.TempDir(),
+			err:      errors.New("no monsters, spells, magic items or conditions found"),
+		},
+		{
+			location: "testdata/missing.json",
+			err:      os.ErrNotExist,
+		},
+	}
+
+	for _, c := range cases {
+		sources, entries, err := Import(c.location, "Prefix", "Author", "prefix", "Description")
+		if c.err != nil {
+			if err == nil || (!errors.Is(err, c.err) && err.Error() != c.err.Error()) {
+				t.Errorf("%s: expected error '%s', got '%v'", c.location, c.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %s", c.location, err)
+			continue
+		}
+
+		res := map[string][]string{}
+		for i := range sources {
+			if sources[i].Author != "Author" || !strings.HasPrefix(sources[i].Name, "Prefix - ") {
+				t.Errorf("%s: unexpected source %+v", c.location, sources[i])
+			}
+			res[sources[i].Slug] = entryIds(entries[i])
+		}
+
+		if !reflect.DeepEqual(res, c.sources) {
+			t.Errorf("%s: expected %v, got %v", c.location, c.sources, res)
+		}
+	}
+}
+
+func TestImportURL(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.RequestURI())
+
+		if r.Header.Get("Accept") != "application/json" {
+			t.Errorf("expected json accept header, got '%s'", r.Header.Get("Accept"))
+		}
+
+		var file string
+		switch r.URL.RequestURI() {
+		case "/v1/magicitems/":
+			file = "magicitems-1.json"
+		case "/v1/magicitems/?page=2":
+			file = "magicitems-2.json"
+		default:
+			http.NotFound(w, r)
+			return
+		}
+
+		data, err := os.ReadFile(filepath.Join("testdata", "api", file))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// The next and previous links point to the test server
+		_, _ = w.Write([]byte(strings.ReplaceAll(string(data), "{{server}}", "http://"+r.Host)))
+	}))
+	defer server.Close()
+
+	sources, entries, err := Import(server.URL+"/v1/magicitems/", "Prefix", "Author", "prefix", "Description")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(sources) != 1 || sources[0].Slug != "prefix-magic-items" {
+		t.Fatalf("unexpected sources %+v", sources)
+	}
+
+	expected := []string{"bag-of-holding", "cloak-of-elvenkind", "potion-of-healing"}
+	if !reflect.DeepEqual(entryIds(entries[0]), expected) {
+		t.Fatalf("expected %v, got %v", expected, entryIds(entries[0]))
+	}
+
+	if !reflect.DeepEqual(requests, []string{"/v1/magicitems/", "/v1/magicitems/?page=2"}) {
+		t.Fatalf("unexpected requests %v", requests)
+	}
+
+	if _, _, err := ImportURL(server.URL+"/v1/spells/", "Prefix", "Author", "prefix", "Description"); err == nil || !strings.Contains(err.Error(), "status 404") {
+		t.Fatalf("expected a status error, got %v", err)
+	}
+}
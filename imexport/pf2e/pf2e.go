@@ -0,0 +1,344 @@
+// Package pf2e imports Pathfinder 2e data from the packs of the PF2e FoundryVTT system
+// (https://github.com/foundryvtt/pf2e) and from Archives of Nethys style JSON exports.
+//
+// Foundry documents are converted like the documents of the vtt package, so they can be
+// exported back to FoundryVTT. Additionally, the most used values (level, traits, rarity,
+// ...) that are nested deep in the system data are copied to the top level of the entry.
+package pf2e
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/BigJk/snd"
+	"github.com/BigJk/snd/imexport"
+	"github.com/BigJk/snd/imexport/vtt"
+)
+
+// groups maps the Foundry document types and Archives of Nethys categories to the name
+// of the data source they are imported to.
+var groups = map[string]string{
+	"npc":        "Creatures",
+	"creature":   "Creatures",
+	"character":  "Characters",
+	"hazard":     "Hazards",
+	"vehicle":    "Vehicles",
+	"spell":      "Spells",
+	"feat":       "Feats",
+	"action":     "Actions",
+	"condition":  "Conditions",
+	"effect":     "Effects",
+	"ancestry":   "Ancestries",
+	"heritage":   "Heritages",
+	"background": "Backgrounds",
+	"class":      "Classes",
+	"deity":      "Deities",
+	"equipment":  "Items",
+	"weapon":     "Items",
+	"armor":      "Items",
+	"shield":     "Items",
+	"consumable": "Items",
+	"treasure":   "Items",
+	"backpack":   "Items",
+	"kit":        "Items",
+}
+
+// groupName returns the data source name for a document type or category.
+func groupName(kind string) string {
+	kind = strings.ToLower(strings.TrimSpace(kind))
+	if name, ok := groups[kind]; ok {
+		return name
+	}
+	if len(kind) == 0 {
+		return "Other"
+	}
+	return strings.ToUpper(kind[:1]) + kind[1:]
+}
+
+var slugRegex = regexp.MustCompile(`[^a-z0-9]+`)
+
+// summaryFields are copied from the Foundry system data to the top level of the entry. The
+// first path that exists is used.
+var summaryFields = []struct {
+	key   string
+	paths []string
+}{
+	{"level", []string{"system.level.value", "system.details.level.value"}},
+	{"traits", []string{"system.traits.value"}},
+	{"rarity", []string{"system.traits.rarity"}},
+	{"size", []string{"system.traits.size.value"}},
+	{"hp", []string{"system.attributes.hp.max"}},
+	{"ac", []string{"system.attributes.ac.value"}},
+	{"perception", []string{"system.perception.mod", "system.attributes.perception.value"}},
+	{"description", []string{"system.description.value", "system.details.publicNotes"}},
+}
+
+// ConvertDocument converts a Foundry document of the PF2e system or an Archives of Nethys
+// record to an entry and returns the name of the group it belongs to.
+func ConvertDocument(doc map[string]any) (snd.Entry, string, error) {
+	// Archives of Nethys
+	if _, isFoundry := doc["system"]; !isFoundry {
+		category, _ := doc["category"].(string)
+		if len(category) == 0 {
+			return snd.Entry{}, "", errors.New("document is neither a foundry document nor a record")
+		}
+
+		name, _ := doc["name"].(string)
+		if len(name) == 0 {
+			return snd.Entry{}, "", errors.New("record has no name")
+		}
+
+		id := fmt.Sprint(doc["id"])
+		if doc["id"] == nil {
+			id = slugRegex.ReplaceAllString(strings.ToLower(category+"-"+name), "-")
+		}
+
+		return snd.Entry{ID: id, Name: name, Data: doc}, groupName(category), nil
+	}
+
+	kind, _ := doc["type"].(string)
+	entry, err := vtt.ConvertDocument(doc)
+	if err != nil {
+		return snd.Entry{}, "", err
+	}
+
+	if len(entry.Name) == 0 {
+		return snd.Entry{}, "", errors.New("document has no name")
+	}
+
+	if len(entry.ID) == 0 {
+		entry.ID = slugRegex.ReplaceAllString(strings.ToLower(kind+"-"+entry.Name), "-")
+	}
+
+	for _, field := range summaryFields {
+		if _, exists := entry.Data[field.key]; exists {
+			continue
+		}
+
+		for _, path := range field.paths {
+			if value, ok := imexport.GetPath(doc, path); ok && value != nil {
+				if text, ok := value.(string); ok && field.key == "description" {
+					value = CleanEnrichers(text)
+				}
+				entry.Data[field.key] = value
+				break
+			}
+		}
+	}
+
+	return entry, groupName(kind), nil
+}
+
+// CleanEnrichers replaces the Foundry text enrichers like "@UUID[Compendium.pf2e.conditionitems.Item.Frightened]{Frightened 1}"
+// or "@Damage[2d6[fire]]" by their label or a readable version of their content.
+func CleanEnrichers(text string) string {
+	var sb strings.Builder
+	for i := 0; i < len(text); i++ {
+		if text[i] != '@' {
+			sb.WriteByte(text[i])
+			continue
+		}
+
+		// Name of the enricher
+		j := i + 1
+		for j < len(text) && (text[j] >= 'a' && text[j] <= 'z' || text[j] >= 'A' && text[j] <= 'Z') {
+			j++
+		}
+		if j == i+1 || j >= len(text) || text[j] != '[' {
+			sb.WriteByte(text[i])
+			continue
+		}
+
+		// Content with nested brackets
+		depth := 0
+		end := -1
+		for k := j; k < len(text) && end < 0; k++ {
+			switch text[k] {
+			case '[':
+				depth++
+			case ']':
+				if depth--; depth == 0 {
+					end = k
+				}
+			}
+		}
+		if end < 0 {
+			sb.WriteByte(text[i])
+			continue
+		}
+
+		enricher := text[i+1 : j]
+		content := text[j+1 : end]
+		i = end
+
+		if end+1 < len(text) && text[end+1] == '{' {
+			if close := strings.IndexByte(text[end+1:], '}'); close > 0 {
+				i = end + 1 + close
+
+				// An empty label falls back to the content
+				if label := text[end+2 : end+1+close]; len(strings.TrimSpace(label)) > 0 {
+					sb.WriteString(label)
+					continue
+				}
+			}
+		}
+
+		if enricher == "UUID" || enricher == "Compendium" {
+			content = content[strings.LastIndexByte(content, '.')+1:]
+		} else {
+			content, _, _ = strings.Cut(content, "|")
+			content = strings.Join(strings.Fields(strings.NewReplacer("[", " ", "]", " ").Replace(content)), " ")
+		}
+		sb.WriteString(content)
+	}
+	return sb.String()
+}
+
+// readDocuments reads the documents of a JSON file, a JSONL or NeDB (.db) file or an Archives
+// of Nethys export. Elasticsearch responses ({"hits": {"hits": [{"_source": ...}]}}) are unwrapped.
+func readDocuments(file string) ([]map[string]any, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	data, err := imexport.DecodeJSONValues(f)
+	if err != nil {
+		return nil, err
+	}
+
+	if hits, ok := imexport.GetPath(map[string]any{"root": data}, "root.hits.hits"); ok {
+		data = hits
+	}
+
+	var values []any
+	switch v := data.(type) {
+	case []any:
+		values = v
+	case map[string]any:
+		values = []any{v}
+	}
+
+	var docs []map[string]any
+	for i := range values {
+		doc, ok := values[i].(map[string]any)
+		if !ok {
+			continue
+		}
+
+		if source, ok := doc["_source"].(map[string]any); ok {
+			doc = source
+		}
+
+		docs = append(docs, doc)
+	}
+
+	return docs, nil
+}
+
+// Import imports a single file or all files of a folder and its sub-folders. Built
+// Foundry packs (LevelDB) are supported as well. Files that start with "_" like the
+// "_folders.json" of the system sources are skipped. One data source is created for
+// every group (creatures, spells, items, ...).
+func Import(location string, name string, author string, slug string, description string) ([]snd.DataSource, [][]snd.Entry, error) {
+	info, err := os.Stat(location)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var docs []map[string]any
+	if info.IsDir() {
+		err = filepath.Walk(location, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if info.IsDir() {
+				if vtt.IsLevelDBPack(path) {
+					packDocs, err := vtt.ReadLevelDBPack(path)
+					if err != nil {
+						return fmt.Errorf("%s: %w", filepath.Base(path), err)
+					}
+					docs = append(docs, packDocs...)
+					return filepath.SkipDir
+				}
+				return nil
+			}
+
+			switch filepath.Ext(path) {
+			case ".json", ".jsonl", ".db":
+			default:
+				return nil
+			}
+
+			if strings.HasPrefix(filepath.Base(path), "_") {
+				return nil
+			}
+
+			fileDocs, err := readDocuments(path)
+			if err != nil {
+				return fmt.Errorf("%s: %w", filepath.Base(path), err)
+			}
+			docs = append(docs, fileDocs...)
+			return nil
+		})
+	} else {
+		docs, err = readDocuments(location)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	grouped := map[string][]snd.Entry{}
+	ids := map[string]map[string]bool{}
+	for i := range docs {
+		entry, group, err := ConvertDocument(docs[i])
+		if err != nil {
+			// Folders and other documents that aren't content are skipped
+			continue
+		}
+
+		if ids[group] == nil {
+			ids[group] = map[string]bool{}
+		}
+
+		id := entry.ID
+		for j := 2; ids[group][id]; j++ {
+			id = fmt.Sprintf("%s-%d", entry.ID, j)
+		}
+		ids[group][id] = true
+		entry.ID = id
+
+		grouped[group] = append(grouped[group], entry)
+	}
+
+	if len(grouped) == 0 {
+		return nil, nil, errors.New("no pathfinder 2e documents found")
+	}
+
+	var names []string
+	for group := range grouped {
+		names = append(names, group)
+	}
+	sort.Strings(names)
+
+	var sources []snd.DataSource
+	var entries [][]snd.Entry
+	for _, group := range names {
+		sources = append(sources, snd.DataSource{
+			Name:        name + " - " + group,
+			Slug:        slug + "-" + strings.Trim(slugRegex.ReplaceAllString(strings.ToLower(group), "-"), "-"),
+			Author:      author,
+			Description: description,
+		})
+		entries = append(entries, grouped[group])
+	}
+
+	return sources, entries, nil
+}
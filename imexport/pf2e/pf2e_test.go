@@ -0,0 +1,39 @@
+package pf2e
+
+import (
+	"testing"
+)
+
+func TestCleanEnrichers(t *testing.T) {
+	cases := []struct {
+		text string
+		want string
+	}{
+		{text: "No enrichers.", want: "No enrichers."},
+		{text: "You are @UUID[Compendium.pf2e.conditionitems.Item.Frightened]{Frightened 1}.", want: "You are Frightened 1."},
+		{text: "You are @UUID[Compendium.pf2e.conditionitems.Item.Frightened].", want: "You are Frightened."},
+		{text: "See @Compendium[pf2e.actionspf2e.Stride].", want: "See Stride."},
+		{text: "Deal @Damage[2d6[fire]] damage.", want: "Deal 2d6 fire damage."},
+		{text: "Deal @Damage[(2d6+4)[slashing],1d6[persistent,fire]] damage.", want: "Deal (2d6+4) slashing ,1d6 persistent,fire damage."},
+		{text: "@Check[type:reflex|dc:20|basic:true] save", want: "type:reflex save"},
+		{text: "@Check[type:reflex|dc:20]{Basic Reflex} save", want: "Basic Reflex save"},
+		{text: "@Template[type:burst|distance:20] and @Damage[1d4[acid]]", want: "type:burst and 1d4 acid"},
+		{text: "@UUID[a.b]{A} and @UUID[c.d]{C}", want: "A and C"},
+		{text: "@UUID[Compendium.pf2e.spells.Item.Heal]{} spell", want: "Heal spell"},
+
+		// Malformed or no enrichers are kept as they are
+		{text: "mail@example.com", want: "mail@example.com"},
+		{text: "@ the end @", want: "@ the end @"},
+		{text: "@[no name]", want: "@[no name]"},
+		{text: "@UUID without content", want: "@UUID without content"},
+		{text: "@Damage[2d6[fire] missing bracket", want: "@Damage[2d6[fire] missing bracket"},
+		{text: "@UUID[a.b]{unclosed label", want: "b{unclosed label"},
+		{text: "@UUID[", want: "@UUID["},
+	}
+
+	for _, c := range cases {
+		if res := CleanEnrichers(c.text); res != c.want {
+			t.Errorf("%s: expected '%s', got '%s'", c.text, c.want, res)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package imexport
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+
+	"github.com/BigJk/snd"
+)
+
+type promptMeta struct {
+	Name        string               `json:"name"`
+	Slug        string               `json:"slug"`
+	Author      string               `json:"author"`
+	Description string               `json:"description"`
+	Variables   []snd.PromptVariable `json:"variables"`
+	Version     string               `json:"version"`
+	History     []snd.PromptRevision `json:"history"`
+}
+
+func writePromptMeta(writer io.Writer, prompt snd.Prompt) error {
+	enc := json.NewEncoder(writer)
+	enc.SetIndent("", "\t")
+	enc.SetEscapeHTML(true)
+	if err := enc.Encode(&promptMeta{
+		Name:        prompt.Name,
+		Slug:        prompt.Slug,
+		Author:      prompt.Author,
+		Description: prompt.Description,
+		Variables:   prompt.Variables,
+		Version:     prompt.Version,
+		History:     prompt.History,
+	}); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ImportPrompt imports a prompt from a given ImportReader interface instance.
+//
+// Following files are needed:
+// - meta.json
+// - system.txt
+// - user.txt
+func ImportPrompt(reader ImportReader) (snd.Prompt, error) {
+	files, err := readFiles(reader, []string{"meta.json", "system.txt", "user.txt"})
+	if err != nil {
+		return snd.Prompt{}, err
+	}
+
+	var prompt snd.Prompt
+	if err := json.Unmarshal(files["meta.json"], &prompt); err != nil {
+		return snd.Prompt{}, err
+	}
+
+	prompt.System = string(files["system.txt"])
+	prompt.User = string(files["user.txt"])
+
+	if len(prompt.Slug) == 0 || len(prompt.Author) == 0 || len(prompt.Name) == 0 {
+		return snd.Prompt{}, errors.New("meta data incomplete (e.g. name, author, slug missing)")
+	}
+
+	if !validChars.MatchString(prompt.Slug) || !validChars.MatchString(prompt.Author) {
+		return snd.Prompt{}, errors.New("slug or author contains illegal characters")
+	}
+
+	return prompt, nil
+}
+
+// ExportPrompt exports a prompt to a given ExportWriter interface instance.
+//
+// Following files will be created:
+// - meta.json
+// - system.txt
+// - user.txt
+func ExportPrompt(prompt snd.Prompt, writer ExportWriter) error {
+	metaData := &bytes.Buffer{}
+
+	if err := writePromptMeta(metaData, prompt); err != nil {
+		return err
+	}
+
+	return writeFiles(writer, map[string][]byte{
+		"meta.json":  metaData.Bytes(),
+		"system.txt": []byte(prompt.System),
+		"user.txt":   []byte(prompt.User),
+	})
+}
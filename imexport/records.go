@@ -0,0 +1,154 @@
+package imexport
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/BigJk/snd"
+)
+
+// RecordOptions describes where the records and their fields are located in generic JSON data.
+// All fields are JSONPath expressions (see SelectJSONPath). The name and id are relative to the record.
+type RecordOptions struct {
+	Records string `json:"records"`
+	Name    string `json:"name"`
+	ID      string `json:"id"`
+}
+
+// DecodeJSONValues decodes a single JSON value or a stream of values like JSONL. A stream
+// with more than one value is returned as list.
+func DecodeJSONValues(reader io.Reader) (any, error) {
+	decoder := json.NewDecoder(reader)
+
+	var values []any
+	for {
+		var value any
+		if err := decoder.Decode(&value); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+
+	switch len(values) {
+	case 0:
+		return nil, errors.New("no json data found")
+	case 1:
+		return values[0], nil
+	}
+	return values, nil
+}
+
+// ImportRecords converts the records of generic JSON data to entries. If no records
+// path is given all elements of the top level list or object are used. Records without
+// id use their name as id and duplicated ids get a suffix.
+func ImportRecords(data any, options RecordOptions) ([]snd.Entry, error) {
+	if len(strings.TrimSpace(options.Records)) == 0 {
+		options.Records = "$[*]"
+	}
+	if len(strings.TrimSpace(options.Name)) == 0 {
+		options.Name = "name"
+	}
+
+	records, err := SelectJSONPath(data, options.Records)
+	if err != nil {
+		return nil, fmt.Errorf("records: %w", err)
+	}
+
+	if len(records) == 0 {
+		return nil, errors.New("no records found")
+	}
+
+	var entries []snd.Entry
+	ids := map[string]bool{}
+	for i := range records {
+		record, ok := records[i].(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("record %d is not an object", i+1)
+		}
+
+		name, err := recordField(record, options.Name)
+		if err != nil {
+			return nil, fmt.Errorf("name: %w", err)
+		}
+
+		if len(name) == 0 {
+			return nil, fmt.Errorf("record %d has no name", i+1)
+		}
+
+		id := name
+		if len(strings.TrimSpace(options.ID)) > 0 {
+			if id, err = recordField(record, options.ID); err != nil {
+				return nil, fmt.Errorf("id: %w", err)
+			}
+			if len(id) == 0 {
+				id = name
+			}
+		}
+
+		unique := id
+		for j := 2; ids[unique]; j++ {
+			unique = fmt.Sprintf("%s-%d", id, j)
+		}
+		ids[unique] = true
+
+		entries = append(entries, snd.Entry{
+			ID:   unique,
+			Name: name,
+			Data: record,
+		})
+	}
+
+	return entries, nil
+}
+
+// recordField returns the first value at the path as text.
+func recordField(record map[string]any, path string) (string, error) {
+	values, err := SelectJSONPath(record, path)
+	if err != nil {
+		return "", err
+	}
+
+	if len(values) == 0 || values[0] == nil {
+		return "", nil
+	}
+
+	switch v := values[0].(type) {
+	case string:
+		return strings.TrimSpace(v), nil
+	case float64, bool:
+		return fmt.Sprint(v), nil
+	}
+	return "", fmt.Errorf("'%s' is not a text or number", path)
+}
+
+// ImportRecordsFile imports the records of a JSON or JSONL file as data source.
+func ImportRecordsFile(file string, name string, author string, slug string, description string, options RecordOptions) (snd.DataSource, []snd.Entry, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return snd.DataSource{}, nil, err
+	}
+	defer f.Close()
+
+	data, err := DecodeJSONValues(f)
+	if err != nil {
+		return snd.DataSource{}, nil, err
+	}
+
+	entries, err := ImportRecords(data, options)
+	if err != nil {
+		return snd.DataSource{}, nil, err
+	}
+
+	return snd.DataSource{
+		Name:        name,
+		Slug:        slug,
+		Author:      author,
+		Description: description,
+	}, entries, nil
+}
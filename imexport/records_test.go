@@ -0,0 +1,99 @@
+package imexport
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecodeJSONValues(t *testing.T) {
+	cases := []struct {
+		data string
+		want any
+		err  bool
+	}{
+		{data: `{"name": "Goblin"}`, want: map[string]any{"name": "Goblin"}},
+		{data: `[1, 2]`, want: []any{1.0, 2.0}},
+		{data: "{\"name\": \"Goblin\"}\n{\"name\": \"Orc\"}\n", want: []any{map[string]any{"name": "Goblin"}, map[string]any{"name": "Orc"}}},
+		{data: "", err: true},
+		{data: "{\"name\": \"Goblin\"}\n{\"name\":", err: true},
+	}
+
+	for _, c := range cases {
+		res, err := DecodeJSONValues(strings.NewReader(c.data))
+		if (err != nil) != c.err || !reflect.DeepEqual(res, c.want) {
+			t.Errorf("%q: expected %v (error %v), got %v (%v)", c.data, c.want, c.err, res, err)
+		}
+	}
+}
+
+func TestImportRecords(t *testing.T) {
+	data := map[string]any{
+		"results": []any{
+			map[string]any{"name": "Goblin", "info": map[string]any{"id": "gob"}},
+			map[string]any{"name": " Goblin ", "info": map[string]any{"id": 2.0}},
+			map[string]any{"name": "Goblin"},
+			map[string]any{"title": "Orc", "info": map[string]any{"id": "gob"}},
+		},
+	}
+
+	cases := []struct {
+		name    string
+		options RecordOptions
+		ids     []string
+		names   []string
+		err     bool
+	}{
+		{name: "defaults", options: RecordOptions{Records: "results[0:3]"}, ids: []string{"Goblin", "Goblin-2", "Goblin-3"}, names: []string{"Goblin", "Goblin", "Goblin"}},
+		{name: "id path", options: RecordOptions{Records: "$.results[0:3]", ID: "info.id"}, ids: []string{"gob", "2", "Goblin"}, names: []string{"Goblin", "Goblin", "Goblin"}},
+		{name: "name path", options: RecordOptions{Records: "$.results[?(@.info)]", Name: "$['title','name']", ID: "$.info.id"}, ids: []string{"gob", "2", "gob-2"}, names: []string{"Goblin", "Goblin", "Orc"}},
+		{name: "missing name", options: RecordOptions{Records: "$.results[*]"}, err: true},
+		{name: "no records", options: RecordOptions{Records: "$.missing[*]"}, err: true},
+		{name: "records aren't objects", options: RecordOptions{Records: "$.results[*].name"}, err: true},
+		{name: "name is an object", options: RecordOptions{Records: "$.results[0:1]", Name: "info"}, err: true},
+		{name: "invalid records path", options: RecordOptions{Records: "$.results["}, err: true},
+		{name: "invalid id path", options: RecordOptions{Records: "$.results[0:1]", ID: "info["}, err: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			entries, err := ImportRecords(data, c.options)
+			if c.err {
+				if err == nil {
+					t.Fatalf("expected an error, got %+v", entries)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var ids, names []string
+			for i := range entries {
+				ids = append(ids, entries[i].ID)
+				names = append(names, entries[i].Name)
+			}
+
+			if !reflect.DeepEqual(ids, c.ids) || !reflect.DeepEqual(names, c.names) {
+				t.Fatalf("expected %v %v, got %v %v", c.ids, c.names, ids, names)
+			}
+		})
+	}
+}
+
+func TestImportRecordsFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "monsters.jsonl")
+	if err := os.WriteFile(file, []byte("{\"name\": \"Goblin\"}\n{\"name\": \"Orc\"}\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	ds, entries, err := ImportRecordsFile(file, "Monsters", "author", "monsters", "", RecordOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ds.Slug != "monsters" || len(entries) != 2 || entries[1].ID != "Orc" || entries[1].Data["name"] != "Orc" {
+		t.Fatalf("unexpected import %+v %+v", ds, entries)
+	}
+}
@@ -0,0 +1,30 @@
+package imexport
+
+import (
+	"net/url"
+	"strings"
+)
+
+// ReferencesFolder is the folder in a template folder that contains the
+// reference images of the visual regression tests.
+const ReferencesFolder = "references"
+
+// ReferenceFileName returns the file name of the reference image of an entry.
+func ReferenceFileName(entryId string) string {
+	return url.QueryEscape(entryId) + ".png"
+}
+
+// ReferenceEntryID returns the entry id of a reference image file name. The second
+// return value is false if the name is not a valid reference image name.
+func ReferenceEntryID(name string) (string, bool) {
+	if !strings.HasSuffix(name, ".png") {
+		return "", false
+	}
+
+	id, err := url.QueryUnescape(strings.TrimSuffix(name, ".png"))
+	if err != nil {
+		return "", false
+	}
+
+	return id, true
+}
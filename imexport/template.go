@@ -60,19 +60,21 @@ func writeEntries(writer io.Writer, entries []snd.Entry) error {
 // - list.html.njk
 // - skeleton.json
 // - entries.json
-func ImportTemplate(reader ImportReader) (snd.Template, []snd.Entry, error) {
+//
+// If the reader implements ImportLister the attachments (e.g. references/*.png) are imported too.
+func ImportTemplate(reader ImportReader) (snd.Template, []snd.Entry, Attachments, error) {
 	var tmpl snd.Template
 	var entries []snd.Entry
 
 	files, err := readFiles(reader, []string{"meta.json", "print.html.njk", "list.html.njk", "skeleton.json", "entries.json"})
 	if err != nil {
-		return snd.Template{}, nil, err
+		return snd.Template{}, nil, nil, err
 	}
 
 	// meta data
 	var meta templateMeta
 	if err := json.Unmarshal(files["meta.json"], &meta); err != nil {
-		return snd.Template{}, nil, err
+		return snd.Template{}, nil, nil, err
 	}
 
 	tmpl = snd.Template{
@@ -91,11 +93,16 @@ func ImportTemplate(reader ImportReader) (snd.Template, []snd.Entry, error) {
 	}
 
 	if len(tmpl.Slug) == 0 || len(tmpl.Author) == 0 || len(tmpl.Name) == 0 {
-		return snd.Template{}, nil, errors.New("meta data incomplete (e.g. name, author, slug missing)")
+		return snd.Template{}, nil, nil, errors.New("meta data incomplete (e.g. name, author, slug missing)")
 	}
 
 	if !validChars.MatchString(tmpl.Slug) || !validChars.MatchString(tmpl.Author) {
-		return snd.Template{}, nil, errors.New("slug or author contains illegal characters")
+		return snd.Template{}, nil, nil, errors.New("slug or author contains illegal characters")
+	}
+
+	tmpl.Images, err = importImages(tmpl.Images, reader)
+	if err != nil {
+		return snd.Template{}, nil, nil, err
 	}
 
 	tmpl.PrintTemplate = string(files["print.html.njk"])
@@ -103,15 +110,20 @@ func ImportTemplate(reader ImportReader) (snd.Template, []snd.Entry, error) {
 
 	// skeleton
 	if err := json.Unmarshal(files["skeleton.json"], &tmpl.SkeletonData); err != nil {
-		return snd.Template{}, nil, err
+		return snd.Template{}, nil, nil, err
 	}
 
 	// entries
 	if err := json.Unmarshal(files["entries.json"], &entries); err != nil {
-		return snd.Template{}, nil, err
+		return snd.Template{}, nil, nil, err
 	}
 
-	return tmpl, entries, nil
+	attachments, err := readAttachments(reader)
+	if err != nil {
+		return snd.Template{}, nil, nil, err
+	}
+
+	return tmpl, entries, attachments, nil
 }
 
 // ExportTemplate exports a template to a given ExportWriter interface instance.
@@ -122,7 +134,16 @@ func ImportTemplate(reader ImportReader) (snd.Template, []snd.Entry, error) {
 // - list.html.njk
 // - skeleton.json
 // - entries.json
-func ExportTemplate(tmpl snd.Template, entries []snd.Entry, writer ExportWriter) error {
+//
+// The attachments are written beside them. Images are written as files into the images
+// folder and meta.json references them by their path.
+func ExportTemplate(tmpl snd.Template, entries []snd.Entry, attachments Attachments, writer ExportWriter) error {
+	images, err := exportImages(tmpl.Images, writer)
+	if err != nil {
+		return err
+	}
+	tmpl.Images = images
+
 	metaData := &bytes.Buffer{}
 	skeletonData := &bytes.Buffer{}
 	entriesData := &bytes.Buffer{}
@@ -139,11 +160,15 @@ func ExportTemplate(tmpl snd.Template, entries []snd.Entry, writer ExportWriter)
 		return nil
 	}
 
-	return writeFiles(writer, map[string][]byte{
+	if err := writeFiles(writer, map[string][]byte{
 		"meta.json":      metaData.Bytes(),
 		"skeleton.json":  skeletonData.Bytes(),
 		"entries.json":   entriesData.Bytes(),
 		"print.html.njk": []byte(tmpl.PrintTemplate),
 		"list.html.njk":  []byte(tmpl.ListTemplate),
-	})
+	}); err != nil {
+		return err
+	}
+
+	return writeFiles(writer, attachments)
 }
@@ -0,0 +1,280 @@
+package vtt
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/BigJk/snd"
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+// DocumentTypes maps the document types that can be exported to the collection they are stored in.
+var DocumentTypes = map[string]string{
+	"Item":         "items",
+	"Actor":        "actors",
+	"JournalEntry": "journal",
+	"RollTable":    "tables",
+	"Macro":        "macros",
+}
+
+var documentIdRegex = regexp.MustCompile(`^[a-zA-Z0-9]{16}$`)
+
+// documentId returns the id of the entry if it is a valid FoundryVTT id, otherwise a
+// id is derived from it, so that exporting the same entry again results in the same id.
+func documentId(id string) string {
+	if documentIdRegex.MatchString(id) {
+		return id
+	}
+
+	const chars = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+	hash := sha256.Sum256([]byte(id))
+	res := make([]byte, 16)
+	for i := range res {
+		res[i] = chars[int(hash[i])%len(chars)]
+	}
+	return string(res)
+}
+
+// ConvertEntry converts a S&D entry to a FoundryVTT document. It is the inverse of the import,
+// so the meta information of entries that were imported from FoundryVTT is restored.
+func ConvertEntry(entry snd.Entry, documentType string) map[string]any {
+	doc := map[string]any{}
+	for k, v := range entry.Data {
+		if k == "vtt_meta" {
+			continue
+		}
+		doc[k] = v
+	}
+
+	doc["_id"] = documentId(entry.ID)
+	doc["name"] = entry.Name
+
+	if meta, ok := entry.Data["vtt_meta"].(map[string]any); ok {
+		if t, ok := meta["Type"].(string); ok && len(t) > 0 {
+			doc["type"] = t
+		}
+		if img, ok := meta["Img"].(string); ok && len(img) > 0 {
+			doc["img"] = img
+		}
+		if flags, ok := meta["Flags"].(map[string]any); ok {
+			doc["flags"] = flags
+		}
+	}
+
+	// The import resolves folders to their path, but the pack only contains the documents.
+	if folder, ok := doc["folder"].(string); ok && !documentIdRegex.MatchString(folder) {
+		doc["folder"] = nil
+	}
+
+	if _, ok := doc["flags"]; !ok {
+		doc["flags"] = map[string]any{}
+	}
+
+	// Journal entries without pages would be empty in FoundryVTT, so the data is added as a page.
+	if _, ok := doc["pages"]; !ok && documentType == "JournalEntry" {
+		data, _ := json.MarshalIndent(entry.Data, "", "  ")
+		doc["pages"] = []any{map[string]any{
+			"_id":  documentId(entry.ID + "-page"),
+			"name": entry.Name,
+			"type": "text",
+			"text": map[string]any{"format": 1, "content": "<pre>" + strings.ReplaceAll(string(data), "<", "&lt;") + "</pre>"},
+		}}
+	}
+
+	return doc
+}
+
+// ModuleName returns the name of the module that is created for the data source.
+func ModuleName(ds snd.DataSource) string {
+	return strings.ToLower(fmt.Sprintf("%s-%s", ds.Author, ds.Slug))
+}
+
+// ExportModule writes the data source as a FoundryVTT module with a single pack to the folder.
+// If levelDB is true the pack is written as LevelDB folder like FoundryVTT v11+ stores them,
+// otherwise as a legacy .db file which FoundryVTT migrates when the module is loaded.
+//
+// The function returns the path of the module folder with the pattern "{ds.Author}-{ds.Slug}" in lower case.
+func ExportModule(ds snd.DataSource, entries []snd.Entry, documentType string, levelDB bool, folder string) (string, error) {
+	collection, ok := DocumentTypes[documentType]
+	if !ok {
+		return "", fmt.Errorf("unsupported document type '%s'", documentType)
+	}
+
+	name := ModuleName(ds)
+	moduleFolder := filepath.Join(folder, name)
+
+	packPath := "packs/" + ds.Slug
+	if !levelDB {
+		packPath += ".db"
+	}
+
+	if err := os.MkdirAll(filepath.Join(moduleFolder, "packs"), 0777); err != nil {
+		return "", err
+	}
+
+	docs := make([]map[string]any, len(entries))
+	for i := range entries {
+		docs[i] = ConvertEntry(entries[i], documentType)
+	}
+
+	var err error
+	if levelDB {
+		err = writeLevelDBPack(filepath.Join(moduleFolder, packPath), collection, docs)
+	} else {
+		err = writeNeDBPack(filepath.Join(moduleFolder, packPath), docs)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	minimumVersion := "10"
+	if levelDB {
+		minimumVersion = "11"
+	}
+
+	version := ds.Version
+	if len(version) == 0 {
+		version = "1.0.0"
+	}
+
+	manifest := map[string]any{
+		"id":          name,
+		"name":        name,
+		"title":       ds.Name,
+		"description": ds.Description,
+		"version":     version,
+		"authors":     []any{map[string]any{"name": ds.Author}},
+		"compatibility": map[string]any{
+			"minimum": minimumVersion,
+		},
+		"packs": []any{map[string]any{
+			"name":   ds.Slug,
+			"label":  ds.Name,
+			"path":   packPath,
+			"type":   documentType,
+			"entity": documentType,
+		}},
+	}
+
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	return moduleFolder, os.WriteFile(filepath.Join(moduleFolder, "module.json"), manifestData, 0666)
+}
+
+// writeNeDBPack writes the documents as a .db file with one JSON document per line.
+func writeNeDBPack(file string, docs []map[string]any) error {
+	var lines []string
+	for i := range docs {
+		data, err := json.Marshal(docs[i])
+		if err != nil {
+			return err
+		}
+		lines = append(lines, string(data))
+	}
+
+	return os.WriteFile(file, []byte(strings.Join(lines, "\n")+"\n"), 0666)
+}
+
+// writeLevelDBPack writes the documents as a LevelDB pack. Embedded documents are stored
+// under their own key and replaced by their ids, the inverse of ReadLevelDBPack.
+func writeLevelDBPack(packDir string, collection string, docs []map[string]any) error {
+	if err := os.RemoveAll(packDir); err != nil {
+		return err
+	}
+
+	db, err := leveldb.OpenFile(packDir, nil)
+	if err != nil {
+		return err
+	}
+
+	batch := new(leveldb.Batch)
+	for i := range docs {
+		if err := putDocument(batch, collection, "", docs[i]); err != nil {
+			_ = db.Close()
+			return err
+		}
+	}
+
+	if err := db.Write(batch, nil); err != nil {
+		_ = db.Close()
+		return err
+	}
+
+	return db.Close()
+}
+
+func putDocument(batch *leveldb.Batch, collection string, parent string, doc map[string]any) error {
+	id, _ := doc["_id"].(string)
+
+	path := id
+	if len(parent) > 0 {
+		path = parent + "." + id
+	}
+
+	stored := map[string]any{}
+
+	var fields []string
+	for k := range doc {
+		fields = append(fields, k)
+	}
+	sort.Strings(fields)
+
+	for _, field := range fields {
+		embedded, ok := embeddedDocuments(doc[field])
+		if !ok {
+			stored[field] = doc[field]
+			continue
+		}
+
+		ids := make([]any, len(embedded))
+		for i := range embedded {
+			ids[i] = embedded[i]["_id"]
+			if err := putDocument(batch, collection+"."+field, path, embedded[i]); err != nil {
+				return err
+			}
+		}
+		stored[field] = ids
+	}
+
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return err
+	}
+
+	batch.Put([]byte(fmt.Sprintf("!%s!%s", collection, path)), data)
+	return nil
+}
+
+// embeddedDocuments checks if the value is a list of documents, which means all elements are objects with an id.
+func embeddedDocuments(value any) ([]map[string]any, bool) {
+	list, ok := value.([]any)
+	if !ok || len(list) == 0 {
+		return nil, false
+	}
+
+	docs := make([]map[string]any, len(list))
+	for i := range list {
+		doc, ok := list[i].(map[string]any)
+		if !ok {
+			return nil, false
+		}
+
+		if id, ok := doc["_id"].(string); !ok || len(id) == 0 || strings.ContainsAny(id, ".!") {
+			return nil, false
+		}
+
+		docs[i] = doc
+	}
+
+	return docs, true
+}
@@ -0,0 +1,75 @@
+package vtt
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/BigJk/snd"
+)
+
+func TestExportImportRoundTrip(t *testing.T) {
+	entries := []snd.Entry{
+		{ID: "aaaaaaaaaaaaaaa1", Name: "Goblin", Data: map[string]any{
+			"name":     "Goblin",
+			"system":   map[string]any{"hp": 7.0, "size": "sm"},
+			"items":    []any{map[string]any{"_id": "bbbbbbbbbbbbbbb1", "name": "Scimitar"}},
+			"vtt_meta": map[string]any{"ID": "aaaaaaaaaaaaaaa1", "Type": "npc", "Img": "goblin.png", "Flags": map[string]any{}},
+		}},
+		// Entries that were not imported from FoundryVTT get a stable id
+		{ID: "orc", Name: "Orc", Data: map[string]any{"system": map[string]any{"hp": 15.0}}},
+	}
+
+	ds := snd.DataSource{Name: "Monsters", Author: "Author", Slug: "monsters", Description: "Some monsters", Version: "1.2.0"}
+
+	for _, levelDB := range []bool{false, true} {
+		t.Run(map[bool]string{false: "nedb", true: "leveldb"}[levelDB], func(t *testing.T) {
+			folder, err := ExportModule(ds, entries, "Actor", levelDB, t.TempDir())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if filepath.Base(folder) != "author-monsters" {
+				t.Fatalf("unexpected module folder %s", folder)
+			}
+
+			sources, imported, err := ConvertDataSources(filepath.Join(folder, "module.json"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(sources) != 1 || len(imported) != 1 || len(imported[0]) != 2 {
+				t.Fatalf("unexpected import %+v %+v", sources, imported)
+			}
+			if sources[0].Name != "Monsters (Monsters)" || sources[0].Author != "Author" || sources[0].Version != "1.2.0" || sources[0].Description != "Some monsters" {
+				t.Fatalf("unexpected source %+v", sources[0])
+			}
+
+			goblin, orc := imported[0][0], imported[0][1]
+			if goblin.Name == "Orc" {
+				goblin, orc = orc, goblin
+			}
+
+			if goblin.ID != "aaaaaaaaaaaaaaa1" || goblin.Name != "Goblin" {
+				t.Fatalf("unexpected entry %+v", goblin)
+			}
+			if !reflect.DeepEqual(goblin.Data["system"], entries[0].Data["system"]) || !reflect.DeepEqual(goblin.Data["items"], entries[0].Data["items"]) {
+				t.Fatalf("expected the data to be kept, got %+v", goblin.Data)
+			}
+
+			meta := normalize(t, goblin.Data["vtt_meta"]).(map[string]any)
+			if meta["Type"] != "npc" || meta["Img"] != "goblin.png" {
+				t.Fatalf("expected the meta information to be restored, got %+v", meta)
+			}
+
+			if orc.ID != documentId("orc") || orc.Name != "Orc" || !reflect.DeepEqual(orc.Data["system"], entries[1].Data["system"]) {
+				t.Fatalf("unexpected entry %+v", orc)
+			}
+		})
+	}
+}
+
+func TestExportUnsupportedDocumentType(t *testing.T) {
+	if _, err := ExportModule(snd.DataSource{Slug: "a"}, nil, "Scene", false, t.TempDir()); err == nil {
+		t.Fatal("expected an error for an unsupported document type")
+	}
+}
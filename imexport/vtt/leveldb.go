@@ -0,0 +1,280 @@
+package vtt
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BigJk/snd"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+)
+
+// Since v11 FoundryVTT stores compendium packs as LevelDB directories. Every document
+// is stored as JSON under a key of the form:
+//
+//	!<collection>!<id>                      e.g. !items!ab12...
+//	!<collection>.<embedded>!<parent>.<id>  e.g. !actors.items!ab12...cd34...
+//
+// The parent document only contains the ids of its embedded documents, so they have
+// to be resolved to get the full document.
+
+type levelDBKey struct {
+	collection string
+	embedded   string
+	parent     string
+	id         string
+}
+
+// parseLevelDBKey splits a document key into its parts.
+func parseLevelDBKey(key string) (levelDBKey, bool) {
+	parts := strings.SplitN(strings.TrimPrefix(key, "!"), "!", 2)
+	if !strings.HasPrefix(key, "!") || len(parts) != 2 {
+		return levelDBKey{}, false
+	}
+
+	res := levelDBKey{collection: parts[0], id: parts[1]}
+
+	// Embedded documents can be nested (e.g. !actors.items.effects!<actor>.<item>.<id>), so
+	// the last part is the embedded collection and id and the rest identifies the parent.
+	if dot := strings.LastIndex(parts[0], "."); dot >= 0 {
+		idDot := strings.LastIndex(parts[1], ".")
+		if idDot < 0 {
+			return levelDBKey{}, false
+		}
+
+		res.collection = parts[0][:dot]
+		res.embedded = parts[0][dot+1:]
+		res.parent = parts[1][:idDot]
+		res.id = parts[1][idDot+1:]
+	}
+
+	return res, true
+}
+
+// IsLevelDBPack checks if the path is a LevelDB compendium pack.
+func IsLevelDBPack(path string) bool {
+	fi, err := os.Stat(filepath.Join(path, "CURRENT"))
+	return err == nil && !fi.IsDir()
+}
+
+// openLevelDB opens the pack read-only. If FoundryVTT is running it holds the lock of the
+// pack, so in that case the pack is copied to a temporary folder and opened from there.
+func openLevelDB(packDir string) (*leveldb.DB, func(), error) {
+	options := &opt.Options{ReadOnly: true, ErrorIfMissing: true}
+
+	db, err := leveldb.OpenFile(packDir, options)
+	if err == nil {
+		return db, func() { _ = db.Close() }, nil
+	}
+
+	tempDir, copyErr := os.MkdirTemp("", "snd-vtt-pack-")
+	if copyErr != nil {
+		return nil, nil, err
+	}
+
+	if copyErr := copyPackFiles(packDir, tempDir); copyErr != nil {
+		_ = os.RemoveAll(tempDir)
+		return nil, nil, err
+	}
+
+	db, err = leveldb.OpenFile(tempDir, options)
+	if err != nil {
+		_ = os.RemoveAll(tempDir)
+		return nil, nil, err
+	}
+
+	return db, func() {
+		_ = db.Close()
+		_ = os.RemoveAll(tempDir)
+	}, nil
+}
+
+func copyPackFiles(from string, to string) error {
+	files, err := os.ReadDir(from)
+	if err != nil {
+		return err
+	}
+
+	for i := range files {
+		if files[i].IsDir() || files[i].Name() == "LOCK" {
+			continue
+		}
+
+		if err := copyFile(filepath.Join(from, files[i].Name()), filepath.Join(to, files[i].Name())); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func copyFile(from string, to string) error {
+	src, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.Create(to)
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+
+	_, err = io.Copy(dst, src)
+	return err
+}
+
+// ReadLevelDBPack reads all top-level documents of a LevelDB pack. The ids of embedded
+// documents (e.g. the items of an actor or the pages of a journal entry) are replaced by
+// the documents themselves and the folder of each document is resolved to its path.
+func ReadLevelDBPack(packDir string) ([]map[string]any, error) {
+	db, closeDB, err := openLevelDB(packDir)
+	if err != nil {
+		return nil, err
+	}
+	defer closeDB()
+
+	var keys []levelDBKey
+	documents := map[levelDBKey]map[string]any{}
+	folders := map[string]map[string]any{}
+
+	iter := db.NewIterator(nil, nil)
+	for iter.Next() {
+		key, ok := parseLevelDBKey(string(iter.Key()))
+		if !ok {
+			continue
+		}
+
+		doc := map[string]any{}
+		if err := json.Unmarshal(iter.Value(), &doc); err != nil {
+			iter.Release()
+			return nil, fmt.Errorf("document '%s' is not valid json: %w", string(iter.Key()), err)
+		}
+
+		if key.collection == "folders" && key.embedded == "" {
+			folders[key.id] = doc
+			continue
+		}
+
+		keys = append(keys, key)
+		documents[key] = doc
+	}
+	iter.Release()
+	if err := iter.Error(); err != nil {
+		return nil, err
+	}
+
+	var res []map[string]any
+	for _, key := range keys {
+		if key.embedded != "" {
+			continue
+		}
+
+		doc := documents[key]
+		resolveEmbedded(key, doc, documents)
+
+		if path := folderPath(doc["folder"], folders); path != "" {
+			doc["folder"] = path
+		}
+
+		res = append(res, doc)
+	}
+
+	return res, nil
+}
+
+// resolveEmbedded replaces the id lists of the embedded collections of doc by the documents.
+// Embedded documents can have embedded documents themselves (e.g. the effects of an item of
+// an actor), so they are resolved recursively.
+func resolveEmbedded(key levelDBKey, doc map[string]any, documents map[levelDBKey]map[string]any) {
+	collection := key.collection
+	parent := key.id
+	if key.embedded != "" {
+		collection += "." + key.embedded
+		parent = key.parent + "." + key.id
+	}
+
+	for field, value := range doc {
+		ids, ok := value.([]any)
+		if !ok || len(ids) == 0 {
+			continue
+		}
+
+		resolved := make([]any, 0, len(ids))
+		for _, id := range ids {
+			idString, ok := id.(string)
+			if !ok {
+				break
+			}
+
+			embeddedKey := levelDBKey{collection: collection, embedded: field, parent: parent, id: idString}
+			embedded, ok := documents[embeddedKey]
+			if !ok {
+				break
+			}
+
+			resolveEmbedded(embeddedKey, embedded, documents)
+			resolved = append(resolved, embedded)
+		}
+
+		// Only replace the field if all ids could be resolved, otherwise it is a normal list.
+		if len(resolved) == len(ids) {
+			doc[field] = resolved
+		}
+	}
+}
+
+// folderPath returns the path of the folder like "Monsters/Dragons".
+func folderPath(id any, folders map[string]map[string]any) string {
+	var path []string
+	seen := map[string]bool{}
+
+	for {
+		idString, ok := id.(string)
+		if !ok || seen[idString] {
+			break
+		}
+		seen[idString] = true
+
+		folder, ok := folders[idString]
+		if !ok {
+			break
+		}
+
+		if name, ok := folder["name"].(string); ok {
+			path = append(path, name)
+		}
+		id = folder["folder"]
+	}
+
+	// The path was collected from the innermost folder outwards
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+
+	return strings.Join(path, "/")
+}
+
+// ConvertLevelDBPack converts a LevelDB compendium pack to S&D entries.
+func ConvertLevelDBPack(packDir string) ([]snd.Entry, error) {
+	docs, err := ReadLevelDBPack(packDir)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]snd.Entry, 0, len(docs))
+	for i := range docs {
+		entry, err := ConvertDocument(docs[i])
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
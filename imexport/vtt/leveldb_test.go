@@ -0,0 +1,133 @@
+package vtt
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+func TestParseLevelDBKey(t *testing.T) {
+	cases := []struct {
+		key  string
+		want levelDBKey
+		ok   bool
+	}{
+		{key: "!items!ab12", want: levelDBKey{collection: "items", id: "ab12"}, ok: true},
+		{key: "!actors.items!actor1.item1", want: levelDBKey{collection: "actors", embedded: "items", parent: "actor1", id: "item1"}, ok: true},
+		{key: "!actors.items.effects!actor1.item1.effect1", want: levelDBKey{collection: "actors.items", embedded: "effects", parent: "actor1.item1", id: "effect1"}, ok: true},
+		{key: "!actors.items!item1", ok: false},
+		{key: "items!ab12", ok: false},
+		{key: "!items", ok: false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.key, func(t *testing.T) {
+			got, ok := parseLevelDBKey(c.key)
+			if ok != c.ok || got != c.want {
+				t.Fatalf("expected %+v (%v), got %+v (%v)", c.want, c.ok, got, ok)
+			}
+		})
+	}
+}
+
+// normalize passes the value through json, so that it can be compared with decoded documents.
+func normalize(t *testing.T, value any) any {
+	data, err := json.Marshal(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var res any
+	if err := json.Unmarshal(data, &res); err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+func TestLevelDBPackRoundTrip(t *testing.T) {
+	docs := []map[string]any{
+		{
+			"_id":    "actor1",
+			"name":   "Goblin",
+			"folder": "folderInner",
+			"system": map[string]any{"hp": 7},
+			"tags":   []any{"small", "humanoid"},
+			"items": []any{
+				map[string]any{
+					"_id":  "item1",
+					"name": "Scimitar",
+					"effects": []any{
+						map[string]any{"_id": "effect1", "name": "Sharp"},
+					},
+				},
+				map[string]any{"_id": "item2", "name": "Shortbow"},
+			},
+		},
+		{"_id": "actor2", "name": "Orc", "items": []any{}},
+	}
+
+	packDir := filepath.Join(t.TempDir(), "monsters")
+	if err := writeLevelDBPack(packDir, "actors", docs); err != nil {
+		t.Fatal(err)
+	}
+
+	// Folders are stored next to the documents
+	db, err := leveldb.OpenFile(packDir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = db.Put([]byte("!folders!folderOuter"), []byte(`{"_id": "folderOuter", "name": "Monsters", "folder": null}`), nil)
+	_ = db.Put([]byte("!folders!folderInner"), []byte(`{"_id": "folderInner", "name": "Goblins", "folder": "folderOuter"}`), nil)
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !IsLevelDBPack(packDir) {
+		t.Fatal("expected the folder to be a LevelDB pack")
+	}
+
+	read, err := ReadLevelDBPack(packDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := normalize(t, docs).([]any)
+	want[0].(map[string]any)["folder"] = "Monsters/Goblins"
+
+	if got := normalize(t, read); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+
+	entries, err := ConvertLevelDBPack(packDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Name != "Goblin" || entries[1].Name != "Orc" {
+		t.Fatalf("unexpected entries %+v", entries)
+	}
+}
+
+func TestReadLockedLevelDBPack(t *testing.T) {
+	packDir := filepath.Join(t.TempDir(), "items")
+	if err := writeLevelDBPack(packDir, "items", []map[string]any{{"_id": "item1", "name": "Rope"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// FoundryVTT holds the lock of the packs while it is running
+	db, err := leveldb.OpenFile(packDir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	read, err := ReadLevelDBPack(packDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(read) != 1 || read[0]["name"] != "Rope" {
+		t.Fatalf("unexpected documents %v", read)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/BigJk/snd"
+	"github.com/BigJk/snd/log"
 )
 
 type Module struct {
@@ -30,6 +31,7 @@ type Module struct {
 		Package string `json:"package"`
 		Path    string `json:"path"`
 		Entity  string `json:"entity"`
+		Type    string `json:"type"`
 	} `json:"packs"`
 	MinimumCoreVersion    string `json:"minimumCoreVersion"`
 	CompatibleCoreVersion string `json:"compatibleCoreVersion"`
@@ -50,7 +52,44 @@ type PackEntry struct {
 	Img  string `json:"img"`
 }
 
-// ConvertPackEntries converts a .db FoundryVTT file to S&D entries.
+// ConvertDocument converts a single FoundryVTT document to a S&D entry.
+func ConvertDocument(packRaw map[string]any) (snd.Entry, error) {
+	packBytes, err := json.Marshal(packRaw)
+	if err != nil {
+		return snd.Entry{}, err
+	}
+
+	pack := PackEntry{}
+	if err := json.Unmarshal(packBytes, &pack); err != nil {
+		return snd.Entry{}, err
+	}
+
+	data := map[string]any{}
+	data["name"] = pack.Name
+	data["vtt_meta"] = map[string]interface{}{
+		"ID":         pack.ID,
+		"Img":        pack.Img,
+		"Permission": pack.Permission,
+		"Flags":      pack.Flags,
+		"Type":       pack.Type,
+	}
+
+	for k, v := range packRaw {
+		if k == "_id" || k == "name" || k == "permission" || k == "flags" || k == "type" || k == "img" {
+			continue
+		}
+		data[k] = v
+	}
+
+	return snd.Entry{
+		ID:   pack.ID,
+		Name: pack.Name,
+		Data: data,
+	}, nil
+}
+
+// ConvertPackEntries converts a .db FoundryVTT file, a folder of JSON files or a LevelDB
+// pack folder to S&D entries.
 func ConvertPackEntries(packFile string) ([]snd.Entry, error) {
 	// Check if packFile is a directory
 	fi, err := os.Stat(packFile)
@@ -58,6 +97,10 @@ func ConvertPackEntries(packFile string) ([]snd.Entry, error) {
 		return nil, err
 	}
 
+	if fi.IsDir() && IsLevelDBPack(packFile) {
+		return ConvertLevelDBPack(packFile)
+	}
+
 	if fi.IsDir() {
 		var entries []snd.Entry
 		return entries, filepath.Walk(packFile, func(path string, info os.FileInfo, err error) error {
@@ -65,13 +108,13 @@ func ConvertPackEntries(packFile string) ([]snd.Entry, error) {
 				return nil
 			}
 
-			fmt.Println(path)
+			log.Info("converting pack file", log.WithValue("file", path))
 
 			e, err := ConvertPackEntries(path)
 			if err == nil {
 				entries = append(entries, e...)
 			} else {
-				fmt.Println("error while converting pack entries:", err)
+				log.Error(err, log.WithValue("file", path))
 			}
 			return nil
 		})
@@ -99,42 +142,37 @@ func ConvertPackEntries(packFile string) ([]snd.Entry, error) {
 			continue
 		}
 
-		pack := PackEntry{}
 		packRaw := map[string]any{}
-		if err := json.Unmarshal([]byte(packLines[i]), &pack); err != nil {
-			return nil, err
-		}
 		if err := json.Unmarshal([]byte(packLines[i]), &packRaw); err != nil {
 			return nil, err
 		}
 
-		data := map[string]any{}
-		data["name"] = pack.Name
-		data["vtt_meta"] = map[string]interface{}{
-			"ID":         pack.ID,
-			"Img":        pack.Img,
-			"Permission": pack.Permission,
-			"Flags":      pack.Flags,
-			"Type":       pack.Type,
-		}
-
-		for k, v := range packRaw {
-			if k == "_id" || k == "name" || k == "permission" || k == "flags" || k == "type" || k == "img" {
-				continue
-			}
-			data[k] = v
+		entry, err := ConvertDocument(packRaw)
+		if err != nil {
+			return nil, err
 		}
 
-		entries = append(entries, snd.Entry{
-			ID:   pack.ID,
-			Name: pack.Name,
-			Data: data,
-		})
+		entries = append(entries, entry)
 	}
 
 	return entries, nil
 }
 
+// packPath returns the path of a pack. FoundryVTT v11+ migrates .db packs to LevelDB folders of the
+// same name without extension, but the manifest of older modules still points to the .db file.
+func packPath(moduleFile string, path string) string {
+	path = filepath.Join(filepath.Dir(moduleFile), "/", path)
+	if filepath.Ext(path) != ".db" {
+		return path
+	}
+
+	if folder := strings.TrimSuffix(path, ".db"); IsLevelDBPack(folder) {
+		return folder
+	}
+
+	return path
+}
+
 // ConvertDataSources parses a FoundryVTT module.json or system.json file and converts all the specified packs to
 // S&D data sources and entries.
 func ConvertDataSources(moduleFile string) ([]snd.DataSource, [][]snd.Entry, error) {
@@ -168,9 +206,9 @@ func ConvertDataSources(moduleFile string) ([]snd.DataSource, [][]snd.Entry, err
 	var sources []snd.DataSource
 	var sourceEntries [][]snd.Entry
 	for i := range mod.Packs {
-		fmt.Println(mod.Packs[i].Path)
+		log.Info("converting pack", log.WithValue("path", mod.Packs[i].Path))
 
-		entries, err := ConvertPackEntries(filepath.Join(filepath.Dir(moduleFile), "/", mod.Packs[i].Path))
+		entries, err := ConvertPackEntries(packPath(moduleFile, mod.Packs[i].Path))
 		if err != nil {
 			return nil, nil, err
 		}
@@ -0,0 +1,420 @@
+package imexport
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BigJk/snd"
+	"github.com/xuri/excelize/v2"
+)
+
+// Columns of the entry id and name in a XLSX sheet. All other columns are the
+// flattened data of the entries.
+const (
+	XLSXIDColumn   = "_id"
+	XLSXNameColumn = "_name"
+)
+
+var xlsxMetaHeader = []any{"Name", "Author", "Slug", "Description", "Version"}
+
+var invalidSheetNameRegex = regexp.MustCompile(`[\[\]:*?/\\]`)
+
+// sheetName returns a valid and unique name for the sheet of the data source.
+func sheetName(name string, used map[string]bool) string {
+	name = strings.TrimSpace(invalidSheetNameRegex.ReplaceAllString(name, ""))
+	if len(name) == 0 {
+		name = "Sheet"
+	}
+
+	base := []rune(name)
+	for i := 1; ; i++ {
+		candidate := base
+		suffix := ""
+		if i > 1 {
+			suffix = fmt.Sprintf(" (%d)", i)
+		}
+
+		// Sheet names can be at most 31 characters long
+		if len(candidate)+len(suffix) > 31 {
+			candidate = candidate[:31-len(suffix)]
+		}
+
+		res := string(candidate) + suffix
+		if !used[strings.ToLower(res)] {
+			used[strings.ToLower(res)] = true
+			return res
+		}
+	}
+}
+
+// comparePaths sorts dotted paths, comparing array indices as numbers so that "a.2" comes before "a.10".
+func comparePaths(a string, b string) bool {
+	aParts := strings.Split(a, ".")
+	bParts := strings.Split(b, ".")
+
+	for i := 0; i < len(aParts) && i < len(bParts); i++ {
+		if aParts[i] == bParts[i] {
+			continue
+		}
+
+		aNum, aErr := strconv.Atoi(aParts[i])
+		bNum, bErr := strconv.Atoi(bParts[i])
+		if aErr == nil && bErr == nil {
+			return aNum < bNum
+		}
+
+		return aParts[i] < bParts[i]
+	}
+
+	return len(aParts) < len(bParts)
+}
+
+// xlsxValue converts a flattened value to a cell value.
+func xlsxValue(value any) any {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case string, float64, int, bool:
+		return v
+	default:
+		// Empty objects and arrays
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+}
+
+// ExportSourcesXLSX exports the data sources as XLSX workbook with one sheet per data source.
+//
+// Each sheet starts with the meta information of the data source followed by a header with the
+// columns of the entries. Nested data is flattened to columns with dotted paths, example:
+//
+//	Name, Author, Slug, Description, Version
+//	Loot, BigJk, loot, Some loot, 1.0.0
+//	_id, _name, name, price.gp, tags.0, tags.1
+//	sword, Sword, Sword, 10, weapon, melee
+//	...
+func ExportSourcesXLSX(sources []snd.DataSource, entries [][]snd.Entry, writer io.Writer) error {
+	if len(sources) == 0 {
+		return errors.New("no data sources to export")
+	}
+
+	file := excelize.NewFile()
+	defer file.Close()
+
+	defaultSheet := file.GetSheetList()[0]
+	used := map[string]bool{}
+
+	for i := range sources {
+		sheet := sheetName(sources[i].Name, used)
+		if i == 0 {
+			if err := file.SetSheetName(defaultSheet, sheet); err != nil {
+				return err
+			}
+		} else if _, err := file.NewSheet(sheet); err != nil {
+			return err
+		}
+
+		if err := writeSourceSheet(file, sheet, sources[i], entries[i]); err != nil {
+			return err
+		}
+	}
+
+	_, err := file.WriteTo(writer)
+	return err
+}
+
+func writeSourceSheet(file *excelize.File, sheet string, ds snd.DataSource, entries []snd.Entry) error {
+	if err := file.SetSheetRow(sheet, "A1", &xlsxMetaHeader); err != nil {
+		return err
+	}
+
+	if err := file.SetSheetRow(sheet, "A2", &[]any{ds.Name, ds.Author, ds.Slug, ds.Description, ds.Version}); err != nil {
+		return err
+	}
+
+	flat := make([]map[string]any, len(entries))
+	columnSet := map[string]bool{}
+	for i := range entries {
+		flat[i] = Flatten(entries[i].Data)
+		for k := range flat[i] {
+			columnSet[k] = true
+		}
+	}
+
+	var columns []string
+	for k := range columnSet {
+		columns = append(columns, k)
+	}
+	sort.Slice(columns, func(i, j int) bool {
+		return comparePaths(columns[i], columns[j])
+	})
+
+	header := []any{XLSXIDColumn, XLSXNameColumn}
+	for _, c := range columns {
+		header = append(header, c)
+	}
+
+	if err := file.SetSheetRow(sheet, "A3", &header); err != nil {
+		return err
+	}
+
+	for i := range entries {
+		row := []any{entries[i].ID, entries[i].Name}
+		for _, c := range columns {
+			row = append(row, xlsxValue(flat[i][c]))
+		}
+
+		cell, err := excelize.CoordinatesToCellName(1, i+4)
+		if err != nil {
+			return err
+		}
+
+		if err := file.SetSheetRow(sheet, cell, &row); err != nil {
+			return err
+		}
+	}
+
+	return file.SetPanes(sheet, &excelize.Panes{Freeze: true, YSplit: 3, TopLeftCell: "A4", ActivePane: "bottomLeft"})
+}
+
+// ExportSourcesXLSXFile exports the data sources as XLSX workbook to the folder.
+//
+// The function returns the path of the written file. A single data source is written to a file with the
+// pattern "{ds.Author}_{ds.Slug}.xlsx", multiple data sources to "data_sources.xlsx".
+func ExportSourcesXLSXFile(sources []snd.DataSource, entries [][]snd.Entry, folder string) (string, error) {
+	buf := &bytes.Buffer{}
+	if err := ExportSourcesXLSX(sources, entries, buf); err != nil {
+		return "", err
+	}
+
+	name := "data_sources.xlsx"
+	if len(sources) == 1 {
+		name = fmt.Sprintf("%s_%s.xlsx", sources[0].Author, sources[0].Slug)
+	}
+
+	path := filepath.Join(folder, name)
+	return path, os.WriteFile(path, buf.Bytes(), 0666)
+}
+
+// ImportSourcesXLSX imports all sheets of a XLSX workbook as data sources. The sheets need
+// to have the layout that ExportSourcesXLSX creates. The id and name columns are optional,
+// if they are missing the row number and the "name" column are used.
+//
+// The type of each column is inferred from its values: columns that only contain numbers
+// are imported as numbers and columns that only contain TRUE or FALSE as booleans.
+func ImportSourcesXLSX(reader io.Reader) ([]snd.DataSource, [][]snd.Entry, error) {
+	file, err := excelize.OpenReader(reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+
+	var sources []snd.DataSource
+	var allEntries [][]snd.Entry
+	for _, sheet := range file.GetSheetList() {
+		rows, err := file.GetRows(sheet)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if len(rows) == 0 {
+			continue
+		}
+
+		ds, entries, err := readSourceSheet(rows)
+		if err != nil {
+			return nil, nil, fmt.Errorf("sheet '%s': %w", sheet, err)
+		}
+
+		sources = append(sources, ds)
+		allEntries = append(allEntries, entries)
+	}
+
+	if len(sources) == 0 {
+		return nil, nil, errors.New("workbook doesn't contain any data source")
+	}
+
+	return sources, allEntries, nil
+}
+
+// ImportSourcesXLSXFile imports all sheets of a XLSX file as data sources.
+func ImportSourcesXLSXFile(file string) ([]snd.DataSource, [][]snd.Entry, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	return ImportSourcesXLSX(f)
+}
+
+func readSourceSheet(rows [][]string) (snd.DataSource, []snd.Entry, error) {
+	cell := func(row []string, i int) string {
+		if i < len(row) {
+			return strings.TrimSpace(row[i])
+		}
+		return ""
+	}
+
+	if len(rows) < 3 || !strings.EqualFold(cell(rows[0], 0), "Name") || cell(rows[1], 0) == "" {
+		return snd.DataSource{}, nil, errors.New("meta information (name, author, slug, description) not present in the first two rows")
+	}
+
+	ds := snd.DataSource{
+		Name:        cell(rows[1], 0),
+		Author:      cell(rows[1], 1),
+		Slug:        cell(rows[1], 2),
+		Description: cell(rows[1], 3),
+		Version:     cell(rows[1], 4),
+	}
+
+	header := rows[2]
+	values := rows[3:]
+	types := inferColumnTypes(header, values)
+
+	var entries []snd.Entry
+	for i := range values {
+		flat := map[string]any{}
+		id := ""
+		name := ""
+
+		for j := range header {
+			column := strings.TrimSpace(header[j])
+			value := cell(values[i], j)
+			if len(column) == 0 || len(value) == 0 {
+				continue
+			}
+
+			switch column {
+			case XLSXIDColumn:
+				id = value
+			case XLSXNameColumn:
+				name = value
+			default:
+				flat[column] = convertCell(value, types[j])
+			}
+		}
+
+		if len(flat) == 0 && id == "" && name == "" {
+			continue
+		}
+
+		// The values start in the fourth row of the sheet
+		data, err := Unflatten(flat)
+		if err != nil {
+			return snd.DataSource{}, nil, fmt.Errorf("row %d: %w", i+4, err)
+		}
+
+		if id == "" {
+			id = fmt.Sprint(i)
+		}
+
+		if name == "" {
+			if dataName, ok := data["name"].(string); ok {
+				name = dataName
+			} else {
+				name = id
+			}
+		}
+
+		entries = append(entries, snd.Entry{
+			ID:   id,
+			Name: name,
+			Data: data,
+		})
+	}
+
+	// Validate the same way as the other imports
+	dsBytes, _ := json.Marshal(ds)
+	entriesBytes, _ := json.Marshal(entries)
+
+	return ImportSource(&csvReaderWrapper{
+		ds:      dsBytes,
+		entries: entriesBytes,
+	})
+}
+
+const (
+	columnString = iota
+	columnNumber
+	columnBool
+)
+
+// inferColumnTypes checks which type all non-empty values of a column have.
+func inferColumnTypes(header []string, rows [][]string) []int {
+	types := make([]int, len(header))
+	for j := range header {
+		isNumber, isBool, empty := true, true, true
+		for i := range rows {
+			if j >= len(rows[i]) {
+				continue
+			}
+
+			value := strings.TrimSpace(rows[i][j])
+			if len(value) == 0 {
+				continue
+			}
+			empty = false
+
+			if _, ok := parseNumber(value); !ok {
+				isNumber = false
+			}
+
+			if !strings.EqualFold(value, "true") && !strings.EqualFold(value, "false") {
+				isBool = false
+			}
+		}
+
+		switch {
+		case empty:
+			types[j] = columnString
+		case isNumber:
+			types[j] = columnNumber
+		case isBool:
+			types[j] = columnBool
+		}
+	}
+	return types
+}
+
+// parseNumber parses the value as number. Values with leading zeros like "007" are
+// usually codes and not numbers, so they are kept as text.
+func parseNumber(value string) (float64, bool) {
+	digits := strings.TrimPrefix(value, "-")
+	if len(digits) > 1 && digits[0] == '0' && digits[1] != '.' {
+		return 0, false
+	}
+
+	f, err := strconv.ParseFloat(value, 64)
+	return f, err == nil
+}
+
+func convertCell(value string, columnType int) any {
+	switch columnType {
+	case columnNumber:
+		if f, ok := parseNumber(value); ok {
+			return f
+		}
+	case columnBool:
+		return strings.EqualFold(value, "true")
+	}
+
+	// Empty objects and arrays that were exported
+	if value == "{}" {
+		return map[string]any{}
+	}
+	if value == "[]" {
+		return []any{}
+	}
+
+	return value
+}
@@ -8,6 +8,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/BigJk/snd"
 )
@@ -18,6 +19,11 @@ type ZipImportReader struct {
 }
 
 func (z *ZipImportReader) ReadFile(s string) ([]byte, error) {
+	s, err := cleanPath(s)
+	if err != nil {
+		return nil, err
+	}
+
 	metaFs, err := z.reader.Open(s)
 	if err != nil {
 		return nil, err
@@ -32,6 +38,17 @@ func (z *ZipImportReader) ReadFile(s string) ([]byte, error) {
 	return data, nil
 }
 
+func (z *ZipImportReader) ListFiles(folder string) ([]string, error) {
+	var files []string
+	prefix := strings.TrimSuffix(folder, "/") + "/"
+	for _, file := range z.reader.File {
+		if strings.HasPrefix(file.Name, prefix) && !strings.HasSuffix(file.Name, "/") {
+			files = append(files, strings.TrimPrefix(file.Name, prefix))
+		}
+	}
+	return files, nil
+}
+
 // ZipExportWriter represents a writer that writes files to a zip.
 type ZipExportWriter struct {
 	writer *zip.Writer
@@ -57,12 +74,13 @@ func (z *ZipExportWriter) WriteFile(file string, data []byte) error {
 // - skeleton.json
 // - entries.json
 //
-// The function returns the advised name for the zip file with the pattern "{tmpl.Autor}_{tmpl.Slug}.zip".
-func ExportTemplateZIP(tmpl snd.Template, entries []snd.Entry, writer io.Writer) (string, error) {
+// The attachments are written beside them. The function returns the advised name for the zip file
+// with the pattern "{tmpl.Autor}_{tmpl.Slug}.zip".
+func ExportTemplateZIP(tmpl snd.Template, entries []snd.Entry, attachments Attachments, writer io.Writer) (string, error) {
 	zipper := zip.NewWriter(writer)
 	defer zipper.Close()
 
-	return fmt.Sprintf("%s_%s.zip", tmpl.Author, tmpl.Slug), ExportTemplate(tmpl, entries, &ZipExportWriter{writer: zipper})
+	return fmt.Sprintf("%s_%s.zip", tmpl.Author, tmpl.Slug), ExportTemplate(tmpl, entries, attachments, &ZipExportWriter{writer: zipper})
 }
 
 // ExportTemplateZIPFile exports the template and entries as a zip file.
@@ -74,10 +92,11 @@ func ExportTemplateZIP(tmpl snd.Template, entries []snd.Entry, writer io.Writer)
 // - skeleton.json
 // - entries.json
 //
-// The function returns the location where the file was written to as "{folder}/{tmpl.Autor}_{tmpl.Slug}.zip".
-func ExportTemplateZIPFile(tmpl snd.Template, entries []snd.Entry, folder string) (string, error) {
+// The attachments are written beside them. The function returns the location where the file was
+// written to as "{folder}/{tmpl.Autor}_{tmpl.Slug}.zip".
+func ExportTemplateZIPFile(tmpl snd.Template, entries []snd.Entry, attachments Attachments, folder string) (string, error) {
 	buf := &bytes.Buffer{}
-	file, err := ExportTemplateZIP(tmpl, entries, buf)
+	file, err := ExportTemplateZIP(tmpl, entries, attachments, buf)
 	if err != nil {
 		return "", err
 	}
@@ -94,10 +113,10 @@ func ExportTemplateZIPFile(tmpl snd.Template, entries []snd.Entry, folder string
 // - list.html.njk
 // - skeleton.json
 // - entries.json
-func ImportTemplateZIP(reader io.ReaderAt, size int64) (snd.Template, []snd.Entry, error) {
+func ImportTemplateZIP(reader io.ReaderAt, size int64) (snd.Template, []snd.Entry, Attachments, error) {
 	zipper, err := zip.NewReader(reader, size)
 	if err != nil {
-		return snd.Template{}, nil, err
+		return snd.Template{}, nil, nil, err
 	}
 
 	return ImportTemplate(&ZipImportReader{reader: zipper})
@@ -111,16 +130,16 @@ func ImportTemplateZIP(reader io.ReaderAt, size int64) (snd.Template, []snd.Entr
 // - list.html.njk
 // - skeleton.json
 // - entries.json
-func ImportTemplateZIPFile(file string) (snd.Template, []snd.Entry, error) {
+func ImportTemplateZIPFile(file string) (snd.Template, []snd.Entry, Attachments, error) {
 	zipFile, err := os.Open(file)
 	if err != nil {
-		return snd.Template{}, nil, err
+		return snd.Template{}, nil, nil, err
 	}
 	defer zipFile.Close()
 
 	stat, err := zipFile.Stat()
 	if err != nil {
-		return snd.Template{}, nil, err
+		return snd.Template{}, nil, nil, err
 	}
 
 	return ImportTemplateZIP(zipFile, stat.Size())
@@ -257,3 +276,73 @@ func ImportGeneratorZIPFile(file string) (snd.Generator, error) {
 
 	return ImportGeneratorZIP(zipFile, stat.Size())
 }
+
+// ExportPromptZIP exports the prompt as a zip file.
+//
+// Following files will be created in the zip:
+// - meta.json
+// - system.txt
+// - user.txt
+//
+// The function returns the advised name for the zip file with the pattern "prompt_{prompt.Autor}_{prompt.Slug}.zip".
+func ExportPromptZIP(prompt snd.Prompt, writer io.Writer) (string, error) {
+	zipper := zip.NewWriter(writer)
+	defer zipper.Close()
+
+	return fmt.Sprintf("prompt_%s_%s.zip", prompt.Author, prompt.Slug), ExportPrompt(prompt, &ZipExportWriter{writer: zipper})
+}
+
+// ExportPromptZIPFile exports the prompt as a zip file.
+//
+// Following files will be created in the zip:
+// - meta.json
+// - system.txt
+// - user.txt
+//
+// The function returns the location where the file was written to as "{folder}/prompt_{prompt.Autor}_{prompt.Slug}.zip".
+func ExportPromptZIPFile(prompt snd.Prompt, folder string) (string, error) {
+	buf := &bytes.Buffer{}
+	file, err := ExportPromptZIP(prompt, buf)
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(folder, file)
+	return path, ioutil.WriteFile(path, buf.Bytes(), 0666)
+}
+
+// ImportPromptZIP will import a prompt from a given zip.
+//
+// Following files are needed in the zip:
+// - meta.json
+// - system.txt
+// - user.txt
+func ImportPromptZIP(reader io.ReaderAt, size int64) (snd.Prompt, error) {
+	zipper, err := zip.NewReader(reader, size)
+	if err != nil {
+		return snd.Prompt{}, err
+	}
+
+	return ImportPrompt(&ZipImportReader{reader: zipper})
+}
+
+// ImportPromptZIPFile will import a prompt from a given zip file.
+//
+// Following files are needed:
+// - meta.json
+// - system.txt
+// - user.txt
+func ImportPromptZIPFile(file string) (snd.Prompt, error) {
+	zipFile, err := os.Open(file)
+	if err != nil {
+		return snd.Prompt{}, err
+	}
+	defer zipFile.Close()
+
+	stat, err := zipFile.Stat()
+	if err != nil {
+		return snd.Prompt{}, err
+	}
+
+	return ImportPromptZIP(zipFile, stat.Size())
+}
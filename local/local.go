@@ -0,0 +1,163 @@
+// Package local builds the urls of the server that runs in this process. The server sets its
+// scheme, port and certificate when it starts, so that packages that can't import the server,
+// like the rendering of templates, can reach it over http and https.
+package local
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	mtx         sync.RWMutex
+	scheme      = "http"
+	port        = "7123"
+	certificate []byte
+
+	ready     = make(chan struct{})
+	readyOnce sync.Once
+)
+
+// Set sets how the server is reached. The certificate is the DER encoded certificate of the
+// server if it uses https, otherwise nil.
+func Set(newScheme string, newPort string, cert []byte) {
+	mtx.Lock()
+	scheme = newScheme
+	port = newPort
+	certificate = cert
+	mtx.Unlock()
+
+	readyOnce.Do(func() { close(ready) })
+}
+
+// Wait waits until the server is set or the timeout is reached.
+func Wait(timeout time.Duration) bool {
+	select {
+	case <-ready:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// Scheme returns the scheme of the server, either "http" or "https".
+func Scheme() string {
+	mtx.RLock()
+	defer mtx.RUnlock()
+	return scheme
+}
+
+// URL returns the url of the path on the server via the loopback address,
+// e.g. "http://127.0.0.1:7123/api/html/1".
+func URL(path string) string {
+	return HostURL("127.0.0.1", path)
+}
+
+// HostURL returns the url of the path on the server via the given host, e.g. the address
+// of the machine in the local network.
+func HostURL(host string, path string) string {
+	mtx.RLock()
+	defer mtx.RUnlock()
+	return fmt.Sprintf("%s://%s%s", scheme, net.JoinHostPort(host, port), path)
+}
+
+// Certificate returns the DER encoded certificate of the server or nil if it doesn't use https.
+func Certificate() []byte {
+	mtx.RLock()
+	defer mtx.RUnlock()
+	return certificate
+}
+
+// SPKIHash returns the base64 encoded SHA-256 hash of the public key of the certificate, which
+// Chrome and Electron accept with the --ignore-certificate-errors-spki-list switch to trust a
+// self-signed certificate. It is empty if the server doesn't use https.
+func SPKIHash() (string, error) {
+	cert := Certificate()
+	if len(cert) == 0 {
+		return "", nil
+	}
+
+	parsed, err := x509.ParseCertificate(cert)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(parsed.RawSubjectPublicKeyInfo)
+	return base64.StdEncoding.EncodeToString(sum[:]), nil
+}
+
+// Client returns a http client for the server. If the server uses https only its certificate is
+// trusted, so that a self-signed certificate works without trusting every certificate.
+func Client() *http.Client {
+	cert := Certificate()
+	if len(cert) == 0 {
+		return &http.Client{Timeout: time.Minute}
+	}
+
+	return &http.Client{
+		Timeout: time.Minute,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				// The default verification is replaced by the comparison with the certificate
+				InsecureSkipVerify: true,
+				VerifyPeerCertificate: func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+					if len(rawCerts) == 0 || subtle.ConstantTimeCompare(rawCerts[0], cert) != 1 {
+						return errors.New("certificate doesn't match the certificate of the server")
+					}
+					return nil
+				},
+			},
+		},
+	}
+}
+
+// Fingerprint returns the SHA-256 fingerprint of a DER encoded certificate as upper case hex,
+// which the server logs when it starts.
+func Fingerprint(der []byte) string {
+	sum := sha256.Sum256(der)
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+// PinnedClient returns a http client that only trusts the certificate with the SHA-256
+// fingerprint, so that a self-signed certificate works without trusting it as a CA. Colons
+// and the case of the fingerprint are ignored. Without fingerprint the certificate is verified
+// as usual.
+func PinnedClient(fingerprint string, timeout time.Duration) *http.Client {
+	if len(fingerprint) == 0 {
+		return &http.Client{Timeout: timeout}
+	}
+
+	fingerprint = strings.ToUpper(strings.ReplaceAll(fingerprint, ":", ""))
+
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				// The default verification is replaced by the comparison of the fingerprint
+				InsecureSkipVerify: true,
+				VerifyPeerCertificate: func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+					if len(rawCerts) == 0 {
+						return errors.New("no certificate")
+					}
+
+					if subtle.ConstantTimeCompare([]byte(Fingerprint(rawCerts[0])), []byte(fingerprint)) != 1 {
+						return errors.New("certificate doesn't match the fingerprint")
+					}
+
+					return nil
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,96 @@
+package local
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestURL(t *testing.T) {
+	Set("http", "7123", nil)
+	if url := URL("/api/html/1"); url != "http://127.0.0.1:7123/api/html/1" {
+		t.Fatalf("unexpected url %s", url)
+	}
+
+	Set("https", "8443", nil)
+	if url := HostURL("::1", "/api/fonts/"); url != "https://[::1]:8443/api/fonts/" {
+		t.Fatalf("unexpected url %s", url)
+	}
+}
+
+func TestClientTrustsOnlyTheServerCertificate(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cert := server.Certificate()
+	Set("https", "7123", cert.Raw)
+
+	resp, err := Client().Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+
+	parsed, err := x509.ParseCertificate(cert.Raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(parsed.RawSubjectPublicKeyInfo)
+
+	spki, err := SPKIHash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if spki != base64.StdEncoding.EncodeToString(sum[:]) {
+		t.Fatalf("unexpected spki hash %s", spki)
+	}
+
+	// Another certificate, e.g. of a server that replaced the local one, is rejected
+	Set("https", "7123", []byte("other certificate"))
+	if _, err := Client().Get(server.URL); err == nil {
+		t.Fatal("expected the certificate to be rejected")
+	}
+
+	if !Wait(time.Second) {
+		t.Fatal("expected the server to be set")
+	}
+}
+
+func TestPinnedClient(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	fingerprint := Fingerprint(server.Certificate().Raw)
+
+	// The fingerprint can be written with colons and in lower case
+	var colons []string
+	for i := 0; i < len(fingerprint); i += 2 {
+		colons = append(colons, strings.ToLower(fingerprint[i:i+2]))
+	}
+
+	for _, pin := range []string{fingerprint, strings.Join(colons, ":")} {
+		resp, err := PinnedClient(pin, time.Minute).Get(server.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+	}
+
+	if _, err := PinnedClient(Fingerprint([]byte("other certificate")), time.Minute).Get(server.URL); err == nil {
+		t.Fatal("expected the certificate to be rejected")
+	}
+
+	// Without fingerprint the self-signed certificate isn't trusted
+	if _, err := PinnedClient("", time.Minute).Get(server.URL); err == nil {
+		t.Fatal("expected the certificate to be verified")
+	}
+}
@@ -1,5 +1,11 @@
 // Package rsnd provides printing for Sales & Dungeons via a another S&D instance.
 // The printer commands will be sent as http post request to the given endpoint.
+//
+// The endpoint is either the ip of the other instance or its url, e.g. "https://192.168.1.5:7123".
+// If the other instance uses a self-signed certificate its SHA-256 fingerprint, which is logged
+// when the instance starts, can be added to trust it: "https://192.168.1.5:7123#FINGERPRINT".
+// If the other instance requires authentication an API token can be added as user:
+// "https://snd_token@192.168.1.5:7123".
 package rsnd
 
 import (
@@ -9,6 +15,11 @@ import (
 	"image"
 	"io/ioutil"
 	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/BigJk/snd/local"
 )
 
 type RemoteSND struct{}
@@ -18,7 +29,7 @@ func (r *RemoteSND) Name() string {
 }
 
 func (r *RemoteSND) Description() string {
-	return "Print via another Sales & Dungeons instance in your network. Endpoint should be the ip."
+	return "Print via another Sales & Dungeons instance in your network. Endpoint should be the ip or the url (e.g. https://192.168.1.5:7123). Add #FINGERPRINT to trust a self-signed certificate and token@ before the host if a login is required."
 }
 
 func (r *RemoteSND) AvailableEndpoints() (map[string]string, error) {
@@ -26,10 +37,32 @@ func (r *RemoteSND) AvailableEndpoints() (map[string]string, error) {
 }
 
 func (r *RemoteSND) Print(printerEndpoint string, image image.Image, data []byte) error {
-	resp, err := http.Post(fmt.Sprintf("http://%s:7123/api/extern/print_raw", printerEndpoint), "application/octet-stream", bytes.NewBuffer(data))
+	endpoint, err := parseEndpoint(printerEndpoint)
+	if err != nil {
+		return err
+	}
+
+	// The token and fingerprint are not part of the request url
+	token := endpoint.User.Username()
+	fingerprint := endpoint.Fragment
+	endpoint.User = nil
+	endpoint.Fragment = ""
+	endpoint.Path = "/api/extern/print_raw"
+
+	req, err := http.NewRequest(http.MethodPost, endpoint.String(), bytes.NewBuffer(data))
 	if err != nil {
 		return err
 	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	if len(token) > 0 {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := client(fingerprint).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
 
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
@@ -42,3 +75,31 @@ func (r *RemoteSND) Print(printerEndpoint string, image image.Image, data []byte
 
 	return nil
 }
+
+// parseEndpoint parses the endpoint. A plain ip or host uses http and the default port.
+func parseEndpoint(endpoint string) (*url.URL, error) {
+	if !strings.Contains(endpoint, "://") {
+		endpoint = fmt.Sprintf("http://%s:7123", endpoint)
+	}
+
+	parsed, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported scheme '%s'", parsed.Scheme)
+	}
+
+	if len(parsed.Port()) == 0 {
+		parsed.Host = parsed.Hostname() + ":7123"
+	}
+
+	return parsed, nil
+}
+
+// client creates the http client. If a fingerprint is given only the certificate with this
+// fingerprint is trusted, which allows self-signed certificates.
+func client(fingerprint string) *http.Client {
+	return local.PinnedClient(fingerprint, time.Minute)
+}
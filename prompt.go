@@ -0,0 +1,117 @@
+package snd
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// PromptVariable is a variable that can be used in the system and user prompt as {{key}}.
+type PromptVariable struct {
+	Key         string `json:"key"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Default     string `json:"default"`
+}
+
+// PromptRevision is a previous version of a prompt.
+type PromptRevision struct {
+	Version   string           `json:"version"`
+	System    string           `json:"system"`
+	User      string           `json:"user"`
+	Variables []PromptVariable `json:"variables"`
+	Date      time.Time        `json:"date"`
+}
+
+// Prompt represents a reusable AI prompt with variables.
+type Prompt struct {
+	Name        string           `json:"name"`
+	Slug        string           `json:"slug"`
+	Author      string           `json:"author"`
+	Description string           `json:"description"`
+	System      string           `json:"system"`
+	User        string           `json:"user"`
+	Variables   []PromptVariable `json:"variables"`
+	Version     string           `json:"version"`
+	// History contains the previous versions of the prompt, the newest last.
+	History []PromptRevision `json:"history"`
+}
+
+func (p Prompt) ID() string {
+	return fmt.Sprintf("prompt:%s+%s", p.Author, p.Slug)
+}
+
+func IsPromptID(id string) bool {
+	return strings.HasPrefix(id, "prompt:")
+}
+
+// Revision returns the current version of the prompt as revision.
+func (p Prompt) Revision() PromptRevision {
+	return PromptRevision{
+		Version:   p.Version,
+		System:    p.System,
+		User:      p.User,
+		Variables: p.Variables,
+		Date:      time.Now(),
+	}
+}
+
+// AtVersion returns the prompt with the system prompt, user prompt and variables of the version.
+// If the version was saved multiple times the newest one is used. An empty version returns the
+// current prompt.
+func (p Prompt) AtVersion(version string) (Prompt, error) {
+	if version == "" || version == p.Version {
+		return p, nil
+	}
+
+	for i := len(p.History) - 1; i >= 0; i-- {
+		if p.History[i].Version == version {
+			p.Version = version
+			p.System = p.History[i].System
+			p.User = p.History[i].User
+			p.Variables = p.History[i].Variables
+			return p, nil
+		}
+	}
+
+	return Prompt{}, fmt.Errorf("version '%s' of prompt '%s' not found", version, p.Name)
+}
+
+var promptVariableRegex = regexp.MustCompile(`\{\{\s*([a-zA-Z0-9_\-.]+)\s*\}\}`)
+
+// Render replaces the variables in the system and user prompt. Values that are not given use the
+// default of the variable. An error is returned if a used variable has neither.
+func (p Prompt) Render(values map[string]string) (string, string, error) {
+	defaults := map[string]string{}
+	for _, v := range p.Variables {
+		defaults[v.Key] = v.Default
+	}
+
+	var missing []string
+	replace := func(text string) string {
+		return promptVariableRegex.ReplaceAllStringFunc(text, func(match string) string {
+			key := promptVariableRegex.FindStringSubmatch(match)[1]
+
+			if val, ok := values[key]; ok {
+				return val
+			}
+
+			if val, ok := defaults[key]; ok {
+				return val
+			}
+
+			missing = append(missing, key)
+			return match
+		})
+	}
+
+	system := replace(p.System)
+	user := replace(p.User)
+
+	if len(missing) > 0 {
+		return "", "", fmt.Errorf("missing values for variables: %s", strings.Join(missing, ", "))
+	}
+
+	return system, user, nil
+}
@@ -0,0 +1,55 @@
+//go:build !android
+
+package rendering
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/BigJk/snd/auth"
+	"github.com/go-rod/rod/lib/launcher"
+	"github.com/labstack/echo/v4"
+)
+
+func TestRenderWithAuthentication(t *testing.T) {
+	if _, ok := launcher.LookPath(); !ok {
+		t.Skip("chrome is not installed")
+	}
+
+	store, err := auth.Open("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SaveUser("admin", "password123", auth.RoleAdmin); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SetEnabled(true); err != nil {
+		t.Fatal(err)
+	}
+
+	routes := auth.NewRoutes()
+	routes.Set(http.MethodGet, "/api/html/:id", auth.Route{Group: auth.GroupPrint})
+
+	e := echo.New()
+	e.Use(auth.Middleware(store, routes))
+	e.GET("/api/html/:id", func(c echo.Context) error {
+		return c.HTML(http.StatusOK, `<div id="content">rendered `+c.Param("id")+`</div>`)
+	})
+
+	server := httptest.NewServer(e)
+	defer server.Close()
+
+	SetLocalCookie(auth.CookieName, auth.RendererToken())
+	InitBrowser()
+	defer Shutdown()
+
+	html, err := ExtractHTML(server.URL+"/api/html/1", "#content")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(html, "rendered 1") {
+		t.Fatalf("expected the rendered page, got %s", html)
+	}
+}
@@ -0,0 +1,16 @@
+package rendering
+
+// MaxHeight is the maximum height in pixels a page can have to be rendered into an image.
+const MaxHeight = 9500
+
+// Inspection contains the information about a rendered page that is useful to find
+// problems in templates.
+type Inspection struct {
+	// Width is the width of the content. It is larger than the requested width if
+	// the content overflows.
+	Width int `json:"width"`
+	// Height is the height of the content.
+	Height int `json:"height"`
+	// ConsoleErrors contains the errors logged to the console and uncaught exceptions.
+	ConsoleErrors []string `json:"consoleErrors"`
+}
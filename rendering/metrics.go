@@ -0,0 +1,137 @@
+package rendering
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// QueueTimeout is the maximum time a render request waits for a free slot of the pool.
+// The rendering itself is only limited by the context of the request.
+const QueueTimeout = time.Second * 30
+
+// Metrics represents a snapshot of the rendering statistics.
+type Metrics struct {
+	PoolSize      int     `json:"poolSize"`
+	Pages         int     `json:"pages"`
+	Busy          int     `json:"busy"`
+	Queued        int     `json:"queued"`
+	Requests      uint64  `json:"requests"`
+	Failed        uint64  `json:"failed"`
+	Canceled      uint64  `json:"canceled"`
+	Waited        uint64  `json:"waited"`
+	AvgWaitMs     float64 `json:"avgWaitMs"`
+	AvgRenderMs   float64 `json:"avgRenderMs"`
+	TotalRenderMs int64   `json:"totalRenderMs"`
+}
+
+type metricsCollector struct {
+	sync.Mutex
+	queued   int
+	busy     int
+	requests uint64
+	started  uint64
+	failed   uint64
+	canceled uint64
+	waited   uint64
+	waitTime time.Duration
+	runTime  time.Duration
+}
+
+var metrics = &metricsCollector{}
+
+func (m *metricsCollector) enqueue() {
+	m.Lock()
+	defer m.Unlock()
+	m.queued++
+}
+
+// start records that a request got a slot. Only requests that had to wait for a slot
+// count towards the average wait time.
+func (m *metricsCollector) start(waited bool, took time.Duration) {
+	m.Lock()
+	defer m.Unlock()
+	m.queued--
+	m.busy++
+	if waited {
+		m.waited++
+		m.waitTime += took
+	}
+}
+
+// finish records the outcome of a request. If started is false the request
+// never left the queue.
+func (m *metricsCollector) finish(started bool, took time.Duration, err error) {
+	m.Lock()
+	defer m.Unlock()
+
+	if started {
+		m.busy--
+		m.started++
+		m.runTime += took
+	} else {
+		m.queued--
+	}
+
+	m.requests++
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			m.canceled++
+		} else {
+			m.failed++
+		}
+	}
+}
+
+func (m *metricsCollector) snapshot() Metrics {
+	m.Lock()
+	defer m.Unlock()
+
+	res := Metrics{
+		Busy:          m.busy,
+		Queued:        m.queued,
+		Requests:      m.requests,
+		Failed:        m.failed,
+		Canceled:      m.canceled,
+		Waited:        m.waited,
+		TotalRenderMs: m.runTime.Milliseconds(),
+	}
+
+	if m.waited > 0 {
+		res.AvgWaitMs = float64(m.waitTime.Milliseconds()) / float64(m.waited)
+	}
+	// Requests that never got a slot didn't render, so they don't count towards the average
+	if m.started > 0 {
+		res.AvgRenderMs = float64(m.runTime.Milliseconds()) / float64(m.started)
+	}
+
+	return res
+}
+
+// acquireSlot waits until the request gets one of the slots, the context is done or
+// QueueTimeout is reached.
+func acquireSlot(ctx context.Context, slots chan struct{}) error {
+	metrics.enqueue()
+
+	ctx, cancel := context.WithTimeout(ctx, QueueTimeout)
+	defer cancel()
+
+	select {
+	case slots <- struct{}{}:
+		metrics.start(false, 0)
+		return nil
+	default:
+	}
+
+	queuedAt := time.Now()
+
+	select {
+	case slots <- struct{}{}:
+		metrics.start(true, time.Since(queuedAt))
+		return nil
+	case <-ctx.Done():
+		metrics.finish(false, 0, ctx.Err())
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,50 @@
+package rendering
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestAcquireSlotCountsOnlyWaitingRequests(t *testing.T) {
+	metrics = &metricsCollector{}
+	slots := make(chan struct{}, 1)
+
+	// Gets the free slot without waiting
+	if err := acquireSlot(context.Background(), slots); err != nil {
+		t.Fatal(err)
+	}
+
+	// Waits until the first request frees the slot
+	go func() {
+		time.Sleep(time.Millisecond * 50)
+		metrics.finish(true, time.Millisecond, nil)
+		<-slots
+	}()
+	if err := acquireSlot(context.Background(), slots); err != nil {
+		t.Fatal(err)
+	}
+	metrics.finish(true, time.Millisecond, nil)
+
+	// Never gets a slot
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+	if err := acquireSlot(ctx, slots); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+
+	res := metrics.snapshot()
+	if res.Requests != 3 || res.Waited != 1 || res.Canceled != 1 {
+		t.Fatalf("unexpected metrics: %+v", res)
+	}
+	if res.AvgWaitMs < 40 {
+		t.Fatalf("average wait should only include the waiting request, got %v", res.AvgWaitMs)
+	}
+	if res.AvgRenderMs != 1 {
+		t.Fatalf("average render time should only include started requests, got %v", res.AvgRenderMs)
+	}
+	if res.Busy != 0 || res.Queued != 0 {
+		t.Fatalf("expected no busy or queued requests: %+v", res)
+	}
+}
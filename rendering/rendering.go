@@ -4,23 +4,33 @@
 // It uses the Chrome Debug Protocol through the rod package. It will download a headless
 // Chrome version if needed that matches the current platform.
 //
+// Rendering happens on a bounded pool of reusable pages. Requests that exceed the pool size
+// are queued until a page is free or their context is done. The pool size defaults to the
+// number of CPUs (at most 4) and can be changed with the environment variable
+// SND_RENDER_POOL_SIZE or the render pool size in the settings.
+//
 // If the environment variable SND_CHROME_DEBUG=1 it will start the chrome instances
 // in non-headless mode.
 package rendering
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"image"
 	_ "image/png"
 	"net/url"
 	"os"
 	"runtime"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/BigJk/snd/log"
 	"github.com/go-rod/rod/lib/launcher"
 	"github.com/go-rod/rod/lib/launcher/flags"
+	"github.com/samber/lo"
 
 	"github.com/go-rod/rod"
 	"github.com/go-rod/rod/lib/proto"
@@ -29,7 +39,20 @@ import (
 const IdleRequestTimeout = 5
 const ReadyTimeout = 5
 
-var browser *rod.Browser
+var (
+	browserMtx sync.RWMutex
+	browser    *rod.Browser
+
+	cookieMtx sync.Mutex
+	cookies   []*proto.NetworkCookieParam
+
+	// trustedSPKI is the hash of the public key of the certificate the browser trusts. It is only
+	// accessed while holding browserMtx.
+	trustedSPKI string
+)
+
+// localHosts are the hosts the local cookies are set for.
+var localHosts = []string{"127.0.0.1", "localhost"}
 
 type AndroidRenderer interface {
 	RenderURL(url string, width int32) ([]byte, error)
@@ -39,10 +62,76 @@ type AndroidRenderer interface {
 func SetAndroidRenderer(renderer AndroidRenderer) {}
 
 func InitBrowser() {
+	browserMtx.Lock()
+	defer browserMtx.Unlock()
+
+	initBrowser()
+}
+
+// TrustCertificate makes the browser trust the certificate with the given SPKI hash, e.g. the
+// self-signed certificate of the local server. All other certificates are still verified. A
+// running browser is restarted, as the certificate can only be set when it is launched.
+func TrustCertificate(spkiHash string) {
+	browserMtx.Lock()
+	defer browserMtx.Unlock()
+
+	if trustedSPKI == spkiHash {
+		return
+	}
+
+	trustedSPKI = spkiHash
+	if browser != nil {
+		initBrowser()
+	}
+}
+
+// SetLocalCookie sets a cookie for the local server in the rendering browser, e.g. to authenticate
+// the pages that are rendered. The cookie is not sent to other hosts and kept if the browser restarts.
+func SetLocalCookie(name string, value string) {
+	cookieMtx.Lock()
+	cookies = lo.Filter(cookies, func(cookie *proto.NetworkCookieParam, _ int) bool { return cookie.Name != name })
+	for _, host := range localHosts {
+		cookies = append(cookies, &proto.NetworkCookieParam{
+			Name:     name,
+			Value:    value,
+			Domain:   host,
+			Path:     "/",
+			HTTPOnly: true,
+			SameSite: proto.NetworkCookieSameSiteLax,
+		})
+	}
+	cookieMtx.Unlock()
+
+	browserMtx.RLock()
+	defer browserMtx.RUnlock()
+
+	if browser != nil {
+		setCookies(browser)
+	}
+}
+
+// setCookies sets the local cookies in the browser.
+func setCookies(target *rod.Browser) {
+	cookieMtx.Lock()
+	defer cookieMtx.Unlock()
+
+	if len(cookies) == 0 {
+		return
+	}
+
+	if err := target.SetCookies(cookies); err != nil {
+		log.Error(err)
+	}
+}
+
+func initBrowser() {
 	if browser != nil {
 		_ = browser.Close()
 	}
 
+	// all pooled pages belonged to the old browser.
+	resetPool()
+
 	// if you want rod to connect to a custom chrome instance instead of downloading and
 	// auto connecting you can set the SND_CHROME_ADDR to the address of target instance.
 	// Input might look like: 2814, 127.0.0.1:2814, ws://127.0.0.1:2814, ...
@@ -52,8 +141,13 @@ func InitBrowser() {
 	// - https://github.com/go-rod/rod/blob/master/lib/examples/connect-browser/main.go#L21
 	customChromeUrl := os.Getenv("SND_CHROME_ADDR")
 	if len(customChromeUrl) > 0 {
+		if len(trustedSPKI) > 0 {
+			log.Info("the certificate of the server can't be trusted by a custom chrome instance, start it with --ignore-certificate-errors-spki-list", log.WithValue("spki", trustedSPKI))
+		}
+
 		u := launcher.MustResolveURL(customChromeUrl)
 		browser = rod.New().ControlURL(u).MustConnect()
+		setCookies(browser)
 		return
 	}
 
@@ -73,6 +167,11 @@ func InitBrowser() {
 		}
 	}
 
+	// only the certificate of the local server is trusted, other certificates are still verified.
+	if len(trustedSPKI) > 0 {
+		l.Set("ignore-certificate-errors-spki-list", trustedSPKI)
+	}
+
 	// disable leakless for now on windows (https://github.com/BigJk/snd/issues/28)
 	if runtime.GOOS == "windows" {
 		log.Info("disabling leakless on windows")
@@ -86,43 +185,252 @@ func InitBrowser() {
 	}
 
 	browser = rod.New().ControlURL(l.MustLaunch()).MustConnect()
+	setCookies(browser)
 }
 
 func Shutdown() error {
+	browserMtx.Lock()
+	defer browserMtx.Unlock()
+
 	if browser == nil {
 		return nil
 	}
+
+	resetPool()
 	return browser.Close()
 }
 
-func tryOpenPage(url string) (*rod.Page, error) {
-	if browser == nil {
-		return nil, errors.New("rendering browser is not initialized")
+// reconnect re-initializes the browser if it is still the stale one. This prevents
+// multiple failing requests from restarting the browser one after another.
+func reconnect(stale *rod.Browser) {
+	browserMtx.Lock()
+	defer browserMtx.Unlock()
+
+	if browser == stale {
+		initBrowser()
 	}
+}
 
+func tryOpenPage(url string) (*rod.Page, *rod.Browser, error) {
 	var page *rod.Page
 	var err error
 
 	for i := 0; i < 2; i++ {
-		page, err = browser.Page(proto.TargetCreateTarget{
+		browserMtx.RLock()
+		current := browser
+		browserMtx.RUnlock()
+
+		if current == nil {
+			return nil, nil, errors.New("rendering browser is not initialized")
+		}
+
+		page, err = current.Page(proto.TargetCreateTarget{
 			URL: url,
 		})
 
 		if err == nil {
-			return page, nil
+			return page, current, nil
 		}
 
 		// if the pc has gone into standby the cdp session closes,
 		// so we try to init it again and try once more.
-		InitBrowser()
+		reconnect(current)
 	}
 
 	// after retry return last error
-	return nil, err
+	return nil, nil, err
+}
+
+//
+// Page Pool
+//
+
+type pooledPage struct {
+	page    *rod.Page
+	browser *rod.Browser
+}
+
+var (
+	poolMtx   sync.Mutex
+	poolSize  = defaultPoolSize()
+	poolSlots = make(chan struct{}, poolSize)
+	poolIdle  []pooledPage
+	poolPages int
+)
+
+func defaultPoolSize() int {
+	if val, err := strconv.Atoi(os.Getenv("SND_RENDER_POOL_SIZE")); err == nil && val > 0 {
+		return val
+	}
+
+	size := runtime.NumCPU()
+	if size > 4 {
+		size = 4
+	}
+	return size
+}
+
+// SetPoolSize changes the maximum amount of pages that render at the same time. A size
+// below 1 restores the default. Requests that are currently running are not affected.
+func SetPoolSize(size int) {
+	if size < 1 {
+		size = defaultPoolSize()
+	}
+
+	poolMtx.Lock()
+	defer poolMtx.Unlock()
+
+	if size == poolSize {
+		return
+	}
+
+	poolSize = size
+	poolSlots = make(chan struct{}, size)
+}
+
+// resetPool forgets all idle pages. It is expected that the browser they belong to is closed.
+func resetPool() {
+	poolMtx.Lock()
+	defer poolMtx.Unlock()
+
+	poolPages -= len(poolIdle)
+	poolIdle = nil
+}
+
+func takePage() (pooledPage, error) {
+	poolMtx.Lock()
+	if len(poolIdle) > 0 {
+		p := poolIdle[len(poolIdle)-1]
+		poolIdle = poolIdle[:len(poolIdle)-1]
+		poolMtx.Unlock()
+		return p, nil
+	}
+	poolMtx.Unlock()
+
+	page, owner, err := tryOpenPage("about:blank")
+	if err != nil {
+		return pooledPage{}, err
+	}
+
+	poolMtx.Lock()
+	poolPages++
+	poolMtx.Unlock()
+
+	return pooledPage{page: page, browser: owner}, nil
+}
+
+func discardPage(p pooledPage) {
+	_ = p.page.Close()
+
+	poolMtx.Lock()
+	poolPages--
+	poolMtx.Unlock()
+}
+
+func returnPage(p pooledPage) {
+	browserMtx.RLock()
+	current := browser
+	browserMtx.RUnlock()
+
+	// Navigate back to a blank page so that the next request always triggers a full
+	// load. Otherwise, a URL that only differs in the hash would not reload the page.
+	if p.browser != current || p.page.Timeout(time.Second*2).Navigate("about:blank") != nil {
+		discardPage(p)
+		return
+	}
+
+	poolMtx.Lock()
+	defer poolMtx.Unlock()
+
+	if len(poolIdle) >= poolSize {
+		go discardPage(p)
+		return
+	}
+	poolIdle = append(poolIdle, p)
+}
+
+// withPage waits for a free slot in the pool and executes fn with a page bound to ctx.
+func withPage(ctx context.Context, fn func(page *rod.Page) error) (err error) {
+	poolMtx.Lock()
+	slots := poolSlots
+	poolMtx.Unlock()
+
+	if err := acquireSlot(ctx, slots); err != nil {
+		return err
+	}
+	defer func() { <-slots }()
+
+	startedAt := time.Now()
+	defer func() { metrics.finish(true, time.Since(startedAt), err) }()
+
+	p, err := takePage()
+	if err != nil {
+		return err
+	}
+
+	err = fn(p.page.Context(ctx))
+	if err == nil {
+		err = ctx.Err()
+	}
+
+	if err != nil {
+		// The page might be in an undefined state, so it is not reused.
+		discardPage(p)
+		return err
+	}
+
+	returnPage(p)
+	return nil
+}
+
+// GetMetrics returns the current statistics of the rendering pool.
+func GetMetrics() Metrics {
+	res := metrics.snapshot()
+
+	poolMtx.Lock()
+	defer poolMtx.Unlock()
+
+	res.PoolSize = poolSize
+	res.Pages = poolPages
+	return res
+}
+
+//
+// Rendering
+//
+
+func loadPage(page *rod.Page, url string) error {
+	if err := page.Navigate(url); err != nil {
+		return err
+	}
+
+	if err := page.WaitLoad(); err != nil {
+		return err
+	}
+
+	if err := page.WaitIdle(time.Minute); err != nil {
+		return err
+	}
+
+	page.Timeout(time.Second*IdleRequestTimeout).WaitRequestIdle(time.Millisecond*300, nil, nil, nil)()
+	return nil
 }
 
 func screenshotPage(page *rod.Page, width int) (image.Image, error) {
-	imageData, err := page.MustSetViewport(width, 10000, 1.0, false).MustElement("body").Screenshot(proto.PageCaptureScreenshotFormatPng, 100)
+	if err := page.SetViewport(&proto.EmulationSetDeviceMetricsOverride{
+		Width:             width,
+		Height:            10000,
+		DeviceScaleFactor: 1.0,
+	}); err != nil {
+		return nil, err
+	}
+
+	body, err := page.Element("body")
+	if err != nil {
+		return nil, err
+	}
+
+	imageData, err := body.Screenshot(proto.PageCaptureScreenshotFormatPng, 100)
 	if err != nil {
 		return nil, err
 	}
@@ -132,7 +440,7 @@ func screenshotPage(page *rod.Page, width int) (image.Image, error) {
 		return nil, err
 	}
 
-	if img.Bounds().Max.Y >= 9500 {
+	if img.Bounds().Max.Y >= MaxHeight {
 		return nil, errors.New("too large")
 	}
 
@@ -141,54 +449,138 @@ func screenshotPage(page *rod.Page, width int) (image.Image, error) {
 
 // RenderHTML renders the element #content into an image.
 func RenderHTML(html string, width int) (image.Image, error) {
-	page, err := tryOpenPage("data:text/html," + url.PathEscape(html))
-	if err != nil {
-		return nil, err
-	}
-
-	page.MustWaitLoad().MustWaitIdle()
-	page.Timeout(time.Second * IdleRequestTimeout).MustWaitRequestIdle()()
-
-	defer page.Close()
+	return RenderHTMLContext(context.Background(), html, width)
+}
 
-	return screenshotPage(page, width)
+// RenderHTMLContext renders the element #content into an image. The rendering is aborted
+// if the context is done.
+func RenderHTMLContext(ctx context.Context, html string, width int) (image.Image, error) {
+	return RenderURLContext(ctx, "data:text/html,"+url.PathEscape(html), width)
 }
 
 // RenderURL opens the URL and renders the element #content into an image.
 func RenderURL(url string, width int) (image.Image, error) {
-	page, err := tryOpenPage(url)
-	if err != nil {
-		return nil, err
-	}
-
-	page.MustWaitLoad().MustWaitIdle()
-	page.Timeout(time.Second * IdleRequestTimeout).MustWaitRequestIdle()()
+	return RenderURLContext(context.Background(), url, width)
+}
 
-	defer page.Close()
+// RenderURLContext opens the URL and renders the element #content into an image. The
+// rendering is aborted if the context is done.
+func RenderURLContext(ctx context.Context, url string, width int) (image.Image, error) {
+	var img image.Image
+	err := withPage(ctx, func(page *rod.Page) error {
+		if err := loadPage(page, url); err != nil {
+			return err
+		}
 
-	return screenshotPage(page, width)
+		var err error
+		img, err = screenshotPage(page, width)
+		return err
+	})
+	return img, err
 }
 
 // ExtractHTML opens the URL, lets the page executes and returns the HTML.
 func ExtractHTML(url string, selector string) (string, error) {
-	page, err := tryOpenPage(url)
-	if err != nil {
-		return "", err
-	}
+	return ExtractHTMLContext(context.Background(), url, selector)
+}
 
-	page.MustWaitLoad().MustWaitIdle()
-	page.Timeout(time.Second * IdleRequestTimeout).MustWaitRequestIdle()()
+// ExtractHTMLContext opens the URL, lets the page executes and returns the HTML. The
+// extraction is aborted if the context is done.
+func ExtractHTMLContext(ctx context.Context, url string, selector string) (string, error) {
+	var html string
+	err := withPage(ctx, func(page *rod.Page) error {
+		if err := loadPage(page, url); err != nil {
+			return err
+		}
 
-	defer page.Close()
+		if err := page.Timeout(time.Second*ReadyTimeout).WaitElementsMoreThan(selector, 0); err != nil {
+			return err
+		}
 
-	if err := page.Timeout(time.Second*ReadyTimeout).WaitElementsMoreThan(selector, 0); err != nil {
-		return "", err
-	}
+		sel, err := page.Element(selector)
+		if err != nil {
+			return err
+		}
 
-	sel, err := page.Element(selector)
-	if err != nil {
-		return "", err
-	}
+		html, err = sel.HTML()
+		return err
+	})
+	return html, err
+}
 
-	return sel.HTML()
+// InspectURL opens the URL and measures the content as it would be rendered with the given width.
+// Errors logged to the console by scripts of the page are collected.
+func InspectURL(url string, width int) (Inspection, error) {
+	return InspectURLContext(context.Background(), url, width)
+}
+
+// InspectURLContext opens the URL and measures the content as it would be rendered with the given
+// width. Errors logged to the console by scripts of the page are collected. The inspection is
+// aborted if the context is done.
+func InspectURLContext(ctx context.Context, url string, width int) (Inspection, error) {
+	res := Inspection{ConsoleErrors: []string{}}
+	err := withPage(ctx, func(page *rod.Page) error {
+		var consoleMtx sync.Mutex
+		var consoleErrors []string
+
+		eventCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		// Subscribe before navigating, so that errors of scripts that run early are not missed.
+		wait := page.Context(eventCtx).EachEvent(func(e *proto.RuntimeConsoleAPICalled) {
+			if e.Type != proto.RuntimeConsoleAPICalledTypeError && e.Type != proto.RuntimeConsoleAPICalledTypeAssert {
+				return
+			}
+
+			var args []string
+			for _, arg := range e.Args {
+				if arg.Description != "" {
+					args = append(args, arg.Description)
+				} else {
+					args = append(args, arg.Value.String())
+				}
+			}
+
+			consoleMtx.Lock()
+			consoleErrors = append(consoleErrors, strings.Join(args, " "))
+			consoleMtx.Unlock()
+		}, func(e *proto.RuntimeExceptionThrown) {
+			msg := e.ExceptionDetails.Text
+			if e.ExceptionDetails.Exception != nil && e.ExceptionDetails.Exception.Description != "" {
+				msg = e.ExceptionDetails.Exception.Description
+			}
+
+			consoleMtx.Lock()
+			consoleErrors = append(consoleErrors, msg)
+			consoleMtx.Unlock()
+		})
+		go wait()
+
+		if err := page.SetViewport(&proto.EmulationSetDeviceMetricsOverride{
+			Width:             width,
+			Height:            10000,
+			DeviceScaleFactor: 1.0,
+		}); err != nil {
+			return err
+		}
+
+		if err := loadPage(page, url); err != nil {
+			return err
+		}
+
+		size, err := page.Eval(`() => ({ width: document.body.scrollWidth, height: document.body.scrollHeight })`)
+		if err != nil {
+			return err
+		}
+
+		res.Width = size.Value.Get("width").Int()
+		res.Height = size.Value.Get("height").Int()
+
+		consoleMtx.Lock()
+		res.ConsoleErrors = append(res.ConsoleErrors, consoleErrors...)
+		consoleMtx.Unlock()
+
+		return nil
+	})
+	return res, err
 }
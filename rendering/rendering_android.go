@@ -5,11 +5,13 @@ package rendering
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"image"
 	_ "image/png"
 	"net/url"
 	"sync"
+	"time"
 )
 
 type AndroidRenderer interface {
@@ -20,6 +22,10 @@ type AndroidRenderer interface {
 var (
 	androidRendererMu sync.RWMutex
 	androidRenderer   AndroidRenderer
+
+	// androidSlot serializes the requests to the bridge as the host only
+	// renders one page at a time.
+	androidSlot = make(chan struct{}, 1)
 )
 
 func SetAndroidRenderer(renderer AndroidRenderer) {
@@ -30,24 +36,82 @@ func SetAndroidRenderer(renderer AndroidRenderer) {
 
 func InitBrowser() {}
 
+// TrustCertificate is not supported by the android renderer.
+func TrustCertificate(spkiHash string) {}
+
+// SetLocalCookie is not supported by the android renderer.
+func SetLocalCookie(name string, value string) {}
+
 func Shutdown() error {
 	return nil
 }
 
-func RenderHTML(html string, width int) (image.Image, error) {
-	return RenderURL("data:text/html,"+url.PathEscape(html), width)
+func SetPoolSize(size int) {}
+
+func GetMetrics() Metrics {
+	res := metrics.snapshot()
+	res.PoolSize = 1
+	res.Pages = 1
+	return res
 }
 
-func RenderURL(targetURL string, width int) (image.Image, error) {
+// rendererResult is the result of a call to the bridge.
+type rendererResult[T any] struct {
+	value T
+	err   error
+}
+
+// withRenderer waits for the bridge to be free and runs fn in the background. The bridge
+// call itself can't be interrupted, so if the context is done the result is dropped.
+func withRenderer[T any](ctx context.Context, fn func(renderer AndroidRenderer) (T, error)) (value T, err error) {
 	androidRendererMu.RLock()
 	renderer := androidRenderer
 	androidRendererMu.RUnlock()
 
 	if renderer == nil {
-		return nil, errors.New("android renderer is not configured")
+		return value, errors.New("android renderer is not configured")
 	}
 
-	pngData, err := renderer.RenderURL(targetURL, int32(width))
+	if err := acquireSlot(ctx, androidSlot); err != nil {
+		return value, err
+	}
+
+	startedAt := time.Now()
+	defer func() { metrics.finish(true, time.Since(startedAt), err) }()
+
+	// The result is only passed through the channel, as the call might still be running
+	// after this function returned.
+	done := make(chan rendererResult[T], 1)
+	go func() {
+		defer func() { <-androidSlot }()
+		value, err := fn(renderer)
+		done <- rendererResult[T]{value: value, err: err}
+	}()
+
+	select {
+	case res := <-done:
+		return res.value, res.err
+	case <-ctx.Done():
+		return value, ctx.Err()
+	}
+}
+
+func RenderHTML(html string, width int) (image.Image, error) {
+	return RenderHTMLContext(context.Background(), html, width)
+}
+
+func RenderHTMLContext(ctx context.Context, html string, width int) (image.Image, error) {
+	return RenderURLContext(ctx, "data:text/html,"+url.PathEscape(html), width)
+}
+
+func RenderURL(targetURL string, width int) (image.Image, error) {
+	return RenderURLContext(context.Background(), targetURL, width)
+}
+
+func RenderURLContext(ctx context.Context, targetURL string, width int) (image.Image, error) {
+	pngData, err := withRenderer(ctx, func(renderer AndroidRenderer) ([]byte, error) {
+		return renderer.RenderURL(targetURL, int32(width))
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -61,13 +125,30 @@ func RenderURL(targetURL string, width int) (image.Image, error) {
 }
 
 func ExtractHTML(targetURL string, selector string) (string, error) {
-	androidRendererMu.RLock()
-	renderer := androidRenderer
-	androidRendererMu.RUnlock()
+	return ExtractHTMLContext(context.Background(), targetURL, selector)
+}
 
-	if renderer == nil {
-		return "", errors.New("android renderer is not configured")
+func ExtractHTMLContext(ctx context.Context, targetURL string, selector string) (string, error) {
+	return withRenderer(ctx, func(renderer AndroidRenderer) (string, error) {
+		return renderer.ExtractHTML(targetURL, selector)
+	})
+}
+
+// InspectURL on android only measures the rendered image, as the bridge
+// doesn't expose the console of the page.
+func InspectURL(targetURL string, width int) (Inspection, error) {
+	return InspectURLContext(context.Background(), targetURL, width)
+}
+
+func InspectURLContext(ctx context.Context, targetURL string, width int) (Inspection, error) {
+	img, err := RenderURLContext(ctx, targetURL, width)
+	if err != nil {
+		return Inspection{}, err
 	}
 
-	return renderer.ExtractHTML(targetURL, selector)
+	return Inspection{
+		Width:         img.Bounds().Dx(),
+		Height:        img.Bounds().Dy(),
+		ConsoleErrors: []string{},
+	}, nil
 }
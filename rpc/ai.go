@@ -1,15 +1,19 @@
 package rpc
 
 import (
+	"context"
 	"crypto/sha1"
 	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
 	"strings"
 	"time"
 
+	"github.com/BigJk/snd"
 	"github.com/BigJk/snd/ai"
+	"github.com/BigJk/snd/ai/retrieval"
 	"github.com/BigJk/snd/rpc/bind"
 
 	"github.com/BigJk/snd/database"
@@ -24,7 +28,7 @@ func shortHash(text string) string {
 }
 
 func providerToEndpoint(db database.Database, provider string) (string, error) {
-	if provider != ai.ProviderCustom {
+	if !ai.IsLocalProvider(provider) {
 		return ai.EndpointForProvider(provider, "")
 	}
 
@@ -49,15 +53,43 @@ func providerConfig(db database.Database, provider string, apiKey string) (ai.Pr
 	}, nil
 }
 
+// aiConfig returns the settings and the config of the selected provider. An error is returned if AI is not enabled.
+func aiConfig(db database.Database) (snd.Settings, ai.ProviderConfig, error) {
+	settings, err := db.GetSettings()
+	if err != nil {
+		return snd.Settings{}, ai.ProviderConfig{}, err
+	}
+
+	if !settings.AIEnabled {
+		return snd.Settings{}, ai.ProviderConfig{}, errors.New("AI is not enabled")
+	}
+
+	config, err := providerConfig(db, settings.AIProvider, settings.AIApiKey)
+	if err != nil {
+		return snd.Settings{}, ai.ProviderConfig{}, err
+	}
+	config.Timeout = time.Duration(settings.AITimeout) * time.Second
+
+	return settings, config, nil
+}
+
+// streamTimeout limits how long a streamed prompt can take.
+const streamTimeout = time.Minute * 10
+
 func RegisterAI(route *echo.Group, db database.Database) {
-	client := ai.NewClient(&http.Client{Timeout: time.Second * 60})
+	// The timeout is set per request by the settings
+	client := ai.NewClient(&http.Client{})
+
+	tracker := newUsageTracker(db, client)
+	client.SetTracker(tracker)
+
+	cache := newAICache(db)
 
 	bind.MustBind(route, "/aiCached", func(system string, user string, token string) (string, error) {
 		if len(token) == 0 {
 			return "", errors.New("token is empty")
 		}
-		cacheKey := fmt.Sprintf("AI_CACHE_%s_%s", shortHash(system+user), token)
-		if val, err := db.GetKey(cacheKey); err == nil {
+		if val, ok := cache.get(shortHash(system+user), token); ok {
 			return val, nil
 		}
 		return "", errors.New("not cached")
@@ -67,79 +99,280 @@ func RegisterAI(route *echo.Group, db database.Database) {
 		if len(token) == 0 {
 			return errors.New("token is empty")
 		}
-		keys, err := db.GetKeysPrefix("AI_CACHE_")
+		return cache.invalidate(token)
+	})
+
+	bind.MustBind(route, "/aiCacheList", cache.stats)
+
+	bind.MustBind(route, "/aiCacheGet", func(key string) (CacheEntry, error) {
+		if !strings.HasPrefix(key, aiCachePrefix) {
+			return CacheEntry{}, errors.New("not an AI cache key")
+		}
+		return cache.entry(key)
+	})
+
+	bind.MustBind(route, "/aiCachePin", cache.pin)
+
+	bind.MustBind(route, "/aiCacheDelete", cache.delete)
+
+	// Pinned AI responses are kept
+	bind.MustBind(route, "/clearAICache", cache.clear)
+
+	// Removes expired entries and the least recently used ones if the cache is too large.
+	bind.MustBind(route, "/aiCacheEvict", cache.evict)
+
+	bind.MustBind(route, "/aiPrompt", func(req *http.Request, system string, user string, token string) (string, error) {
+		settings, config, err := aiConfig(db)
+		if err != nil {
+			return "", err
+		}
+
+		promptHash := shortHash(system + user)
+		if val, ok := cache.get(promptHash, token); ok {
+			return val, nil
+		}
+
+		response, err := client.RunPromptContext(req.Context(), config, ai.Prompt{
+			Model:     settings.AIModel,
+			MaxTokens: settings.AIMaxTokens,
+			System:    system,
+			User:      user,
+		})
+		if err != nil {
+			return "", err
+		}
+
+		cache.set(promptHash, token, settings.AIModel, system, user, response)
+
+		return response, nil
+	})
+
+	// Streams the response of the prompt as server-sent events. The body contains the same
+	// arguments as /aiPrompt. Every event contains either a part of the response {"chunk": "..."},
+	// the complete response at the end {"done": true, "response": "..."} or an error {"error": "...", "kind": "..."}.
+	// The kind classifies the error, e.g. "auth" or "rate_limit", see ai.ErrorKind.
+	// The generation is cancelled when the client closes the connection.
+	route.POST("/aiPromptStream", func(c echo.Context) error {
+		var args []string
+		if err := json.NewDecoder(c.Request().Body).Decode(&args); err != nil || len(args) != 3 {
+			return c.JSON(http.StatusBadRequest, "expected system, user and token as arguments")
+		}
+		system, user, token := args[0], args[1], args[2]
+
+		settings, config, err := aiConfig(db)
 		if err != nil {
-			return err
+			return c.JSON(http.StatusBadRequest, err.Error())
+		}
+
+		resp := c.Response()
+		resp.Header().Set(echo.HeaderContentType, "text/event-stream")
+		resp.Header().Set(echo.HeaderCacheControl, "no-cache")
+		resp.Header().Set(echo.HeaderConnection, "keep-alive")
+		resp.WriteHeader(http.StatusOK)
+
+		writeEvent := func(event map[string]any) error {
+			data, err := json.Marshal(event)
+			if err != nil {
+				return err
+			}
+
+			if _, err := fmt.Fprintf(resp, "data: %s\n\n", data); err != nil {
+				return err
+			}
+			resp.Flush()
+			return nil
+		}
+
+		promptHash := shortHash(system + user)
+		if val, ok := cache.get(promptHash, token); ok {
+			_ = writeEvent(map[string]any{"chunk": val})
+			return writeEvent(map[string]any{"done": true, "response": val})
 		}
-		for _, k := range keys {
-			if strings.HasSuffix(k, token) {
-				db.DeleteKey(k)
+
+		ctx, cancel := context.WithTimeout(c.Request().Context(), streamTimeout)
+		defer cancel()
+
+		response, err := client.StreamPrompt(ctx, config, ai.Prompt{
+			Model:     settings.AIModel,
+			MaxTokens: settings.AIMaxTokens,
+			System:    system,
+			User:      user,
+		}, func(chunk string) error {
+			return writeEvent(map[string]any{"chunk": chunk})
+		})
+		if err != nil {
+			if ctx.Err() == nil {
+				_ = writeEvent(map[string]any{"error": err.Error(), "kind": ai.Kind(err)})
 			}
+			return nil
 		}
-		return nil
+
+		cache.set(promptHash, token, settings.AIModel, system, user, response)
+
+		return writeEvent(map[string]any{"done": true, "response": response})
 	})
 
-	bind.MustBind(route, "/aiPrompt", func(system string, user string, token string) (string, error) {
-		settings, err := db.GetSettings()
+	bind.MustBind(route, "/aiCodingPrompt", func(req *http.Request, system string, user string) (string, error) {
+		settings, config, err := aiConfig(db)
 		if err != nil {
 			return "", err
 		}
 
-		if !settings.AIEnabled {
-			return "", errors.New("AI is not enabled")
+		model := settings.AICodingModel
+		if strings.TrimSpace(model) == "" {
+			model = settings.AIModel
 		}
 
-		config, err := providerConfig(db, settings.AIProvider, settings.AIApiKey)
+		return client.RunPromptContext(req.Context(), config, ai.Prompt{
+			Model:     model,
+			MaxTokens: settings.AIMaxTokens,
+			System:    system,
+			User:      user,
+		})
+	})
+
+	// Generates new entries for a data source or template that have the same structure as the
+	// existing entries and saves them. If the generation fails midway the entries that were
+	// generated until then are still saved.
+	bind.MustBind(route, "/aiGenerateEntries", func(req *http.Request, id string, instructions string, count int) ([]snd.Entry, error) {
+		if count <= 0 || count > generateMaxEntries {
+			return nil, fmt.Errorf("count has to be between 1 and %d", generateMaxEntries)
+		}
+
+		settings, config, err := aiConfig(db)
+		if err != nil {
+			return nil, err
+		}
+
+		target, err := loadEntryTarget(db, id)
+		if err != nil {
+			return nil, err
+		}
+
+		entries, genErr := generateEntries(req.Context(), client, config, settings, target, instructions, count)
+		if len(entries) > 0 {
+			if err := db.SaveEntries(id, entries); err != nil {
+				return nil, err
+			}
+		}
+
+		if genErr != nil {
+			if len(entries) > 0 {
+				return nil, fmt.Errorf("only %d of %d entries were generated: %w", len(entries), count, genErr)
+			}
+			return nil, genErr
+		}
+
+		return entries, nil
+	})
+
+	// Returns the usage of the last days, including today.
+	bind.MustBind(route, "/aiUsage", func(days int) (UsageReport, error) {
+		if days <= 0 || days > 366 {
+			return UsageReport{}, errors.New("days has to be between 1 and 366")
+		}
+
+		return tracker.Report(days)
+	})
+
+	// Returns the entries of the data sources or templates that are most relevant for the query.
+	bind.MustBind(route, "/aiRetrieve", func(req *http.Request, sources []string, query string, limit int) ([]retrieval.Result, error) {
+		if limit <= 0 {
+			return nil, errors.New("limit has to be greater than 0")
+		}
+
+		settings, config, err := aiConfig(db)
+		if err != nil {
+			return nil, err
+		}
+
+		return retrieveEntries(req.Context(), db, client, config, settings, sources, query, limit, false)
+	})
+
+	// Same as /aiPrompt, but the entries of the data sources or templates that are most relevant
+	// for the user prompt are added to the system prompt, as far as the context window allows.
+	bind.MustBind(route, "/aiGroundedPrompt", func(req *http.Request, system string, user string, sources []string, token string) (string, error) {
+		settings, config, err := aiConfig(db)
 		if err != nil {
 			return "", err
 		}
 
-		cacheKey := fmt.Sprintf("AI_CACHE_%s_%s", shortHash(system+user), token)
-		if val, err := db.GetKey(cacheKey); err == nil {
+		promptHash := shortHash(system + user + strings.Join(sources, ","))
+		if val, ok := cache.get(promptHash, token); ok {
 			return val, nil
 		}
 
-		response, err := client.RunPrompt(config, ai.Prompt{
+		limit := retrievalDefaultEntries
+		if settings.AIContextWindow > 0 {
+			limit = retrievalCandidates
+		}
+
+		results, err := retrieveEntries(req.Context(), db, client, config, settings, sources, user, limit, true)
+		if err != nil {
+			return "", err
+		}
+
+		grounded, err := groundedSystemPrompt(system, results, settings.AIContextWindow)
+		if err != nil {
+			return "", err
+		}
+
+		response, err := client.RunPromptContext(req.Context(), config, ai.Prompt{
 			Model:     settings.AIModel,
 			MaxTokens: settings.AIMaxTokens,
-			System:    system,
+			System:    grounded,
 			User:      user,
 		})
 		if err != nil {
 			return "", err
 		}
 
-		db.SetKey(cacheKey, response)
+		cache.set(promptHash, token, settings.AIModel, grounded, user, response)
 
 		return response, nil
 	})
 
-	bind.MustBind(route, "/aiCodingPrompt", func(system string, user string) (string, error) {
-		settings, err := db.GetSettings()
+	// Runs a prompt of the prompt library. The prompt is found by its id, name or slug and the
+	// variables are filled in before it is sent. An empty version runs the current version.
+	bind.MustBind(route, "/aiRunPrompt", func(req *http.Request, name string, version string, variables map[string]string, token string) (string, error) {
+		settings, config, err := aiConfig(db)
 		if err != nil {
 			return "", err
 		}
 
-		if !settings.AIEnabled {
-			return "", errors.New("AI is not enabled")
+		prompt, err := findPrompt(db, name)
+		if err != nil {
+			return "", err
 		}
 
-		config, err := providerConfig(db, settings.AIProvider, settings.AIApiKey)
+		prompt, err = prompt.AtVersion(version)
 		if err != nil {
 			return "", err
 		}
 
-		model := settings.AICodingModel
-		if strings.TrimSpace(model) == "" {
-			model = settings.AIModel
+		system, user, err := prompt.Render(variables)
+		if err != nil {
+			return "", err
 		}
 
-		return client.RunPrompt(config, ai.Prompt{
-			Model:     model,
+		promptHash := shortHash(system + user)
+		if val, ok := cache.get(promptHash, token); ok {
+			return val, nil
+		}
+
+		response, err := client.RunPromptContext(req.Context(), config, ai.Prompt{
+			Model:     settings.AIModel,
 			MaxTokens: settings.AIMaxTokens,
 			System:    system,
 			User:      user,
 		})
+		if err != nil {
+			return "", err
+		}
+
+		cache.set(promptHash, token, settings.AIModel, system, user, response)
+
+		return response, nil
 	})
 
 	bind.MustBind(route, "/aiProviders", func() ([]string, error) {
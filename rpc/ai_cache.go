@@ -0,0 +1,377 @@
+package rpc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/BigJk/snd"
+	"github.com/BigJk/snd/database"
+	"github.com/BigJk/snd/log"
+)
+
+const (
+	// aiCachePrefix is the key prefix of cached AI responses. The keys are AI_CACHE_<hash>_<token>.
+	aiCachePrefix = "AI_CACHE_"
+	// aiCacheHashLength is the length of the prompt hash in the keys.
+	aiCacheHashLength = 8
+	// aiCacheEvictInterval limits how often expired and oversized entries are evicted when responses are stored.
+	aiCacheEvictInterval = time.Minute
+	// aiCacheTouchInterval limits how often the last use of an entry is updated, so that
+	// cache hits don't write to the database every time.
+	aiCacheTouchInterval = time.Hour
+)
+
+// CacheInfo is the metadata of a cached AI response.
+type CacheInfo struct {
+	Key        string    `json:"key"`
+	Token      string    `json:"token"`
+	PromptHash string    `json:"promptHash"`
+	Model      string    `json:"model"`
+	Created    time.Time `json:"created"`
+	LastUsed   time.Time `json:"lastUsed"`
+	// Size is the size of the prompts and response in bytes.
+	Size int `json:"size"`
+	// Pinned entries are never evicted and not removed when the cache is cleared.
+	Pinned bool `json:"pinned"`
+}
+
+// CacheEntry is a cached AI response together with the prompts it was created from.
+type CacheEntry struct {
+	CacheInfo
+	System   string `json:"system"`
+	User     string `json:"user"`
+	Response string `json:"response"`
+}
+
+// CacheStats is the list of cached responses together with the configured limits.
+type CacheStats struct {
+	Entries []CacheInfo `json:"entries"`
+	Size    int         `json:"size"`
+	// TTL is the time to live in hours. Zero means entries don't expire.
+	TTL int `json:"ttl"`
+	// MaxSize is the maximum size in MB. Zero means no limit.
+	MaxSize int `json:"maxSize"`
+}
+
+func aiCacheKey(promptHash string, token string) string {
+	return aiCachePrefix + promptHash + "_" + token
+}
+
+// parseCacheEntry decodes a cached response. Responses cached before metadata was added are
+// stored as plain text and have no creation time, so they are the first to expire.
+func parseCacheEntry(key string, value string) CacheEntry {
+	var entry CacheEntry
+	if err := json.Unmarshal([]byte(value), &entry); err != nil || entry.Created.IsZero() {
+		entry = CacheEntry{
+			CacheInfo: CacheInfo{Size: len(value)},
+			Response:  value,
+		}
+	}
+
+	entry.Key = key
+	if rest := strings.TrimPrefix(key, aiCachePrefix); len(rest) > aiCacheHashLength {
+		entry.PromptHash = rest[:aiCacheHashLength]
+		entry.Token = rest[aiCacheHashLength+1:]
+	}
+
+	return entry
+}
+
+func (e CacheEntry) expired(settings snd.Settings, now time.Time) bool {
+	if e.Pinned || settings.AICacheTTL <= 0 {
+		return false
+	}
+	return now.Sub(e.Created) > time.Duration(settings.AICacheTTL)*time.Hour
+}
+
+// aiCache stores AI responses with metadata and keeps the cache inside the configured TTL and size.
+// There must only be one cache per database, as the lock serializes all changes of the entries so
+// that stores, evictions and clears don't interleave.
+type aiCache struct {
+	sync.Mutex
+	db        database.Database
+	lastEvict time.Time
+}
+
+func newAICache(db database.Database) *aiCache {
+	return &aiCache{db: db}
+}
+
+func (c *aiCache) entry(key string) (CacheEntry, error) {
+	value, err := c.db.GetKey(key)
+	if err != nil {
+		return CacheEntry{}, err
+	}
+	return parseCacheEntry(key, value), nil
+}
+
+func (c *aiCache) save(entry CacheEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return c.db.SetKey(entry.Key, string(data))
+}
+
+// get returns the cached response for the prompt hash and token. Expired entries are removed.
+func (c *aiCache) get(promptHash string, token string) (string, bool) {
+	c.Lock()
+	defer c.Unlock()
+
+	entry, err := c.entry(aiCacheKey(promptHash, token))
+	if err != nil {
+		return "", false
+	}
+
+	now := time.Now()
+	if settings, err := c.db.GetSettings(); err == nil && entry.expired(settings, now) {
+		_ = c.db.DeleteKey(entry.Key)
+		return "", false
+	}
+
+	if !entry.Created.IsZero() && now.Sub(entry.LastUsed) > aiCacheTouchInterval {
+		entry.LastUsed = now
+		if err := c.save(entry); err != nil {
+			log.Error(err, log.WithValue("key", entry.Key))
+		}
+	}
+
+	return entry.Response, true
+}
+
+// set caches the response and evicts old entries if the last eviction was a while ago.
+func (c *aiCache) set(promptHash string, token string, model string, system string, user string, response string) {
+	now := time.Now()
+	entry := CacheEntry{
+		CacheInfo: CacheInfo{
+			Key:        aiCacheKey(promptHash, token),
+			Token:      token,
+			PromptHash: promptHash,
+			Model:      model,
+			Created:    now,
+			LastUsed:   now,
+			Size:       len(system) + len(user) + len(response),
+		},
+		System:   system,
+		User:     user,
+		Response: response,
+	}
+
+	c.Lock()
+
+	// Keep the pin if the response is regenerated
+	if existing, err := c.entry(entry.Key); err == nil {
+		entry.Pinned = existing.Pinned
+	}
+
+	if err := c.save(entry); err != nil {
+		c.Unlock()
+		log.Error(err, log.WithValue("key", entry.Key))
+		return
+	}
+
+	evict := now.Sub(c.lastEvict) > aiCacheEvictInterval
+	if evict {
+		c.lastEvict = now
+	}
+	c.Unlock()
+
+	if evict {
+		if _, err := c.evict(); err != nil {
+			log.Error(err)
+		}
+	}
+}
+
+// entries returns all cached entries.
+func (c *aiCache) entries() ([]CacheEntry, error) {
+	keys, err := c.db.GetKeysPrefix(aiCachePrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]CacheEntry, 0, len(keys))
+	for _, key := range keys {
+		entry, err := c.entry(key)
+		if err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// evict removes the expired entries and, if the cache is larger than the maximum size, the least
+// recently used entries. Pinned entries are never removed. Returns the number of removed entries.
+func (c *aiCache) evict() (int, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	settings, err := c.db.GetSettings()
+	if err != nil {
+		return 0, err
+	}
+
+	if settings.AICacheTTL <= 0 && settings.AICacheMaxSize <= 0 {
+		return 0, nil
+	}
+
+	entries, err := c.entries()
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	removed := 0
+	size := 0
+	var candidates []CacheEntry
+	for i := range entries {
+		if entries[i].expired(settings, now) {
+			if err := c.db.DeleteKey(entries[i].Key); err != nil {
+				return removed, err
+			}
+			removed++
+			continue
+		}
+
+		size += entries[i].Size
+		if !entries[i].Pinned {
+			candidates = append(candidates, entries[i])
+		}
+	}
+
+	maxSize := settings.AICacheMaxSize * 1024 * 1024
+	if maxSize <= 0 || size <= maxSize {
+		return removed, nil
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].LastUsed.Before(candidates[j].LastUsed)
+	})
+
+	for i := 0; i < len(candidates) && size > maxSize; i++ {
+		if err := c.db.DeleteKey(candidates[i].Key); err != nil {
+			return removed, err
+		}
+		size -= candidates[i].Size
+		removed++
+	}
+
+	return removed, nil
+}
+
+// stats returns the metadata of all cached entries, the most recently created first.
+func (c *aiCache) stats() (CacheStats, error) {
+	settings, err := c.db.GetSettings()
+	if err != nil {
+		return CacheStats{}, err
+	}
+
+	entries, err := c.entries()
+	if err != nil {
+		return CacheStats{}, err
+	}
+
+	stats := CacheStats{
+		Entries: make([]CacheInfo, len(entries)),
+		TTL:     settings.AICacheTTL,
+		MaxSize: settings.AICacheMaxSize,
+	}
+	for i := range entries {
+		stats.Entries[i] = entries[i].CacheInfo
+		stats.Size += entries[i].Size
+	}
+
+	sort.SliceStable(stats.Entries, func(i, j int) bool {
+		return stats.Entries[i].Created.After(stats.Entries[j].Created)
+	})
+
+	return stats, nil
+}
+
+// pin sets if the entry is protected from eviction.
+func (c *aiCache) pin(key string, pinned bool) error {
+	if !strings.HasPrefix(key, aiCachePrefix) {
+		return errors.New("not an AI cache key")
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	entry, err := c.entry(key)
+	if err != nil {
+		return fmt.Errorf("cache entry '%s' not found", key)
+	}
+
+	// Plain text entries from older versions are converted, so they can carry the pin
+	if entry.Created.IsZero() {
+		entry.Created = time.Now()
+		entry.LastUsed = entry.Created
+	}
+
+	entry.Pinned = pinned
+	return c.save(entry)
+}
+
+// clear removes all entries that are not pinned. Returns the number of removed entries.
+func (c *aiCache) clear() (int, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	entries, err := c.entries()
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for i := range entries {
+		if entries[i].Pinned {
+			continue
+		}
+
+		if err := c.db.DeleteKey(entries[i].Key); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+
+	return removed, nil
+}
+
+// delete removes the entry.
+func (c *aiCache) delete(key string) error {
+	if !strings.HasPrefix(key, aiCachePrefix) {
+		return errors.New("not an AI cache key")
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	return c.db.DeleteKey(key)
+}
+
+// invalidate removes all entries of the token.
+func (c *aiCache) invalidate(token string) error {
+	c.Lock()
+	defer c.Unlock()
+
+	keys, err := c.db.GetKeysPrefix(aiCachePrefix)
+	if err != nil {
+		return err
+	}
+
+	for _, k := range keys {
+		if strings.HasSuffix(k, token) {
+			if err := c.db.DeleteKey(k); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package rpc
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/BigJk/snd"
+	"github.com/BigJk/snd/database/badger"
+)
+
+func TestAICacheClearKeepsPinned(t *testing.T) {
+	db, err := badger.New(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	cache := newAICache(db)
+	cache.set("hash1", "tokenA", "model", "system", "user", "first")
+	cache.set("hash2", "tokenA", "model", "system", "user", "second")
+	cache.set("hash3", "tokenB", "model", "system", "user", "third")
+
+	if err := cache.pin(aiCacheKey("hash1", "tokenA"), true); err != nil {
+		t.Fatal(err)
+	}
+
+	removed, err := cache.clear()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removed != 2 {
+		t.Fatalf("expected 2 removed entries, got %d", removed)
+	}
+	if val, ok := cache.get("hash1", "tokenA"); !ok || val != "first" {
+		t.Fatalf("expected the pinned entry to be kept, got '%s'", val)
+	}
+
+	if err := cache.invalidate("tokenA"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cache.get("hash1", "tokenA"); ok {
+		t.Fatal("expected the entries of the token to be removed")
+	}
+
+	if err := cache.delete("SETTINGS"); err == nil {
+		t.Fatal("expected keys outside of the cache to be rejected")
+	}
+}
+
+func TestAICacheConcurrentChanges(t *testing.T) {
+	db, err := badger.New(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// A small size limit, so that the evictions remove entries
+	if err := db.SaveSettings(snd.Settings{AICacheMaxSize: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	cache := newAICache(db)
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 25; j++ {
+				cache.set(fmt.Sprintf("hash%d", j), fmt.Sprintf("token%d", i), "model", "system", "user", "response")
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			if _, err := cache.clear(); err != nil {
+				t.Error(err)
+			}
+			if _, err := cache.evict(); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	stats, err := cache.stats()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	size := 0
+	for _, entry := range stats.Entries {
+		size += entry.Size
+	}
+	if size != stats.Size {
+		t.Fatalf("size %d doesn't match the entries %d", stats.Size, size)
+	}
+}
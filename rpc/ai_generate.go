@@ -0,0 +1,287 @@
+package rpc
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/BigJk/snd"
+	"github.com/BigJk/snd/ai"
+	"github.com/BigJk/snd/database"
+	"github.com/BigJk/snd/log"
+	"github.com/samber/lo"
+)
+
+const (
+	// generateBatchSize is the number of entries that are requested from the model at once.
+	generateBatchSize = 10
+	// generateMaxEntries limits the number of entries of a single generation.
+	generateMaxEntries = 100
+	// generateExamples is the number of existing entries that are shown to the model.
+	generateExamples = 3
+	// generateMaxNames is the number of existing names that the model should avoid.
+	generateMaxNames = 200
+)
+
+// entryTarget contains the information about a data source or template that is needed to
+// generate new entries for it.
+type entryTarget struct {
+	name        string
+	description string
+	shape       *ai.Shape
+	examples    []snd.Entry
+	names       []string
+}
+
+// loadEntryTarget derives the shape of the entries of the data source or template. The shape of a
+// template is based on its skeleton data and entries, the shape of a data source on its entries.
+func loadEntryTarget(db database.Database, id string) (entryTarget, error) {
+	var target entryTarget
+	var values []any
+
+	switch {
+	case snd.IsTemplateID(id):
+		tmpl, err := db.GetTemplate(id)
+		if err != nil {
+			return target, err
+		}
+
+		target.name = tmpl.Name
+		target.description = tmpl.Description
+		if tmpl.SkeletonData != nil {
+			values = append(values, map[string]any(tmpl.SkeletonData))
+		}
+	case snd.IsDataSourceID(id):
+		ds, err := db.GetSource(id)
+		if err != nil {
+			return target, err
+		}
+
+		target.name = ds.Name
+		target.description = ds.Description
+	default:
+		return target, errors.New("id is not a data source or template")
+	}
+
+	entries, err := db.GetEntries(id)
+	if err != nil {
+		return target, err
+	}
+
+	for i := range entries {
+		if entries[i].Data != nil {
+			values = append(values, map[string]any(entries[i].Data))
+		}
+		if len(target.names) < generateMaxNames {
+			target.names = append(target.names, entries[i].Name)
+		}
+	}
+
+	if len(values) == 0 {
+		return target, errors.New("no entries or skeleton data to derive the structure of new entries from")
+	}
+
+	// Shapes are derived from json, so the values need the same types as parsed json
+	data, err := json.Marshal(values)
+	if err != nil {
+		return target, err
+	}
+
+	var parsed []any
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return target, err
+	}
+
+	target.shape = ai.ShapeOf(parsed...)
+	if target.shape.Type != ai.ShapeObject {
+		return target, errors.New("entries have no consistent structure")
+	}
+
+	// Entries without data are no useful examples
+	for i := range entries {
+		if len(target.examples) == generateExamples {
+			break
+		}
+		if len(entries[i].Data) > 0 {
+			target.examples = append(target.examples, entries[i])
+		}
+	}
+
+	return target, nil
+}
+
+// systemPrompt builds the prompt that describes the expected json. If contextWindow is set, examples
+// and names are only added as long as the prompt is shorter.
+func (t entryTarget) systemPrompt(count int, contextWindow int) (string, error) {
+	schema, err := json.MarshalIndent(t.shape.Schema(), "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	sb.WriteString("You output JSON.\n")
+	sb.WriteString("You are a helper to generate data in a Software for Pen & Paper / TTRPGs.\n")
+	fmt.Fprintf(&sb, "You generate new entries for %q.", t.name)
+	if len(t.description) > 0 {
+		fmt.Fprintf(&sb, " The description is: %s", t.description)
+	}
+	sb.WriteString("\n\n")
+	fmt.Fprintf(&sb, "Respond with a JSON object with the key \"entries\" that contains an array of exactly %d new entries.\n", count)
+	sb.WriteString("Every entry is an object with a \"name\" string and a \"data\" object. The \"data\" of every entry must follow this JSON schema:\n\n")
+	sb.Write(schema)
+
+	fits := func(text string) bool {
+		return contextWindow <= 0 || sb.Len()+len(text) <= contextWindow
+	}
+
+	for i := range t.examples {
+		example, err := json.MarshalIndent(map[string]any{"name": t.examples[i].Name, "data": t.examples[i].Data}, "", "  ")
+		if err != nil {
+			return "", err
+		}
+
+		text := "\n\nExample entry:\n\n" + string(example)
+		if !fits(text) {
+			break
+		}
+		sb.WriteString(text)
+	}
+
+	if len(t.names) > 0 {
+		text := "\n\nDon't repeat these existing entries: " + strings.Join(t.names, ", ")
+		if fits(text) {
+			sb.WriteString(text)
+		}
+	}
+
+	return sb.String(), nil
+}
+
+// parseEntries reads the entries of a response and conforms their data to the shape. Responses
+// with a single entry or a plain array of entries are accepted as well.
+func (t entryTarget) parseEntries(response string) ([]snd.Entry, error) {
+	value, err := ai.ParseJSON(response)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []any
+	switch val := value.(type) {
+	case []any:
+		items = val
+	case map[string]any:
+		if entries, ok := val["entries"].([]any); ok {
+			items = entries
+		} else {
+			items = []any{val}
+		}
+	}
+
+	var entries []snd.Entry
+	for i := range items {
+		item, ok := items[i].(map[string]any)
+		if !ok {
+			continue
+		}
+
+		data, ok := item["data"].(map[string]any)
+		if !ok {
+			// The model skipped the wrapper and returned the data directly
+			data = item
+		}
+
+		// Entries that were cut off before any data are useless
+		if !lo.ContainsBy(lo.Keys(data), func(k string) bool { _, ok := t.shape.Fields[k]; return ok }) {
+			continue
+		}
+
+		name, _ := item["name"].(string)
+		if strings.TrimSpace(name) == "" {
+			name, _ = data["name"].(string)
+		}
+		if strings.TrimSpace(name) == "" {
+			name = "AI Generated"
+		}
+
+		entries = append(entries, snd.Entry{
+			Name: strings.TrimSpace(name),
+			Data: t.shape.Conform(data).(map[string]any),
+		})
+	}
+
+	if len(entries) == 0 {
+		return nil, errors.New("response doesn't contain entries")
+	}
+
+	return entries, nil
+}
+
+// generateEntryID creates a random id for a generated entry.
+func generateEntryID() string {
+	data := make([]byte, 6)
+	_, _ = rand.Read(data)
+	return "ai#" + hex.EncodeToString(data)
+}
+
+// generateEntries asks the model for new entries in batches. If a response can't be parsed, the
+// model is asked once to repair it. Entries that were generated before an error are still returned.
+func generateEntries(ctx context.Context, client *ai.Client, config ai.ProviderConfig, settings snd.Settings, target entryTarget, instructions string, count int) ([]snd.Entry, error) {
+	if strings.TrimSpace(instructions) == "" {
+		instructions = "Generate new entries."
+	}
+
+	var generated []snd.Entry
+	for len(generated) < count {
+		batch := min(count-len(generated), generateBatchSize)
+
+		system, err := target.systemPrompt(batch, settings.AIContextWindow)
+		if err != nil {
+			return generated, err
+		}
+
+		prompt := ai.Prompt{
+			Model:     settings.AIModel,
+			MaxTokens: settings.AIMaxTokens,
+			System:    system,
+			User:      instructions,
+			JSON:      true,
+		}
+
+		response, err := client.RunPromptContext(ctx, config, prompt)
+		if err != nil {
+			return generated, err
+		}
+
+		entries, err := target.parseEntries(response)
+		if err != nil {
+			log.Error(err, log.WithValue("response", response))
+
+			prompt.User = fmt.Sprintf("Your previous response was not valid (%s). Respond again with only the corrected JSON.\n\nPrevious response:\n\n%s", err.Error(), response)
+			response, err = client.RunPromptContext(ctx, config, prompt)
+			if err != nil {
+				return generated, err
+			}
+
+			entries, err = target.parseEntries(response)
+			if err != nil {
+				return generated, err
+			}
+		}
+
+		if len(entries) > batch {
+			entries = entries[:batch]
+		}
+
+		for i := range entries {
+			entries[i].ID = generateEntryID()
+			target.names = append(target.names, entries[i].Name)
+		}
+		generated = append(generated, entries...)
+	}
+
+	return generated, nil
+}
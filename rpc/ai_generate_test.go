@@ -0,0 +1,37 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/BigJk/snd"
+	"github.com/BigJk/snd/ai"
+)
+
+func TestGenerateEntriesStopsWhenCancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "10")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	target := entryTarget{name: "Items", shape: ai.ShapeOf(map[string]any{"price": 10})}
+	config := ai.ProviderConfig{Provider: ai.ProviderOpenAI, Endpoint: server.URL, APIKey: "key"}
+
+	// A closed request cancels its context
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(time.Millisecond*50, cancel)
+
+	start := time.Now()
+	entries, err := generateEntries(ctx, ai.NewClient(server.Client()), config, snd.Settings{AIModel: "model"}, target, "", 5)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected cancellation, got %v", err)
+	}
+	if len(entries) != 0 || time.Since(start) > time.Second*5 {
+		t.Fatalf("expected generation to stop right away, got %d entries in %s", len(entries), time.Since(start))
+	}
+}
@@ -0,0 +1,226 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/BigJk/snd"
+	"github.com/BigJk/snd/ai"
+	"github.com/BigJk/snd/ai/retrieval"
+	"github.com/BigJk/snd/database"
+	"github.com/BigJk/snd/log"
+)
+
+const (
+	// embeddingsPrefix is the key prefix of the cached embeddings of the entries of a data source.
+	embeddingsPrefix = "AI_EMBEDDINGS_"
+	// embeddingBatchSize is the number of texts that are embedded with a single request.
+	embeddingBatchSize = 64
+	// embeddingMaxText limits the text of an entry that is embedded, as embedding models have small context windows.
+	embeddingMaxText = 2000
+	// retrievalCandidates is the number of entries that each ranking contributes before they are fused.
+	retrievalCandidates = 100
+	// retrievalDefaultEntries is the number of entries that are added to a prompt if no context window is set.
+	retrievalDefaultEntries = 10
+)
+
+// cachedEmbedding is the embedding of an entry together with the hash of the text it was created from.
+type cachedEmbedding struct {
+	Hash   string    `json:"hash"`
+	Vector []float64 `json:"vector"`
+}
+
+func embeddingsKey(model string, source string) string {
+	return embeddingsPrefix + shortHash(model) + "_" + source
+}
+
+// loadDocuments loads the entries of the data sources or templates as documents.
+func loadDocuments(db database.Database, sources []string) ([]retrieval.Document, error) {
+	var docs []retrieval.Document
+	for _, source := range sources {
+		entries, err := db.GetEntries(source)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range entries {
+			docs = append(docs, retrieval.NewDocument(source, entries[i]))
+		}
+	}
+	return docs, nil
+}
+
+func embeddingText(text string) string {
+	if len(text) > embeddingMaxText {
+		return strings.ToValidUTF8(text[:embeddingMaxText], "")
+	}
+	return text
+}
+
+// embedDocuments returns the embeddings of the documents in the same order. Embeddings are cached
+// per data source and only created for entries that are new or changed since the last time.
+func embedDocuments(ctx context.Context, db database.Database, client *ai.Client, config ai.ProviderConfig, model string, docs []retrieval.Document) ([][]float64, error) {
+	caches := map[string]map[string]cachedEmbedding{}
+	for i := range docs {
+		if _, ok := caches[docs[i].Source]; ok {
+			continue
+		}
+
+		cache := map[string]cachedEmbedding{}
+		if data, err := db.GetKey(embeddingsKey(model, docs[i].Source)); err == nil {
+			if err := json.Unmarshal([]byte(data), &cache); err != nil {
+				log.Error(err, log.WithValue("source", docs[i].Source))
+			}
+		}
+		caches[docs[i].Source] = cache
+	}
+
+	embeddings := make([][]float64, len(docs))
+	var missing []int
+	for i := range docs {
+		cached, ok := caches[docs[i].Source][docs[i].Entry.ID]
+		if ok && cached.Hash == shortHash(embeddingText(docs[i].Text)) {
+			embeddings[i] = cached.Vector
+		} else {
+			missing = append(missing, i)
+		}
+	}
+
+	if len(missing) == 0 {
+		return embeddings, nil
+	}
+
+	changed := map[string]bool{}
+	for start := 0; start < len(missing); start += embeddingBatchSize {
+		batch := missing[start:min(start+embeddingBatchSize, len(missing))]
+
+		texts := make([]string, len(batch))
+		for i, doc := range batch {
+			texts[i] = embeddingText(docs[doc].Text)
+		}
+
+		vectors, err := client.EmbedContext(ctx, config, model, texts)
+		if err != nil {
+			return nil, err
+		}
+
+		for i, doc := range batch {
+			embeddings[doc] = vectors[i]
+			caches[docs[doc].Source][docs[doc].Entry.ID] = cachedEmbedding{Hash: shortHash(texts[i]), Vector: vectors[i]}
+			changed[docs[doc].Source] = true
+		}
+	}
+
+	for source := range changed {
+		// Entries that were deleted from the source don't need to be cached anymore
+		current := map[string]bool{}
+		for i := range docs {
+			if docs[i].Source == source {
+				current[docs[i].Entry.ID] = true
+			}
+		}
+		for id := range caches[source] {
+			if !current[id] {
+				delete(caches[source], id)
+			}
+		}
+
+		data, err := json.Marshal(caches[source])
+		if err != nil {
+			return nil, err
+		}
+
+		if err := db.SetKey(embeddingsKey(model, source), string(data)); err != nil {
+			return nil, err
+		}
+	}
+
+	return embeddings, nil
+}
+
+// retrieveEntries returns the entries of the sources that are most relevant for the query. The
+// entries are ranked with BM25 and, if an embedding model is set, by the similarity of the
+// embeddings. If the embeddings fail the BM25 ranking is used on its own.
+//
+// With fill, entries that don't match the query are added after the matching ones until the limit
+// is reached. Without embeddings a request like "use items from our source" often matches no entry
+// by its words, but should still see the entries.
+func retrieveEntries(ctx context.Context, db database.Database, client *ai.Client, config ai.ProviderConfig, settings snd.Settings, sources []string, query string, limit int, fill bool) ([]retrieval.Result, error) {
+	docs, err := loadDocuments(db, sources)
+	if err != nil {
+		return nil, err
+	}
+
+	results := retrieval.NewIndex(docs).Search(query, retrievalCandidates)
+	if strings.TrimSpace(settings.AIEmbeddingModel) != "" && len(docs) > 0 {
+		if embeddings, err := embedDocuments(ctx, db, client, config, settings.AIEmbeddingModel, docs); err != nil {
+			log.Error(err, log.WithValue("model", settings.AIEmbeddingModel))
+		} else if queryEmbedding, err := client.EmbedContext(ctx, config, settings.AIEmbeddingModel, []string{query}); err != nil {
+			log.Error(err, log.WithValue("model", settings.AIEmbeddingModel))
+		} else {
+			results = retrieval.Fuse(retrievalCandidates, results, retrieval.RankByEmbedding(docs, embeddings, queryEmbedding[0], retrievalCandidates))
+		}
+
+		// A closed request doesn't need the entries anymore
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	if fill && len(results) < limit {
+		found := map[string]bool{}
+		for i := range results {
+			found[results[i].Source+results[i].Entry.ID] = true
+		}
+
+		for i := range docs {
+			if len(results) >= limit {
+				break
+			}
+			if !found[docs[i].Source+docs[i].Entry.ID] {
+				results = append(results, retrieval.Result{Document: docs[i]})
+			}
+		}
+	}
+
+	return results[:min(limit, len(results))], nil
+}
+
+// groundedSystemPrompt adds the entries to the system prompt. If contextWindow is set, entries are
+// added as long as the prompt stays inside the window, otherwise a fixed number of entries is added.
+func groundedSystemPrompt(system string, results []retrieval.Result, contextWindow int) (string, error) {
+	if len(results) == 0 {
+		return system, nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString(system)
+	sb.WriteString("\n\nUse the following entries from the data sources of the user where they are relevant. Prefer them over inventing new content:")
+
+	added := 0
+	for i := range results {
+		if contextWindow <= 0 && i == retrievalDefaultEntries {
+			break
+		}
+
+		data, err := json.Marshal(results[i].Entry.Data)
+		if err != nil {
+			return "", err
+		}
+
+		text := fmt.Sprintf("\n\n%s: %s", results[i].Entry.Name, data)
+		if contextWindow > 0 && sb.Len()+len(text) > contextWindow {
+			break
+		}
+		sb.WriteString(text)
+		added++
+	}
+
+	if added == 0 {
+		return system, nil
+	}
+
+	return sb.String(), nil
+}
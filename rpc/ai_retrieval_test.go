@@ -0,0 +1,150 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/BigJk/snd"
+	"github.com/BigJk/snd/ai"
+	"github.com/BigJk/snd/ai/retrieval"
+	"github.com/BigJk/snd/database/badger"
+)
+
+func TestRetrieveEntries(t *testing.T) {
+	// Texts about fire get one embedding and all other texts another one
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+
+		var body struct {
+			Input []string `json:"input"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Error(err)
+		}
+
+		var data []string
+		for i, text := range body.Input {
+			vector := "[0, 1]"
+			if strings.Contains(text, "fire") || strings.Contains(text, "burn") {
+				vector = "[1, 0]"
+			}
+			data = append(data, fmt.Sprintf(`{"index": %d, "embedding": %s}`, i, vector))
+		}
+
+		_, _ = fmt.Fprintf(w, `{"data": [%s]}`, strings.Join(data, ","))
+	}))
+	defer server.Close()
+
+	db, err := badger.New(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	for _, entry := range []snd.Entry{
+		{ID: "goblin", Name: "Goblin", Data: map[string]any{"description": "A small humanoid."}},
+		{ID: "orc", Name: "Orc", Data: map[string]any{"description": "A large humanoid."}},
+		{ID: "dragon", Name: "Red Dragon", Data: map[string]any{"description": "Breathes fire."}},
+	} {
+		if err := db.SaveEntry("ds:monsters", entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	client := ai.NewClient(server.Client())
+	config := ai.ProviderConfig{Provider: ai.ProviderOpenAI, Endpoint: server.URL, APIKey: "key"}
+	settings := snd.Settings{AIEmbeddingModel: "text-embedding-3-small"}
+	sources := []string{"ds:monsters"}
+
+	// No word of the query is in the entries, only the embeddings find the dragon
+	results, err := retrieveEntries(context.Background(), db, client, config, settings, sources, "a beast that burns villages", 1, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Entry.ID != "dragon" || requests.Load() != 2 {
+		t.Fatalf("expected the dragon with 2 requests, got %+v with %d requests", results, requests.Load())
+	}
+
+	// The embeddings of the entries are cached
+	if _, err := retrieveEntries(context.Background(), db, client, config, settings, sources, "burning", 1, false); err != nil {
+		t.Fatal(err)
+	}
+	if requests.Load() != 3 {
+		t.Fatalf("expected only the query to be embedded, got %d requests", requests.Load())
+	}
+
+	// A closed request stops the retrieval
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := retrieveEntries(ctx, db, client, config, settings, sources, "a new query", 1, true); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected cancellation, got %v", err)
+	}
+
+	// Without embeddings the entries that don't match are filled in
+	results, err = retrieveEntries(context.Background(), db, client, config, snd.Settings{}, sources, "orc", 5, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 3 || results[0].Entry.ID != "orc" || results[0].Score == 0 || results[1].Score != 0 {
+		t.Fatalf("expected the orc first and the other entries after it, got %+v", results)
+	}
+}
+
+func TestGroundedSystemPrompt(t *testing.T) {
+	var results []retrieval.Result
+	for i := 0; i < retrievalDefaultEntries+2; i++ {
+		results = append(results, retrieval.Result{Document: retrieval.Document{Entry: snd.Entry{Name: fmt.Sprintf("Entry %d", i), Data: map[string]any{"hp": i}}}})
+	}
+
+	cases := []struct {
+		name          string
+		results       []retrieval.Result
+		contextWindow int
+		entries       []string
+	}{
+		{name: "no results", results: nil, contextWindow: 0},
+		{name: "default number of entries", results: results, contextWindow: 0, entries: []string{"Entry 0", "Entry 9"}},
+		{name: "context window", results: results, contextWindow: 200, entries: []string{"Entry 0", "Entry 1"}},
+		{name: "window too small", results: results, contextWindow: 10},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			prompt, err := groundedSystemPrompt("You are a game master.", c.results, c.contextWindow)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(c.entries) == 0 {
+				if prompt != "You are a game master." {
+					t.Fatalf("expected the system prompt to be unchanged, got %q", prompt)
+				}
+				return
+			}
+
+			if !strings.HasPrefix(prompt, "You are a game master.\n\n") || (c.contextWindow > 0 && len(prompt) > c.contextWindow) {
+				t.Fatalf("unexpected prompt %q", prompt)
+			}
+
+			var names []string
+			for _, line := range strings.Split(prompt, "\n\n")[2:] {
+				name, _, _ := strings.Cut(line, ":")
+				names = append(names, name)
+			}
+
+			if first, last := names[0], names[len(names)-1]; !reflect.DeepEqual([]string{first, last}, c.entries) {
+				t.Fatalf("expected the entries %v, got %v", c.entries, names)
+			}
+		})
+	}
+}
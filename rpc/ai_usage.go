@@ -0,0 +1,397 @@
+package rpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/BigJk/snd"
+	"github.com/BigJk/snd/ai"
+	"github.com/BigJk/snd/database"
+	"github.com/BigJk/snd/log"
+	"github.com/samber/lo"
+)
+
+const (
+	// usagePrefix is the key prefix of the AI usage of a single day.
+	usagePrefix = "AI_USAGE_"
+	// usageDateFormat is the format of the day in the usage keys.
+	usageDateFormat = "2006-01-02"
+	// pricingTTL is how long the pricing of the models of a provider is cached.
+	pricingTTL = time.Hour
+	// pricingErrorTTL is how long to wait before fetching the pricing again after it failed.
+	pricingErrorTTL = time.Minute * 10
+)
+
+// UsageTotals is the accumulated usage of AI prompts.
+type UsageTotals struct {
+	Requests         int `json:"requests"`
+	PromptTokens     int `json:"promptTokens"`
+	CompletionTokens int `json:"completionTokens"`
+	// EstimatedTokens is the part of the tokens that were estimated, because the provider didn't report the usage.
+	EstimatedTokens int `json:"estimatedTokens"`
+	// UnpricedTokens is the part of the tokens of models without known pricing. They are not part of the cost.
+	UnpricedTokens int `json:"unpricedTokens"`
+	// Cost is the cost in USD.
+	Cost float64 `json:"cost"`
+}
+
+// Tokens returns the number of all tokens.
+func (t UsageTotals) Tokens() int {
+	return t.PromptTokens + t.CompletionTokens
+}
+
+func (t *UsageTotals) add(other UsageTotals) {
+	t.Requests += other.Requests
+	t.PromptTokens += other.PromptTokens
+	t.CompletionTokens += other.CompletionTokens
+	t.EstimatedTokens += other.EstimatedTokens
+	t.UnpricedTokens += other.UnpricedTokens
+	t.Cost += other.Cost
+}
+
+// ModelUsage is the usage of a single model.
+type ModelUsage struct {
+	Provider string `json:"provider"`
+	Model    string `json:"model"`
+	UsageTotals
+}
+
+// DayUsage is the usage of a single day.
+type DayUsage struct {
+	Date   string       `json:"date"`
+	Models []ModelUsage `json:"models"`
+	Total  UsageTotals  `json:"total"`
+}
+
+// UsageReport contains the usage of the last days together with the configured limits.
+type UsageReport struct {
+	Days  []DayUsage  `json:"days"`
+	Total UsageTotals `json:"total"`
+	Today UsageTotals `json:"today"`
+
+	DailyBudget     float64 `json:"dailyBudget"`
+	DailyTokenLimit int     `json:"dailyTokenLimit"`
+	RateLimit       int     `json:"rateLimit"`
+	// RecentRequests is the number of requests in the last minute.
+	RecentRequests int `json:"recentRequests"`
+	// BudgetWarning is set if the daily budget is set but the pricing of the used models is unknown.
+	BudgetWarning string `json:"budgetWarning"`
+}
+
+type modelPricing struct {
+	models  map[string]ai.Model
+	expires time.Time
+}
+
+// usageTracker records the usage of all prompts of the ai client and enforces the budgets and
+// rate limit of the settings.
+type usageTracker struct {
+	sync.Mutex
+	db       database.Database
+	client   *ai.Client
+	requests []time.Time
+	pricing  map[string]modelPricing
+	// refreshing contains the providers whose pricing is currently fetched.
+	refreshing map[string]bool
+}
+
+func newUsageTracker(db database.Database, client *ai.Client) *usageTracker {
+	return &usageTracker{
+		db:         db,
+		client:     client,
+		pricing:    map[string]modelPricing{},
+		refreshing: map[string]bool{},
+	}
+}
+
+func usageKey(date time.Time) string {
+	return usagePrefix + date.Format(usageDateFormat)
+}
+
+// loadDay returns the usage of the day. If nothing was used on the day an empty usage is returned.
+func (t *usageTracker) loadDay(date time.Time) DayUsage {
+	day := DayUsage{Date: date.Format(usageDateFormat)}
+
+	data, err := t.db.GetKey(usageKey(date))
+	if err != nil {
+		return day
+	}
+
+	if err := json.Unmarshal([]byte(data), &day.Models); err != nil {
+		log.Error(err, log.WithValue("date", day.Date))
+		return day
+	}
+
+	for i := range day.Models {
+		day.Total.add(day.Models[i].UsageTotals)
+	}
+
+	return day
+}
+
+func (t *usageTracker) saveDay(date time.Time, day DayUsage) error {
+	data, err := json.Marshal(day.Models)
+	if err != nil {
+		return err
+	}
+
+	return t.db.SetKey(usageKey(date), string(data))
+}
+
+// recentRequests removes the requests that are older than a minute and returns the remaining ones.
+func (t *usageTracker) recentRequests() int {
+	cutoff := time.Now().Add(-time.Minute)
+	for len(t.requests) > 0 && t.requests[0].Before(cutoff) {
+		t.requests = t.requests[1:]
+	}
+	return len(t.requests)
+}
+
+func (t *usageTracker) Allow(config ai.ProviderConfig, prompt ai.Prompt) error {
+	settings, err := t.db.GetSettings()
+	if err != nil {
+		return err
+	}
+
+	// The pricing is fetched while the prompt runs, so that it is known when the usage is tracked
+	t.refreshPricing(config)
+
+	t.Lock()
+	defer t.Unlock()
+
+	if settings.AIRateLimit > 0 && t.recentRequests() >= settings.AIRateLimit {
+		return fmt.Errorf("AI rate limit of %d requests per minute reached", settings.AIRateLimit)
+	}
+
+	if settings.AIDailyBudget > 0 || settings.AIDailyTokenLimit > 0 {
+		today := t.loadDay(time.Now())
+
+		// Local providers are free, so the budget doesn't need to stop them
+		if settings.AIDailyBudget > 0 && !ai.IsLocalProvider(config.Provider) && today.Total.Cost >= settings.AIDailyBudget {
+			return fmt.Errorf("daily AI budget of $%.2f reached", settings.AIDailyBudget)
+		}
+
+		if settings.AIDailyTokenLimit > 0 && today.Total.Tokens() >= settings.AIDailyTokenLimit {
+			return fmt.Errorf("daily AI limit of %d tokens reached", settings.AIDailyTokenLimit)
+		}
+	}
+
+	// Requests count towards the rate limit even if they fail, so that retry loops are limited as well
+	t.requests = append(t.requests, time.Now())
+
+	return nil
+}
+
+// refreshPricing fetches the models of the provider including the pricing in the background if
+// they are not cached or expired, so that no prompt has to wait for it. Local providers are
+// free, so the models are not fetched for them.
+func (t *usageTracker) refreshPricing(config ai.ProviderConfig) {
+	if ai.IsLocalProvider(config.Provider) {
+		return
+	}
+
+	key := config.Provider + config.Endpoint
+
+	t.Lock()
+	defer t.Unlock()
+
+	if pricing, ok := t.pricing[key]; (ok && time.Now().Before(pricing.expires)) || t.refreshing[key] {
+		return
+	}
+	t.refreshing[key] = true
+
+	go func() {
+		models, err := t.client.Models(config)
+
+		t.Lock()
+		defer t.Unlock()
+
+		delete(t.refreshing, key)
+
+		// The previous pricing is kept if the models can't be fetched
+		if err != nil {
+			log.Error(err, log.WithValue("provider", config.Provider))
+
+			pricing := t.pricing[key]
+			pricing.expires = time.Now().Add(pricingErrorTTL)
+			t.pricing[key] = pricing
+			return
+		}
+
+		pricing := modelPricing{models: map[string]ai.Model{}, expires: time.Now().Add(pricingTTL)}
+		for i := range models {
+			pricing.models[models[i].ID] = models[i]
+		}
+		t.pricing[key] = pricing
+	}()
+}
+
+// modelInfo returns the information of the model including the pricing. The pricing of the model
+// list of the provider is preferred, for providers that don't report it the list prices are used.
+// The second return value is false if the pricing of the model is unknown.
+func (t *usageTracker) modelInfo(config ai.ProviderConfig, model string) (ai.Model, bool) {
+	if ai.IsLocalProvider(config.Provider) {
+		return ai.Model{}, false
+	}
+
+	t.refreshPricing(config)
+
+	t.Lock()
+	info, ok := t.pricing[config.Provider+config.Endpoint].models[model]
+	t.Unlock()
+
+	if ok && info.Pricing != nil {
+		return info, true
+	}
+
+	return ai.ListPrice(config.Provider, model)
+}
+
+// pricingLoaded checks if the models of the provider were fetched at least once.
+func (t *usageTracker) pricingLoaded(config ai.ProviderConfig) bool {
+	t.Lock()
+	defer t.Unlock()
+
+	return t.pricing[config.Provider+config.Endpoint].models != nil
+}
+
+func (t *usageTracker) Track(config ai.ProviderConfig, prompt ai.Prompt, response ai.Response) {
+	model := prompt.Model
+	if model == "" {
+		model = response.Model
+	}
+	if model == "" {
+		model = "unknown"
+	}
+
+	usage := UsageTotals{
+		Requests:         1,
+		PromptTokens:     response.Usage.PromptTokens,
+		CompletionTokens: response.Usage.CompletionTokens,
+	}
+
+	if response.Usage.Estimated {
+		usage.EstimatedTokens = usage.Tokens()
+	}
+
+	info, ok := t.modelInfo(config, model)
+	if !ok && response.Model != "" {
+		info, ok = t.modelInfo(config, response.Model)
+	}
+
+	cost, priced := info.Cost(response.Usage)
+	if ok && priced {
+		usage.Cost = cost
+	} else {
+		usage.UnpricedTokens = usage.Tokens()
+	}
+
+	t.Lock()
+	defer t.Unlock()
+
+	now := time.Now()
+	day := t.loadDay(now)
+
+	found := false
+	for i := range day.Models {
+		if day.Models[i].Provider == config.Provider && day.Models[i].Model == model {
+			day.Models[i].add(usage)
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		day.Models = append(day.Models, ModelUsage{Provider: config.Provider, Model: model, UsageTotals: usage})
+	}
+
+	if err := t.saveDay(now, day); err != nil {
+		log.Error(err, log.WithValue("model", model))
+	}
+}
+
+// budgetWarning returns a warning if the daily budget is set, but the pricing of the selected models
+// or the models that were used today is unknown. Their usage doesn't count towards the budget.
+func (t *usageTracker) budgetWarning(settings snd.Settings) string {
+	if settings.AIDailyBudget <= 0 {
+		return ""
+	}
+
+	var unpriced []string
+	if !ai.IsLocalProvider(settings.AIProvider) {
+		config, err := providerConfig(t.db, settings.AIProvider, settings.AIApiKey)
+		if err == nil {
+			// Until the models are fetched only the list prices are known
+			t.refreshPricing(config)
+			if t.pricingLoaded(config) {
+				for _, model := range []string{settings.AIModel, settings.AICodingModel, settings.AIEmbeddingModel} {
+					if _, ok := t.modelInfo(config, model); len(model) > 0 && !ok {
+						unpriced = append(unpriced, model)
+					}
+				}
+			}
+		}
+	}
+
+	t.Lock()
+	today := t.loadDay(time.Now())
+	t.Unlock()
+
+	for _, usage := range today.Models {
+		if usage.UnpricedTokens > 0 && !ai.IsLocalProvider(usage.Provider) {
+			unpriced = append(unpriced, usage.Model)
+		}
+	}
+
+	if len(unpriced) == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("The pricing of %s is unknown, so the usage doesn't count towards the daily budget. Use the daily token limit to limit it.", strings.Join(lo.Uniq(unpriced), ", "))
+}
+
+// Report returns the usage of the last days, including today.
+func (t *usageTracker) Report(days int) (UsageReport, error) {
+	settings, err := t.db.GetSettings()
+	if err != nil {
+		return UsageReport{}, err
+	}
+
+	budgetWarning := t.budgetWarning(settings)
+
+	t.Lock()
+	defer t.Unlock()
+
+	report := UsageReport{
+		DailyBudget:     settings.AIDailyBudget,
+		DailyTokenLimit: settings.AIDailyTokenLimit,
+		RateLimit:       settings.AIRateLimit,
+		RecentRequests:  t.recentRequests(),
+		BudgetWarning:   budgetWarning,
+	}
+
+	now := time.Now()
+	for i := 0; i < days; i++ {
+		day := t.loadDay(now.AddDate(0, 0, -i))
+		if i == 0 {
+			report.Today = day.Total
+		}
+
+		if len(day.Models) == 0 {
+			continue
+		}
+
+		sort.Slice(day.Models, func(a, b int) bool {
+			return day.Models[a].Tokens() > day.Models[b].Tokens()
+		})
+
+		report.Total.add(day.Total)
+		report.Days = append(report.Days, day)
+	}
+
+	return report, nil
+}
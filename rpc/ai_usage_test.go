@@ -0,0 +1,103 @@
+package rpc
+
+import (
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/BigJk/snd"
+	"github.com/BigJk/snd/ai"
+	"github.com/BigJk/snd/database/badger"
+)
+
+func TestUsageTrackerBudget(t *testing.T) {
+	var modelRequests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		modelRequests.Add(1)
+
+		// The model list of OpenAI has no pricing
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data": [{"id": "gpt-4o-mini"}, {"id": "custom-model"}]}`))
+	}))
+	defer server.Close()
+
+	db, err := badger.New(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if err := db.SaveSettings(snd.Settings{AIProvider: ai.ProviderOllama, AIDailyBudget: 0.5}); err != nil {
+		t.Fatal(err)
+	}
+
+	tracker := newUsageTracker(db, ai.NewClient(server.Client()))
+	config := ai.ProviderConfig{Provider: ai.ProviderOpenAI, Endpoint: server.URL, APIKey: "key"}
+
+	if err := tracker.Allow(config, ai.Prompt{Model: "gpt-4o-mini"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The pricing is fetched in the background
+	for start := time.Now(); !tracker.pricingLoaded(config); time.Sleep(time.Millisecond * 10) {
+		if time.Since(start) > time.Second*5 {
+			t.Fatal("expected the pricing to be fetched")
+		}
+	}
+
+	usage := ai.Usage{PromptTokens: 1_000_000, CompletionTokens: 1_000_000}
+	tracker.Track(config, ai.Prompt{Model: "gpt-4o-mini-2024-07-18"}, ai.Response{Usage: usage})
+	tracker.Track(config, ai.Prompt{Model: "custom-model"}, ai.Response{Usage: usage})
+
+	report, err := tracker.Report(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if math.Abs(report.Today.Cost-0.75) > 1e-9 || report.Today.UnpricedTokens != 2_000_000 {
+		t.Fatalf("expected the list price to be used, got %+v", report.Today)
+	}
+	if !strings.Contains(report.BudgetWarning, "custom-model") || strings.Contains(report.BudgetWarning, "gpt-4o-mini") {
+		t.Fatalf("expected a warning for the unpriced model, got '%s'", report.BudgetWarning)
+	}
+
+	if err := tracker.Allow(config, ai.Prompt{Model: "gpt-4o-mini"}); err == nil || !strings.Contains(err.Error(), "budget") {
+		t.Fatalf("expected the budget to be reached, got %v", err)
+	}
+
+	if n := modelRequests.Load(); n != 1 {
+		t.Fatalf("expected the models to be fetched once, got %d", n)
+	}
+}
+
+func TestUsageTrackerWarnsForUnpricedSelectedModel(t *testing.T) {
+	db, err := badger.New(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	settings := snd.Settings{AIProvider: ai.ProviderAnthropic, AIModel: "claude-sonnet-4-5-20250929", AIEmbeddingModel: "embedding", AIDailyBudget: 1}
+	if err := db.SaveSettings(settings); err != nil {
+		t.Fatal(err)
+	}
+
+	tracker := newUsageTracker(db, ai.NewClient(&http.Client{}))
+
+	// The models were already fetched, so no request is made
+	endpoint, _ := ai.EndpointForProvider(ai.ProviderAnthropic, "")
+	tracker.pricing[ai.ProviderAnthropic+endpoint] = modelPricing{models: map[string]ai.Model{}, expires: time.Now().Add(time.Hour)}
+
+	if warning := tracker.budgetWarning(settings); !strings.Contains(warning, "embedding") || strings.Contains(warning, "claude") {
+		t.Fatalf("expected a warning for the embedding model, got '%s'", warning)
+	}
+
+	settings.AIDailyBudget = 0
+	if warning := tracker.budgetWarning(settings); warning != "" {
+		t.Fatalf("expected no warning without budget, got '%s'", warning)
+	}
+}
@@ -0,0 +1,85 @@
+package rpc
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/BigJk/snd/auth"
+	"github.com/BigJk/snd/rpc/bind"
+	"github.com/labstack/echo/v4"
+)
+
+// AuthStatus tells the frontend if a login is needed and what the current user can access.
+type AuthStatus struct {
+	Enabled bool `json:"enabled"`
+	// Identity is nil if the request is not authenticated.
+	Identity    *auth.Identity   `json:"identity"`
+	Permissions auth.Permissions `json:"permissions"`
+}
+
+// RegisterLogin registers the login of the UI. These routes have to be public.
+func RegisterLogin(route *echo.Group, store *auth.Store) {
+	// The body contains the name and password as json array like the other rpc functions.
+	route.POST("/auth/login", func(c echo.Context) error {
+		var args []string
+		if err := json.NewDecoder(c.Request().Body).Decode(&args); err != nil || len(args) != 2 {
+			return c.JSON(http.StatusBadRequest, "expected name and password as arguments")
+		}
+
+		token, identity, err := store.Login(args[0], args[1])
+		if err != nil {
+			return c.JSON(http.StatusUnauthorized, err.Error())
+		}
+
+		c.SetCookie(auth.SessionCookie(token, c.IsTLS()))
+		return c.JSON(http.StatusOK, identity)
+	})
+
+	route.POST("/auth/logout", func(c echo.Context) error {
+		if cookie, err := c.Cookie(auth.CookieName); err == nil {
+			if err := store.Logout(cookie.Value); err != nil {
+				return c.JSON(http.StatusBadRequest, err.Error())
+			}
+		}
+
+		c.SetCookie(auth.SessionCookie("", c.IsTLS()))
+		return c.NoContent(http.StatusOK)
+	})
+
+	route.GET("/auth/status", func(c echo.Context) error {
+		status := AuthStatus{Enabled: store.Enabled()}
+		if identity, ok := auth.IdentityFrom(c); ok {
+			status.Identity = &identity
+			status.Permissions = store.Permissions(identity.Role)
+		}
+		return c.JSON(http.StatusOK, status)
+	})
+}
+
+// RegisterAuth registers the management of users, tokens and permissions. The bindings can't decode
+// named types, so roles and permissions are passed as plain strings.
+func RegisterAuth(route *echo.Group, store *auth.Store) {
+	bind.MustBind(route, "/getAuthConfig", func() (auth.Config, error) {
+		return store.Config(), nil
+	})
+	bind.MustBind(route, "/setAuthEnabled", store.SetEnabled)
+	bind.MustBind(route, "/setAuthTrustLocal", store.SetTrustLocal)
+	bind.MustBind(route, "/setAuthPermissions", func(role string, permissions map[string]any) error {
+		converted := auth.Permissions{}
+		for group, access := range permissions {
+			value, _ := access.(string)
+			converted[auth.Group(group)] = auth.Access(value)
+		}
+		return store.SetPermissions(auth.Role(role), converted)
+	})
+	bind.MustBind(route, "/saveAuthUser", func(name string, password string, role string) error {
+		return store.SaveUser(name, password, auth.Role(role))
+	})
+	bind.MustBind(route, "/deleteAuthUser", store.DeleteUser)
+
+	// Returns the token. It is only shown once, as only its hash is stored.
+	bind.MustBind(route, "/createAuthToken", func(name string, role string) (string, error) {
+		return store.CreateToken(name, auth.Role(role))
+	})
+	bind.MustBind(route, "/deleteAuthToken", store.DeleteToken)
+}
@@ -3,6 +3,7 @@ package bind
 import (
 	"github.com/BigJk/nra"
 	"github.com/labstack/echo/v4"
+	"net/http"
 	"reflect"
 	"strings"
 )
@@ -21,10 +22,13 @@ var functions = make(map[string]Function)
 func MustBind(handler PostHandler, path string, fn interface{}, m ...echo.MiddlewareFunc) {
 	fnType := reflect.TypeOf(fn)
 
-	argNum := fnType.NumIn()
-	args := make([]string, argNum)
-	for i := 0; i < argNum; i++ {
-		args[i] = fnType.In(i).String()
+	// A leading *http.Request is passed by nra and is not an argument of the caller
+	args := []string{}
+	for i := 0; i < fnType.NumIn(); i++ {
+		if i == 0 && fnType.In(i) == reflect.TypeOf(new(http.Request)) {
+			continue
+		}
+		args = append(args, fnType.In(i).String())
 	}
 
 	name := strings.TrimLeft(path, "/")
@@ -0,0 +1,93 @@
+package rpc
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/BigJk/snd/fonts"
+	"github.com/BigJk/snd/local"
+	"github.com/BigJk/snd/rpc/bind"
+	"github.com/labstack/echo/v4"
+	"github.com/vincent-petithory/dataurl"
+)
+
+// fontFaces returns a style tag containing the @font-face rules of the font library.
+func fontFaces() (string, error) {
+	ip, err := GetOutboundIP()
+	if err != nil {
+		return "", err
+	}
+
+	css, err := fonts.FontFaces(local.HostURL(ip.String(), "/api/fonts/"))
+	if err != nil || len(css) == 0 {
+		return "", err
+	}
+
+	return "<style>\n" + css + "</style>", nil
+}
+
+func RegisterFonts(route *echo.Group) {
+	bind.MustBind(route, "/getFonts", fonts.List)
+
+	// addFont adds a font from a file path or data uri. If the name is empty
+	// the name of the file is used.
+	bind.MustBind(route, "/addFont", func(name string, file string) (fonts.Font, error) {
+		var data []byte
+
+		if strings.HasPrefix(file, "data:") {
+			decoded, err := dataurl.DecodeString(file)
+			if err != nil {
+				return fonts.Font{}, err
+			}
+			data = decoded.Data
+
+			if len(name) == 0 {
+				return fonts.Font{}, errors.New("a name is needed for fonts from data uris")
+			}
+		} else {
+			read, err := os.ReadFile(file)
+			if err != nil {
+				return fonts.Font{}, err
+			}
+			data = read
+
+			if len(name) == 0 {
+				name = fonts.NameFromFile(file)
+			}
+		}
+
+		return fonts.Add(name, data)
+	})
+
+	bind.MustBind(route, "/removeFont", fonts.Remove)
+
+	route.GET("/fonts.css", func(c echo.Context) error {
+		css, err := fonts.FontFaces("/api/fonts/")
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, err.Error())
+		}
+		return c.Blob(http.StatusOK, "text/css", []byte(css))
+	})
+
+	route.GET("/fonts/:file", func(c echo.Context) error {
+		file, err := url.PathUnescape(c.Param("file"))
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, err.Error())
+		}
+
+		font, err := fonts.Get(fonts.NameFromFile(file))
+		if err != nil || font.File != file {
+			return c.NoContent(http.StatusNotFound)
+		}
+
+		data, err := fonts.Read(font)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, err.Error())
+		}
+
+		return c.Blob(http.StatusOK, font.MimeType(), data)
+	})
+}
@@ -0,0 +1,309 @@
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/png"
+	"math/rand"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/BigJk/snd"
+	"github.com/BigJk/snd/database"
+	"github.com/BigJk/snd/golden"
+	"github.com/BigJk/snd/local"
+	"github.com/BigJk/snd/rendering"
+	"github.com/BigJk/snd/rpc/bind"
+	"github.com/BigJk/snd/templating"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	GoldenStatusPass  = "pass"
+	GoldenStatusFail  = "fail"
+	GoldenStatusError = "error"
+)
+
+// goldenSeed is used if the config doesn't contain a seed, so that templates
+// using random values render the same every time.
+const goldenSeed = "golden"
+
+// GoldenOptions changes how reference images are rendered and compared.
+type GoldenOptions struct {
+	// Config is the template config the entries are rendered with.
+	Config map[string]any `json:"config"`
+	// Tolerance overwrites the default tolerance of the comparison.
+	Tolerance *golden.Tolerance `json:"tolerance"`
+	// Limit is the maximum amount of entries per template or data source that get
+	// a reference when all entries are approved. If it is 0 all entries are approved.
+	Limit int `json:"limit"`
+	// Images adds the diff and actual images of failed comparisons to the results.
+	Images bool `json:"images"`
+}
+
+// GoldenResult is the result of comparing a single entry with its reference.
+type GoldenResult struct {
+	golden.Comparison
+	// Entry is the reference id of the entry.
+	Entry   string `json:"entry"`
+	Status  string `json:"status"`
+	Message string `json:"message"`
+	// DiffImage and ActualImage are the png encoded images of a failed comparison. They are only
+	// set if the images were requested.
+	DiffImage   []byte `json:"diffImage,omitempty"`
+	ActualImage []byte `json:"actualImage,omitempty"`
+}
+
+// GoldenReport is the result of comparing all references of a template.
+type GoldenReport struct {
+	Template string         `json:"template"`
+	Passed   int            `json:"passed"`
+	Failed   int            `json:"failed"`
+	Results  []GoldenResult `json:"results"`
+}
+
+// goldenEntry returns the entry of the reference id and the id the reference is stored by. The
+// skeleton entry can be referenced with the id "skeleton". A plain entry id is looked up in the
+// template and its data sources.
+func goldenEntry(db database.Database, tmpl snd.Template, ref string) (snd.Entry, string, error) {
+	skeleton := templating.SkeletonEntry(tmpl)
+	if ref == skeleton.ID {
+		return skeleton, skeleton.ID, nil
+	}
+
+	sources := append([]string{tmpl.ID()}, tmpl.DataSources...)
+	for _, sid := range sources {
+		if eid, ok := strings.CutPrefix(ref, sid+"/"); ok {
+			entry, err := db.GetEntry(sid, eid)
+			return entry, ref, err
+		}
+	}
+
+	for _, sid := range sources {
+		if entry, err := db.GetEntry(sid, ref); err == nil {
+			return entry, golden.ReferenceID(sid, ref), nil
+		}
+	}
+
+	return snd.Entry{}, "", fmt.Errorf("entry '%s' not found", ref)
+}
+
+// goldenImage renders the print template for the entry the same way it would be printed.
+func goldenImage(ctx context.Context, db database.Database, renderer *templating.Renderer, tmpl snd.Template, entry snd.Entry, config map[string]any) (image.Image, error) {
+	settings, err := db.GetSettings()
+	if err != nil {
+		return nil, err
+	}
+
+	withSeed := map[string]any{"seed": goldenSeed}
+	for k, v := range config {
+		withSeed[k] = v
+	}
+
+	html, err := templateHtml(ctx, renderer, tmpl, entry, withSeed)
+	if err != nil {
+		return nil, err
+	}
+
+	finalHtml, err := fixHtml(html, settings)
+	if err != nil {
+		return nil, err
+	}
+
+	tempId := fmt.Sprint(rand.Int63())
+	renderCache.SetDefault(tempId, finalHtml)
+
+	return rendering.RenderURLContext(ctx, local.URL("/api/html/"+tempId), settings.PrinterWidth)
+}
+
+// encodePNG encodes the image as png.
+func encodePNG(img image.Image) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	if err := png.Encode(buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// testGolden re-renders all entries that have a reference image and compares them.
+func testGolden(ctx context.Context, db database.Database, renderer *templating.Renderer, tmpl snd.Template, options GoldenOptions) (GoldenReport, error) {
+	refs, err := golden.GetReferences(db, tmpl.ID())
+	if err != nil {
+		return GoldenReport{}, err
+	}
+
+	tolerance := golden.DefaultTolerance
+	if options.Tolerance != nil {
+		tolerance = *options.Tolerance
+	}
+
+	var ids []string
+	for id := range refs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	report := GoldenReport{
+		Template: tmpl.ID(),
+		Results:  []GoldenResult{},
+	}
+
+	for _, eid := range ids {
+		if ctx.Err() != nil {
+			return GoldenReport{}, ctx.Err()
+		}
+
+		res := compareGolden(ctx, db, renderer, tmpl, eid, refs[eid], tolerance, options)
+		if res.Status == GoldenStatusPass {
+			report.Passed++
+		} else {
+			report.Failed++
+		}
+		report.Results = append(report.Results, res)
+	}
+
+	return report, nil
+}
+
+func compareGolden(ctx context.Context, db database.Database, renderer *templating.Renderer, tmpl snd.Template, eid string, ref []byte, tolerance golden.Tolerance, options GoldenOptions) GoldenResult {
+	res := GoldenResult{Entry: eid, Status: GoldenStatusError}
+
+	reference, err := png.Decode(bytes.NewReader(ref))
+	if err != nil {
+		res.Message = fmt.Sprintf("reference is not a valid png: %v", err)
+		return res
+	}
+
+	entry, _, err := goldenEntry(db, tmpl, eid)
+	if err != nil {
+		res.Message = fmt.Sprintf("entry not found: %v", err)
+		return res
+	}
+
+	actual, err := goldenImage(ctx, db, renderer, tmpl, entry, options.Config)
+	if err != nil {
+		res.Message = fmt.Sprintf("rendering failed: %v", err)
+		return res
+	}
+
+	res.Comparison = golden.Compare(reference, actual, tolerance)
+	if res.Pass {
+		res.Status = GoldenStatusPass
+		return res
+	}
+
+	res.Status = GoldenStatusFail
+	if res.SizeMismatch {
+		res.Message = fmt.Sprintf("size changed from %dx%d to %dx%d", reference.Bounds().Dx(), reference.Bounds().Dy(), actual.Bounds().Dx(), actual.Bounds().Dy())
+	} else {
+		res.Message = fmt.Sprintf("%d pixels (%.3f%%) differ", res.DiffPixels, res.DiffRatio*100)
+	}
+
+	if options.Images {
+		if res.DiffImage, err = encodePNG(res.Diff); err != nil {
+			res.Message += fmt.Sprintf(", diff image could not be encoded: %v", err)
+		}
+		if res.ActualImage, err = encodePNG(actual); err != nil {
+			res.Message += fmt.Sprintf(", actual image could not be encoded: %v", err)
+		}
+	}
+
+	return res
+}
+
+func RegisterGolden(route *echo.Group, db database.Database) {
+	renderer := templating.New(db)
+
+	bind.MustBind(route, "/getTemplateReferences", func(id string) ([]string, error) {
+		refs, err := golden.GetReferences(db, id)
+		if err != nil {
+			return nil, err
+		}
+
+		ids := []string{}
+		for eid := range refs {
+			ids = append(ids, eid)
+		}
+		sort.Strings(ids)
+
+		return ids, nil
+	})
+
+	bind.MustBind(route, "/approveTemplateReference", func(req *http.Request, id string, eid string, options GoldenOptions) error {
+		tmpl, err := db.GetTemplate(id)
+		if err != nil {
+			return err
+		}
+
+		entry, ref, err := goldenEntry(db, tmpl, eid)
+		if err != nil {
+			return err
+		}
+
+		img, err := goldenImage(req.Context(), db, renderer, tmpl, entry, options.Config)
+		if err != nil {
+			return err
+		}
+
+		return golden.SaveReference(db, id, ref, img)
+	})
+
+	bind.MustBind(route, "/approveTemplateReferences", func(req *http.Request, id string, options GoldenOptions) (int, error) {
+		tmpl, err := db.GetTemplate(id)
+		if err != nil {
+			return 0, err
+		}
+
+		skeleton := templating.SkeletonEntry(tmpl)
+		entries := []snd.Entry{skeleton}
+		refs := []string{skeleton.ID}
+		for _, sid := range append([]string{tmpl.ID()}, tmpl.DataSources...) {
+			sourceEntries, err := db.GetEntries(sid)
+			if err != nil {
+				return 0, err
+			}
+
+			if options.Limit > 0 && len(sourceEntries) > options.Limit {
+				sourceEntries = sourceEntries[:options.Limit]
+			}
+
+			for _, entry := range sourceEntries {
+				entries = append(entries, entry)
+				refs = append(refs, golden.ReferenceID(sid, entry.ID))
+			}
+		}
+
+		for i := range entries {
+			img, err := goldenImage(req.Context(), db, renderer, tmpl, entries[i], options.Config)
+			if err != nil {
+				return i, fmt.Errorf("rendering of '%s' failed: %w", refs[i], err)
+			}
+
+			if err := golden.SaveReference(db, id, refs[i], img); err != nil {
+				return i, err
+			}
+		}
+
+		return len(entries), nil
+	})
+
+	bind.MustBind(route, "/deleteTemplateReference", func(id string, eid string) error {
+		return golden.DeleteReference(db, id, eid)
+	})
+
+	bind.MustBind(route, "/deleteTemplateReferences", func(id string) error {
+		return golden.DeleteReferences(db, id)
+	})
+
+	bind.MustBind(route, "/testTemplateReferences", func(req *http.Request, id string, options GoldenOptions) (GoldenReport, error) {
+		tmpl, err := db.GetTemplate(id)
+		if err != nil {
+			return GoldenReport{}, err
+		}
+
+		return testGolden(req.Context(), db, renderer, tmpl, options)
+	})
+}
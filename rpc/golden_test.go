@@ -0,0 +1,55 @@
+package rpc
+
+import (
+	"testing"
+
+	"github.com/BigJk/snd"
+	"github.com/BigJk/snd/database/badger"
+)
+
+func TestGoldenEntrySeparatesSources(t *testing.T) {
+	db, err := badger.New(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	first := snd.DataSource{Author: "a", Slug: "first"}
+	second := snd.DataSource{Author: "a", Slug: "second"}
+	tmpl := snd.Template{Author: "a", Slug: "tmpl", DataSources: []string{first.ID(), second.ID()}}
+
+	for _, ds := range []snd.DataSource{first, second} {
+		if err := db.SaveSource(ds); err != nil {
+			t.Fatal(err)
+		}
+		if err := db.SaveEntry(ds.ID(), snd.Entry{ID: "goblin", Name: ds.Slug}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		ref     string
+		name    string
+		storeAs string
+	}{
+		{ref: first.ID() + "/goblin", name: "first", storeAs: first.ID() + "/goblin"},
+		{ref: second.ID() + "/goblin", name: "second", storeAs: second.ID() + "/goblin"},
+		// References stored by the plain entry id resolve to the first match
+		{ref: "goblin", name: "first", storeAs: first.ID() + "/goblin"},
+		{ref: "skeleton", name: "Skeleton", storeAs: "skeleton"},
+	}
+
+	for _, test := range tests {
+		entry, ref, err := goldenEntry(db, tmpl, test.ref)
+		if err != nil {
+			t.Fatalf("%s: %v", test.ref, err)
+		}
+		if entry.Name != test.name || ref != test.storeAs {
+			t.Errorf("%s: got entry %q stored as %q, want %q stored as %q", test.ref, entry.Name, ref, test.name, test.storeAs)
+		}
+	}
+
+	if _, _, err := goldenEntry(db, tmpl, "missing"); err == nil {
+		t.Error("expected an error for a missing entry")
+	}
+}
@@ -0,0 +1,195 @@
+package rpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/BigJk/snd/database"
+	"github.com/BigJk/snd/log"
+	"github.com/BigJk/snd/rpc/bind"
+	rpcImexport "github.com/BigJk/snd/rpc/imexport"
+	"github.com/fsnotify/fsnotify"
+	"github.com/labstack/echo/v4"
+	"github.com/samber/lo"
+	"gopkg.in/olahol/melody.v1"
+)
+
+// hotFolderDelay is the time a file has to stay unchanged before it is imported, so that
+// files that are still copied or synced are not imported half written.
+const hotFolderDelay = time.Second * 2
+
+// Sub-folders of the hot folder that the files are moved to after the import.
+const (
+	hotFolderImported = "imported"
+	hotFolderFailed   = "failed"
+)
+
+// HotFolderResult is the result of the import of a file in the hot folder.
+type HotFolderResult struct {
+	File    string    `json:"file"`
+	Message string    `json:"message"`
+	Error   string    `json:"error"`
+	Time    time.Time `json:"time"`
+}
+
+// RegisterHotFolder watches the folder and imports all files that are dropped into it.
+// Imported files are moved to the "imported" sub-folder. Failed files are moved to the
+// "failed" sub-folder together with a "<file>.error.txt" report.
+func RegisterHotFolder(route *echo.Group, m *melody.Melody, db database.Database, folder string) {
+	type wsEvent struct {
+		Type string      `json:"type"`
+		Data interface{} `json:"data"`
+	}
+
+	resultsMtx := sync.Mutex{}
+	var results []HotFolderResult
+
+	bind.MustBind(route, "/hotFolder", func() (string, error) {
+		return filepath.Abs(folder)
+	})
+
+	// Returns the results of the imports since the start, newest first.
+	bind.MustBind(route, "/hotFolderResults", func() ([]HotFolderResult, error) {
+		resultsMtx.Lock()
+		defer resultsMtx.Unlock()
+
+		return lo.Reverse(append([]HotFolderResult{}, results...)), nil
+	})
+
+	for _, sub := range []string{hotFolderImported, hotFolderFailed} {
+		if err := os.MkdirAll(filepath.Join(folder, sub), 0777); err != nil {
+			_ = log.Error(err, log.WithValue("folder", folder))
+			return
+		}
+	}
+
+	moveFile := func(file string, sub string) string {
+		target := filepath.Join(folder, sub, filepath.Base(file))
+		if _, err := os.Stat(target); err == nil {
+			ext := filepath.Ext(target)
+			target = fmt.Sprintf("%s_%s%s", strings.TrimSuffix(target, ext), time.Now().Format("20060102-150405"), ext)
+		}
+
+		if err := os.Rename(file, target); err != nil {
+			_ = log.Error(err, log.WithValue("file", file))
+		}
+		return target
+	}
+
+	// Imports run one after another, so that files of the same data source don't race
+	importMtx := sync.Mutex{}
+	importFile := func(file string) {
+		importMtx.Lock()
+		defer importMtx.Unlock()
+
+		if info, err := os.Stat(file); err != nil || info.IsDir() {
+			return
+		}
+
+		result := HotFolderResult{File: filepath.Base(file), Time: time.Now()}
+
+		strategy := rpcImexport.DefaultAutoImportStrategy
+		if settings, err := db.GetSettings(); err == nil && len(settings.HotFolderStrategy) > 0 {
+			strategy = settings.HotFolderStrategy
+		}
+
+		message, err := rpcImexport.ImportFile(db, file, strategy)
+		if err != nil {
+			result.Error = err.Error()
+
+			target := moveFile(file, hotFolderFailed)
+			report := fmt.Sprintf("File: %s\nTime: %s\nError: %s\n", result.File, result.Time.Format(time.RFC3339), result.Error)
+			if err := os.WriteFile(target+".error.txt", []byte(report), 0666); err != nil {
+				_ = log.Error(err, log.WithValue("file", target))
+			}
+
+			_ = log.ErrorString(fmt.Sprintf("hot folder import of '%s' failed: %s", result.File, result.Error))
+		} else {
+			result.Message = message
+			moveFile(file, hotFolderImported)
+
+			log.Info("hot folder import", log.WithValue("file", result.File), log.WithValue("result", message))
+		}
+
+		resultsMtx.Lock()
+		results = append(results, result)
+		resultsMtx.Unlock()
+
+		data, _ := json.Marshal(wsEvent{
+			Type: "HotFolderImported",
+			Data: result,
+		})
+		_ = m.Broadcast(data)
+	}
+
+	// Hidden and temporary files of sync tools (e.g. ".dropbox" or "~file.tmp") are ignored
+	ignored := func(file string) bool {
+		base := filepath.Base(file)
+		return strings.HasPrefix(base, ".") || strings.HasPrefix(base, "~") || filepath.Dir(file) != filepath.Clean(folder)
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		_ = log.Error(err, log.WithValue("folder", folder))
+		return
+	}
+	if err := watcher.Add(folder); err != nil {
+		_ = log.Error(err, log.WithValue("folder", folder))
+		return
+	}
+
+	// Files that were dropped while S&D wasn't running
+	existing, _ := os.ReadDir(folder)
+
+	go func() {
+		timersMtx := sync.Mutex{}
+		timers := map[string]*time.Timer{}
+
+		schedule := func(file string) {
+			timersMtx.Lock()
+			defer timersMtx.Unlock()
+
+			if timer, ok := timers[file]; ok {
+				timer.Reset(hotFolderDelay)
+				return
+			}
+
+			timers[file] = time.AfterFunc(hotFolderDelay, func() {
+				timersMtx.Lock()
+				delete(timers, file)
+				timersMtx.Unlock()
+
+				importFile(file)
+			})
+		}
+
+		for _, e := range existing {
+			file := filepath.Join(folder, e.Name())
+			if !e.IsDir() && !ignored(file) {
+				schedule(file)
+			}
+		}
+
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if event.Op&(fsnotify.Create|fsnotify.Write) != 0 && !ignored(event.Name) {
+					schedule(event.Name)
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				_ = log.ErrorString(fmt.Sprintf("error in '%s' watcher: %s", folder, err))
+			}
+		}
+	}()
+}
@@ -0,0 +1,215 @@
+package imexport
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/BigJk/snd"
+	"github.com/BigJk/snd/database"
+	"github.com/BigJk/snd/imexport"
+	"github.com/BigJk/snd/imexport/fightclub5e"
+	"github.com/BigJk/snd/imexport/tools5e"
+)
+
+// DefaultAutoImportStrategy is the merge strategy of automatic imports if none is configured. Entries
+// that were modified locally since the last import are kept, so that a re-import doesn't destroy them.
+const DefaultAutoImportStrategy = imexport.MergeKeepLocal
+
+// autoImportAuthor is the author of imported data that doesn't contain one (e.g. Fight Club 5e compendiums).
+const autoImportAuthor = "import"
+
+var autoSlugRegex = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Kinds of S&D exports.
+const (
+	kindTemplate  = "template"
+	kindGenerator = "generator"
+	kindSource    = "source"
+	kindPrompt    = "prompt"
+)
+
+// exportKind detects if the files of a S&D export belong to a template, generator, data source or prompt.
+func exportKind(files []string) string {
+	has := map[string]bool{}
+	for _, f := range files {
+		has[filepath.Base(f)] = true
+	}
+
+	switch {
+	case has["skeleton.json"] && has["print.html.njk"]:
+		return kindTemplate
+	case has["print.html.njk"]:
+		return kindGenerator
+	case has["entries.json"]:
+		return kindSource
+	case has["system.txt"] && has["user.txt"]:
+		return kindPrompt
+	}
+	return ""
+}
+
+// ImportFile imports a file with the importer that matches its type and content:
+//
+//   - .zip: template, generator, data source or prompt export
+//   - .json: template, generator, data source or prompt export, otherwise 5e.tools data
+//   - .csv: data source
+//   - .xml: Fight Club 5e compendium
+//
+// The entries of data sources and templates are merged with the existing ones using the strategy.
+// A short summary of what was imported is returned.
+func ImportFile(db database.Database, file string, strategy string) (string, error) {
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".zip":
+		reader, err := zip.OpenReader(file)
+		if err != nil {
+			return "", err
+		}
+
+		var files []string
+		for _, f := range reader.File {
+			files = append(files, f.Name)
+		}
+		_ = reader.Close()
+
+		switch exportKind(files) {
+		case kindTemplate:
+			tmpl, entries, attachments, err := imexport.ImportTemplateZIPFile(file)
+			if err != nil {
+				return "", err
+			}
+			return importedTemplate(db, tmpl, entries, attachments, strategy)
+		case kindGenerator:
+			gen, err := imexport.ImportGeneratorZIPFile(file)
+			if err != nil {
+				return "", err
+			}
+			return importedGenerator(db, gen)
+		case kindSource:
+			ds, entries, err := imexport.ImportSourceZIPFile(file)
+			if err != nil {
+				return "", err
+			}
+			return importedSources(db, []snd.DataSource{ds}, [][]snd.Entry{entries}, strategy)
+		case kindPrompt:
+			prompt, err := imexport.ImportPromptZIPFile(file)
+			if err != nil {
+				return "", err
+			}
+			return importedPrompt(db, prompt)
+		}
+
+		return "", errors.New("zip is neither a template, generator, data source nor prompt export")
+	case ".json":
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return "", err
+		}
+
+		var folder imexport.JSONFolder
+		if err := json.Unmarshal(data, &folder); err == nil && len(folder.Files) > 0 {
+			var files []string
+			for name := range folder.Files {
+				files = append(files, name)
+			}
+
+			switch exportKind(files) {
+			case kindTemplate:
+				tmpl, entries, err := imexport.ImportTemplateJSON(string(data))
+				if err != nil {
+					return "", err
+				}
+				return importedTemplate(db, tmpl, entries, nil, strategy)
+			case kindGenerator:
+				gen, err := imexport.ImportGeneratorJSON(string(data))
+				if err != nil {
+					return "", err
+				}
+				return importedGenerator(db, gen)
+			case kindSource:
+				ds, entries, err := imexport.ImportSourceJSON(string(data))
+				if err != nil {
+					return "", err
+				}
+				return importedSources(db, []snd.DataSource{ds}, [][]snd.Entry{entries}, strategy)
+			case kindPrompt:
+				prompt, err := imexport.ImportPromptJSON(string(data))
+				if err != nil {
+					return "", err
+				}
+				return importedPrompt(db, prompt)
+			}
+		}
+
+		sources, entries, err := tools5e.ImportFile(file)
+		if err != nil {
+			return "", err
+		}
+		return importedSources(db, sources, entries, strategy)
+	case ".csv":
+		f, err := os.Open(file)
+		if err != nil {
+			return "", err
+		}
+		defer f.Close()
+
+		ds, entries, err := imexport.ImportDataSourceCSV(f)
+		if err != nil {
+			return "", err
+		}
+		return importedSources(db, []snd.DataSource{ds}, [][]snd.Entry{entries}, strategy)
+	case ".xml":
+		name := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+		slug := strings.Trim(autoSlugRegex.ReplaceAllString(strings.ToLower(name), "-"), "-")
+
+		sources, entries, err := fightclub5e.ImportCompedium(file, name, autoImportAuthor, slug, "")
+		if err != nil {
+			return "", err
+		}
+		return importedSources(db, sources, entries, strategy)
+	}
+
+	return "", fmt.Errorf("unsupported file type '%s'", filepath.Ext(file))
+}
+
+func importedTemplate(db database.Database, tmpl snd.Template, entries []snd.Entry, attachments imexport.Attachments, strategy string) (string, error) {
+	if err := SaveTemplate(db, tmpl, entries, attachments, strategy); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("imported template '%s' with %d entries", tmpl.ID(), len(entries)), nil
+}
+
+func importedGenerator(db database.Database, gen snd.Generator) (string, error) {
+	if err := db.SaveGenerator(gen); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("imported generator '%s'", gen.ID()), nil
+}
+
+func importedPrompt(db database.Database, prompt snd.Prompt) (string, error) {
+	if err := SavePrompt(db, prompt); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("imported prompt '%s'", prompt.ID()), nil
+}
+
+func importedSources(db database.Database, sources []snd.DataSource, entries [][]snd.Entry, strategy string) (string, error) {
+	if len(sources) == 0 {
+		return "", errors.New("no data found")
+	}
+
+	if err := SaveSources(db, sources, entries, strategy); err != nil {
+		return "", err
+	}
+
+	ids := make([]string, len(sources))
+	for i := range sources {
+		ids[i] = fmt.Sprintf("'%s' (%d entries)", sources[i].ID(), len(entries[i]))
+	}
+	return "imported data sources " + strings.Join(ids, ", "), nil
+}
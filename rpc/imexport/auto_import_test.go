@@ -0,0 +1,130 @@
+package imexport
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/BigJk/snd"
+	"github.com/BigJk/snd/database"
+	"github.com/BigJk/snd/database/badger"
+	"github.com/BigJk/snd/imexport"
+)
+
+// failingEntriesDB fails to save entries.
+type failingEntriesDB struct {
+	database.Database
+}
+
+func (f failingEntriesDB) SaveEntries(id string, entries []snd.Entry) error {
+	return errors.New("disk full")
+}
+
+func writeTemplateJSON(t *testing.T, tmpl snd.Template, entries []snd.Entry) string {
+	data, err := imexport.ExportTemplateJSON(tmpl, entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file := filepath.Join(t.TempDir(), "template.json")
+	if err := os.WriteFile(file, data, 0666); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestImportFileKeepsLocalModifications(t *testing.T) {
+	db, err := badger.New(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	tmpl := snd.Template{Name: "Monsters", Author: "a", Slug: "monsters", PrintTemplate: "<div></div>", ListTemplate: "<div></div>", SkeletonData: map[string]any{}}
+	file := writeTemplateJSON(t, tmpl, []snd.Entry{{ID: "goblin", Name: "Goblin", Data: map[string]any{"hp": 7.0}}})
+
+	if _, err := ImportFile(db, file, DefaultAutoImportStrategy); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := db.SaveEntry(tmpl.ID(), snd.Entry{ID: "goblin", Name: "Goblin", Data: map[string]any{"hp": 10.0}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Dropping the same file again must not overwrite the local modification
+	if _, err := ImportFile(db, file, DefaultAutoImportStrategy); err != nil {
+		t.Fatal(err)
+	}
+
+	entry, err := db.GetEntry(tmpl.ID(), "goblin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.Data["hp"] != 10.0 {
+		t.Fatalf("local modification was overwritten: %v", entry.Data)
+	}
+}
+
+func TestImportFileReportsSaveErrors(t *testing.T) {
+	db, err := badger.New(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	tmpl := snd.Template{Name: "Monsters", Author: "a", Slug: "monsters", PrintTemplate: "<div></div>", ListTemplate: "<div></div>", SkeletonData: map[string]any{}}
+	file := writeTemplateJSON(t, tmpl, []snd.Entry{{ID: "goblin", Name: "Goblin"}})
+
+	if _, err := ImportFile(failingEntriesDB{Database: db}, file, DefaultAutoImportStrategy); err == nil {
+		t.Fatal("expected the failed save to be reported")
+	}
+}
+
+func TestImportFileKeepsPromptHistory(t *testing.T) {
+	db, err := badger.New(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	local := snd.Prompt{Name: "Tavern", Author: "a", Slug: "tavern", System: "You are a bard.", User: "Describe a tavern.", Version: "1.0.0"}
+	if err := SavePrompt(db, local); err != nil {
+		t.Fatal(err)
+	}
+
+	local.User = "Describe a busy tavern."
+	local.Version = "1.1.0"
+	if err := SavePrompt(db, local); err != nil {
+		t.Fatal(err)
+	}
+
+	// The export of another user doesn't contain the local history
+	imported := local
+	imported.User = "Describe a quiet tavern."
+	imported.Version = "2.0.0"
+	imported.History = nil
+
+	data, err := imexport.ExportPromptJSON(imported)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file := filepath.Join(t.TempDir(), "prompt.json")
+	if err := os.WriteFile(file, data, 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ImportFile(db, file, DefaultAutoImportStrategy); err != nil {
+		t.Fatal(err)
+	}
+
+	prompt, err := db.GetPrompt(local.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if prompt.User != imported.User || len(prompt.History) != 2 || prompt.History[0].Version != "1.0.0" || prompt.History[1].Version != "1.1.0" {
+		t.Fatalf("expected the imported prompt with the local history, got %+v", prompt)
+	}
+}
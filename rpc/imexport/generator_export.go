@@ -27,9 +27,10 @@ var generatorExports = []GeneratorExport{
 	{
 		ImExport: NewImExport("Folder", "Folder", "Export a folder.",
 			Arg("Folder", "The folder to create the folder in.", "FolderPath", nil),
+			localizeArg,
 		),
 		Func: func(generator snd.Generator, args []any) (string, error) {
-			if len(args) != 1 {
+			if len(args) != 2 {
 				return "", errors.New("invalid number of arguments")
 			}
 
@@ -38,6 +39,15 @@ var generatorExports = []GeneratorExport{
 				return "", errors.New("invalid argument type")
 			}
 
+			localize, ok := args[1].(bool)
+			if !ok {
+				return "", errors.New("invalid argument type")
+			}
+
+			if localize {
+				generator.Images, generator.PrintTemplate = localizeImages(generator.ID(), generator.Images, generator.PrintTemplate)
+			}
+
 			name, err := imexport.ExportGeneratorFolder(generator, folderPath)
 			if err != nil {
 				return "", err
@@ -52,9 +62,10 @@ var generatorExports = []GeneratorExport{
 	{
 		ImExport: NewImExport("ZIP", "ZIP", "Export a ZIP file.",
 			Arg("Folder", "The folder to save the zip.", "FolderPath", nil),
+			localizeArg,
 		),
 		Func: func(generator snd.Generator, args []any) (string, error) {
-			if len(args) != 1 {
+			if len(args) != 2 {
 				return "", errors.New("invalid number of arguments")
 			}
 
@@ -63,6 +74,15 @@ var generatorExports = []GeneratorExport{
 				return "", errors.New("invalid argument type")
 			}
 
+			localize, ok := args[1].(bool)
+			if !ok {
+				return "", errors.New("invalid argument type")
+			}
+
+			if localize {
+				generator.Images, generator.PrintTemplate = localizeImages(generator.ID(), generator.Images, generator.PrintTemplate)
+			}
+
 			name, err := imexport.ExportGeneratorZIPFile(generator, folderPath)
 			if err != nil {
 				return "", err
@@ -1,5 +1,14 @@
 package imexport
 
+import (
+	"github.com/BigJk/snd"
+	"github.com/BigJk/snd/database"
+	"github.com/BigJk/snd/fonts"
+	"github.com/BigJk/snd/golden"
+	"github.com/BigJk/snd/imexport"
+	"github.com/BigJk/snd/log"
+)
+
 type NativeFileSaver interface {
 	SaveFile(fileName string, mimeType string, data []byte) error
 }
@@ -41,3 +50,69 @@ func NewImExport(name, rpcName, description string, arguments ...Argument) ImExp
 		Arguments:   arguments,
 	}
 }
+
+// optionArg returns the selected value of an argument of the type "Options". The frontend
+// sends the whole option config, but a plain string is accepted as well.
+func optionArg(arg any) (string, bool) {
+	switch v := arg.(type) {
+	case string:
+		return v, true
+	case map[string]any:
+		selected, ok := v["selected"].(string)
+		return selected, ok
+	}
+	return "", false
+}
+
+// localizeArg is the argument of exports to download remote images into the export.
+var localizeArg = Arg("Localize Images", "Download remote images into the export, so that it still works when they are gone.", "Checkbox", false)
+
+// localizeImages downloads the remote images of a template or generator. Images that can't be
+// downloaded are logged and left as they are, so that a dead link doesn't prevent the export.
+func localizeImages(id string, images map[string]string, template string) (map[string]string, string) {
+	images, template, err := imexport.LocalizeImages(images, template)
+	if err != nil {
+		_ = log.Error(err, log.WithValue("id", id))
+	}
+	return images, template
+}
+
+// entriesSource returns a data source with the meta information of the template, so that
+// the entries of a template can be exported like the ones of a data source.
+func entriesSource(tmpl snd.Template) snd.DataSource {
+	return snd.DataSource{
+		Name:        tmpl.Name,
+		Slug:        tmpl.Slug,
+		Author:      tmpl.Author,
+		Description: tmpl.Description,
+		Version:     tmpl.Version,
+	}
+}
+
+// TemplateAttachments collects the files that are exported beside the template:
+// the reference images and the fonts the template uses.
+func TemplateAttachments(db database.Database, tmpl snd.Template) (imexport.Attachments, error) {
+	res, err := golden.Attachments(db, tmpl.ID())
+	if err != nil {
+		return nil, err
+	}
+
+	fontFiles, err := fonts.Attachments(tmpl.PrintTemplate, tmpl.ListTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	for file, data := range fontFiles {
+		res[file] = data
+	}
+
+	return res, nil
+}
+
+// SaveTemplateAttachments stores the files that were imported beside the template.
+func SaveTemplateAttachments(db database.Database, tmplId string, attachments imexport.Attachments) error {
+	if err := golden.SaveAttachments(db, tmplId, attachments); err != nil {
+		return err
+	}
+	return fonts.InstallAttachments(attachments)
+}
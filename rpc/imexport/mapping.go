@@ -0,0 +1,78 @@
+package imexport
+
+import (
+	"encoding/json"
+
+	"github.com/BigJk/snd"
+	"github.com/BigJk/snd/database"
+	"github.com/BigJk/snd/imexport"
+	"github.com/BigJk/snd/rpc/bind"
+	"github.com/BigJk/snd/templating"
+	"github.com/labstack/echo/v4"
+)
+
+// mappingPrefix is the key prefix of the mappings that are applied when a data source is imported.
+const mappingPrefix = "IMPORT_MAPPING_"
+
+// GetMapping returns the mapping of the data source or template. If none is saved an empty mapping is returned.
+func GetMapping(db database.Database, id string) (imexport.Mapping, error) {
+	data, err := db.GetKey(mappingPrefix + id)
+	if err != nil {
+		return imexport.Mapping{Rules: []imexport.MappingRule{}}, nil
+	}
+
+	var mapping imexport.Mapping
+	if err := json.Unmarshal([]byte(data), &mapping); err != nil {
+		return imexport.Mapping{}, err
+	}
+
+	if mapping.Rules == nil {
+		mapping.Rules = []imexport.MappingRule{}
+	}
+
+	return mapping, nil
+}
+
+// SaveMapping validates and saves the mapping of the data source or template.
+func SaveMapping(db database.Database, id string, mapping imexport.Mapping) error {
+	if err := mapping.Validate(); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(mapping)
+	if err != nil {
+		return err
+	}
+
+	return db.SetKey(mappingPrefix+id, string(data))
+}
+
+// applyMapping transforms imported entries with the mapping that is saved for the id.
+func applyMapping(db database.Database, id string, entries []snd.Entry) ([]snd.Entry, error) {
+	mapping, err := GetMapping(db, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return mapping.Apply(templating.New(db), entries)
+}
+
+// RegisterMappings registers the rpc functions to manage the mappings of imports.
+func RegisterMappings(route *echo.Group, db database.Database) {
+	bind.MustBind(route, "/getImportMapping", func(id string) (imexport.Mapping, error) {
+		return GetMapping(db, id)
+	})
+
+	bind.MustBind(route, "/saveImportMapping", func(id string, mapping imexport.Mapping) error {
+		return SaveMapping(db, id, mapping)
+	})
+
+	bind.MustBind(route, "/deleteImportMapping", func(id string) error {
+		return db.DeleteKey(mappingPrefix + id)
+	})
+
+	// Applies the mapping to the given entries without saving anything, so that rules can be tried out.
+	bind.MustBind(route, "/testImportMapping", func(mapping imexport.Mapping, entries []snd.Entry) ([]snd.Entry, error) {
+		return mapping.Apply(templating.New(db), entries)
+	})
+}
@@ -0,0 +1,181 @@
+package imexport
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/BigJk/snd"
+	"github.com/BigJk/snd/database"
+	"github.com/BigJk/snd/imexport"
+	"github.com/samber/lo"
+)
+
+// importBasePrefix is the key prefix of the entry hashes of the last import of a data source or template.
+const importBasePrefix = "IMPORT_BASE_"
+
+// SourceDiff is the result of a dry-run import for a single data source.
+type SourceDiff struct {
+	imexport.EntryDiff
+	Source string `json:"source"`
+	Name   string `json:"name"`
+	// Exists is true if the data source is already in the database.
+	Exists bool `json:"exists"`
+}
+
+// TemplateDiff is the result of a dry-run import for a single template.
+type TemplateDiff struct {
+	imexport.EntryDiff
+	Template string `json:"template"`
+	Name     string `json:"name"`
+	// Exists is true if the template is already in the database.
+	Exists bool `json:"exists"`
+}
+
+// GetImportBase returns the entry hashes of the last import of the data source or template. If it
+// was never imported an empty base is returned, so that all entries count as local.
+func GetImportBase(db database.Database, id string) imexport.ImportBase {
+	data, err := db.GetKey(importBasePrefix + id)
+	if err != nil {
+		return imexport.ImportBase{}
+	}
+
+	var base imexport.ImportBase
+	if err := json.Unmarshal([]byte(data), &base); err != nil || base == nil {
+		return imexport.ImportBase{}
+	}
+
+	return base
+}
+
+// SaveImportBase remembers the imported entries of the data source or template.
+func SaveImportBase(db database.Database, id string, entries []snd.Entry) error {
+	data, err := json.Marshal(imexport.NewImportBase(entries))
+	if err != nil {
+		return err
+	}
+
+	return db.SetKey(importBasePrefix+id, string(data))
+}
+
+// DeleteImportBase removes the import information of the data source or template.
+func DeleteImportBase(db database.Database, id string) error {
+	return db.DeleteKey(importBasePrefix + id)
+}
+
+// PreviewSources compares the imported data sources with the ones in the database without changing anything.
+func PreviewSources(db database.Database, sources []snd.DataSource, entries [][]snd.Entry) ([]SourceDiff, error) {
+	diffs := make([]SourceDiff, len(sources))
+	for i := range sources {
+		mapped, err := applyMapping(db, sources[i].ID(), entries[i])
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = db.GetSource(sources[i].ID())
+		exists := err == nil
+
+		var existing []snd.Entry
+		if exists {
+			if existing, err = db.GetEntries(sources[i].ID()); err != nil {
+				return nil, err
+			}
+		}
+
+		diffs[i] = SourceDiff{
+			EntryDiff: imexport.DiffEntries(existing, mapped, GetImportBase(db, sources[i].ID())),
+			Source:    sources[i].ID(),
+			Name:      sources[i].Name,
+			Exists:    exists,
+		}
+	}
+
+	return diffs, nil
+}
+
+// PreviewTemplates compares the entries of the imported templates with the ones in the database without changing anything.
+func PreviewTemplates(db database.Database, templates []snd.Template, entries [][]snd.Entry) ([]TemplateDiff, error) {
+	diffs := make([]TemplateDiff, len(templates))
+	for i := range templates {
+		mapped, err := applyMapping(db, templates[i].ID(), entries[i])
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = db.GetTemplate(templates[i].ID())
+		exists := err == nil
+
+		var existing []snd.Entry
+		if exists {
+			if existing, err = db.GetEntries(templates[i].ID()); err != nil {
+				return nil, err
+			}
+		}
+
+		diffs[i] = TemplateDiff{
+			EntryDiff: imexport.DiffEntries(existing, mapped, GetImportBase(db, templates[i].ID())),
+			Template:  templates[i].ID(),
+			Name:      templates[i].Name,
+			Exists:    exists,
+		}
+	}
+
+	return diffs, nil
+}
+
+// SaveSources saves the imported data sources and merges their entries with the existing
+// ones using the strategy.
+func SaveSources(db database.Database, sources []snd.DataSource, entries [][]snd.Entry, strategy string) error {
+	if !lo.Contains(imexport.MergeStrategies, strategy) {
+		return fmt.Errorf("unknown merge strategy '%s'", strategy)
+	}
+
+	for i := range sources {
+		if err := db.SaveSource(sources[i]); err != nil {
+			return err
+		}
+
+		if err := MergeEntries(db, sources[i].ID(), entries[i], strategy); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// MergeEntries applies the saved mapping to the imported entries of a data source or template, merges them
+// with the existing ones using the strategy and remembers them as base for the next import.
+func MergeEntries(db database.Database, id string, entries []snd.Entry, strategy string) error {
+	entries, err := applyMapping(db, id, entries)
+	if err != nil {
+		return err
+	}
+
+	// Without the existing entries the strategies would overwrite local changes
+	existing, err := db.GetEntries(id)
+	if err != nil {
+		return err
+	}
+
+	save, remove, replace, err := imexport.MergeEntries(existing, entries, GetImportBase(db, id), strategy)
+	if err != nil {
+		return err
+	}
+
+	if replace {
+		if err := db.DeleteEntries(id); err != nil {
+			return err
+		}
+	}
+
+	for _, eid := range remove {
+		if err := db.DeleteEntry(id, eid); err != nil {
+			return err
+		}
+	}
+
+	if err := db.SaveEntries(id, save); err != nil {
+		return err
+	}
+
+	return SaveImportBase(db, id, entries)
+}
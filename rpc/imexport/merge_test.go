@@ -0,0 +1,118 @@
+package imexport
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/BigJk/snd"
+	"github.com/BigJk/snd/database"
+	"github.com/BigJk/snd/database/badger"
+	"github.com/BigJk/snd/imexport"
+)
+
+func TestMergeTemplatesKeepsLocalEntries(t *testing.T) {
+	db, err := badger.New(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	tmpl := snd.Template{Name: "Monsters", Author: "a", Slug: "monsters"}
+	imported := []snd.Entry{
+		{ID: "goblin", Name: "Goblin", Data: map[string]any{"hp": 7.0}},
+		{ID: "orc", Name: "Orc", Data: map[string]any{"hp": 15.0}},
+	}
+
+	if err := MergeTemplates(db, []snd.Template{tmpl}, [][]snd.Entry{imported}, []imexport.Attachments{nil}, imexport.MergeReplace); err != nil {
+		t.Fatal(err)
+	}
+
+	// Local edit of the goblin and a new local entry
+	if err := db.SaveEntry(tmpl.ID(), snd.Entry{ID: "goblin", Name: "Goblin", Data: map[string]any{"hp": 10.0}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.SaveEntry(tmpl.ID(), snd.Entry{ID: "kobold", Name: "Kobold"}); err != nil {
+		t.Fatal(err)
+	}
+
+	reimport := []snd.Entry{
+		{ID: "goblin", Name: "Goblin", Data: map[string]any{"hp": 8.0}},
+		{ID: "troll", Name: "Troll"},
+	}
+
+	diffs, err := PreviewTemplates(db, []snd.Template{tmpl}, [][]snd.Entry{reimport})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diffs) != 1 || !diffs[0].Exists || diffs[0].Template != tmpl.ID() {
+		t.Fatalf("unexpected preview %+v", diffs)
+	}
+	if len(diffs[0].New) != 1 || len(diffs[0].Changed) != 1 || !diffs[0].Changed[0].LocallyModified || len(diffs[0].Removed) != 2 {
+		t.Fatalf("unexpected preview %+v", diffs[0])
+	}
+
+	if err := MergeTemplates(db, []snd.Template{tmpl}, [][]snd.Entry{reimport}, []imexport.Attachments{nil}, imexport.MergeKeepLocal); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := db.GetEntries(tmpl.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	byId := map[string]snd.Entry{}
+	for i := range entries {
+		byId[entries[i].ID] = entries[i]
+	}
+
+	if hp := byId["goblin"].Data["hp"]; hp != 10.0 {
+		t.Fatalf("local modification of goblin was overwritten: %v", hp)
+	}
+	for _, id := range []string{"kobold", "troll"} {
+		if _, ok := byId[id]; !ok {
+			t.Fatalf("expected entry %s", id)
+		}
+	}
+	if _, ok := byId["orc"]; ok {
+		t.Fatal("expected orc to be removed")
+	}
+
+	if err := MergeTemplates(db, []snd.Template{tmpl}, [][]snd.Entry{reimport}, []imexport.Attachments{nil}, "unknown"); err == nil {
+		t.Fatal("expected unknown strategy to fail")
+	}
+}
+
+// failingReadDB fails to read entries.
+type failingReadDB struct {
+	database.Database
+}
+
+func (f failingReadDB) GetEntries(id string) ([]snd.Entry, error) {
+	return nil, errors.New("read failed")
+}
+
+func TestMergeEntriesReportsReadErrors(t *testing.T) {
+	db, err := badger.New(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	local := snd.Entry{ID: "goblin", Name: "Goblin", Data: map[string]any{"hp": 10.0}}
+	if err := db.SaveEntry("ds:monsters", local); err != nil {
+		t.Fatal(err)
+	}
+
+	imported := []snd.Entry{{ID: "goblin", Name: "Goblin", Data: map[string]any{"hp": 8.0}}}
+	if err := MergeEntries(failingReadDB{Database: db}, "ds:monsters", imported, imexport.MergeKeepLocal); err == nil {
+		t.Fatal("expected the read error to be reported")
+	}
+
+	entry, err := db.GetEntry("ds:monsters", "goblin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.Data["hp"] != 10.0 {
+		t.Fatalf("expected the local entry to be kept, got %v", entry.Data["hp"])
+	}
+}
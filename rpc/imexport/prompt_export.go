@@ -0,0 +1,124 @@
+package imexport
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/BigJk/snd"
+	"github.com/BigJk/snd/database"
+	"github.com/BigJk/snd/imexport"
+	"github.com/BigJk/snd/rpc/bind"
+	"github.com/labstack/echo/v4"
+)
+
+// PromptExportFunction is a function that exports a prompt from a given set of arguments.
+type PromptExportFunction func(prompt snd.Prompt, args []any) (string, error)
+
+// PromptExport is a prompt export.
+type PromptExport struct {
+	ImExport
+	Func PromptExportFunction `json:"-"`
+}
+
+var promptExports = []PromptExport{
+	//
+	// Folder
+	//
+	{
+		ImExport: NewImExport("Folder", "Folder", "Export a folder.",
+			Arg("Folder", "The folder to create the folder in.", "FolderPath", nil),
+		),
+		Func: func(prompt snd.Prompt, args []any) (string, error) {
+			if len(args) != 1 {
+				return "", errors.New("invalid number of arguments")
+			}
+
+			folderPath, ok := args[0].(string)
+			if !ok {
+				return "", errors.New("invalid argument type")
+			}
+
+			name, err := imexport.ExportPromptFolder(prompt, folderPath)
+			if err != nil {
+				return "", err
+			}
+
+			return fmt.Sprintf("Successfully saved to '%s'", name), nil
+		},
+	},
+	//
+	// ZIP
+	//
+	{
+		ImExport: NewImExport("ZIP", "ZIP", "Export a ZIP file.",
+			Arg("Folder", "The folder to save the zip.", "FolderPath", nil),
+		),
+		Func: func(prompt snd.Prompt, args []any) (string, error) {
+			if len(args) != 1 {
+				return "", errors.New("invalid number of arguments")
+			}
+
+			folderPath, ok := args[0].(string)
+			if !ok {
+				return "", errors.New("invalid argument type")
+			}
+
+			name, err := imexport.ExportPromptZIPFile(prompt, folderPath)
+			if err != nil {
+				return "", err
+			}
+
+			return fmt.Sprintf("Successfully saved to '%s'", name), nil
+		},
+	},
+}
+
+// RegisterPromptExports registers all prompt exports.
+func RegisterPromptExports(route *echo.Group, db database.Database, nativeSaver NativeFileSaver) {
+	bind.MustBind(route, "/exportsPrompt", func() ([]PromptExport, error) {
+		if nativeSaver != nil {
+			return []PromptExport{
+				{
+					ImExport: NewImExport("ZIP", "NativeZIP", "Export a ZIP file."),
+				},
+			}, nil
+		}
+		return promptExports, nil
+	})
+
+	bind.MustBind(route, "/exportsPromptNativeZIP", func(id string, args []any) (string, error) {
+		if nativeSaver == nil {
+			return "", errors.New("native save dialog is not available")
+		}
+
+		prompt, err := db.GetPrompt(id)
+		if err != nil {
+			return "", err
+		}
+
+		buf := &bytes.Buffer{}
+		file, err := imexport.ExportPromptZIP(prompt, buf)
+		if err != nil {
+			return "", err
+		}
+
+		if err := nativeSaver.SaveFile(file, "application/zip", buf.Bytes()); err != nil {
+			return "", err
+		}
+
+		return fmt.Sprintf("Successfully saved '%s'", file), nil
+	})
+
+	for i := range promptExports {
+		exportFunc := promptExports[i].Func
+		bind.MustBind(route, "/exportsPrompt"+promptExports[i].RPCName, func(id string, args []any) (string, error) {
+			prompt, err := db.GetPrompt(id)
+			if err != nil {
+				return "", err
+			}
+
+			return exportFunc(prompt, args)
+		})
+	}
+}
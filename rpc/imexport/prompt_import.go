@@ -0,0 +1,216 @@
+package imexport
+
+import (
+	"encoding/base64"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/BigJk/snd"
+	"github.com/BigJk/snd/database"
+	"github.com/BigJk/snd/imexport"
+	"github.com/BigJk/snd/rpc/bind"
+	"github.com/labstack/echo/v4"
+	"github.com/mattetti/filebuffer"
+)
+
+// maxPromptHistory is the number of previous versions that are kept per prompt.
+const maxPromptHistory = 20
+
+// SavePrompt saves the prompt. If the system prompt, user prompt or variables changed, the
+// previously saved version is added to the history. The history is always taken from the
+// database, so clients and imports can't overwrite it.
+func SavePrompt(db database.Database, prompt snd.Prompt) error {
+	existing, err := db.GetPrompt(prompt.ID())
+	if err != nil || existing.Slug == "" {
+		return db.SavePrompt(prompt)
+	}
+
+	prompt.History = existing.History
+	if existing.System != prompt.System || existing.User != prompt.User || !reflect.DeepEqual(existing.Variables, prompt.Variables) {
+		prompt.History = append(prompt.History, existing.Revision())
+		if len(prompt.History) > maxPromptHistory {
+			prompt.History = prompt.History[len(prompt.History)-maxPromptHistory:]
+		}
+	}
+
+	return db.SavePrompt(prompt)
+}
+
+// PromptImportFunc is a function that imports prompts from a given set of arguments.
+type PromptImportFunc func(args []any) ([]snd.Prompt, error)
+
+// PromptImport is a prompt import.
+type PromptImport struct {
+	ImExport
+	Func PromptImportFunc `json:"-"`
+}
+
+var promptImports = []PromptImport{
+	//
+	// Folder
+	//
+	{
+		ImExport: NewImExport("Folder", "Folder", "Import a folder.",
+			Arg("Folder", "The folder to import.", "FolderPath", nil),
+		),
+		Func: func(args []any) ([]snd.Prompt, error) {
+			if len(args) != 1 {
+				return nil, errors.New("invalid number of arguments")
+			}
+
+			folderPath, ok := args[0].(string)
+			if !ok {
+				return nil, errors.New("invalid argument type")
+			}
+
+			prompt, err := imexport.ImportPromptFolder(folderPath)
+			if err != nil {
+				return nil, err
+			}
+
+			return []snd.Prompt{prompt}, nil
+		},
+	},
+	//
+	// ZIP
+	//
+	{
+		ImExport: NewImExport("ZIP", "ZIP", "Import a ZIP file.",
+			Arg("File", "The path to the ZIP file.", "FilePath", nil),
+		),
+		Func: func(args []any) ([]snd.Prompt, error) {
+			if len(args) != 1 {
+				return nil, errors.New("invalid number of arguments")
+			}
+
+			filePath, ok := args[0].(string)
+			if !ok {
+				return nil, errors.New("invalid argument type")
+			}
+
+			var prompt snd.Prompt
+			var err error
+
+			if strings.HasPrefix(filePath, "data:") {
+				// read from data uri
+				split := strings.Split(filePath, ",")
+				if len(split) != 2 {
+					return nil, errors.New("not a valid data url")
+				}
+
+				data, err := base64.StdEncoding.DecodeString(split[1])
+				if err != nil {
+					return nil, err
+				}
+
+				buf := filebuffer.New(data)
+				defer buf.Close()
+
+				prompt, err = imexport.ImportPromptZIP(buf, int64(len(data)))
+				if err != nil {
+					return nil, err
+				}
+			} else {
+				// read from path
+				prompt, err = imexport.ImportPromptZIPFile(filePath)
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			return []snd.Prompt{prompt}, nil
+		},
+	},
+	//
+	// URL
+	//
+	{
+		ImExport: NewImExport("URL", "URL", "Import a .zip from URL.",
+			Arg("URL", "The URL to the zip file.", "Text", nil),
+		),
+		Func: func(args []any) ([]snd.Prompt, error) {
+			if len(args) != 1 {
+				return nil, errors.New("invalid number of arguments")
+			}
+
+			url, ok := args[0].(string)
+			if !ok {
+				return nil, errors.New("invalid argument type")
+			}
+
+			resp, err := http.Get(url)
+			if err != nil {
+				return nil, err
+			}
+			defer resp.Body.Close()
+
+			data, err := io.ReadAll(resp.Body)
+			if err != nil {
+				return nil, err
+			}
+
+			buf := filebuffer.New(data)
+			defer buf.Close()
+
+			prompt, err := imexport.ImportPromptZIP(buf, int64(len(data)))
+			if err != nil {
+				return nil, err
+			}
+
+			return []snd.Prompt{prompt}, nil
+		},
+	},
+	//
+	// JSON
+	//
+	{
+		ImExport: NewImExport("JSON", "JSON", "Import a JSON string.",
+			Arg("JSON", "The JSON string.", "Text", nil),
+		),
+		Func: func(args []any) ([]snd.Prompt, error) {
+			if len(args) != 1 {
+				return nil, errors.New("invalid number of arguments")
+			}
+
+			json, ok := args[0].(string)
+			if !ok {
+				return nil, errors.New("invalid argument type")
+			}
+
+			prompt, err := imexport.ImportPromptJSON(json)
+			if err != nil {
+				return nil, err
+			}
+
+			return []snd.Prompt{prompt}, nil
+		},
+	},
+}
+
+// RegisterPromptImports registers all prompt imports.
+func RegisterPromptImports(route *echo.Group, db database.Database) {
+	bind.MustBind(route, "/importsPrompt", func() ([]PromptImport, error) {
+		return promptImports, nil
+	})
+
+	for i := range promptImports {
+		importFunc := promptImports[i].Func
+		bind.MustBind(route, "/importsPrompt"+promptImports[i].RPCName, func(args []any) error {
+			prompts, err := importFunc(args)
+			if err != nil {
+				return err
+			}
+
+			for i := range prompts {
+				if err := SavePrompt(db, prompts[i]); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		})
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"github.com/BigJk/snd"
 	"github.com/BigJk/snd/database"
 	"github.com/BigJk/snd/imexport"
+	"github.com/BigJk/snd/imexport/fightclub5e"
+	"github.com/BigJk/snd/imexport/vtt"
 	"github.com/BigJk/snd/rpc/bind"
 	"github.com/labstack/echo/v4"
 )
@@ -68,6 +70,106 @@ var sourceExports = []DataSourceExport{
 				return "", err
 			}
 
+			return fmt.Sprintf("Successfully saved to '%s'", name), nil
+		},
+	},
+	//
+	// XLSX
+	//
+	{
+		ImExport: NewImExport("XLSX", "XLSX", "Export an Excel workbook. Nested values are written as dotted columns like 'price.gp' or 'tags.0' and dots in keys are escaped like 'a\\.b', so the file can be edited and imported again.",
+			Arg("Folder", "The folder to save the workbook in.", "FolderPath", nil),
+		),
+		Func: func(source snd.DataSource, entries []snd.Entry, args []any) (string, error) {
+			if len(args) != 1 {
+				return "", errors.New("invalid number of arguments")
+			}
+
+			folderPath, ok := args[0].(string)
+			if !ok {
+				return "", errors.New("invalid argument type")
+			}
+
+			name, err := imexport.ExportSourcesXLSXFile([]snd.DataSource{source}, [][]snd.Entry{entries}, folderPath)
+			if err != nil {
+				return "", err
+			}
+
+			return fmt.Sprintf("Successfully saved to '%s'", name), nil
+		},
+	},
+	//
+	// Fight Club 5e
+	//
+	{
+		ImExport: NewImExport("Fight Club 5e", "FightClub5e", "Export a Fight Club 5e compendium XML file. The type decides which element the entries are written as. If it is set to 'auto' the type is taken from data sources that were imported from a compendium.",
+			Arg("Folder", "The folder to save the compendium in.", "FolderPath", nil),
+			Arg("Type", "The type of the entries.", "Options", map[string]any{"choices": append([]string{"auto"}, fightclub5e.Types...), "selected": "auto"}),
+		),
+		Func: func(source snd.DataSource, entries []snd.Entry, args []any) (string, error) {
+			if len(args) != 2 {
+				return "", errors.New("invalid number of arguments")
+			}
+
+			folderPath, ok := args[0].(string)
+			if !ok {
+				return "", errors.New("invalid argument type")
+			}
+
+			elementType, ok := optionArg(args[1])
+			if !ok {
+				return "", errors.New("invalid argument type")
+			}
+
+			if elementType == "auto" {
+				elementType = fightclub5e.TypeFromSource(source)
+				if elementType == "" {
+					return "", errors.New("type could not be detected, please select it manually")
+				}
+			}
+
+			name, err := fightclub5e.ExportCompendiumFile(source, entries, elementType, folderPath)
+			if err != nil {
+				return "", err
+			}
+
+			return fmt.Sprintf("Successfully saved to '%s'", name), nil
+		},
+	},
+	//
+	// FoundryVTT
+	//
+	{
+		ImExport: NewImExport("FoundryVTT", "FoundryVTT", "Export a FoundryVTT module containing the data source as compendium pack. Copy the created folder into the modules folder of your FoundryVTT data.",
+			Arg("Folder", "The folder to create the module in.", "FolderPath", nil),
+			Arg("Document Type", "The type of the documents in the pack.", "Options", map[string]any{"choices": []string{"Item", "Actor", "JournalEntry", "RollTable", "Macro"}, "selected": "Item"}),
+			Arg("LevelDB", "Write the pack as LevelDB like FoundryVTT v11+. Otherwise a legacy .db file is written, which FoundryVTT v10 can read as well.", "Checkbox", true),
+		),
+		Func: func(source snd.DataSource, entries []snd.Entry, args []any) (string, error) {
+			if len(args) != 3 {
+				return "", errors.New("invalid number of arguments")
+			}
+
+			folderPath, ok := args[0].(string)
+			if !ok {
+				return "", errors.New("invalid argument type")
+			}
+
+			documentType, ok := optionArg(args[1])
+			if !ok {
+				return "", errors.New("invalid argument type")
+			}
+
+			levelDB, ok := args[2].(bool)
+			if !ok {
+				return "", errors.New("invalid argument type")
+			}
+
+			name, err := vtt.ExportModule(source, entries, documentType, levelDB, folderPath)
+			if err != nil {
+				return "", err
+			}
+
 			return fmt.Sprintf("Successfully saved to '%s'", name), nil
 		},
 	},
@@ -114,6 +216,29 @@ func RegisterDataSourceExports(route *echo.Group, db database.Database, nativeSa
 		return fmt.Sprintf("Successfully saved '%s'", file), nil
 	})
 
+	// Exports multiple data sources into a single workbook with one sheet per source.
+	bind.MustBind(route, "/exportSourcesXLSX", func(ids []string, folder string) (string, error) {
+		sources := make([]snd.DataSource, len(ids))
+		entries := make([][]snd.Entry, len(ids))
+		for i := range ids {
+			var err error
+			if sources[i], err = db.GetSource(ids[i]); err != nil {
+				return "", err
+			}
+
+			if entries[i], err = db.GetEntries(ids[i]); err != nil {
+				return "", err
+			}
+		}
+
+		name, err := imexport.ExportSourcesXLSXFile(sources, entries, folder)
+		if err != nil {
+			return "", err
+		}
+
+		return fmt.Sprintf("Successfully saved to '%s'", name), nil
+	})
+
 	for i := range sourceExports {
 		exportFunc := sourceExports[i].Func
 		bind.MustBind(route, "/exportsSource"+sourceExports[i].RPCName, func(id string, args []any) (string, error) {
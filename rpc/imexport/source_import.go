@@ -7,6 +7,9 @@ import (
 	"github.com/BigJk/snd/database"
 	"github.com/BigJk/snd/imexport"
 	"github.com/BigJk/snd/imexport/fightclub5e"
+	"github.com/BigJk/snd/imexport/markdown"
+	"github.com/BigJk/snd/imexport/open5e"
+	"github.com/BigJk/snd/imexport/pf2e"
 	"github.com/BigJk/snd/imexport/tools5e"
 	"github.com/BigJk/snd/imexport/vtt"
 	"github.com/BigJk/snd/rpc/bind"
@@ -156,6 +159,26 @@ var sourceImports = []DataSourceImport{
 		},
 	},
 	//
+	// XLSX
+	//
+	{
+		ImExport: NewImExport("XLSX", "XLSX", "Import an Excel workbook. Every sheet is imported as own Data Source. The first two rows of a sheet contain the name, author, slug, description and version of the source, the third row the columns of the entries. Nested values are written as dotted columns like 'price.gp' or 'tags.0', a dot that is part of a key is escaped like 'a\\.b'.",
+			Arg("File", "The path to the .xlsx file.", "FilePath", nil),
+		),
+		Func: func(args []any) ([]snd.DataSource, [][]snd.Entry, error) {
+			if len(args) != 1 {
+				return nil, nil, errors.New("invalid number of arguments")
+			}
+
+			filePath, ok := args[0].(string)
+			if !ok {
+				return nil, nil, errors.New("invalid argument type")
+			}
+
+			return imexport.ImportSourcesXLSXFile(filePath)
+		},
+	},
+	//
 	// Fight Club 5e
 	//
 	{
@@ -200,10 +223,240 @@ var sourceImports = []DataSourceImport{
 		},
 	},
 	//
+	// Open5e / 5e SRD
+	//
+	{
+		ImExport: NewImExport("Open5e / 5e SRD", "Open5e", "Import the openly licensed SRD data of Open5e or the 5e-SRD-API. Monsters, spells, magic items and conditions are added as separate Data Sources. The location can be a .json file (e.g. 5e-SRD-Monsters.json), a folder containing them or an api url like https://api.open5e.com/v1/monsters/.",
+			Arg("Location", "The path to a .json file or folder, or an url.", "Text", "https://api.open5e.com/v1/monsters/"),
+			Arg("Name", "The name of the source.", "Text", "Source Name Prefix"),
+			Arg("Author", "The author of the source.", "Text", "Source Author"),
+			Arg("Description", "The description of the source.", "Text", "Some cool description..."),
+			Arg("Slug", "The slug of the source.", "Text", "source-slug-prefix"),
+		),
+		Func: func(args []any) ([]snd.DataSource, [][]snd.Entry, error) {
+			if len(args) != 5 {
+				return nil, nil, errors.New("invalid number of arguments")
+			}
+
+			location, ok := args[0].(string)
+			if !ok {
+				return nil, nil, errors.New("invalid argument type")
+			}
+
+			name, ok := args[1].(string)
+			if !ok {
+				return nil, nil, errors.New("invalid argument type")
+			}
+
+			author, ok := args[2].(string)
+			if !ok {
+				return nil, nil, errors.New("invalid argument type")
+			}
+
+			description, ok := args[3].(string)
+			if !ok {
+				return nil, nil, errors.New("invalid argument type")
+			}
+
+			slug, ok := args[4].(string)
+			if !ok {
+				return nil, nil, errors.New("invalid argument type")
+			}
+
+			return open5e.Import(location, name, author, slug, description)
+		},
+	},
+	//
+	// Pathfinder 2e
+	//
+	{
+		ImExport: NewImExport("Pathfinder 2e", "PF2e", "Import Pathfinder 2e data from the packs of the PF2e FoundryVTT system (the JSON files of the system sources, .db files or built LevelDB packs) or from Archives of Nethys style JSON exports. Every kind of content (Creatures, Spells, Items, Feats, ...) is added as separate Data Source.",
+			Arg("Path", "The path to a file or a folder like the 'packs' folder of the system.", "Text", ""),
+			Arg("Name", "The name of the source.", "Text", "Source Name Prefix"),
+			Arg("Author", "The author of the source.", "Text", "Source Author"),
+			Arg("Description", "The description of the source.", "Text", "Some cool description..."),
+			Arg("Slug", "The slug of the source.", "Text", "source-slug-prefix"),
+		),
+		Func: func(args []any) ([]snd.DataSource, [][]snd.Entry, error) {
+			if len(args) != 5 {
+				return nil, nil, errors.New("invalid number of arguments")
+			}
+
+			path, ok := args[0].(string)
+			if !ok {
+				return nil, nil, errors.New("invalid argument type")
+			}
+
+			name, ok := args[1].(string)
+			if !ok {
+				return nil, nil, errors.New("invalid argument type")
+			}
+
+			author, ok := args[2].(string)
+			if !ok {
+				return nil, nil, errors.New("invalid argument type")
+			}
+
+			description, ok := args[3].(string)
+			if !ok {
+				return nil, nil, errors.New("invalid argument type")
+			}
+
+			slug, ok := args[4].(string)
+			if !ok {
+				return nil, nil, errors.New("invalid argument type")
+			}
+
+			return pf2e.Import(path, name, author, slug, description)
+		},
+	},
+	//
+	// Generic JSON / JSONL
+	//
+	{
+		ImExport: NewImExport("JSON Records", "JSONRecords", "Import the records of any JSON or JSONL file. The records and their fields are selected with JSONPath expressions, e.g. '$.results[*]' for the elements of the 'results' list or '$[?(@.type == \"npc\")]' for all elements with a type of 'npc'. The name and id are relative to the record, like 'name' or 'system.details.id'.",
+			Arg("File", "The path to the .json or .jsonl file.", "FilePath", nil),
+			Arg("Records", "The JSONPath to the records.", "Text", "$[*]"),
+			Arg("Name Field", "The JSONPath to the name of a record.", "Text", "name"),
+			Arg("ID Field", "The JSONPath to the id of a record. If empty the name is used.", "Text", "id"),
+			Arg("Name", "The name of the source.", "Text", "Source Name"),
+			Arg("Author", "The author of the source.", "Text", "Source Author"),
+			Arg("Description", "The description of the source.", "Text", "Some cool description..."),
+			Arg("Slug", "The slug of the source.", "Text", "source-slug"),
+		),
+		Func: func(args []any) ([]snd.DataSource, [][]snd.Entry, error) {
+			if len(args) != 8 {
+				return nil, nil, errors.New("invalid number of arguments")
+			}
+
+			filePath, ok := args[0].(string)
+			if !ok {
+				return nil, nil, errors.New("invalid argument type")
+			}
+
+			records, ok := args[1].(string)
+			if !ok {
+				return nil, nil, errors.New("invalid argument type")
+			}
+
+			nameField, ok := args[2].(string)
+			if !ok {
+				return nil, nil, errors.New("invalid argument type")
+			}
+
+			idField, ok := args[3].(string)
+			if !ok {
+				return nil, nil, errors.New("invalid argument type")
+			}
+
+			name, ok := args[4].(string)
+			if !ok {
+				return nil, nil, errors.New("invalid argument type")
+			}
+
+			author, ok := args[5].(string)
+			if !ok {
+				return nil, nil, errors.New("invalid argument type")
+			}
+
+			description, ok := args[6].(string)
+			if !ok {
+				return nil, nil, errors.New("invalid argument type")
+			}
+
+			slug, ok := args[7].(string)
+			if !ok {
+				return nil, nil, errors.New("invalid argument type")
+			}
+
+			ds, entries, err := imexport.ImportRecordsFile(filePath, name, author, slug, description, imexport.RecordOptions{
+				Records: records,
+				Name:    nameField,
+				ID:      idField,
+			})
+			if err != nil {
+				return nil, nil, err
+			}
+
+			return []snd.DataSource{ds}, [][]snd.Entry{entries}, nil
+		},
+	},
+	//
+	// Markdown
+	//
+	{
+		ImExport: NewImExport("Markdown", "Markdown", "Import a Markdown document, e.g. from Homebrewery or GM Binder. In the 'Headings' mode every heading of the heading level becomes an entry and the stat block fields (**Armor Class** 15), tables and sub-sections below it become its data. In the 'Table Rows' mode every row of the tables becomes an entry. The field rules rename fields with one rule per line like 'Armor Class => ac' or '/^Hit Points?$/ => hp', a key of '-' drops the field.",
+			Arg("File", "The path to the .md file.", "FilePath", nil),
+			Arg("Name", "The name of the source.", "Text", "Source Name"),
+			Arg("Author", "The author of the source.", "Text", "Source Author"),
+			Arg("Description", "The description of the source.", "Text", "Some cool description..."),
+			Arg("Slug", "The slug of the source.", "Text", "source-slug"),
+			Arg("Mode", "What becomes an entry.", "Options", map[string]any{"choices": []string{markdown.ModeHeadings, markdown.ModeTableRows}, "selected": markdown.ModeHeadings}),
+			Arg("Heading Level", "The level of the headings that start a new entry (e.g. 2 for '## Goblin').", "Number", 2),
+			Arg("Field Rules", "Rules that rename or drop fields, one per line.", "Text", ""),
+		),
+		Func: func(args []any) ([]snd.DataSource, [][]snd.Entry, error) {
+			if len(args) != 8 {
+				return nil, nil, errors.New("invalid number of arguments")
+			}
+
+			filePath, ok := args[0].(string)
+			if !ok {
+				return nil, nil, errors.New("invalid argument type")
+			}
+
+			name, ok := args[1].(string)
+			if !ok {
+				return nil, nil, errors.New("invalid argument type")
+			}
+
+			author, ok := args[2].(string)
+			if !ok {
+				return nil, nil, errors.New("invalid argument type")
+			}
+
+			description, ok := args[3].(string)
+			if !ok {
+				return nil, nil, errors.New("invalid argument type")
+			}
+
+			slug, ok := args[4].(string)
+			if !ok {
+				return nil, nil, errors.New("invalid argument type")
+			}
+
+			mode, ok := optionArg(args[5])
+			if !ok {
+				return nil, nil, errors.New("invalid argument type")
+			}
+
+			level, ok := args[6].(float64)
+			if !ok {
+				return nil, nil, errors.New("invalid argument type")
+			}
+
+			ruleText, ok := args[7].(string)
+			if !ok {
+				return nil, nil, errors.New("invalid argument type")
+			}
+
+			rules, err := markdown.ParseRules(ruleText)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			return markdown.Import(filePath, name, author, slug, description, markdown.Options{
+				HeadingLevel: int(level),
+				Mode:         mode,
+				Rules:        rules,
+			})
+		},
+	},
+	//
 	// FoundryVTT
 	//
 	{
-		ImExport: NewImExport("FoundryVTT", "FoundryVTT", "You can import data from FoundryVTT Modules and Systems. This will convert all the included packs and add them as Data Sources. To import a Module or System open the module.json or system.json file in it's folder.",
+		ImExport: NewImExport("FoundryVTT", "FoundryVTT", "You can import data from FoundryVTT Modules and Systems. This will convert all the included packs (legacy .db files as well as the LevelDB packs of FoundryVTT v11+) and add them as Data Sources. To import a Module or System open the module.json or system.json file in it's folder.",
 			Arg("File", "The path to the system.json or module.json file.", "FilePath", nil),
 		),
 		Func: func(args []any) ([]snd.DataSource, [][]snd.Entry, error) {
@@ -267,6 +520,10 @@ func RegisterDataSourceImports(route *echo.Group, db database.Database) {
 		return sourceImports, nil
 	})
 
+	bind.MustBind(route, "/importsSourceStrategies", func() ([]string, error) {
+		return imexport.MergeStrategies, nil
+	})
+
 	for i := range sourceImports {
 		importFunc := sourceImports[i].Func
 		bind.MustBind(route, "/importsSource"+sourceImports[i].RPCName, func(args []any) error {
@@ -275,16 +532,27 @@ func RegisterDataSourceImports(route *echo.Group, db database.Database) {
 				return err
 			}
 
-			for i := range sources {
-				// Delete old entries
-				_ = db.DeleteEntries(sources[i].ID())
+			return SaveSources(db, sources, entries, imexport.MergeReplace)
+		})
 
-				// Save new source and entries
-				_ = db.SaveSource(sources[i])
-				_ = db.SaveEntries(sources[i].ID(), entries[i])
+		// Dry-run that reports what the import would change.
+		bind.MustBind(route, "/previewImportsSource"+sourceImports[i].RPCName, func(args []any) ([]SourceDiff, error) {
+			sources, entries, err := importFunc(args)
+			if err != nil {
+				return nil, err
+			}
+
+			return PreviewSources(db, sources, entries)
+		})
+
+		// Import that merges the entries with the existing ones instead of replacing them.
+		bind.MustBind(route, "/mergeImportsSource"+sourceImports[i].RPCName, func(args []any, strategy string) error {
+			sources, entries, err := importFunc(args)
+			if err != nil {
+				return err
 			}
 
-			return nil
+			return SaveSources(db, sources, entries, strategy)
 		})
 	}
 }
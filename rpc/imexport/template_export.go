@@ -12,7 +12,7 @@ import (
 )
 
 // TemplateExportFunction is a function that exports a template from a given set of arguments.
-type TemplateExportFunction func(template snd.Template, entries []snd.Entry, args []any) (string, error)
+type TemplateExportFunction func(template snd.Template, entries []snd.Entry, attachments imexport.Attachments, args []any) (string, error)
 
 // TemplateExport is a template export.
 type TemplateExport struct {
@@ -27,9 +27,10 @@ var templateExports = []TemplateExport{
 	{
 		ImExport: NewImExport("Folder", "Folder", "Export a folder.",
 			Arg("Folder", "The folder to create the folder in.", "FolderPath", nil),
+			localizeArg,
 		),
-		Func: func(template snd.Template, entries []snd.Entry, args []any) (string, error) {
-			if len(args) != 1 {
+		Func: func(template snd.Template, entries []snd.Entry, attachments imexport.Attachments, args []any) (string, error) {
+			if len(args) != 2 {
 				return "", errors.New("invalid number of arguments")
 			}
 
@@ -38,7 +39,16 @@ var templateExports = []TemplateExport{
 				return "", errors.New("invalid argument type")
 			}
 
-			name, err := imexport.ExportTemplateFolder(template, entries, folderPath)
+			localize, ok := args[1].(bool)
+			if !ok {
+				return "", errors.New("invalid argument type")
+			}
+
+			if localize {
+				template.Images, template.PrintTemplate = localizeImages(template.ID(), template.Images, template.PrintTemplate)
+			}
+
+			name, err := imexport.ExportTemplateFolder(template, entries, attachments, folderPath)
 			if err != nil {
 				return "", err
 			}
@@ -52,9 +62,10 @@ var templateExports = []TemplateExport{
 	{
 		ImExport: NewImExport("ZIP", "ZIP", "Export a ZIP file.",
 			Arg("Folder", "The folder to save the zip.", "FolderPath", nil),
+			localizeArg,
 		),
-		Func: func(template snd.Template, entries []snd.Entry, args []any) (string, error) {
-			if len(args) != 1 {
+		Func: func(template snd.Template, entries []snd.Entry, attachments imexport.Attachments, args []any) (string, error) {
+			if len(args) != 2 {
 				return "", errors.New("invalid number of arguments")
 			}
 
@@ -63,7 +74,16 @@ var templateExports = []TemplateExport{
 				return "", errors.New("invalid argument type")
 			}
 
-			name, err := imexport.ExportTemplateZIPFile(template, entries, folderPath)
+			localize, ok := args[1].(bool)
+			if !ok {
+				return "", errors.New("invalid argument type")
+			}
+
+			if localize {
+				template.Images, template.PrintTemplate = localizeImages(template.ID(), template.Images, template.PrintTemplate)
+			}
+
+			name, err := imexport.ExportTemplateZIPFile(template, entries, attachments, folderPath)
 			if err != nil {
 				return "", err
 			}
@@ -101,8 +121,13 @@ func RegisterTemplateExports(route *echo.Group, db database.Database, nativeSave
 			return "", err
 		}
 
+		attachments, err := TemplateAttachments(db, template)
+		if err != nil {
+			return "", err
+		}
+
 		buf := &bytes.Buffer{}
-		file, err := imexport.ExportTemplateZIP(template, entries, buf)
+		file, err := imexport.ExportTemplateZIP(template, entries, attachments, buf)
 		if err != nil {
 			return "", err
 		}
@@ -114,6 +139,26 @@ func RegisterTemplateExports(route *echo.Group, db database.Database, nativeSave
 		return fmt.Sprintf("Successfully saved '%s'", file), nil
 	})
 
+	// Exports only the entries of the template, so they can be edited in a spreadsheet.
+	bind.MustBind(route, "/exportTemplateEntriesXLSX", func(id string, folder string) (string, error) {
+		template, err := db.GetTemplate(id)
+		if err != nil {
+			return "", err
+		}
+
+		entries, err := db.GetEntries(id)
+		if err != nil {
+			return "", err
+		}
+
+		name, err := imexport.ExportSourcesXLSXFile([]snd.DataSource{entriesSource(template)}, [][]snd.Entry{entries}, folder)
+		if err != nil {
+			return "", err
+		}
+
+		return fmt.Sprintf("Successfully saved to '%s'", name), nil
+	})
+
 	for i := range templateExports {
 		exportFunc := templateExports[i].Func
 		bind.MustBind(route, "/exportsTemplate"+templateExports[i].RPCName, func(id string, args []any) (string, error) {
@@ -127,7 +172,12 @@ func RegisterTemplateExports(route *echo.Group, db database.Database, nativeSave
 				return "", err
 			}
 
-			return exportFunc(template, entries, args)
+			attachments, err := TemplateAttachments(db, template)
+			if err != nil {
+				return "", err
+			}
+
+			return exportFunc(template, entries, attachments, args)
 		})
 	}
 }
@@ -3,19 +3,21 @@ package imexport
 import (
 	"encoding/base64"
 	"errors"
+	"fmt"
 	"github.com/BigJk/snd"
 	"github.com/BigJk/snd/database"
 	"github.com/BigJk/snd/imexport"
 	"github.com/BigJk/snd/rpc/bind"
 	"github.com/labstack/echo/v4"
 	"github.com/mattetti/filebuffer"
+	"github.com/samber/lo"
 	"io/ioutil"
 	"net/http"
 	"strings"
 )
 
 // TemplateImportFunc is a function that imports a template from a given set of arguments.
-type TemplateImportFunc func(args []any) ([]snd.Template, [][]snd.Entry, error)
+type TemplateImportFunc func(args []any) ([]snd.Template, [][]snd.Entry, []imexport.Attachments, error)
 
 // TemplateImport is a template import.
 type TemplateImport struct {
@@ -31,22 +33,22 @@ var templateImports = []TemplateImport{
 		ImExport: NewImExport("Folder", "Folder", "Import a folder.",
 			Arg("Folder", "The folder to import.", "FolderPath", nil),
 		),
-		Func: func(args []any) ([]snd.Template, [][]snd.Entry, error) {
+		Func: func(args []any) ([]snd.Template, [][]snd.Entry, []imexport.Attachments, error) {
 			if len(args) != 1 {
-				return nil, nil, errors.New("invalid number of arguments")
+				return nil, nil, nil, errors.New("invalid number of arguments")
 			}
 
 			folderPath, ok := args[0].(string)
 			if !ok {
-				return nil, nil, errors.New("invalid argument type")
+				return nil, nil, nil, errors.New("invalid argument type")
 			}
 
-			tmpl, entries, err := imexport.ImportTemplateFolder(folderPath)
+			tmpl, entries, attachments, err := imexport.ImportTemplateFolder(folderPath)
 			if err != nil {
-				return nil, nil, err
+				return nil, nil, nil, err
 			}
 
-			return []snd.Template{tmpl}, [][]snd.Entry{entries}, nil
+			return []snd.Template{tmpl}, [][]snd.Entry{entries}, []imexport.Attachments{attachments}, nil
 		},
 	},
 	//
@@ -56,46 +58,47 @@ var templateImports = []TemplateImport{
 		ImExport: NewImExport("ZIP", "ZIP", "Import a ZIP file.",
 			Arg("File", "The path to the ZIP file.", "FilePath", nil),
 		),
-		Func: func(args []any) ([]snd.Template, [][]snd.Entry, error) {
+		Func: func(args []any) ([]snd.Template, [][]snd.Entry, []imexport.Attachments, error) {
 			if len(args) != 1 {
-				return nil, nil, errors.New("invalid number of arguments")
+				return nil, nil, nil, errors.New("invalid number of arguments")
 			}
 
 			filePath, ok := args[0].(string)
 			if !ok {
-				return nil, nil, errors.New("invalid argument type")
+				return nil, nil, nil, errors.New("invalid argument type")
 			}
 
 			var tmpl snd.Template
 			var entries []snd.Entry
+			var attachments imexport.Attachments
 			var err error
 
 			if strings.HasPrefix(filePath, "data:") {
 				// read from data uri
 				split := strings.Split(filePath, ",")
 				if len(split) != 2 {
-					return nil, nil, errors.New("not a valid data url")
+					return nil, nil, nil, errors.New("not a valid data url")
 				}
 
 				data, err := base64.StdEncoding.DecodeString(split[1])
 				if err != nil {
-					return nil, nil, err
+					return nil, nil, nil, err
 				}
 
 				buf := filebuffer.New(data)
 				defer buf.Close()
 
-				tmpl, entries, err = imexport.ImportTemplateZIP(buf, int64(len(data)))
+				tmpl, entries, attachments, err = imexport.ImportTemplateZIP(buf, int64(len(data)))
 			} else {
 				// read from path
-				tmpl, entries, err = imexport.ImportTemplateZIPFile(filePath)
+				tmpl, entries, attachments, err = imexport.ImportTemplateZIPFile(filePath)
 			}
 
 			if err != nil {
-				return nil, nil, err
+				return nil, nil, nil, err
 			}
 
-			return []snd.Template{tmpl}, [][]snd.Entry{entries}, nil
+			return []snd.Template{tmpl}, [][]snd.Entry{entries}, []imexport.Attachments{attachments}, nil
 		},
 	},
 	//
@@ -105,31 +108,31 @@ var templateImports = []TemplateImport{
 		ImExport: NewImExport("URL", "URL", "Import a .zip from URL.",
 			Arg("URL", "The URL to the zip file.", "Text", nil),
 		),
-		Func: func(args []any) ([]snd.Template, [][]snd.Entry, error) {
+		Func: func(args []any) ([]snd.Template, [][]snd.Entry, []imexport.Attachments, error) {
 			if len(args) != 1 {
-				return nil, nil, errors.New("invalid number of arguments")
+				return nil, nil, nil, errors.New("invalid number of arguments")
 			}
 
 			url, ok := args[0].(string)
 			if !ok {
-				return nil, nil, errors.New("invalid argument type")
+				return nil, nil, nil, errors.New("invalid argument type")
 			}
 
 			resp, err := http.Get(url)
 			if err != nil {
-				return nil, nil, err
+				return nil, nil, nil, err
 			}
 
 			data, err := ioutil.ReadAll(resp.Body)
 			buf := filebuffer.New(data)
 			defer buf.Close()
 
-			tmpl, entries, err := imexport.ImportTemplateZIP(buf, int64(len(data)))
+			tmpl, entries, attachments, err := imexport.ImportTemplateZIP(buf, int64(len(data)))
 			if err != nil {
-				return nil, nil, err
+				return nil, nil, nil, err
 			}
 
-			return []snd.Template{tmpl}, [][]snd.Entry{entries}, nil
+			return []snd.Template{tmpl}, [][]snd.Entry{entries}, []imexport.Attachments{attachments}, nil
 		},
 	},
 	//
@@ -139,50 +142,111 @@ var templateImports = []TemplateImport{
 		ImExport: NewImExport("JSON", "JSON", "Import a JSON string.",
 			Arg("JSON", "The JSON string.", "Text", nil),
 		),
-		Func: func(args []any) ([]snd.Template, [][]snd.Entry, error) {
+		Func: func(args []any) ([]snd.Template, [][]snd.Entry, []imexport.Attachments, error) {
 			if len(args) != 1 {
-				return nil, nil, errors.New("invalid number of arguments")
+				return nil, nil, nil, errors.New("invalid number of arguments")
 			}
 
 			json, ok := args[0].(string)
 			if !ok {
-				return nil, nil, errors.New("invalid argument type")
+				return nil, nil, nil, errors.New("invalid argument type")
 			}
 
 			tmpl, entries, err := imexport.ImportTemplateJSON(json)
 			if err != nil {
-				return nil, nil, err
+				return nil, nil, nil, err
 			}
 
-			return []snd.Template{tmpl}, [][]snd.Entry{entries}, nil
+			return []snd.Template{tmpl}, [][]snd.Entry{entries}, []imexport.Attachments{nil}, nil
 		},
 	},
 }
 
+// SaveTemplate saves an imported template and merges its entries with the existing ones using the strategy.
+func SaveTemplate(db database.Database, tmpl snd.Template, entries []snd.Entry, attachments imexport.Attachments, strategy string) error {
+	return MergeTemplates(db, []snd.Template{tmpl}, [][]snd.Entry{entries}, []imexport.Attachments{attachments}, strategy)
+}
+
+// MergeTemplates saves the imported templates and merges their entries with the existing
+// ones using the strategy.
+func MergeTemplates(db database.Database, templates []snd.Template, entries [][]snd.Entry, attachments []imexport.Attachments, strategy string) error {
+	if !lo.Contains(imexport.MergeStrategies, strategy) {
+		return fmt.Errorf("unknown merge strategy '%s'", strategy)
+	}
+
+	for i := range templates {
+		if err := db.SaveTemplate(templates[i]); err != nil {
+			return err
+		}
+
+		if err := MergeEntries(db, templates[i].ID(), entries[i], strategy); err != nil {
+			return err
+		}
+
+		// Save the reference images and fonts that came with the template
+		if err := SaveTemplateAttachments(db, templates[i].ID(), attachments[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // RegisterTemplateImports registers all template imports.
 func RegisterTemplateImports(route *echo.Group, db database.Database) {
 	bind.MustBind(route, "/importsTemplate", func() ([]TemplateImport, error) {
 		return templateImports, nil
 	})
 
+	// Imports the entries of the first sheet of a workbook into the template.
+	bind.MustBind(route, "/importTemplateEntriesXLSX", func(id string, file string, strategy string) error {
+		if _, err := db.GetTemplate(id); err != nil {
+			return err
+		}
+
+		_, entries, err := imexport.ImportSourcesXLSXFile(file)
+		if err != nil {
+			return err
+		}
+
+		return MergeEntries(db, id, entries[0], strategy)
+	})
+
 	for i := range templateImports {
 		importFunc := templateImports[i].Func
 		bind.MustBind(route, "/importsTemplate"+templateImports[i].RPCName, func(args []any) error {
-			templates, entries, err := importFunc(args)
+			templates, entries, attachments, err := importFunc(args)
 			if err != nil {
 				return err
 			}
 
 			for i := range templates {
-				// Delete old entries
-				_ = db.DeleteEntries(templates[i].ID())
-
-				// Save new template and entries
-				_ = db.SaveTemplate(templates[i])
-				_ = db.SaveEntries(templates[i].ID(), entries[i])
+				if err := SaveTemplate(db, templates[i], entries[i], attachments[i], imexport.MergeReplace); err != nil {
+					return err
+				}
 			}
 
 			return nil
 		})
+
+		// Dry-run that reports what the import would change.
+		bind.MustBind(route, "/previewImportsTemplate"+templateImports[i].RPCName, func(args []any) ([]TemplateDiff, error) {
+			templates, entries, _, err := importFunc(args)
+			if err != nil {
+				return nil, err
+			}
+
+			return PreviewTemplates(db, templates, entries)
+		})
+
+		// Import that merges the entries with the existing ones instead of replacing them.
+		bind.MustBind(route, "/mergeImportsTemplate"+templateImports[i].RPCName, func(args []any, strategy string) error {
+			templates, entries, attachments, err := importFunc(args)
+			if err != nil {
+				return err
+			}
+
+			return MergeTemplates(db, templates, entries, attachments, strategy)
+		})
 	}
 }
@@ -25,19 +25,4 @@ func RegisterKeyValue(route *echo.Group, db database.Database) {
 		}
 		return len(keys), nil
 	})
-
-	bind.MustBind(route, "/clearAICache", func() (int, error) {
-		keys, err := db.GetKeysPrefix("AI_CACHE_")
-		if err != nil {
-			return 0, err
-		}
-
-		for _, key := range keys {
-			err = db.DeleteKey(key)
-			if err != nil {
-				return 0, err
-			}
-		}
-		return len(keys), nil
-	})
 }
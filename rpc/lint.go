@@ -0,0 +1,332 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/BigJk/snd"
+	"github.com/BigJk/snd/database"
+	"github.com/BigJk/snd/local"
+	"github.com/BigJk/snd/rendering"
+	"github.com/BigJk/snd/rpc/bind"
+	"github.com/BigJk/snd/templating"
+	"github.com/PuerkitoBio/goquery"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	LintSeverityError   = "error"
+	LintSeverityWarning = "warning"
+)
+
+const (
+	LintKindTemplate     = "template"
+	LintKindMissingField = "missing-field"
+	LintKindEmpty        = "empty"
+	LintKindConsole      = "console"
+	LintKindRender       = "render"
+	LintKindWidth        = "width"
+	LintKindHeight       = "height"
+)
+
+// fieldRegex finds the fields of the entry that are referenced in a template.
+var fieldRegex = regexp.MustCompile(`\bit\.([a-zA-Z_]\w*(?:\.[a-zA-Z_]\w*)*)`)
+
+// LintOptions changes how a template is linted.
+type LintOptions struct {
+	// Config is the template config the entries are rendered with.
+	Config map[string]any `json:"config"`
+	// Limit is the maximum amount of entries that are checked per template or data source.
+	// If it is 0 all entries are checked.
+	Limit int `json:"limit"`
+	// SkipBrowser skips the checks that need to render the template in the browser.
+	SkipBrowser bool `json:"skipBrowser"`
+}
+
+// LintIssue represents a single problem that was found while linting.
+type LintIssue struct {
+	Severity string `json:"severity"`
+	Kind     string `json:"kind"`
+	Source   string `json:"source"`
+	Entry    string `json:"entry"`
+	Template string `json:"template"`
+	Message  string `json:"message"`
+}
+
+// LintReport is the result of linting a template.
+type LintReport struct {
+	Template string      `json:"template"`
+	Entries  int         `json:"entries"`
+	Errors   int         `json:"errors"`
+	Warnings int         `json:"warnings"`
+	Issues   []LintIssue `json:"issues"`
+}
+
+type lintTarget struct {
+	source string
+	entry  snd.Entry
+}
+
+// lintTemplate renders the print and list template of tmpl against its skeleton, all its
+// entries and the entries of its data sources and collects the found problems.
+func lintTemplate(ctx context.Context, db database.Database, renderer *templating.Renderer, tmpl snd.Template, options LintOptions) (LintReport, error) {
+	settings, err := db.GetSettings()
+	if err != nil {
+		return LintReport{}, err
+	}
+
+	if options.Config == nil {
+		options.Config = map[string]any{}
+	}
+
+	targets := []lintTarget{{source: tmpl.ID(), entry: templating.SkeletonEntry(tmpl)}}
+
+	for _, id := range append([]string{tmpl.ID()}, tmpl.DataSources...) {
+		entries, err := db.GetEntries(id)
+		if err != nil {
+			return LintReport{}, fmt.Errorf("could not get entries of '%s': %w", id, err)
+		}
+
+		if options.Limit > 0 && len(entries) > options.Limit {
+			entries = entries[:options.Limit]
+		}
+
+		for i := range entries {
+			targets = append(targets, lintTarget{source: id, entry: entries[i]})
+		}
+	}
+
+	fields := map[string][]string{
+		"print": referencedFields(tmpl.PrintTemplate),
+		"list":  referencedFields(tmpl.ListTemplate),
+	}
+
+	// The rendering pool already limits the browser, so we don't need more workers than it has pages.
+	workers := rendering.GetMetrics().PoolSize
+	if workers < 1 {
+		workers = 1
+	}
+
+	results := make([][]LintIssue, len(targets))
+	jobs := make(chan int)
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = lintEntry(ctx, renderer, settings, tmpl, targets[i], fields, options)
+			}
+		}()
+	}
+
+	for i := range targets {
+		if ctx.Err() != nil {
+			break
+		}
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		return LintReport{}, ctx.Err()
+	}
+
+	report := LintReport{
+		Template: tmpl.ID(),
+		Entries:  len(targets),
+		Issues:   []LintIssue{},
+	}
+
+	for i := range results {
+		for _, issue := range results[i] {
+			switch issue.Severity {
+			case LintSeverityError:
+				report.Errors++
+			case LintSeverityWarning:
+				report.Warnings++
+			}
+			report.Issues = append(report.Issues, issue)
+		}
+	}
+
+	return report, nil
+}
+
+func lintEntry(ctx context.Context, renderer *templating.Renderer, settings snd.Settings, tmpl snd.Template, target lintTarget, fields map[string][]string, options LintOptions) []LintIssue {
+	var issues []LintIssue
+
+	add := func(severity string, kind string, template string, format string, args ...any) {
+		issues = append(issues, LintIssue{
+			Severity: severity,
+			Kind:     kind,
+			Source:   target.source,
+			Entry:    target.entry.ID,
+			Template: template,
+			Message:  fmt.Sprintf(format, args...),
+		})
+	}
+
+	for _, template := range []string{"print", "list"} {
+		reported := map[string]bool{}
+		for _, field := range fields[template] {
+			if missing := missingField(target.entry.Data, field); missing != "" && !reported[missing] {
+				reported[missing] = true
+				add(LintSeverityWarning, LintKindMissingField, template, "field 'it.%s' is missing", missing)
+			}
+		}
+	}
+
+	// List Template
+
+	if list, err := renderer.RenderTemplateList(tmpl, target.entry, options.Config); err == nil {
+		if isEmptyHtml(list) {
+			add(LintSeverityError, LintKindEmpty, "list", "output is empty")
+		}
+	} else if !errors.Is(err, templating.ErrUnsupported) {
+		// The list is rendered by the frontend, so a failure on the server might not show up there.
+		add(LintSeverityWarning, LintKindTemplate, "list", "%v", err)
+	}
+
+	// Print Template
+
+	html, err := renderer.RenderTemplate(tmpl, target.entry, options.Config)
+	if err != nil {
+		serverErr := err
+		unsupported := errors.Is(err, templating.ErrUnsupported)
+
+		if options.SkipBrowser {
+			if !unsupported {
+				add(LintSeverityError, LintKindTemplate, "print", "%v", serverErr)
+			}
+			return issues
+		}
+
+		html, err = browserTemplateHtml(ctx, tmpl, target.entry, options.Config)
+		if err != nil {
+			add(LintSeverityError, LintKindRender, "print", "%v", err)
+			return issues
+		}
+
+		if strings.HasPrefix(html, "Template Error:") {
+			add(LintSeverityError, LintKindTemplate, "print", "%s", strings.TrimSpace(strings.TrimPrefix(html, "Template Error:")))
+			return issues
+		}
+
+		if !unsupported {
+			add(LintSeverityWarning, LintKindTemplate, "print", "server-side rendering failed, falling back to the browser: %v", serverErr)
+		}
+	}
+
+	if isEmptyHtml(html) {
+		add(LintSeverityError, LintKindEmpty, "print", "output is empty")
+		return issues
+	}
+
+	if options.SkipBrowser {
+		return issues
+	}
+
+	finalHtml, err := fixHtml(html, settings)
+	if err != nil {
+		add(LintSeverityError, LintKindRender, "print", "%v", err)
+		return issues
+	}
+
+	tempId := fmt.Sprint(rand.Int63())
+	renderCache.SetDefault(tempId, finalHtml)
+
+	inspection, err := rendering.InspectURLContext(ctx, local.URL("/api/html/"+tempId), settings.PrinterWidth)
+	if err != nil {
+		add(LintSeverityError, LintKindRender, "print", "%v", err)
+		return issues
+	}
+
+	for _, msg := range inspection.ConsoleErrors {
+		add(LintSeverityError, LintKindConsole, "print", "%s", msg)
+	}
+
+	if inspection.Width > settings.PrinterWidth {
+		add(LintSeverityError, LintKindWidth, "print", "output is %dpx wide, but the printer width is %dpx", inspection.Width, settings.PrinterWidth)
+	}
+
+	if inspection.Height >= rendering.MaxHeight {
+		add(LintSeverityError, LintKindHeight, "print", "output is %dpx tall, but at most %dpx can be printed", inspection.Height, rendering.MaxHeight)
+	}
+
+	return issues
+}
+
+// referencedFields returns the sorted and unique fields of it that are used in the template.
+func referencedFields(template string) []string {
+	found := map[string]bool{}
+	for _, match := range fieldRegex.FindAllStringSubmatch(template, -1) {
+		if match[1] == "snd" || strings.HasPrefix(match[1], "snd.") {
+			continue
+		}
+		found[match[1]] = true
+	}
+
+	var fields []string
+	for field := range found {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	return fields
+}
+
+// missingField returns the part of the dotted path that doesn't exist in the data or an empty string
+// if the field exists. If the path reaches a value that is not an object (e.g. a string whose method
+// is called) the field counts as present.
+func missingField(data map[string]any, field string) string {
+	var current any = data
+	keys := strings.Split(field, ".")
+	for i, key := range keys {
+		obj, ok := current.(map[string]any)
+		if !ok {
+			return ""
+		}
+
+		current, ok = obj[key]
+		if !ok {
+			return strings.Join(keys[:i+1], ".")
+		}
+	}
+	return ""
+}
+
+// isEmptyHtml checks if the html has neither visible text nor media.
+func isEmptyHtml(html string) bool {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		return false
+	}
+
+	doc.Find("script, style").Remove()
+
+	return strings.TrimSpace(doc.Text()) == "" && doc.Find("img, svg, canvas, video, iframe").Length() == 0
+}
+
+func RegisterLint(route *echo.Group, db database.Database) {
+	renderer := templating.New(db)
+
+	// The request is passed so that linting stops if the client disconnects.
+	bind.MustBind(route, "/lintTemplate", func(req *http.Request, id string, options LintOptions) (LintReport, error) {
+		tmpl, err := db.GetTemplate(id)
+		if err != nil {
+			return LintReport{}, err
+		}
+
+		return lintTemplate(req.Context(), db, renderer, tmpl, options)
+	})
+}
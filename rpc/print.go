@@ -2,6 +2,7 @@ package rpc
 
 import (
 	"bytes"
+	"context"
 	"crypto/sha256"
 	"encoding/base64"
 	"encoding/json"
@@ -17,7 +18,6 @@ import (
 	"net/http"
 	"regexp"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/BigJk/snd/rpc/bind"
@@ -26,9 +26,11 @@ import (
 	"github.com/BigJk/snd/database"
 	"github.com/patrickmn/go-cache"
 
+	"github.com/BigJk/snd/local"
 	"github.com/BigJk/snd/log"
 	"github.com/BigJk/snd/printing"
 	"github.com/BigJk/snd/rendering"
+	"github.com/BigJk/snd/templating"
 	"github.com/BigJk/snd/thermalprinter/epson"
 	"github.com/PuerkitoBio/goquery"
 	"github.com/labstack/echo/v4"
@@ -69,12 +71,19 @@ func fixHtml(html string, settings snd.Settings) (string, error) {
 		return "", log.ErrorUser(err, "html parsing failed")
 	}
 
+	// Make the fonts of the font library available
+	faces, err := fontFaces()
+	if err != nil {
+		return "", log.ErrorUser(err, "font loading failed")
+	}
+	doc.Find("head").AppendHtml(faces)
+
 	// Convert relative image urls to absolute ones
 	doc.Find("img").Each(func(i int, s *goquery.Selection) {
 		url := s.AttrOr("src", "")
 
 		if !strings.HasPrefix(url, "http") && !strings.HasPrefix(url, "data:") {
-			url = "http://" + ip.String() + ":7123/" + strings.TrimLeft(url, "/")
+			url = local.HostURL(ip.String(), "/"+strings.TrimLeft(url, "/"))
 			s.SetAttr("src", url)
 		}
 	})
@@ -104,7 +113,7 @@ func fixHtml(html string, settings snd.Settings) (string, error) {
 			return s
 		}
 
-		return fmt.Sprintf("url(%s%s%s%s%s)", symbol, "http://", ip, ":7123/", strings.TrimLeft(content, symbol+"/"))
+		return fmt.Sprintf("url(%s%s)", symbol, local.HostURL(ip.String(), "/"+strings.TrimLeft(content, symbol+"/")))
 	})
 
 	return finalHtml, nil
@@ -138,7 +147,7 @@ func print(db database.Database, printer printing.PossiblePrinter, html string)
 	renderCache.SetDefault(tempId, finalHtml)
 
 	// Render the html to image
-	renderedImage, err := rendering.RenderURL(fmt.Sprintf("http://127.0.0.1:7123/api/html/%s", tempId), settings.PrinterWidth)
+	renderedImage, err := rendering.RenderURL(local.URL("/api/html/"+tempId), settings.PrinterWidth)
 	if err != nil {
 		return fmt.Errorf("html to image rendering failed: %w", err)
 	}
@@ -238,6 +247,55 @@ func hashObject(data any) (string, error) {
 	return fmt.Sprintf("%x", hash.Sum(nil)), nil
 }
 
+// findTemplateEntry searches the entry in the template and its linked data sources.
+func findTemplateEntry(db database.Database, tmpl snd.Template, eid string) (snd.Entry, error) {
+	ent, err := db.GetEntry(tmpl.ID(), eid)
+	if err == nil {
+		return ent, nil
+	}
+
+	// Try to find the entry in the linked data sources
+	for _, dsid := range tmpl.DataSources {
+		if ds, err := db.GetSource(dsid); err == nil {
+			if ent, err := db.GetEntry(ds.ID(), eid); err == nil {
+				return ent, nil
+			}
+		}
+	}
+
+	return snd.Entry{}, err
+}
+
+// templateHtml renders the print template of tmpl. The template is rendered on the server if
+// possible. Otherwise, it falls back to rendering the template through the frontend.
+func templateHtml(ctx context.Context, renderer *templating.Renderer, tmpl snd.Template, entry snd.Entry, config map[string]any) (string, error) {
+	html, err := renderer.RenderTemplate(tmpl, entry, config)
+	if err == nil {
+		return html, nil
+	}
+
+	if !errors.Is(err, templating.ErrUnsupported) {
+		_ = log.Error(err, log.WithValue("template", tmpl.ID()), log.WithValue("fallback", "browser"))
+	}
+
+	return browserTemplateHtml(ctx, tmpl, entry, config)
+}
+
+// browserTemplateHtml renders the print template of tmpl through the frontend.
+func browserTemplateHtml(ctx context.Context, tmpl snd.Template, entry snd.Entry, config map[string]any) (string, error) {
+	entryJson, err := json.Marshal(entry)
+	if err != nil {
+		return "", err
+	}
+
+	configJson, err := json.Marshal(config)
+	if err != nil {
+		return "", err
+	}
+
+	return rendering.ExtractHTMLContext(ctx, local.URL(fmt.Sprintf("/#!/extern-print/template/%s/%s/%s", tmpl.ID(), base64.StdEncoding.EncodeToString(entryJson), base64.StdEncoding.EncodeToString(configJson))), "#render-done")
+}
+
 func RegisterPrint(route *echo.Group, extern *echo.Group, db database.Database, printer printing.PossiblePrinter, filePicker FilePicker) {
 	route.GET("/html/:id", func(c echo.Context) error {
 		val, ok := renderCache.Get(c.Param("id"))
@@ -276,18 +334,10 @@ func RegisterPrint(route *echo.Group, extern *echo.Group, db database.Database,
 		return print(db, printer, html)
 	})
 
-	externPrintTemplate := func(tmplId string, entry any, config any) error {
-		entryJson, err := json.Marshal(entry)
-		if err != nil {
-			return err
-		}
+	renderer := templating.New(db)
 
-		configJson, err := json.Marshal(config)
-		if err != nil {
-			return err
-		}
-
-		html, err := rendering.ExtractHTML(fmt.Sprintf("http://127.0.0.1:7123/#!/extern-print/template/%s/%s/%s", tmplId, base64.StdEncoding.EncodeToString(entryJson), base64.StdEncoding.EncodeToString(configJson)), "#render-done")
+	externPrintTemplate := func(tmpl snd.Template, entry snd.Entry, config map[string]any) error {
+		html, err := templateHtml(context.Background(), renderer, tmpl, entry, config)
 		if err != nil {
 			return err
 		}
@@ -300,11 +350,11 @@ func RegisterPrint(route *echo.Group, extern *echo.Group, db database.Database,
 	}
 
 	bind.MustBind(route, "/printTemplate", func(id string, entry snd.Entry, config map[string]any) error {
-		_, err := db.GetTemplate(id)
+		tmpl, err := db.GetTemplate(id)
 		if err != nil {
 			return err
 		}
-		return externPrintTemplate(id, entry, config)
+		return externPrintTemplate(tmpl, entry, config)
 	})
 
 	bind.MustBind(route, "/printTemplateEntry", func(id string, eid string, config map[string]any) error {
@@ -313,21 +363,45 @@ func RegisterPrint(route *echo.Group, extern *echo.Group, db database.Database,
 			return err
 		}
 
-		ent, err := db.GetEntry(id, eid)
+		ent, err := findTemplateEntry(db, tmpl, eid)
 		if err != nil {
-			// Try to find the entry in the linked data sources
-			for _, dsid := range tmpl.DataSources {
-				if ds, err := db.GetSource(dsid); err == nil {
-					if ent, err = db.GetEntry(ds.ID(), eid); err == nil {
-						return externPrintTemplate(id, ent, config)
-					}
-				}
-			}
-
 			return err
 		}
 
-		return externPrintTemplate(id, ent, config)
+		return externPrintTemplate(tmpl, ent, config)
+	})
+
+	bind.MustBind(route, "/renderTemplateEntry", func(id string, eid string, config map[string]any) (string, error) {
+		tmpl, err := db.GetTemplate(id)
+		if err != nil {
+			return "", err
+		}
+
+		ent, err := findTemplateEntry(db, tmpl, eid)
+		if err != nil {
+			return "", err
+		}
+
+		return templateHtml(context.Background(), renderer, tmpl, ent, config)
+	})
+
+	extern.GET("/templates/:id/entries/:eid/html", func(c echo.Context) error {
+		tmpl, err := db.GetTemplate(c.Param("id"))
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, err.Error())
+		}
+
+		ent, err := findTemplateEntry(db, tmpl, c.Param("eid"))
+		if err != nil {
+			return c.JSON(http.StatusNotFound, err.Error())
+		}
+
+		html, err := templateHtml(c.Request().Context(), renderer, tmpl, ent, map[string]any{})
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, err.Error())
+		}
+
+		return c.HTML(http.StatusOK, html)
 	})
 
 	externPrintGenerator := func(genId string, config map[string]any) error {
@@ -336,7 +410,7 @@ func RegisterPrint(route *echo.Group, extern *echo.Group, db database.Database,
 			return err
 		}
 
-		html, err := rendering.ExtractHTML(fmt.Sprintf("http://127.0.0.1:7123/#!/extern-print/generator/%s/%s", genId, base64.StdEncoding.EncodeToString(configJson)), "#render-done")
+		html, err := rendering.ExtractHTML(local.URL(fmt.Sprintf("/#!/extern-print/generator/%s/%s", genId, base64.StdEncoding.EncodeToString(configJson))), "#render-done")
 		if err != nil {
 			return err
 		}
@@ -376,7 +450,7 @@ func RegisterPrint(route *echo.Group, extern *echo.Group, db database.Database,
 		tempId := fmt.Sprint(rand.Int63())
 		renderCache.SetDefault(tempId, finalHtml)
 
-		img, err := rendering.RenderURL(fmt.Sprintf("http://127.0.0.1:7123/api/html/%s", tempId), settings.PrinterWidth)
+		img, err := rendering.RenderURL(local.URL("/api/html/"+tempId), settings.PrinterWidth)
 		if err != nil {
 			return err
 		}
@@ -411,7 +485,7 @@ func RegisterPrint(route *echo.Group, extern *echo.Group, db database.Database,
 		tempId := fmt.Sprint(rand.Int63())
 		renderCache.SetDefault(tempId, finalHtml)
 
-		img, err := rendering.RenderURL(fmt.Sprintf("http://127.0.0.1:7123/api/html/%s", tempId), settings.PrinterWidth)
+		img, err := rendering.RenderURL(local.URL("/api/html/"+tempId), settings.PrinterWidth)
 		if err != nil {
 			return err
 		}
@@ -430,14 +504,17 @@ func RegisterPrint(route *echo.Group, extern *echo.Group, db database.Database,
 
 	bind.MustBind(route, "/previewCache", func(id string, html string) (string, error) {
 		renderCache.SetDefault(id, html)
-		return fmt.Sprintf("http://127.0.0.1:7123/api/html/%s", id), nil
+		return local.URL("/api/html/" + id), nil
+	})
+
+	bind.MustBind(route, "/getRenderingMetrics", func() (rendering.Metrics, error) {
+		return rendering.GetMetrics(), nil
 	})
 
-	previewImageMutex := sync.Mutex{}
 	route.GET("/preview-image/:id", func(c echo.Context) error {
-		// Lock the mutex to prevent too many requests at once
-		previewImageMutex.Lock()
-		defer previewImageMutex.Unlock()
+		// The rendering pool limits how many previews render at once. Using the request
+		// context aborts queued renders if the client is gone.
+		ctx := c.Request().Context()
 
 		id := c.Param("id")
 		html := ""
@@ -459,15 +536,7 @@ func RegisterPrint(route *echo.Group, extern *echo.Group, db database.Database,
 				}
 			}
 
-			entryJson, err := json.Marshal(map[string]any{
-				"id":   "skeleton",
-				"name": "Skeleton",
-				"data": tmpl.SkeletonData,
-			})
-			if err != nil {
-				return err
-			}
-			tmplHtml, err := rendering.ExtractHTML(fmt.Sprintf("http://127.0.0.1:7123/#!/extern-print/template/%s/%s/%s", id, base64.StdEncoding.EncodeToString(entryJson), base64.StdEncoding.EncodeToString([]byte("{}"))), "#render-done")
+			tmplHtml, err := templateHtml(ctx, renderer, tmpl, templating.SkeletonEntry(tmpl), map[string]any{})
 			if err != nil {
 				return err
 			}
@@ -489,7 +558,7 @@ func RegisterPrint(route *echo.Group, extern *echo.Group, db database.Database,
 				}
 			}
 
-			genHtml, err := rendering.ExtractHTML(fmt.Sprintf("http://127.0.0.1:7123/#!/extern-print/generator/%s/%s", id, base64.StdEncoding.EncodeToString([]byte("{}"))), "#render-done")
+			genHtml, err := rendering.ExtractHTMLContext(ctx, local.URL(fmt.Sprintf("/#!/extern-print/generator/%s/%s", id, base64.StdEncoding.EncodeToString([]byte("{}")))), "#render-done")
 			if err != nil {
 				return err
 			}
@@ -515,7 +584,7 @@ func RegisterPrint(route *echo.Group, extern *echo.Group, db database.Database,
 		tempId := fmt.Sprint(rand.Int63())
 		renderCache.SetDefault(tempId, finalHtml)
 
-		img, err := rendering.RenderURL(fmt.Sprintf("http://127.0.0.1:7123/api/html/%s", tempId), settings.PrinterWidth)
+		img, err := rendering.RenderURLContext(ctx, local.URL("/api/html/"+tempId), settings.PrinterWidth)
 		if err != nil {
 			return err
 		}
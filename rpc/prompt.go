@@ -0,0 +1,99 @@
+package rpc
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/BigJk/snd"
+	"github.com/BigJk/snd/database"
+	"github.com/BigJk/snd/imexport"
+	"github.com/BigJk/snd/rpc/bind"
+	rpcImexport "github.com/BigJk/snd/rpc/imexport"
+	"github.com/labstack/echo/v4"
+)
+
+// findPrompt finds a prompt by its id, name or slug.
+func findPrompt(db database.Database, name string) (snd.Prompt, error) {
+	if snd.IsPromptID(name) {
+		if prompt, err := db.GetPrompt(name); err == nil && prompt.Slug != "" {
+			return prompt, nil
+		}
+	}
+
+	prompts, err := db.GetPrompts()
+	if err != nil {
+		return snd.Prompt{}, err
+	}
+
+	for i := range prompts {
+		if strings.EqualFold(prompts[i].Name, name) {
+			return prompts[i], nil
+		}
+	}
+
+	for i := range prompts {
+		if prompts[i].Slug == name {
+			return prompts[i], nil
+		}
+	}
+
+	return snd.Prompt{}, fmt.Errorf("prompt '%s' not found", name)
+}
+
+func RegisterPrompt(route *echo.Group, db database.Database, filePicker FilePicker) {
+	bind.MustBind(route, "/savePrompt", func(prompt snd.Prompt) error {
+		return rpcImexport.SavePrompt(db, prompt)
+	})
+	bind.MustBind(route, "/deletePrompt", db.DeletePrompt)
+	bind.MustBind(route, "/getPrompts", db.GetPrompts)
+	bind.MustBind(route, "/getPrompt", db.GetPrompt)
+
+	bind.MustBind(route, "/importPromptJSON", func(json string) (string, error) {
+		prompt, err := imexport.ImportPromptJSON(json)
+		if err != nil {
+			return "", err
+		}
+
+		if err := rpcImexport.SavePrompt(db, prompt); err != nil {
+			return "", err
+		}
+
+		return prompt.Name, nil
+	})
+
+	bind.MustBind(route, "/exportPromptJSON", func(id string) (string, error) {
+		prompt, err := db.GetPrompt(id)
+		if err != nil {
+			return "", err
+		}
+
+		data, err := imexport.ExportPromptJSON(prompt)
+		if err != nil {
+			return "", err
+		}
+
+		return string(data), nil
+	})
+
+	// ZIP export route so export is possible in headless mode
+	route.GET("/export/prompt/zip/:id", func(c echo.Context) error {
+		prompt, err := db.GetPrompt(c.Param("id"))
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, err)
+		}
+
+		buf := &bytes.Buffer{}
+		file, err := imexport.ExportPromptZIP(prompt, buf)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, err)
+		}
+
+		c.Response().Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", file))
+		return c.Blob(http.StatusOK, "application/zip", buf.Bytes())
+	})
+
+	rpcImexport.RegisterPromptExports(route, db, filePicker)
+	rpcImexport.RegisterPromptImports(route, db)
+}
@@ -1,12 +1,25 @@
 package rpc
 
 import (
+	"github.com/BigJk/snd"
 	"github.com/BigJk/snd/database"
+	"github.com/BigJk/snd/rendering"
 	"github.com/BigJk/snd/rpc/bind"
 	"github.com/labstack/echo/v4"
 )
 
 func RegisterSettings(route *echo.Group, db database.Database) {
+	if settings, err := db.GetSettings(); err == nil {
+		rendering.SetPoolSize(settings.RenderPoolSize)
+	}
+
 	bind.MustBind(route, "/getSettings", db.GetSettings)
-	bind.MustBind(route, "/saveSettings", db.SaveSettings)
+	bind.MustBind(route, "/saveSettings", func(settings snd.Settings) error {
+		if err := db.SaveSettings(settings); err != nil {
+			return err
+		}
+
+		rendering.SetPoolSize(settings.RenderPoolSize)
+		return nil
+	})
 }
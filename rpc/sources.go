@@ -10,7 +10,15 @@ import (
 
 func RegisterSources(route *echo.Group, db database.Database, filePicker FilePicker) {
 	bind.MustBind(route, "/saveSource", db.SaveSource)
-	bind.MustBind(route, "/deleteSource", db.DeleteSource)
+	bind.MustBind(route, "/deleteSource", func(id string) error {
+		if err := db.DeleteSource(id); err != nil {
+			return err
+		}
+
+		// Without the source the information of the last import is meaningless
+		_ = rpcImexport.DeleteImportBase(db, id)
+		return nil
+	})
 	bind.MustBind(route, "/getSources", db.GetSources)
 	bind.MustBind(route, "/getSource", db.GetSource)
 
@@ -34,4 +42,5 @@ func RegisterSources(route *echo.Group, db database.Database, filePicker FilePic
 
 	rpcImexport.RegisterDataSourceExports(route, db, filePicker)
 	rpcImexport.RegisterDataSourceImports(route, db)
+	rpcImexport.RegisterMappings(route, db)
 }
@@ -70,7 +70,7 @@ func RegisterSync(route *echo.Group, m *melody.Melody, db database.Database) {
 			return "", err
 		}
 
-		tmplFolder, err := imexport.ExportTemplateFolder(tmpl, nil, folder)
+		tmplFolder, err := imexport.ExportTemplateFolder(tmpl, nil, nil, folder)
 		if err != nil {
 			return "", err
 		}
@@ -105,7 +105,7 @@ func RegisterSync(route *echo.Group, m *melody.Melody, db database.Database) {
 						return
 					}
 					if event.Op&fsnotify.Write == fsnotify.Write {
-						updated, _, err := imexport.ImportTemplateFolder(session.folder)
+						updated, _, _, err := imexport.ImportTemplateFolder(session.folder)
 						if err != nil {
 							_ = log.ErrorString(fmt.Sprintf("error in '%s' watcher: %s", session.folder, err))
 							continue
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"github.com/BigJk/snd/database"
+	"github.com/BigJk/snd/golden"
 	"github.com/BigJk/snd/imexport"
 	"github.com/BigJk/snd/rpc/bind"
 	rpcImexport "github.com/BigJk/snd/rpc/imexport"
@@ -14,7 +15,12 @@ import (
 
 func RegisterTemplate(route *echo.Group, extern *echo.Group, db database.Database, filePicker FilePicker) {
 	bind.MustBind(route, "/saveTemplate", db.SaveTemplate)
-	bind.MustBind(route, "/deleteTemplate", db.DeleteTemplate)
+	bind.MustBind(route, "/deleteTemplate", func(id string) error {
+		if err := db.DeleteTemplate(id); err != nil {
+			return err
+		}
+		return golden.DeleteReferences(db, id)
+	})
 	bind.MustBind(route, "/getTemplates", db.GetTemplates)
 	bind.MustBind(route, "/getTemplate", db.GetTemplate)
 
@@ -30,8 +36,13 @@ func RegisterTemplate(route *echo.Group, extern *echo.Group, db database.Databas
 			return c.JSON(http.StatusBadRequest, err)
 		}
 
+		attachments, err := rpcImexport.TemplateAttachments(db, tmpl)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, err)
+		}
+
 		buf := &bytes.Buffer{}
-		file, err := imexport.ExportTemplateZIP(tmpl, entries, buf)
+		file, err := imexport.ExportTemplateZIP(tmpl, entries, attachments, buf)
 		if err != nil {
 			return c.JSON(http.StatusBadRequest, err)
 		}
@@ -12,23 +12,30 @@ import requests
 
 class SndAPI:
 
-    def __init__(self, base_url):
+    def __init__(self, base_url, token=None, verify=True):
         """
         Initialize the Sales & Dungeon API class with the base URL of the API.
 
         Parameters:
-        - base_url (str): The base URL of the API. Most likely "http://127.0.0.1:7123"
+        - base_url (str): The base URL of the API. Most likely "http://127.0.0.1:7123" or "https://127.0.0.1:7123" if TLS is enabled
+        - token (str): The API token if authentication is enabled. Tokens can be created in the settings.
+        - verify (bool or str): Verify the certificate of the server. To pin a self-signed certificate pass
+          the path to its cert.pem, which is located in the "tls" folder of the data directory.
         """
-        self.base_url = base_url
+        self.base_url = base_url.rstrip("/")
+        self.session = requests.Session()
+        self.session.verify = verify
+        if token:
+            self.session.headers["Authorization"] = f"Bearer {token}"
 
     def _make_request(self, endpoint, method='GET', params=None):
         url = f"{self.base_url}/{endpoint}"
         if method == 'GET':
-            response = requests.get(url, params=params)
+            response = self.session.get(url, params=params)
         elif method == 'POST':
-            response = requests.post(url, json=params)
+            response = self.session.post(url, json=params)
         elif method == 'DELETE':
-            response = requests.delete(url, json=params)
+            response = self.session.delete(url, json=params)
         else:
             raise ValueError(f"Unsupported HTTP method: {method}")
         
@@ -37,703 +44,1885 @@ class SndAPI:
         else:
             response.raise_for_status()
 
-    def get_settings(self, ):
+    def add_font(self, arg0, arg1):
         """
-        Perform an action using the getSettings API endpoint.
+        Perform an action using the addFont API endpoint.
 
         Parameters:
-
+        - arg0 (str): parameter
+        - arg1 (str): parameter
         """
-        endpoint = "api/getSettings"
-        return self._make_request(endpoint, method='POST', params=[])
+        endpoint = "api/addFont"
+        return self._make_request(endpoint, method='POST', params=[arg0, arg1])
 
-    def imports_source_csv(self, arg0):
+    def ai_cache_delete(self, arg0):
         """
-        Perform an action using the importsSourceCSV API endpoint.
+        Perform an action using the aiCacheDelete API endpoint.
 
         Parameters:
-        - arg0 (list of dict): parameter
+        - arg0 (str): parameter
         """
-        endpoint = "api/importsSourceCSV"
+        endpoint = "api/aiCacheDelete"
         return self._make_request(endpoint, method='POST', params=[arg0])
 
-    def get_printer(self, ):
-        """
-        Perform an action using the getPrinter API endpoint.
-
-        Parameters:
-
-        """
-        endpoint = "api/getPrinter"
-        return self._make_request(endpoint, method='POST', params=[])
-
-    def get_version(self, ):
+    def ai_cache_evict(self, ):
         """
-        Perform an action using the getVersion API endpoint.
+        Perform an action using the aiCacheEvict API endpoint.
 
         Parameters:
 
         """
-        endpoint = "api/getVersion"
+        endpoint = "api/aiCacheEvict"
         return self._make_request(endpoint, method='POST', params=[])
 
-    def fetch_image(self, arg0):
+    def ai_cache_get(self, arg0):
         """
-        Perform an action using the fetchImage API endpoint.
+        Perform an action using the aiCacheGet API endpoint.
 
         Parameters:
         - arg0 (str): parameter
         """
-        endpoint = "api/fetchImage"
+        endpoint = "api/aiCacheGet"
         return self._make_request(endpoint, method='POST', params=[arg0])
 
-    def get_templates(self, ):
+    def ai_cache_list(self, ):
         """
-        Perform an action using the getTemplates API endpoint.
+        Perform an action using the aiCacheList API endpoint.
 
         Parameters:
 
         """
-        endpoint = "api/getTemplates"
+        endpoint = "api/aiCacheList"
         return self._make_request(endpoint, method='POST', params=[])
 
-    def exports_generator_zip(self, arg0, arg1):
+    def ai_cache_pin(self, arg0, arg1):
         """
-        Perform an action using the exportsGeneratorZIP API endpoint.
+        Perform an action using the aiCachePin API endpoint.
 
         Parameters:
         - arg0 (str): parameter
-        - arg1 (list of dict): parameter
+        - arg1 (bool): parameter
         """
-        endpoint = "api/exportsGeneratorZIP"
+        endpoint = "api/aiCachePin"
         return self._make_request(endpoint, method='POST', params=[arg0, arg1])
 
-    def count_entries(self, arg0):
+    def ai_cached(self, arg0, arg1, arg2):
         """
-        Perform an action using the countEntries API endpoint.
+        Perform an action using the aiCached API endpoint.
 
         Parameters:
         - arg0 (str): parameter
+        - arg1 (str): parameter
+        - arg2 (str): parameter
         """
-        endpoint = "api/countEntries"
-        return self._make_request(endpoint, method='POST', params=[arg0])
+        endpoint = "api/aiCached"
+        return self._make_request(endpoint, method='POST', params=[arg0, arg1, arg2])
 
-    def sync_start(self, arg0, arg1):
+    def ai_coding_prompt(self, arg0, arg1):
         """
-        Perform an action using the syncStart API endpoint.
+        Perform an action using the aiCodingPrompt API endpoint.
 
         Parameters:
         - arg0 (str): parameter
         - arg1 (str): parameter
         """
-        endpoint = "api/syncStart"
+        endpoint = "api/aiCodingPrompt"
         return self._make_request(endpoint, method='POST', params=[arg0, arg1])
 
-    def ai_providers(self, ):
+    def ai_generate_entries(self, arg0, arg1, arg2):
         """
-        Perform an action using the aiProviders API endpoint.
+        Perform an action using the aiGenerateEntries API endpoint.
 
         Parameters:
+        - arg0 (str): parameter
+        - arg1 (str): parameter
+        - arg2 (int): parameter
+        """
+        endpoint = "api/aiGenerateEntries"
+        return self._make_request(endpoint, method='POST', params=[arg0, arg1, arg2])
 
+    def ai_grounded_prompt(self, arg0, arg1, arg2, arg3):
         """
-        endpoint = "api/aiProviders"
-        return self._make_request(endpoint, method='POST', params=[])
+        Perform an action using the aiGroundedPrompt API endpoint.
 
-    def ai_models(self, arg0):
+        Parameters:
+        - arg0 (str): parameter
+        - arg1 (str): parameter
+        - arg2 (list of str): parameter
+        - arg3 (str): parameter
         """
-        Perform an action using the aiModels API endpoint.
+        endpoint = "api/aiGroundedPrompt"
+        return self._make_request(endpoint, method='POST', params=[arg0, arg1, arg2, arg3])
+
+    def ai_invalidate_cached(self, arg0):
+        """
+        Perform an action using the aiInvalidateCached API endpoint.
 
         Parameters:
         - arg0 (str): parameter
         """
-        endpoint = "api/aiModels"
+        endpoint = "api/aiInvalidateCached"
         return self._make_request(endpoint, method='POST', params=[arg0])
 
-    def save_template(self, arg0):
+    def ai_models(self, arg0):
         """
-        Perform an action using the saveTemplate API endpoint.
+        Perform an action using the aiModels API endpoint.
 
         Parameters:
-        - arg0 (dict [snd type template]): parameter
+        - arg0 (str): parameter
         """
-        endpoint = "api/saveTemplate"
+        endpoint = "api/aiModels"
         return self._make_request(endpoint, method='POST', params=[arg0])
 
-    def get_available_printer(self, ):
+    def ai_prompt(self, arg0, arg1, arg2):
         """
-        Perform an action using the getAvailablePrinter API endpoint.
+        Perform an action using the aiPrompt API endpoint.
 
         Parameters:
-
+        - arg0 (str): parameter
+        - arg1 (str): parameter
+        - arg2 (str): parameter
         """
-        endpoint = "api/getAvailablePrinter"
-        return self._make_request(endpoint, method='POST', params=[])
+        endpoint = "api/aiPrompt"
+        return self._make_request(endpoint, method='POST', params=[arg0, arg1, arg2])
 
-    def exports_template(self, ):
+    def ai_providers(self, ):
         """
-        Perform an action using the exportsTemplate API endpoint.
+        Perform an action using the aiProviders API endpoint.
 
         Parameters:
 
         """
-        endpoint = "api/exportsTemplate"
+        endpoint = "api/aiProviders"
         return self._make_request(endpoint, method='POST', params=[])
 
-    def save_generator(self, arg0):
+    def ai_retrieve(self, arg0, arg1, arg2):
         """
-        Perform an action using the saveGenerator API endpoint.
+        Perform an action using the aiRetrieve API endpoint.
 
         Parameters:
-        - arg0 (dict [snd type generator]): parameter
+        - arg0 (list of str): parameter
+        - arg1 (str): parameter
+        - arg2 (int): parameter
         """
-        endpoint = "api/saveGenerator"
-        return self._make_request(endpoint, method='POST', params=[arg0])
+        endpoint = "api/aiRetrieve"
+        return self._make_request(endpoint, method='POST', params=[arg0, arg1, arg2])
 
-    def get_generators(self, ):
+    def ai_run_prompt(self, arg0, arg1, arg2, arg3):
         """
-        Perform an action using the getGenerators API endpoint.
+        Perform an action using the aiRunPrompt API endpoint.
 
         Parameters:
-
+        - arg0 (str): parameter
+        - arg1 (str): parameter
+        - arg2 (dict): parameter
+        - arg3 (str): parameter
         """
-        endpoint = "api/getGenerators"
-        return self._make_request(endpoint, method='POST', params=[])
+        endpoint = "api/aiRunPrompt"
+        return self._make_request(endpoint, method='POST', params=[arg0, arg1, arg2, arg3])
 
-    def save_entry(self, arg0, arg1):
+    def ai_usage(self, arg0):
         """
-        Perform an action using the saveEntry API endpoint.
+        Perform an action using the aiUsage API endpoint.
 
         Parameters:
-        - arg0 (str): parameter
-        - arg1 (dict [snd type entry]): parameter
+        - arg0 (int): parameter
         """
-        endpoint = "api/saveEntry"
-        return self._make_request(endpoint, method='POST', params=[arg0, arg1])
+        endpoint = "api/aiUsage"
+        return self._make_request(endpoint, method='POST', params=[arg0])
 
-    def imports_template_json(self, arg0):
+    def approve_template_reference(self, arg0, arg1, arg2):
         """
-        Perform an action using the importsTemplateJSON API endpoint.
+        Perform an action using the approveTemplateReference API endpoint.
 
         Parameters:
-        - arg0 (list of dict): parameter
+        - arg0 (str): parameter
+        - arg1 (str): parameter
+        - arg2 (rpc.GoldenOptions): parameter
         """
-        endpoint = "api/importsTemplateJSON"
-        return self._make_request(endpoint, method='POST', params=[arg0])
+        endpoint = "api/approveTemplateReference"
+        return self._make_request(endpoint, method='POST', params=[arg0, arg1, arg2])
 
-    def copy_entries(self, arg0, arg1):
+    def approve_template_references(self, arg0, arg1):
         """
-        Perform an action using the copyEntries API endpoint.
+        Perform an action using the approveTemplateReferences API endpoint.
 
         Parameters:
         - arg0 (str): parameter
-        - arg1 (str): parameter
+        - arg1 (rpc.GoldenOptions): parameter
         """
-        endpoint = "api/copyEntries"
+        endpoint = "api/approveTemplateReferences"
         return self._make_request(endpoint, method='POST', params=[arg0, arg1])
 
-    def get_sources(self, ):
+    def clear_ai_cache(self, ):
         """
-        Perform an action using the getSources API endpoint.
+        Perform an action using the clearAICache API endpoint.
 
         Parameters:
 
         """
-        endpoint = "api/getSources"
+        endpoint = "api/clearAICache"
         return self._make_request(endpoint, method='POST', params=[])
 
-    def exports_source_folder(self, arg0, arg1):
+    def clear_preview_cache(self, ):
         """
-        Perform an action using the exportsSourceFolder API endpoint.
+        Perform an action using the clearPreviewCache API endpoint.
 
         Parameters:
-        - arg0 (str): parameter
-        - arg1 (list of dict): parameter
+
         """
-        endpoint = "api/exportsSourceFolder"
-        return self._make_request(endpoint, method='POST', params=[arg0, arg1])
+        endpoint = "api/clearPreviewCache"
+        return self._make_request(endpoint, method='POST', params=[])
 
-    def exports_source_zip(self, arg0, arg1):
+    def copy_entries(self, arg0, arg1):
         """
-        Perform an action using the exportsSourceZIP API endpoint.
+        Perform an action using the copyEntries API endpoint.
 
         Parameters:
         - arg0 (str): parameter
-        - arg1 (list of dict): parameter
+        - arg1 (str): parameter
         """
-        endpoint = "api/exportsSourceZIP"
+        endpoint = "api/copyEntries"
         return self._make_request(endpoint, method='POST', params=[arg0, arg1])
 
-    def imports_source_5e_tools_folder(self, arg0):
+    def count_entries(self, arg0):
         """
-        Perform an action using the importsSource5eToolsFolder API endpoint.
+        Perform an action using the countEntries API endpoint.
 
         Parameters:
-        - arg0 (list of dict): parameter
+        - arg0 (str): parameter
         """
-        endpoint = "api/importsSource5eToolsFolder"
+        endpoint = "api/countEntries"
         return self._make_request(endpoint, method='POST', params=[arg0])
 
-    def exports_template_folder(self, arg0, arg1):
+    def create_auth_token(self, arg0, arg1):
         """
-        Perform an action using the exportsTemplateFolder API endpoint.
+        Perform an action using the createAuthToken API endpoint.
 
         Parameters:
         - arg0 (str): parameter
-        - arg1 (list of dict): parameter
+        - arg1 (str): parameter
         """
-        endpoint = "api/exportsTemplateFolder"
+        endpoint = "api/createAuthToken"
         return self._make_request(endpoint, method='POST', params=[arg0, arg1])
 
-    def imports_template(self, ):
+    def cut_paper(self, ):
         """
-        Perform an action using the importsTemplate API endpoint.
+        Perform an action using the cutPaper API endpoint.
 
         Parameters:
 
         """
-        endpoint = "api/importsTemplate"
+        endpoint = "api/cutPaper"
         return self._make_request(endpoint, method='POST', params=[])
 
-    def imports_generator_url(self, arg0):
+    def delete_auth_token(self, arg0):
         """
-        Perform an action using the importsGeneratorURL API endpoint.
+        Perform an action using the deleteAuthToken API endpoint.
 
         Parameters:
-        - arg0 (list of dict): parameter
+        - arg0 (str): parameter
         """
-        endpoint = "api/importsGeneratorURL"
+        endpoint = "api/deleteAuthToken"
         return self._make_request(endpoint, method='POST', params=[arg0])
 
-    def delete_source(self, arg0):
+    def delete_auth_user(self, arg0):
         """
-        Perform an action using the deleteSource API endpoint.
+        Perform an action using the deleteAuthUser API endpoint.
 
         Parameters:
         - arg0 (str): parameter
         """
-        endpoint = "api/deleteSource"
+        endpoint = "api/deleteAuthUser"
         return self._make_request(endpoint, method='POST', params=[arg0])
 
-    def ai_cached(self, arg0, arg1, arg2):
+    def delete_entries(self, arg0):
         """
-        Perform an action using the aiCached API endpoint.
+        Perform an action using the deleteEntries API endpoint.
 
         Parameters:
         - arg0 (str): parameter
-        - arg1 (str): parameter
-        - arg2 (str): parameter
         """
-        endpoint = "api/aiCached"
-        return self._make_request(endpoint, method='POST', params=[arg0, arg1, arg2])
+        endpoint = "api/deleteEntries"
+        return self._make_request(endpoint, method='POST', params=[arg0])
 
-    def save_settings(self, arg0):
+    def delete_entry(self, arg0, arg1):
         """
-        Perform an action using the saveSettings API endpoint.
+        Perform an action using the deleteEntry API endpoint.
 
         Parameters:
-        - arg0 (dict [snd type settings]): parameter
+        - arg0 (str): parameter
+        - arg1 (str): parameter
         """
-        endpoint = "api/saveSettings"
-        return self._make_request(endpoint, method='POST', params=[arg0])
+        endpoint = "api/deleteEntry"
+        return self._make_request(endpoint, method='POST', params=[arg0, arg1])
 
-    def delete_template(self, arg0):
+    def delete_generator(self, arg0):
         """
-        Perform an action using the deleteTemplate API endpoint.
+        Perform an action using the deleteGenerator API endpoint.
 
         Parameters:
         - arg0 (str): parameter
         """
-        endpoint = "api/deleteTemplate"
+        endpoint = "api/deleteGenerator"
         return self._make_request(endpoint, method='POST', params=[arg0])
 
-    def get_template(self, arg0):
+    def delete_import_mapping(self, arg0):
         """
-        Perform an action using the getTemplate API endpoint.
+        Perform an action using the deleteImportMapping API endpoint.
 
         Parameters:
         - arg0 (str): parameter
         """
-        endpoint = "api/getTemplate"
+        endpoint = "api/deleteImportMapping"
         return self._make_request(endpoint, method='POST', params=[arg0])
 
-    def imports_generator_zip(self, arg0):
+    def delete_prompt(self, arg0):
         """
-        Perform an action using the importsGeneratorZIP API endpoint.
+        Perform an action using the deletePrompt API endpoint.
 
         Parameters:
-        - arg0 (list of dict): parameter
+        - arg0 (str): parameter
         """
-        endpoint = "api/importsGeneratorZIP"
+        endpoint = "api/deletePrompt"
         return self._make_request(endpoint, method='POST', params=[arg0])
 
-    def save_source(self, arg0):
+    def delete_source(self, arg0):
         """
-        Perform an action using the saveSource API endpoint.
+        Perform an action using the deleteSource API endpoint.
 
         Parameters:
-        - arg0 (dict [snd type data_source]): parameter
+        - arg0 (str): parameter
         """
-        endpoint = "api/saveSource"
+        endpoint = "api/deleteSource"
         return self._make_request(endpoint, method='POST', params=[arg0])
 
-    def imports_source_zip(self, arg0):
+    def delete_template(self, arg0):
         """
-        Perform an action using the importsSourceZIP API endpoint.
+        Perform an action using the deleteTemplate API endpoint.
 
         Parameters:
-        - arg0 (list of dict): parameter
+        - arg0 (str): parameter
         """
-        endpoint = "api/importsSourceZIP"
+        endpoint = "api/deleteTemplate"
         return self._make_request(endpoint, method='POST', params=[arg0])
 
-    def imports_generator(self, ):
+    def delete_template_reference(self, arg0, arg1):
         """
-        Perform an action using the importsGenerator API endpoint.
+        Perform an action using the deleteTemplateReference API endpoint.
 
         Parameters:
-
+        - arg0 (str): parameter
+        - arg1 (str): parameter
         """
-        endpoint = "api/importsGenerator"
-        return self._make_request(endpoint, method='POST', params=[])
+        endpoint = "api/deleteTemplateReference"
+        return self._make_request(endpoint, method='POST', params=[arg0, arg1])
 
-    def export_source_json(self, arg0):
+    def delete_template_references(self, arg0):
         """
-        Perform an action using the exportSourceJSON API endpoint.
+        Perform an action using the deleteTemplateReferences API endpoint.
 
         Parameters:
         - arg0 (str): parameter
         """
-        endpoint = "api/exportSourceJSON"
+        endpoint = "api/deleteTemplateReferences"
         return self._make_request(endpoint, method='POST', params=[arg0])
 
-    def imports_source(self, ):
+    def export_prompt_json(self, arg0):
         """
-        Perform an action using the importsSource API endpoint.
+        Perform an action using the exportPromptJSON API endpoint.
 
         Parameters:
-
+        - arg0 (str): parameter
         """
-        endpoint = "api/importsSource"
-        return self._make_request(endpoint, method='POST', params=[])
+        endpoint = "api/exportPromptJSON"
+        return self._make_request(endpoint, method='POST', params=[arg0])
 
-    def imports_source_5e_tools_single_file(self, arg0):
+    def export_source_json(self, arg0):
         """
-        Perform an action using the importsSource5eToolsSingleFile API endpoint.
+        Perform an action using the exportSourceJSON API endpoint.
 
         Parameters:
-        - arg0 (list of dict): parameter
+        - arg0 (str): parameter
         """
-        endpoint = "api/importsSource5eToolsSingleFile"
+        endpoint = "api/exportSourceJSON"
         return self._make_request(endpoint, method='POST', params=[arg0])
 
-    def sync_local_to_cloud(self, ):
+    def export_sources_xlsx(self, arg0, arg1):
         """
-        Perform an action using the syncLocalToCloud API endpoint.
+        Perform an action using the exportSourcesXLSX API endpoint.
 
         Parameters:
-
+        - arg0 (list of str): parameter
+        - arg1 (str): parameter
         """
-        endpoint = "api/syncLocalToCloud"
-        return self._make_request(endpoint, method='POST', params=[])
+        endpoint = "api/exportSourcesXLSX"
+        return self._make_request(endpoint, method='POST', params=[arg0, arg1])
 
-    def import_generator_json(self, arg0):
+    def export_template_entries_xlsx(self, arg0, arg1):
         """
-        Perform an action using the importGeneratorJSON API endpoint.
+        Perform an action using the exportTemplateEntriesXLSX API endpoint.
 
         Parameters:
         - arg0 (str): parameter
+        - arg1 (str): parameter
         """
-        endpoint = "api/importGeneratorJSON"
-        return self._make_request(endpoint, method='POST', params=[arg0])
+        endpoint = "api/exportTemplateEntriesXLSX"
+        return self._make_request(endpoint, method='POST', params=[arg0, arg1])
+
+    def exports_generator(self, ):
+        """
+        Perform an action using the exportsGenerator API endpoint.
+
+        Parameters:
+
+        """
+        endpoint = "api/exportsGenerator"
+        return self._make_request(endpoint, method='POST', params=[])
+
+    def exports_generator_folder(self, arg0, arg1):
+        """
+        Perform an action using the exportsGeneratorFolder API endpoint.
+
+        Parameters:
+        - arg0 (str): parameter
+        - arg1 (list of dict): parameter
+        """
+        endpoint = "api/exportsGeneratorFolder"
+        return self._make_request(endpoint, method='POST', params=[arg0, arg1])
+
+    def exports_generator_native_zip(self, arg0, arg1):
+        """
+        Perform an action using the exportsGeneratorNativeZIP API endpoint.
+
+        Parameters:
+        - arg0 (str): parameter
+        - arg1 (list of dict): parameter
+        """
+        endpoint = "api/exportsGeneratorNativeZIP"
+        return self._make_request(endpoint, method='POST', params=[arg0, arg1])
+
+    def exports_generator_zip(self, arg0, arg1):
+        """
+        Perform an action using the exportsGeneratorZIP API endpoint.
+
+        Parameters:
+        - arg0 (str): parameter
+        - arg1 (list of dict): parameter
+        """
+        endpoint = "api/exportsGeneratorZIP"
+        return self._make_request(endpoint, method='POST', params=[arg0, arg1])
+
+    def exports_prompt(self, ):
+        """
+        Perform an action using the exportsPrompt API endpoint.
+
+        Parameters:
+
+        """
+        endpoint = "api/exportsPrompt"
+        return self._make_request(endpoint, method='POST', params=[])
+
+    def exports_prompt_folder(self, arg0, arg1):
+        """
+        Perform an action using the exportsPromptFolder API endpoint.
+
+        Parameters:
+        - arg0 (str): parameter
+        - arg1 (list of dict): parameter
+        """
+        endpoint = "api/exportsPromptFolder"
+        return self._make_request(endpoint, method='POST', params=[arg0, arg1])
+
+    def exports_prompt_native_zip(self, arg0, arg1):
+        """
+        Perform an action using the exportsPromptNativeZIP API endpoint.
+
+        Parameters:
+        - arg0 (str): parameter
+        - arg1 (list of dict): parameter
+        """
+        endpoint = "api/exportsPromptNativeZIP"
+        return self._make_request(endpoint, method='POST', params=[arg0, arg1])
+
+    def exports_prompt_zip(self, arg0, arg1):
+        """
+        Perform an action using the exportsPromptZIP API endpoint.
+
+        Parameters:
+        - arg0 (str): parameter
+        - arg1 (list of dict): parameter
+        """
+        endpoint = "api/exportsPromptZIP"
+        return self._make_request(endpoint, method='POST', params=[arg0, arg1])
+
+    def exports_source(self, ):
+        """
+        Perform an action using the exportsSource API endpoint.
+
+        Parameters:
+
+        """
+        endpoint = "api/exportsSource"
+        return self._make_request(endpoint, method='POST', params=[])
+
+    def exports_source_fight_club_5e(self, arg0, arg1):
+        """
+        Perform an action using the exportsSourceFightClub5e API endpoint.
+
+        Parameters:
+        - arg0 (str): parameter
+        - arg1 (list of dict): parameter
+        """
+        endpoint = "api/exportsSourceFightClub5e"
+        return self._make_request(endpoint, method='POST', params=[arg0, arg1])
+
+    def exports_source_folder(self, arg0, arg1):
+        """
+        Perform an action using the exportsSourceFolder API endpoint.
+
+        Parameters:
+        - arg0 (str): parameter
+        - arg1 (list of dict): parameter
+        """
+        endpoint = "api/exportsSourceFolder"
+        return self._make_request(endpoint, method='POST', params=[arg0, arg1])
+
+    def exports_source_foundry_vtt(self, arg0, arg1):
+        """
+        Perform an action using the exportsSourceFoundryVTT API endpoint.
+
+        Parameters:
+        - arg0 (str): parameter
+        - arg1 (list of dict): parameter
+        """
+        endpoint = "api/exportsSourceFoundryVTT"
+        return self._make_request(endpoint, method='POST', params=[arg0, arg1])
+
+    def exports_source_native_zip(self, arg0, arg1):
+        """
+        Perform an action using the exportsSourceNativeZIP API endpoint.
+
+        Parameters:
+        - arg0 (str): parameter
+        - arg1 (list of dict): parameter
+        """
+        endpoint = "api/exportsSourceNativeZIP"
+        return self._make_request(endpoint, method='POST', params=[arg0, arg1])
+
+    def exports_source_xlsx(self, arg0, arg1):
+        """
+        Perform an action using the exportsSourceXLSX API endpoint.
+
+        Parameters:
+        - arg0 (str): parameter
+        - arg1 (list of dict): parameter
+        """
+        endpoint = "api/exportsSourceXLSX"
+        return self._make_request(endpoint, method='POST', params=[arg0, arg1])
+
+    def exports_source_zip(self, arg0, arg1):
+        """
+        Perform an action using the exportsSourceZIP API endpoint.
+
+        Parameters:
+        - arg0 (str): parameter
+        - arg1 (list of dict): parameter
+        """
+        endpoint = "api/exportsSourceZIP"
+        return self._make_request(endpoint, method='POST', params=[arg0, arg1])
+
+    def exports_template(self, ):
+        """
+        Perform an action using the exportsTemplate API endpoint.
+
+        Parameters:
+
+        """
+        endpoint = "api/exportsTemplate"
+        return self._make_request(endpoint, method='POST', params=[])
+
+    def exports_template_folder(self, arg0, arg1):
+        """
+        Perform an action using the exportsTemplateFolder API endpoint.
+
+        Parameters:
+        - arg0 (str): parameter
+        - arg1 (list of dict): parameter
+        """
+        endpoint = "api/exportsTemplateFolder"
+        return self._make_request(endpoint, method='POST', params=[arg0, arg1])
+
+    def exports_template_native_zip(self, arg0, arg1):
+        """
+        Perform an action using the exportsTemplateNativeZIP API endpoint.
+
+        Parameters:
+        - arg0 (str): parameter
+        - arg1 (list of dict): parameter
+        """
+        endpoint = "api/exportsTemplateNativeZIP"
+        return self._make_request(endpoint, method='POST', params=[arg0, arg1])
+
+    def exports_template_zip(self, arg0, arg1):
+        """
+        Perform an action using the exportsTemplateZIP API endpoint.
+
+        Parameters:
+        - arg0 (str): parameter
+        - arg1 (list of dict): parameter
+        """
+        endpoint = "api/exportsTemplateZIP"
+        return self._make_request(endpoint, method='POST', params=[arg0, arg1])
+
+    def fetch_image(self, arg0):
+        """
+        Perform an action using the fetchImage API endpoint.
+
+        Parameters:
+        - arg0 (str): parameter
+        """
+        endpoint = "api/fetchImage"
+        return self._make_request(endpoint, method='POST', params=[arg0])
+
+    def get_auth_config(self, ):
+        """
+        Perform an action using the getAuthConfig API endpoint.
+
+        Parameters:
+
+        """
+        endpoint = "api/getAuthConfig"
+        return self._make_request(endpoint, method='POST', params=[])
+
+    def get_available_printer(self, ):
+        """
+        Perform an action using the getAvailablePrinter API endpoint.
+
+        Parameters:
+
+        """
+        endpoint = "api/getAvailablePrinter"
+        return self._make_request(endpoint, method='POST', params=[])
+
+    def get_default_directories(self, ):
+        """
+        Perform an action using the getDefaultDirectories API endpoint.
+
+        Parameters:
+
+        """
+        endpoint = "api/getDefaultDirectories"
+        return self._make_request(endpoint, method='POST', params=[])
+
+    def get_entries(self, arg0):
+        """
+        Perform an action using the getEntries API endpoint.
+
+        Parameters:
+        - arg0 (str): parameter
+        """
+        endpoint = "api/getEntries"
+        return self._make_request(endpoint, method='POST', params=[arg0])
+
+    def get_entries_with_sources(self, arg0):
+        """
+        Perform an action using the getEntriesWithSources API endpoint.
+
+        Parameters:
+        - arg0 (str): parameter
+        """
+        endpoint = "api/getEntriesWithSources"
+        return self._make_request(endpoint, method='POST', params=[arg0])
+
+    def get_entry(self, arg0, arg1):
+        """
+        Perform an action using the getEntry API endpoint.
+
+        Parameters:
+        - arg0 (str): parameter
+        - arg1 (str): parameter
+        """
+        endpoint = "api/getEntry"
+        return self._make_request(endpoint, method='POST', params=[arg0, arg1])
+
+    def get_files(self, arg0, arg1, arg2):
+        """
+        Perform an action using the getFiles API endpoint.
+
+        Parameters:
+        - arg0 (str): parameter
+        - arg1 (list of str): parameter
+        - arg2 (bool): parameter
+        """
+        endpoint = "api/getFiles"
+        return self._make_request(endpoint, method='POST', params=[arg0, arg1, arg2])
+
+    def get_fonts(self, ):
+        """
+        Perform an action using the getFonts API endpoint.
+
+        Parameters:
+
+        """
+        endpoint = "api/getFonts"
+        return self._make_request(endpoint, method='POST', params=[])
+
+    def get_generator(self, arg0):
+        """
+        Perform an action using the getGenerator API endpoint.
+
+        Parameters:
+        - arg0 (str): parameter
+        """
+        endpoint = "api/getGenerator"
+        return self._make_request(endpoint, method='POST', params=[arg0])
+
+    def get_generators(self, ):
+        """
+        Perform an action using the getGenerators API endpoint.
+
+        Parameters:
+
+        """
+        endpoint = "api/getGenerators"
+        return self._make_request(endpoint, method='POST', params=[])
+
+    def get_import_mapping(self, arg0):
+        """
+        Perform an action using the getImportMapping API endpoint.
+
+        Parameters:
+        - arg0 (str): parameter
+        """
+        endpoint = "api/getImportMapping"
+        return self._make_request(endpoint, method='POST', params=[arg0])
+
+    def get_key(self, arg0):
+        """
+        Perform an action using the getKey API endpoint.
+
+        Parameters:
+        - arg0 (str): parameter
+        """
+        endpoint = "api/getKey"
+        return self._make_request(endpoint, method='POST', params=[arg0])
+
+    def get_keys_prefix(self, arg0):
+        """
+        Perform an action using the getKeysPrefix API endpoint.
+
+        Parameters:
+        - arg0 (str): parameter
+        """
+        endpoint = "api/getKeysPrefix"
+        return self._make_request(endpoint, method='POST', params=[arg0])
+
+    def get_local_url(self, arg0):
+        """
+        Perform an action using the getLocalURL API endpoint.
+
+        Parameters:
+        - arg0 (str): parameter
+        """
+        endpoint = "api/getLocalURL"
+        return self._make_request(endpoint, method='POST', params=[arg0])
+
+    def get_packages(self, arg0, arg1):
+        """
+        Perform an action using the getPackages API endpoint.
+
+        Parameters:
+        - arg0 (str): parameter
+        - arg1 (dict): parameter
+        """
+        endpoint = "api/getPackages"
+        return self._make_request(endpoint, method='POST', params=[arg0, arg1])
+
+    def get_printer(self, ):
+        """
+        Perform an action using the getPrinter API endpoint.
+
+        Parameters:
+
+        """
+        endpoint = "api/getPrinter"
+        return self._make_request(endpoint, method='POST', params=[])
+
+    def get_prompt(self, arg0):
+        """
+        Perform an action using the getPrompt API endpoint.
+
+        Parameters:
+        - arg0 (str): parameter
+        """
+        endpoint = "api/getPrompt"
+        return self._make_request(endpoint, method='POST', params=[arg0])
+
+    def get_prompts(self, ):
+        """
+        Perform an action using the getPrompts API endpoint.
+
+        Parameters:
+
+        """
+        endpoint = "api/getPrompts"
+        return self._make_request(endpoint, method='POST', params=[])
+
+    def get_public_packages(self, ):
+        """
+        Perform an action using the getPublicPackages API endpoint.
+
+        Parameters:
+
+        """
+        endpoint = "api/getPublicPackages"
+        return self._make_request(endpoint, method='POST', params=[])
+
+    def get_rendering_metrics(self, ):
+        """
+        Perform an action using the getRenderingMetrics API endpoint.
+
+        Parameters:
+
+        """
+        endpoint = "api/getRenderingMetrics"
+        return self._make_request(endpoint, method='POST', params=[])
+
+    def get_repo(self, arg0):
+        """
+        Perform an action using the getRepo API endpoint.
+
+        Parameters:
+        - arg0 (str): parameter
+        """
+        endpoint = "api/getRepo"
+        return self._make_request(endpoint, method='POST', params=[arg0])
+
+    def get_settings(self, ):
+        """
+        Perform an action using the getSettings API endpoint.
+
+        Parameters:
+
+        """
+        endpoint = "api/getSettings"
+        return self._make_request(endpoint, method='POST', params=[])
+
+    def get_source(self, arg0):
+        """
+        Perform an action using the getSource API endpoint.
+
+        Parameters:
+        - arg0 (str): parameter
+        """
+        endpoint = "api/getSource"
+        return self._make_request(endpoint, method='POST', params=[arg0])
+
+    def get_sources(self, ):
+        """
+        Perform an action using the getSources API endpoint.
+
+        Parameters:
+
+        """
+        endpoint = "api/getSources"
+        return self._make_request(endpoint, method='POST', params=[])
+
+    def get_template(self, arg0):
+        """
+        Perform an action using the getTemplate API endpoint.
+
+        Parameters:
+        - arg0 (str): parameter
+        """
+        endpoint = "api/getTemplate"
+        return self._make_request(endpoint, method='POST', params=[arg0])
+
+    def get_template_references(self, arg0):
+        """
+        Perform an action using the getTemplateReferences API endpoint.
+
+        Parameters:
+        - arg0 (str): parameter
+        """
+        endpoint = "api/getTemplateReferences"
+        return self._make_request(endpoint, method='POST', params=[arg0])
+
+    def get_templates(self, ):
+        """
+        Perform an action using the getTemplates API endpoint.
+
+        Parameters:
+
+        """
+        endpoint = "api/getTemplates"
+        return self._make_request(endpoint, method='POST', params=[])
+
+    def get_version(self, ):
+        """
+        Perform an action using the getVersion API endpoint.
+
+        Parameters:
+
+        """
+        endpoint = "api/getVersion"
+        return self._make_request(endpoint, method='POST', params=[])
+
+    def has_native_file_picker(self, ):
+        """
+        Perform an action using the hasNativeFilePicker API endpoint.
+
+        Parameters:
+
+        """
+        endpoint = "api/hasNativeFilePicker"
+        return self._make_request(endpoint, method='POST', params=[])
+
+    def import_generator_json(self, arg0):
+        """
+        Perform an action using the importGeneratorJSON API endpoint.
+
+        Parameters:
+        - arg0 (str): parameter
+        """
+        endpoint = "api/importGeneratorJSON"
+        return self._make_request(endpoint, method='POST', params=[arg0])
+
+    def import_generator_url(self, arg0):
+        """
+        Perform an action using the importGeneratorUrl API endpoint.
+
+        Parameters:
+        - arg0 (str): parameter
+        """
+        endpoint = "api/importGeneratorUrl"
+        return self._make_request(endpoint, method='POST', params=[arg0])
+
+    def import_package(self, arg0, arg1, arg2):
+        """
+        Perform an action using the importPackage API endpoint.
+
+        Parameters:
+        - arg0 (str): parameter
+        - arg1 (dict): parameter
+        - arg2 (str): parameter
+        """
+        endpoint = "api/importPackage"
+        return self._make_request(endpoint, method='POST', params=[arg0, arg1, arg2])
+
+    def import_prompt_json(self, arg0):
+        """
+        Perform an action using the importPromptJSON API endpoint.
+
+        Parameters:
+        - arg0 (str): parameter
+        """
+        endpoint = "api/importPromptJSON"
+        return self._make_request(endpoint, method='POST', params=[arg0])
+
+    def import_template_entries_xlsx(self, arg0, arg1, arg2):
+        """
+        Perform an action using the importTemplateEntriesXLSX API endpoint.
+
+        Parameters:
+        - arg0 (str): parameter
+        - arg1 (str): parameter
+        - arg2 (str): parameter
+        """
+        endpoint = "api/importTemplateEntriesXLSX"
+        return self._make_request(endpoint, method='POST', params=[arg0, arg1, arg2])
+
+    def imports_generator(self, ):
+        """
+        Perform an action using the importsGenerator API endpoint.
+
+        Parameters:
+
+        """
+        endpoint = "api/importsGenerator"
+        return self._make_request(endpoint, method='POST', params=[])
+
+    def imports_generator_folder(self, arg0):
+        """
+        Perform an action using the importsGeneratorFolder API endpoint.
+
+        Parameters:
+        - arg0 (list of dict): parameter
+        """
+        endpoint = "api/importsGeneratorFolder"
+        return self._make_request(endpoint, method='POST', params=[arg0])
+
+    def imports_generator_json(self, arg0):
+        """
+        Perform an action using the importsGeneratorJSON API endpoint.
+
+        Parameters:
+        - arg0 (list of dict): parameter
+        """
+        endpoint = "api/importsGeneratorJSON"
+        return self._make_request(endpoint, method='POST', params=[arg0])
+
+    def imports_generator_url(self, arg0):
+        """
+        Perform an action using the importsGeneratorURL API endpoint.
+
+        Parameters:
+        - arg0 (list of dict): parameter
+        """
+        endpoint = "api/importsGeneratorURL"
+        return self._make_request(endpoint, method='POST', params=[arg0])
+
+    def imports_generator_zip(self, arg0):
+        """
+        Perform an action using the importsGeneratorZIP API endpoint.
+
+        Parameters:
+        - arg0 (list of dict): parameter
+        """
+        endpoint = "api/importsGeneratorZIP"
+        return self._make_request(endpoint, method='POST', params=[arg0])
+
+    def imports_prompt(self, ):
+        """
+        Perform an action using the importsPrompt API endpoint.
+
+        Parameters:
+
+        """
+        endpoint = "api/importsPrompt"
+        return self._make_request(endpoint, method='POST', params=[])
+
+    def imports_prompt_folder(self, arg0):
+        """
+        Perform an action using the importsPromptFolder API endpoint.
+
+        Parameters:
+        - arg0 (list of dict): parameter
+        """
+        endpoint = "api/importsPromptFolder"
+        return self._make_request(endpoint, method='POST', params=[arg0])
+
+    def imports_prompt_json(self, arg0):
+        """
+        Perform an action using the importsPromptJSON API endpoint.
+
+        Parameters:
+        - arg0 (list of dict): parameter
+        """
+        endpoint = "api/importsPromptJSON"
+        return self._make_request(endpoint, method='POST', params=[arg0])
+
+    def imports_prompt_url(self, arg0):
+        """
+        Perform an action using the importsPromptURL API endpoint.
+
+        Parameters:
+        - arg0 (list of dict): parameter
+        """
+        endpoint = "api/importsPromptURL"
+        return self._make_request(endpoint, method='POST', params=[arg0])
+
+    def imports_prompt_zip(self, arg0):
+        """
+        Perform an action using the importsPromptZIP API endpoint.
+
+        Parameters:
+        - arg0 (list of dict): parameter
+        """
+        endpoint = "api/importsPromptZIP"
+        return self._make_request(endpoint, method='POST', params=[arg0])
+
+    def imports_source(self, ):
+        """
+        Perform an action using the importsSource API endpoint.
+
+        Parameters:
+
+        """
+        endpoint = "api/importsSource"
+        return self._make_request(endpoint, method='POST', params=[])
+
+    def imports_source_5e_tools_folder(self, arg0):
+        """
+        Perform an action using the importsSource5eToolsFolder API endpoint.
+
+        Parameters:
+        - arg0 (list of dict): parameter
+        """
+        endpoint = "api/importsSource5eToolsFolder"
+        return self._make_request(endpoint, method='POST', params=[arg0])
+
+    def imports_source_5e_tools_single_file(self, arg0):
+        """
+        Perform an action using the importsSource5eToolsSingleFile API endpoint.
+
+        Parameters:
+        - arg0 (list of dict): parameter
+        """
+        endpoint = "api/importsSource5eToolsSingleFile"
+        return self._make_request(endpoint, method='POST', params=[arg0])
+
+    def imports_source_csv(self, arg0):
+        """
+        Perform an action using the importsSourceCSV API endpoint.
+
+        Parameters:
+        - arg0 (list of dict): parameter
+        """
+        endpoint = "api/importsSourceCSV"
+        return self._make_request(endpoint, method='POST', params=[arg0])
+
+    def imports_source_fight_club_5e(self, arg0):
+        """
+        Perform an action using the importsSourceFightClub5e API endpoint.
+
+        Parameters:
+        - arg0 (list of dict): parameter
+        """
+        endpoint = "api/importsSourceFightClub5e"
+        return self._make_request(endpoint, method='POST', params=[arg0])
+
+    def imports_source_folder(self, arg0):
+        """
+        Perform an action using the importsSourceFolder API endpoint.
+
+        Parameters:
+        - arg0 (list of dict): parameter
+        """
+        endpoint = "api/importsSourceFolder"
+        return self._make_request(endpoint, method='POST', params=[arg0])
+
+    def imports_source_foundry_vtt(self, arg0):
+        """
+        Perform an action using the importsSourceFoundryVTT API endpoint.
+
+        Parameters:
+        - arg0 (list of dict): parameter
+        """
+        endpoint = "api/importsSourceFoundryVTT"
+        return self._make_request(endpoint, method='POST', params=[arg0])
+
+    def imports_source_json(self, arg0):
+        """
+        Perform an action using the importsSourceJSON API endpoint.
+
+        Parameters:
+        - arg0 (list of dict): parameter
+        """
+        endpoint = "api/importsSourceJSON"
+        return self._make_request(endpoint, method='POST', params=[arg0])
+
+    def imports_source_json_records(self, arg0):
+        """
+        Perform an action using the importsSourceJSONRecords API endpoint.
+
+        Parameters:
+        - arg0 (list of dict): parameter
+        """
+        endpoint = "api/importsSourceJSONRecords"
+        return self._make_request(endpoint, method='POST', params=[arg0])
+
+    def imports_source_markdown(self, arg0):
+        """
+        Perform an action using the importsSourceMarkdown API endpoint.
+
+        Parameters:
+        - arg0 (list of dict): parameter
+        """
+        endpoint = "api/importsSourceMarkdown"
+        return self._make_request(endpoint, method='POST', params=[arg0])
+
+    def imports_source_open_5e(self, arg0):
+        """
+        Perform an action using the importsSourceOpen5e API endpoint.
+
+        Parameters:
+        - arg0 (list of dict): parameter
+        """
+        endpoint = "api/importsSourceOpen5e"
+        return self._make_request(endpoint, method='POST', params=[arg0])
+
+    def imports_source_pf_2_e(self, arg0):
+        """
+        Perform an action using the importsSourcePF2e API endpoint.
+
+        Parameters:
+        - arg0 (list of dict): parameter
+        """
+        endpoint = "api/importsSourcePF2e"
+        return self._make_request(endpoint, method='POST', params=[arg0])
+
+    def imports_source_strategies(self, ):
+        """
+        Perform an action using the importsSourceStrategies API endpoint.
+
+        Parameters:
+
+        """
+        endpoint = "api/importsSourceStrategies"
+        return self._make_request(endpoint, method='POST', params=[])
+
+    def imports_source_xlsx(self, arg0):
+        """
+        Perform an action using the importsSourceXLSX API endpoint.
+
+        Parameters:
+        - arg0 (list of dict): parameter
+        """
+        endpoint = "api/importsSourceXLSX"
+        return self._make_request(endpoint, method='POST', params=[arg0])
+
+    def imports_source_zip(self, arg0):
+        """
+        Perform an action using the importsSourceZIP API endpoint.
+
+        Parameters:
+        - arg0 (list of dict): parameter
+        """
+        endpoint = "api/importsSourceZIP"
+        return self._make_request(endpoint, method='POST', params=[arg0])
+
+    def imports_template(self, ):
+        """
+        Perform an action using the importsTemplate API endpoint.
+
+        Parameters:
+
+        """
+        endpoint = "api/importsTemplate"
+        return self._make_request(endpoint, method='POST', params=[])
+
+    def imports_template_folder(self, arg0):
+        """
+        Perform an action using the importsTemplateFolder API endpoint.
+
+        Parameters:
+        - arg0 (list of dict): parameter
+        """
+        endpoint = "api/importsTemplateFolder"
+        return self._make_request(endpoint, method='POST', params=[arg0])
+
+    def imports_template_json(self, arg0):
+        """
+        Perform an action using the importsTemplateJSON API endpoint.
+
+        Parameters:
+        - arg0 (list of dict): parameter
+        """
+        endpoint = "api/importsTemplateJSON"
+        return self._make_request(endpoint, method='POST', params=[arg0])
+
+    def imports_template_url(self, arg0):
+        """
+        Perform an action using the importsTemplateURL API endpoint.
+
+        Parameters:
+        - arg0 (list of dict): parameter
+        """
+        endpoint = "api/importsTemplateURL"
+        return self._make_request(endpoint, method='POST', params=[arg0])
+
+    def imports_template_zip(self, arg0):
+        """
+        Perform an action using the importsTemplateZIP API endpoint.
+
+        Parameters:
+        - arg0 (list of dict): parameter
+        """
+        endpoint = "api/importsTemplateZIP"
+        return self._make_request(endpoint, method='POST', params=[arg0])
+
+    def lint_template(self, arg0, arg1):
+        """
+        Perform an action using the lintTemplate API endpoint.
+
+        Parameters:
+        - arg0 (str): parameter
+        - arg1 (rpc.LintOptions): parameter
+        """
+        endpoint = "api/lintTemplate"
+        return self._make_request(endpoint, method='POST', params=[arg0, arg1])
+
+    def merge_imports_source_5e_tools_folder(self, arg0, arg1):
+        """
+        Perform an action using the mergeImportsSource5eToolsFolder API endpoint.
+
+        Parameters:
+        - arg0 (list of dict): parameter
+        - arg1 (str): parameter
+        """
+        endpoint = "api/mergeImportsSource5eToolsFolder"
+        return self._make_request(endpoint, method='POST', params=[arg0, arg1])
+
+    def merge_imports_source_5e_tools_single_file(self, arg0, arg1):
+        """
+        Perform an action using the mergeImportsSource5eToolsSingleFile API endpoint.
+
+        Parameters:
+        - arg0 (list of dict): parameter
+        - arg1 (str): parameter
+        """
+        endpoint = "api/mergeImportsSource5eToolsSingleFile"
+        return self._make_request(endpoint, method='POST', params=[arg0, arg1])
+
+    def merge_imports_source_csv(self, arg0, arg1):
+        """
+        Perform an action using the mergeImportsSourceCSV API endpoint.
+
+        Parameters:
+        - arg0 (list of dict): parameter
+        - arg1 (str): parameter
+        """
+        endpoint = "api/mergeImportsSourceCSV"
+        return self._make_request(endpoint, method='POST', params=[arg0, arg1])
+
+    def merge_imports_source_fight_club_5e(self, arg0, arg1):
+        """
+        Perform an action using the mergeImportsSourceFightClub5e API endpoint.
+
+        Parameters:
+        - arg0 (list of dict): parameter
+        - arg1 (str): parameter
+        """
+        endpoint = "api/mergeImportsSourceFightClub5e"
+        return self._make_request(endpoint, method='POST', params=[arg0, arg1])
+
+    def merge_imports_source_folder(self, arg0, arg1):
+        """
+        Perform an action using the mergeImportsSourceFolder API endpoint.
+
+        Parameters:
+        - arg0 (list of dict): parameter
+        - arg1 (str): parameter
+        """
+        endpoint = "api/mergeImportsSourceFolder"
+        return self._make_request(endpoint, method='POST', params=[arg0, arg1])
+
+    def merge_imports_source_foundry_vtt(self, arg0, arg1):
+        """
+        Perform an action using the mergeImportsSourceFoundryVTT API endpoint.
+
+        Parameters:
+        - arg0 (list of dict): parameter
+        - arg1 (str): parameter
+        """
+        endpoint = "api/mergeImportsSourceFoundryVTT"
+        return self._make_request(endpoint, method='POST', params=[arg0, arg1])
+
+    def merge_imports_source_json(self, arg0, arg1):
+        """
+        Perform an action using the mergeImportsSourceJSON API endpoint.
+
+        Parameters:
+        - arg0 (list of dict): parameter
+        - arg1 (str): parameter
+        """
+        endpoint = "api/mergeImportsSourceJSON"
+        return self._make_request(endpoint, method='POST', params=[arg0, arg1])
+
+    def merge_imports_source_json_records(self, arg0, arg1):
+        """
+        Perform an action using the mergeImportsSourceJSONRecords API endpoint.
+
+        Parameters:
+        - arg0 (list of dict): parameter
+        - arg1 (str): parameter
+        """
+        endpoint = "api/mergeImportsSourceJSONRecords"
+        return self._make_request(endpoint, method='POST', params=[arg0, arg1])
+
+    def merge_imports_source_markdown(self, arg0, arg1):
+        """
+        Perform an action using the mergeImportsSourceMarkdown API endpoint.
+
+        Parameters:
+        - arg0 (list of dict): parameter
+        - arg1 (str): parameter
+        """
+        endpoint = "api/mergeImportsSourceMarkdown"
+        return self._make_request(endpoint, method='POST', params=[arg0, arg1])
+
+    def merge_imports_source_open_5e(self, arg0, arg1):
+        """
+        Perform an action using the mergeImportsSourceOpen5e API endpoint.
+
+        Parameters:
+        - arg0 (list of dict): parameter
+        - arg1 (str): parameter
+        """
+        endpoint = "api/mergeImportsSourceOpen5e"
+        return self._make_request(endpoint, method='POST', params=[arg0, arg1])
+
+    def merge_imports_source_pf_2_e(self, arg0, arg1):
+        """
+        Perform an action using the mergeImportsSourcePF2e API endpoint.
+
+        Parameters:
+        - arg0 (list of dict): parameter
+        - arg1 (str): parameter
+        """
+        endpoint = "api/mergeImportsSourcePF2e"
+        return self._make_request(endpoint, method='POST', params=[arg0, arg1])
+
+    def merge_imports_source_xlsx(self, arg0, arg1):
+        """
+        Perform an action using the mergeImportsSourceXLSX API endpoint.
+
+        Parameters:
+        - arg0 (list of dict): parameter
+        - arg1 (str): parameter
+        """
+        endpoint = "api/mergeImportsSourceXLSX"
+        return self._make_request(endpoint, method='POST', params=[arg0, arg1])
+
+    def merge_imports_source_zip(self, arg0, arg1):
+        """
+        Perform an action using the mergeImportsSourceZIP API endpoint.
+
+        Parameters:
+        - arg0 (list of dict): parameter
+        - arg1 (str): parameter
+        """
+        endpoint = "api/mergeImportsSourceZIP"
+        return self._make_request(endpoint, method='POST', params=[arg0, arg1])
+
+    def merge_imports_template_folder(self, arg0, arg1):
+        """
+        Perform an action using the mergeImportsTemplateFolder API endpoint.
+
+        Parameters:
+        - arg0 (list of dict): parameter
+        - arg1 (str): parameter
+        """
+        endpoint = "api/mergeImportsTemplateFolder"
+        return self._make_request(endpoint, method='POST', params=[arg0, arg1])
+
+    def merge_imports_template_json(self, arg0, arg1):
+        """
+        Perform an action using the mergeImportsTemplateJSON API endpoint.
+
+        Parameters:
+        - arg0 (list of dict): parameter
+        - arg1 (str): parameter
+        """
+        endpoint = "api/mergeImportsTemplateJSON"
+        return self._make_request(endpoint, method='POST', params=[arg0, arg1])
+
+    def merge_imports_template_url(self, arg0, arg1):
+        """
+        Perform an action using the mergeImportsTemplateURL API endpoint.
+
+        Parameters:
+        - arg0 (list of dict): parameter
+        - arg1 (str): parameter
+        """
+        endpoint = "api/mergeImportsTemplateURL"
+        return self._make_request(endpoint, method='POST', params=[arg0, arg1])
+
+    def merge_imports_template_zip(self, arg0, arg1):
+        """
+        Perform an action using the mergeImportsTemplateZIP API endpoint.
+
+        Parameters:
+        - arg0 (list of dict): parameter
+        - arg1 (str): parameter
+        """
+        endpoint = "api/mergeImportsTemplateZIP"
+        return self._make_request(endpoint, method='POST', params=[arg0, arg1])
+
+    def new_version(self, ):
+        """
+        Perform an action using the newVersion API endpoint.
+
+        Parameters:
+
+        """
+        endpoint = "api/newVersion"
+        return self._make_request(endpoint, method='POST', params=[])
+
+    def open_cash_drawer_1(self, ):
+        """
+        Perform an action using the openCashDrawer1 API endpoint.
+
+        Parameters:
+
+        """
+        endpoint = "api/openCashDrawer1"
+        return self._make_request(endpoint, method='POST', params=[])
+
+    def open_cash_drawer_2(self, ):
+        """
+        Perform an action using the openCashDrawer2 API endpoint.
+
+        Parameters:
+
+        """
+        endpoint = "api/openCashDrawer2"
+        return self._make_request(endpoint, method='POST', params=[])
+
+    def pick_file(self, arg0):
+        """
+        Perform an action using the pickFile API endpoint.
+
+        Parameters:
+        - arg0 (list of str): parameter
+        """
+        endpoint = "api/pickFile"
+        return self._make_request(endpoint, method='POST', params=[arg0])
+
+    def pick_folder(self, ):
+        """
+        Perform an action using the pickFolder API endpoint.
+
+        Parameters:
+
+        """
+        endpoint = "api/pickFolder"
+        return self._make_request(endpoint, method='POST', params=[])
+
+    def preview_cache(self, arg0, arg1):
+        """
+        Perform an action using the previewCache API endpoint.
+
+        Parameters:
+        - arg0 (str): parameter
+        - arg1 (str): parameter
+        """
+        endpoint = "api/previewCache"
+        return self._make_request(endpoint, method='POST', params=[arg0, arg1])
+
+    def preview_imports_source_5e_tools_folder(self, arg0):
+        """
+        Perform an action using the previewImportsSource5eToolsFolder API endpoint.
+
+        Parameters:
+        - arg0 (list of dict): parameter
+        """
+        endpoint = "api/previewImportsSource5eToolsFolder"
+        return self._make_request(endpoint, method='POST', params=[arg0])
+
+    def preview_imports_source_5e_tools_single_file(self, arg0):
+        """
+        Perform an action using the previewImportsSource5eToolsSingleFile API endpoint.
+
+        Parameters:
+        - arg0 (list of dict): parameter
+        """
+        endpoint = "api/previewImportsSource5eToolsSingleFile"
+        return self._make_request(endpoint, method='POST', params=[arg0])
+
+    def preview_imports_source_csv(self, arg0):
+        """
+        Perform an action using the previewImportsSourceCSV API endpoint.
+
+        Parameters:
+        - arg0 (list of dict): parameter
+        """
+        endpoint = "api/previewImportsSourceCSV"
+        return self._make_request(endpoint, method='POST', params=[arg0])
 
-    def delete_entry(self, arg0, arg1):
+    def preview_imports_source_fight_club_5e(self, arg0):
         """
-        Perform an action using the deleteEntry API endpoint.
+        Perform an action using the previewImportsSourceFightClub5e API endpoint.
 
         Parameters:
-        - arg0 (str): parameter
-        - arg1 (str): parameter
+        - arg0 (list of dict): parameter
         """
-        endpoint = "api/deleteEntry"
-        return self._make_request(endpoint, method='POST', params=[arg0, arg1])
+        endpoint = "api/previewImportsSourceFightClub5e"
+        return self._make_request(endpoint, method='POST', params=[arg0])
 
-    def imports_source_json(self, arg0):
+    def preview_imports_source_folder(self, arg0):
         """
-        Perform an action using the importsSourceJSON API endpoint.
+        Perform an action using the previewImportsSourceFolder API endpoint.
 
         Parameters:
         - arg0 (list of dict): parameter
         """
-        endpoint = "api/importsSourceJSON"
+        endpoint = "api/previewImportsSourceFolder"
         return self._make_request(endpoint, method='POST', params=[arg0])
 
-    def get_files(self, arg0, arg1, arg2):
+    def preview_imports_source_foundry_vtt(self, arg0):
         """
-        Perform an action using the getFiles API endpoint.
+        Perform an action using the previewImportsSourceFoundryVTT API endpoint.
 
         Parameters:
-        - arg0 (str): parameter
-        - arg1 (list of str): parameter
-        - arg2 (bool): parameter
+        - arg0 (list of dict): parameter
         """
-        endpoint = "api/getFiles"
-        return self._make_request(endpoint, method='POST', params=[arg0, arg1, arg2])
+        endpoint = "api/previewImportsSourceFoundryVTT"
+        return self._make_request(endpoint, method='POST', params=[arg0])
 
-    def new_version(self, ):
+    def preview_imports_source_json(self, arg0):
         """
-        Perform an action using the newVersion API endpoint.
+        Perform an action using the previewImportsSourceJSON API endpoint.
 
         Parameters:
-
+        - arg0 (list of dict): parameter
         """
-        endpoint = "api/newVersion"
-        return self._make_request(endpoint, method='POST', params=[])
+        endpoint = "api/previewImportsSourceJSON"
+        return self._make_request(endpoint, method='POST', params=[arg0])
 
-    def imports_generator_folder(self, arg0):
+    def preview_imports_source_json_records(self, arg0):
         """
-        Perform an action using the importsGeneratorFolder API endpoint.
+        Perform an action using the previewImportsSourceJSONRecords API endpoint.
 
         Parameters:
         - arg0 (list of dict): parameter
         """
-        endpoint = "api/importsGeneratorFolder"
+        endpoint = "api/previewImportsSourceJSONRecords"
         return self._make_request(endpoint, method='POST', params=[arg0])
 
-    def imports_generator_json(self, arg0):
+    def preview_imports_source_markdown(self, arg0):
         """
-        Perform an action using the importsGeneratorJSON API endpoint.
+        Perform an action using the previewImportsSourceMarkdown API endpoint.
 
         Parameters:
         - arg0 (list of dict): parameter
         """
-        endpoint = "api/importsGeneratorJSON"
+        endpoint = "api/previewImportsSourceMarkdown"
         return self._make_request(endpoint, method='POST', params=[arg0])
 
-    def delete_entries(self, arg0):
+    def preview_imports_source_open_5e(self, arg0):
         """
-        Perform an action using the deleteEntries API endpoint.
+        Perform an action using the previewImportsSourceOpen5e API endpoint.
 
         Parameters:
-        - arg0 (str): parameter
+        - arg0 (list of dict): parameter
         """
-        endpoint = "api/deleteEntries"
+        endpoint = "api/previewImportsSourceOpen5e"
         return self._make_request(endpoint, method='POST', params=[arg0])
 
-    def imports_source_foundry_vtt(self, arg0):
+    def preview_imports_source_pf_2_e(self, arg0):
         """
-        Perform an action using the importsSourceFoundryVTT API endpoint.
+        Perform an action using the previewImportsSourcePF2e API endpoint.
 
         Parameters:
         - arg0 (list of dict): parameter
         """
-        endpoint = "api/importsSourceFoundryVTT"
+        endpoint = "api/previewImportsSourcePF2e"
         return self._make_request(endpoint, method='POST', params=[arg0])
 
-    def get_packages(self, arg0, arg1):
+    def preview_imports_source_xlsx(self, arg0):
         """
-        Perform an action using the getPackages API endpoint.
+        Perform an action using the previewImportsSourceXLSX API endpoint.
 
         Parameters:
-        - arg0 (str): parameter
-        - arg1 (dict): parameter
+        - arg0 (list of dict): parameter
         """
-        endpoint = "api/getPackages"
-        return self._make_request(endpoint, method='POST', params=[arg0, arg1])
+        endpoint = "api/previewImportsSourceXLSX"
+        return self._make_request(endpoint, method='POST', params=[arg0])
 
-    def import_package(self, arg0, arg1, arg2):
+    def preview_imports_source_zip(self, arg0):
         """
-        Perform an action using the importPackage API endpoint.
+        Perform an action using the previewImportsSourceZIP API endpoint.
 
         Parameters:
-        - arg0 (str): parameter
-        - arg1 (dict): parameter
-        - arg2 (str): parameter
+        - arg0 (list of dict): parameter
         """
-        endpoint = "api/importPackage"
-        return self._make_request(endpoint, method='POST', params=[arg0, arg1, arg2])
+        endpoint = "api/previewImportsSourceZIP"
+        return self._make_request(endpoint, method='POST', params=[arg0])
 
-    def imports_template_folder(self, arg0):
+    def preview_imports_template_folder(self, arg0):
         """
-        Perform an action using the importsTemplateFolder API endpoint.
+        Perform an action using the previewImportsTemplateFolder API endpoint.
 
         Parameters:
         - arg0 (list of dict): parameter
         """
-        endpoint = "api/importsTemplateFolder"
+        endpoint = "api/previewImportsTemplateFolder"
         return self._make_request(endpoint, method='POST', params=[arg0])
 
-    def get_entries_with_sources(self, arg0):
+    def preview_imports_template_json(self, arg0):
         """
-        Perform an action using the getEntriesWithSources API endpoint.
+        Perform an action using the previewImportsTemplateJSON API endpoint.
 
         Parameters:
-        - arg0 (str): parameter
+        - arg0 (list of dict): parameter
         """
-        endpoint = "api/getEntriesWithSources"
+        endpoint = "api/previewImportsTemplateJSON"
         return self._make_request(endpoint, method='POST', params=[arg0])
 
-    def ai_prompt(self, arg0, arg1, arg2):
+    def preview_imports_template_url(self, arg0):
         """
-        Perform an action using the aiPrompt API endpoint.
+        Perform an action using the previewImportsTemplateURL API endpoint.
 
         Parameters:
-        - arg0 (str): parameter
-        - arg1 (str): parameter
-        - arg2 (str): parameter
+        - arg0 (list of dict): parameter
         """
-        endpoint = "api/aiPrompt"
-        return self._make_request(endpoint, method='POST', params=[arg0, arg1, arg2])
+        endpoint = "api/previewImportsTemplateURL"
+        return self._make_request(endpoint, method='POST', params=[arg0])
 
-    def get_default_directories(self, ):
+    def preview_imports_template_zip(self, arg0):
         """
-        Perform an action using the getDefaultDirectories API endpoint.
+        Perform an action using the previewImportsTemplateZIP API endpoint.
 
         Parameters:
-
+        - arg0 (list of dict): parameter
         """
-        endpoint = "api/getDefaultDirectories"
-        return self._make_request(endpoint, method='POST', params=[])
+        endpoint = "api/previewImportsTemplateZIP"
+        return self._make_request(endpoint, method='POST', params=[arg0])
 
-    def imports_template_zip(self, arg0):
+    def print(self, arg0):
         """
-        Perform an action using the importsTemplateZIP API endpoint.
+        Perform an action using the print API endpoint.
 
         Parameters:
-        - arg0 (list of dict): parameter
+        - arg0 (str): parameter
         """
-        endpoint = "api/importsTemplateZIP"
+        endpoint = "api/print"
         return self._make_request(endpoint, method='POST', params=[arg0])
 
-    def exports_generator(self, ):
+    def print_generator(self, arg0, arg1):
         """
-        Perform an action using the exportsGenerator API endpoint.
+        Perform an action using the printGenerator API endpoint.
 
         Parameters:
-
+        - arg0 (str): parameter
+        - arg1 (dict): parameter
         """
-        endpoint = "api/exportsGenerator"
-        return self._make_request(endpoint, method='POST', params=[])
+        endpoint = "api/printGenerator"
+        return self._make_request(endpoint, method='POST', params=[arg0, arg1])
 
-    def exports_generator_folder(self, arg0, arg1):
+    def print_template(self, arg0, arg1, arg2):
         """
-        Perform an action using the exportsGeneratorFolder API endpoint.
+        Perform an action using the printTemplate API endpoint.
 
         Parameters:
         - arg0 (str): parameter
-        - arg1 (list of dict): parameter
+        - arg1 (dict [snd type entry]): parameter
+        - arg2 (dict): parameter
         """
-        endpoint = "api/exportsGeneratorFolder"
-        return self._make_request(endpoint, method='POST', params=[arg0, arg1])
+        endpoint = "api/printTemplate"
+        return self._make_request(endpoint, method='POST', params=[arg0, arg1, arg2])
 
-    def get_entry(self, arg0, arg1):
+    def print_template_entry(self, arg0, arg1, arg2):
         """
-        Perform an action using the getEntry API endpoint.
+        Perform an action using the printTemplateEntry API endpoint.
 
         Parameters:
         - arg0 (str): parameter
         - arg1 (str): parameter
+        - arg2 (dict): parameter
         """
-        endpoint = "api/getEntry"
-        return self._make_request(endpoint, method='POST', params=[arg0, arg1])
+        endpoint = "api/printTemplateEntry"
+        return self._make_request(endpoint, method='POST', params=[arg0, arg1, arg2])
 
-    def print(self, arg0):
+    def remove_font(self, arg0):
         """
-        Perform an action using the print API endpoint.
+        Perform an action using the removeFont API endpoint.
 
         Parameters:
         - arg0 (str): parameter
         """
-        endpoint = "api/print"
+        endpoint = "api/removeFont"
         return self._make_request(endpoint, method='POST', params=[arg0])
 
-    def sync_stop(self, arg0):
+    def render_template_entry(self, arg0, arg1, arg2):
         """
-        Perform an action using the syncStop API endpoint.
+        Perform an action using the renderTemplateEntry API endpoint.
 
         Parameters:
         - arg0 (str): parameter
+        - arg1 (str): parameter
+        - arg2 (dict): parameter
         """
-        endpoint = "api/syncStop"
-        return self._make_request(endpoint, method='POST', params=[arg0])
+        endpoint = "api/renderTemplateEntry"
+        return self._make_request(endpoint, method='POST', params=[arg0, arg1, arg2])
 
-    def get_public_packages(self, ):
+    def save_auth_user(self, arg0, arg1, arg2):
         """
-        Perform an action using the getPublicPackages API endpoint.
+        Perform an action using the saveAuthUser API endpoint.
 
         Parameters:
-
+        - arg0 (str): parameter
+        - arg1 (str): parameter
+        - arg2 (str): parameter
         """
-        endpoint = "api/getPublicPackages"
-        return self._make_request(endpoint, method='POST', params=[])
+        endpoint = "api/saveAuthUser"
+        return self._make_request(endpoint, method='POST', params=[arg0, arg1, arg2])
 
-    def delete_generator(self, arg0):
+    def save_entry(self, arg0, arg1):
         """
-        Perform an action using the deleteGenerator API endpoint.
+        Perform an action using the saveEntry API endpoint.
 
         Parameters:
         - arg0 (str): parameter
+        - arg1 (dict [snd type entry]): parameter
         """
-        endpoint = "api/deleteGenerator"
-        return self._make_request(endpoint, method='POST', params=[arg0])
+        endpoint = "api/saveEntry"
+        return self._make_request(endpoint, method='POST', params=[arg0, arg1])
 
-    def import_generator_url(self, arg0):
+    def save_file_native(self, arg0, arg1, arg2):
         """
-        Perform an action using the importGeneratorUrl API endpoint.
+        Perform an action using the saveFileNative API endpoint.
 
         Parameters:
         - arg0 (str): parameter
+        - arg1 (str): parameter
+        - arg2 (list of uint8): parameter
         """
-        endpoint = "api/importGeneratorUrl"
-        return self._make_request(endpoint, method='POST', params=[arg0])
+        endpoint = "api/saveFileNative"
+        return self._make_request(endpoint, method='POST', params=[arg0, arg1, arg2])
 
-    def get_entries(self, arg0):
+    def save_generator(self, arg0):
         """
-        Perform an action using the getEntries API endpoint.
+        Perform an action using the saveGenerator API endpoint.
 
         Parameters:
-        - arg0 (str): parameter
+        - arg0 (dict [snd type generator]): parameter
         """
-        endpoint = "api/getEntries"
+        endpoint = "api/saveGenerator"
         return self._make_request(endpoint, method='POST', params=[arg0])
 
-    def exports_source(self, ):
+    def save_import_mapping(self, arg0, arg1):
         """
-        Perform an action using the exportsSource API endpoint.
+        Perform an action using the saveImportMapping API endpoint.
 
         Parameters:
+        - arg0 (str): parameter
+        - arg1 (imexport.Mapping): parameter
+        """
+        endpoint = "api/saveImportMapping"
+        return self._make_request(endpoint, method='POST', params=[arg0, arg1])
 
+    def save_prompt(self, arg0):
         """
-        endpoint = "api/exportsSource"
-        return self._make_request(endpoint, method='POST', params=[])
+        Perform an action using the savePrompt API endpoint.
 
-    def imports_source_fight_club_5e(self, arg0):
+        Parameters:
+        - arg0 (dict [snd type prompt]): parameter
         """
-        Perform an action using the importsSourceFightClub5e API endpoint.
+        endpoint = "api/savePrompt"
+        return self._make_request(endpoint, method='POST', params=[arg0])
+
+    def save_settings(self, arg0):
+        """
+        Perform an action using the saveSettings API endpoint.
 
         Parameters:
-        - arg0 (list of dict): parameter
+        - arg0 (dict [snd type settings]): parameter
         """
-        endpoint = "api/importsSourceFightClub5e"
+        endpoint = "api/saveSettings"
         return self._make_request(endpoint, method='POST', params=[arg0])
 
-    def sync_active(self, arg0):
+    def save_source(self, arg0):
         """
-        Perform an action using the syncActive API endpoint.
+        Perform an action using the saveSource API endpoint.
 
         Parameters:
-        - arg0 (str): parameter
+        - arg0 (dict [snd type data_source]): parameter
         """
-        endpoint = "api/syncActive"
+        endpoint = "api/saveSource"
         return self._make_request(endpoint, method='POST', params=[arg0])
 
-    def get_source(self, arg0):
+    def save_template(self, arg0):
         """
-        Perform an action using the getSource API endpoint.
+        Perform an action using the saveTemplate API endpoint.
 
         Parameters:
-        - arg0 (str): parameter
+        - arg0 (dict [snd type template]): parameter
         """
-        endpoint = "api/getSource"
+        endpoint = "api/saveTemplate"
         return self._make_request(endpoint, method='POST', params=[arg0])
 
     def screenshot(self, arg0, arg1):
@@ -747,35 +1936,67 @@ class SndAPI:
         endpoint = "api/screenshot"
         return self._make_request(endpoint, method='POST', params=[arg0, arg1])
 
-    def preview_cache(self, arg0, arg1):
+    def screenshot_native(self, arg0, arg1):
         """
-        Perform an action using the previewCache API endpoint.
+        Perform an action using the screenshotNative API endpoint.
 
         Parameters:
         - arg0 (str): parameter
         - arg1 (str): parameter
         """
-        endpoint = "api/previewCache"
+        endpoint = "api/screenshotNative"
         return self._make_request(endpoint, method='POST', params=[arg0, arg1])
 
-    def imports_template_url(self, arg0):
+    def set_auth_enabled(self, arg0):
         """
-        Perform an action using the importsTemplateURL API endpoint.
+        Perform an action using the setAuthEnabled API endpoint.
 
         Parameters:
-        - arg0 (list of dict): parameter
+        - arg0 (bool): parameter
         """
-        endpoint = "api/importsTemplateURL"
+        endpoint = "api/setAuthEnabled"
         return self._make_request(endpoint, method='POST', params=[arg0])
 
-    def get_generator(self, arg0):
+    def set_auth_permissions(self, arg0, arg1):
         """
-        Perform an action using the getGenerator API endpoint.
+        Perform an action using the setAuthPermissions API endpoint.
 
         Parameters:
         - arg0 (str): parameter
+        - arg1 (dict): parameter
         """
-        endpoint = "api/getGenerator"
+        endpoint = "api/setAuthPermissions"
+        return self._make_request(endpoint, method='POST', params=[arg0, arg1])
+
+    def set_auth_trust_local(self, arg0):
+        """
+        Perform an action using the setAuthTrustLocal API endpoint.
+
+        Parameters:
+        - arg0 (bool): parameter
+        """
+        endpoint = "api/setAuthTrustLocal"
+        return self._make_request(endpoint, method='POST', params=[arg0])
+
+    def set_key(self, arg0, arg1):
+        """
+        Perform an action using the setKey API endpoint.
+
+        Parameters:
+        - arg0 (str): parameter
+        - arg1 (str): parameter
+        """
+        endpoint = "api/setKey"
+        return self._make_request(endpoint, method='POST', params=[arg0, arg1])
+
+    def sync_active(self, arg0):
+        """
+        Perform an action using the syncActive API endpoint.
+
+        Parameters:
+        - arg0 (str): parameter
+        """
+        endpoint = "api/syncActive"
         return self._make_request(endpoint, method='POST', params=[arg0])
 
     def sync_cloud_to_local(self, ):
@@ -788,33 +2009,55 @@ class SndAPI:
         endpoint = "api/syncCloudToLocal"
         return self._make_request(endpoint, method='POST', params=[])
 
-    def exports_template_zip(self, arg0, arg1):
+    def sync_local_to_cloud(self, ):
         """
-        Perform an action using the exportsTemplateZIP API endpoint.
+        Perform an action using the syncLocalToCloud API endpoint.
+
+        Parameters:
+
+        """
+        endpoint = "api/syncLocalToCloud"
+        return self._make_request(endpoint, method='POST', params=[])
+
+    def sync_start(self, arg0, arg1):
+        """
+        Perform an action using the syncStart API endpoint.
 
         Parameters:
         - arg0 (str): parameter
-        - arg1 (list of dict): parameter
+        - arg1 (str): parameter
         """
-        endpoint = "api/exportsTemplateZIP"
+        endpoint = "api/syncStart"
         return self._make_request(endpoint, method='POST', params=[arg0, arg1])
 
-    def imports_source_folder(self, arg0):
+    def sync_stop(self, arg0):
         """
-        Perform an action using the importsSourceFolder API endpoint.
+        Perform an action using the syncStop API endpoint.
 
         Parameters:
-        - arg0 (list of dict): parameter
+        - arg0 (str): parameter
         """
-        endpoint = "api/importsSourceFolder"
+        endpoint = "api/syncStop"
         return self._make_request(endpoint, method='POST', params=[arg0])
 
-    def get_repo(self, arg0):
+    def test_import_mapping(self, arg0, arg1):
         """
-        Perform an action using the getRepo API endpoint.
+        Perform an action using the testImportMapping API endpoint.
+
+        Parameters:
+        - arg0 (imexport.Mapping): parameter
+        - arg1 (list of dict [snd type entry]): parameter
+        """
+        endpoint = "api/testImportMapping"
+        return self._make_request(endpoint, method='POST', params=[arg0, arg1])
+
+    def test_template_references(self, arg0, arg1):
+        """
+        Perform an action using the testTemplateReferences API endpoint.
 
         Parameters:
         - arg0 (str): parameter
+        - arg1 (rpc.GoldenOptions): parameter
         """
-        endpoint = "api/getRepo"
-        return self._make_request(endpoint, method='POST', params=[arg0])
\ No newline at end of file
+        endpoint = "api/testTemplateReferences"
+        return self._make_request(endpoint, method='POST', params=[arg0, arg1])
\ No newline at end of file
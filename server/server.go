@@ -3,9 +3,10 @@ package server
 
 import (
 	"context"
-	"fmt"
+	"crypto/tls"
 	"io/ioutil"
 	"mime"
+	"net"
 	"net/http"
 	"net/url"
 	"path/filepath"
@@ -13,16 +14,20 @@ import (
 	"sync"
 	"time"
 
+	"github.com/BigJk/snd/auth"
 	"github.com/BigJk/snd/rpc/bind"
 
 	"github.com/BigJk/snd/database"
+	"github.com/BigJk/snd/fonts"
 	"github.com/labstack/echo/v4/middleware"
 	"github.com/patrickmn/go-cache"
 	"gopkg.in/olahol/melody.v1"
 
 	"github.com/BigJk/snd"
+	"github.com/BigJk/snd/local"
 	"github.com/BigJk/snd/log"
 	"github.com/BigJk/snd/printing"
+	"github.com/BigJk/snd/rendering"
 	"github.com/BigJk/snd/rpc"
 
 	"github.com/labstack/echo/v4"
@@ -59,6 +64,8 @@ type Server struct {
 	filePicker       rpc.FilePicker
 	defaultSettings  snd.Settings
 	additionalRoutes []func(e *echo.Group)
+	routes           *auth.Routes
+	tls              *TLSConfig
 }
 
 // New creates a new instance of the S&D server.
@@ -69,6 +76,7 @@ func New(db database.Database, options ...Option) (*Server, error) {
 		m:        melody.New(),
 		cache:    cache.New(time.Minute*10, time.Minute),
 		printers: map[string]printing.Printer{},
+		routes:   auth.NewRoutes(),
 	}
 
 	for i := range options {
@@ -181,6 +189,39 @@ func (s *Server) ensureSettings() error {
 	return nil
 }
 
+// accessOverrides are the routes whose access doesn't follow from auth.IsRead. The value is true
+// if the route needs the write access to its group.
+var accessOverrides = map[string]bool{
+	// Only reads from the AI cache, which is needed to render templates without running AI
+	http.MethodPost + " /api/aiCached": false,
+	// The proxy requests any url from the server, so users that only read can't use it as relay
+	http.MethodGet + " /proxy/*": true,
+	http.MethodGet + " /fetch/*": true,
+}
+
+// protect sets the permission group of all routes that are registered by the function. Reads need
+// the read access to the group and all other routes the write access.
+func (s *Server) protect(group auth.Group, register func()) {
+	known := map[string]bool{}
+	for _, route := range s.e.Routes() {
+		known[route.Method+" "+route.Path] = true
+	}
+
+	register()
+
+	for _, route := range s.e.Routes() {
+		if known[route.Method+" "+route.Path] {
+			continue
+		}
+
+		write, ok := accessOverrides[route.Method+" "+route.Path]
+		if !ok {
+			write = !auth.IsRead(route.Method, route.Path)
+		}
+		s.routes.Set(route.Method, route.Path, auth.Route{Group: group, Write: write})
+	}
+}
+
 // Start starts the server with the given bind address.
 //
 // Examples:
@@ -191,125 +232,210 @@ func (s *Server) Start(bindAddr string) error {
 		return err
 	}
 
-	// Register rpc routes
-	api := s.e.Group("/api")
-	extern := api.Group("/extern")
+	// The font library lives in the data dir
+	fonts.SetDir(filepath.Join(s.dataDir, "fonts"))
 
-	for i := range s.additionalRoutes {
-		s.additionalRoutes[i](api)
+	// Users, tokens and permissions are stored next to the database
+	authFile := ""
+	if len(s.dataDir) > 0 {
+		authFile = filepath.Join(s.dataDir, "auth.json")
 	}
 
-	api.GET("/dataDir", func(c echo.Context) error {
-		absDir, err := filepath.Abs(s.dataDir)
+	authStore, err := auth.Open(authFile)
+	if err != nil {
+		return err
+	}
+
+	// The rendering browser loads the templates from this server, so it has to be authenticated
+	rendering.SetLocalCookie(auth.CookieName, auth.RendererToken())
+
+	_, port, err := net.SplitHostPort(bindAddr)
+	if err != nil {
+		return err
+	}
+
+	// Other packages reach this server through the local urls
+	var cert tls.Certificate
+	var certFile, keyFile string
+	if s.tls != nil {
+		certFile, keyFile, err = s.certificate()
 		if err != nil {
-			return c.JSON(http.StatusInternalServerError, err.Error())
+			return err
 		}
-		return c.JSON(http.StatusOK, absDir)
-	})
 
-	rpc.RegisterVersion(api)
-	rpc.RegisterKeyValue(api, s.db)
-	rpc.RegisterImageUtilities(api, s.db)
-	rpc.RegisterSettings(api, s.db)
-	rpc.RegisterTemplate(api, extern, s.db, s.filePicker)
-	rpc.RegisterGenerator(api, extern, s.db, s.filePicker)
-	rpc.RegisterEntry(api, s.db)
-	rpc.RegisterSources(api, s.db, s.filePicker)
-	rpc.RegisterPrint(api, extern, s.db, s.printers, s.filePicker)
-	rpc.RegisterPrintCommand(api, s.db, s.printers)
-	rpc.RegisterSync(api, s.m, s.db)
-	rpc.RegisterGit(api, s.db)
-	rpc.RegisterCloud(api, s.db)
-	rpc.RegisterAI(api, s.db)
-	rpc.RegisterFileBrowser(api, s.filePicker)
-	rpc.RegisterMisc(api)
-
-	// Expose function list
-	api.GET("/functions", func(c echo.Context) error {
-		funcs := bind.Functions()
-		resp := make(map[string]any)
-		addr := bindAddr
-		if addr[0] == ':' {
-			addr = "127.0.0.1" + addr
+		cert, err = tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return err
 		}
 
-		for _, f := range funcs {
-			resp[f.Name] = map[string]any{
-				"route":  fmt.Sprintf("https://%s/api/%s", addr, f.Name),
-				"args":   f.Args,
-				"method": "POST",
-			}
+		local.Set("https", port, cert.Certificate[0])
+
+		// The rendering browser has to trust the certificate, as it might be self-signed
+		spki, err := local.SPKIHash()
+		if err != nil {
+			return err
 		}
+		rendering.TrustCertificate(spki)
+	} else {
+		local.Set("http", port, nil)
+	}
 
-		return c.JSONPretty(http.StatusOK, resp, "  ")
-	})
+	// Register rpc routes
+	api := s.e.Group("/api")
+	extern := api.Group("/extern")
 
-	// Register proxy route so that the iframes that are used
-	// in the frontend can proxy images and other data that they
-	// otherwise couldn't access because of CORB
-	s.e.GET("/proxy/*", func(c echo.Context) error {
-		reqUrl := c.Request().RequestURI[len("/index/"):]
-		if !strings.HasPrefix(reqUrl, "http") {
-			return c.NoContent(http.StatusBadRequest)
+	s.protect(auth.GroupSystem, func() {
+		for i := range s.additionalRoutes {
+			s.additionalRoutes[i](api)
 		}
+	})
 
-		hit, ok := s.cache.Get(reqUrl)
-		if ok {
-			log.Info("proxy url from cache", log.WithValue("url", reqUrl))
+	s.protect(auth.GroupSettings, func() {
+		api.GET("/dataDir", func(c echo.Context) error {
+			absDir, err := filepath.Abs(s.dataDir)
+			if err != nil {
+				return c.JSON(http.StatusInternalServerError, err.Error())
+			}
+			return c.JSON(http.StatusOK, absDir)
+		})
 
-			entry := hit.(*proxyCacheEntry)
-			return c.Blob(http.StatusOK, entry.ContentType, entry.Data)
-		}
+		rpc.RegisterSettings(api, s.db)
+	})
 
-		log.Info("proxy url", log.WithValue("url", reqUrl))
+	s.protect(auth.GroupPublic, func() {
+		rpc.RegisterLogin(api, authStore)
+	})
 
-		resp, err := http.Get(reqUrl)
-		if err != nil {
-			return c.NoContent(http.StatusBadRequest)
-		}
-		defer resp.Body.Close()
+	s.protect(auth.GroupAuth, func() {
+		rpc.RegisterAuth(api, authStore)
+	})
 
-		data, err := ioutil.ReadAll(resp.Body)
-		if err != nil {
-			return c.NoContent(http.StatusBadRequest)
-		}
+	s.protect(auth.GroupContent, func() {
+		rpc.RegisterVersion(api)
+		rpc.RegisterKeyValue(api, s.db)
+		rpc.RegisterTemplate(api, extern, s.db, s.filePicker)
+		rpc.RegisterGenerator(api, extern, s.db, s.filePicker)
+		rpc.RegisterPrompt(api, s.db, s.filePicker)
+		rpc.RegisterEntry(api, s.db)
+		rpc.RegisterSources(api, s.db, s.filePicker)
+		rpc.RegisterLint(api, s.db)
+		rpc.RegisterGolden(api, s.db)
+		rpc.RegisterFonts(api)
+		rpc.RegisterMisc(api)
+	})
 
-		if resp.StatusCode == http.StatusOK {
-			s.cache.SetDefault(reqUrl, &proxyCacheEntry{
-				ContentType: resp.Header.Get("Content-Type"),
-				Data:        data,
-			})
+	s.protect(auth.GroupPrint, func() {
+		rpc.RegisterImageUtilities(api, s.db)
+		rpc.RegisterPrint(api, extern, s.db, s.printers, s.filePicker)
+		rpc.RegisterPrintCommand(api, s.db, s.printers)
+	})
+
+	s.protect(auth.GroupSystem, func() {
+		rpc.RegisterSync(api, s.m, s.db)
+		if len(s.dataDir) > 0 {
+			rpc.RegisterHotFolder(api, s.m, s.db, filepath.Join(s.dataDir, "import"))
 		}
+		rpc.RegisterGit(api, s.db)
+		rpc.RegisterCloud(api, s.db)
+		rpc.RegisterFileBrowser(api, s.filePicker)
+	})
 
-		return c.Blob(resp.StatusCode, resp.Header.Get("Content-Type"), data)
+	s.protect(auth.GroupAI, func() {
+		rpc.RegisterAI(api, s.db)
 	})
 
-	// Same as proxy route but without caching. Can be used for
-	// dynamic API requests.
-	s.e.GET("/fetch/*", func(c echo.Context) error {
-		reqUrl := c.Request().RequestURI[len("/fetch/"):]
-		if !strings.HasPrefix(reqUrl, "http") {
-			return c.NoContent(http.StatusBadRequest)
-		}
+	s.protect(auth.GroupContent, func() {
+		// Expose function list
+		api.GET("/functions", func(c echo.Context) error {
+			funcs := bind.Functions()
+			resp := make(map[string]any)
+			host, _, _ := net.SplitHostPort(bindAddr)
+			if len(host) == 0 {
+				host = "127.0.0.1"
+			}
 
-		log.Info("proxy url fetch", log.WithValue("url", reqUrl))
+			for _, f := range funcs {
+				resp[f.Name] = map[string]any{
+					"route":  local.HostURL(host, "/api/"+f.Name),
+					"args":   f.Args,
+					"method": "POST",
+				}
+			}
 
-		resp, err := http.Get(reqUrl)
-		if err != nil {
-			return c.JSON(http.StatusBadRequest, err.Error())
-		}
-		defer resp.Body.Close()
+			return c.JSONPretty(http.StatusOK, resp, "  ")
+		})
+	})
 
-		data, err := ioutil.ReadAll(resp.Body)
-		if err != nil {
-			return c.JSON(http.StatusBadRequest, err.Error())
-		}
+	s.protect(auth.GroupPrint, func() {
+		// Register proxy route so that the iframes that are used
+		// in the frontend can proxy images and other data that they
+		// otherwise couldn't access because of CORB
+		s.e.GET("/proxy/*", func(c echo.Context) error {
+			reqUrl := c.Request().RequestURI[len("/index/"):]
+			if !strings.HasPrefix(reqUrl, "http") {
+				return c.NoContent(http.StatusBadRequest)
+			}
+
+			hit, ok := s.cache.Get(reqUrl)
+			if ok {
+				log.Info("proxy url from cache", log.WithValue("url", reqUrl))
+
+				entry := hit.(*proxyCacheEntry)
+				return c.Blob(http.StatusOK, entry.ContentType, entry.Data)
+			}
+
+			log.Info("proxy url", log.WithValue("url", reqUrl))
 
-		return c.Blob(resp.StatusCode, resp.Header.Get("Content-Type"), data)
+			resp, err := http.Get(reqUrl)
+			if err != nil {
+				return c.NoContent(http.StatusBadRequest)
+			}
+			defer resp.Body.Close()
+
+			data, err := ioutil.ReadAll(resp.Body)
+			if err != nil {
+				return c.NoContent(http.StatusBadRequest)
+			}
+
+			if resp.StatusCode == http.StatusOK {
+				s.cache.SetDefault(reqUrl, &proxyCacheEntry{
+					ContentType: resp.Header.Get("Content-Type"),
+					Data:        data,
+				})
+			}
+
+			return c.Blob(resp.StatusCode, resp.Header.Get("Content-Type"), data)
+		})
+
+		// Same as proxy route but without caching. Can be used for
+		// dynamic API requests.
+		s.e.GET("/fetch/*", func(c echo.Context) error {
+			reqUrl := c.Request().RequestURI[len("/fetch/"):]
+			if !strings.HasPrefix(reqUrl, "http") {
+				return c.NoContent(http.StatusBadRequest)
+			}
+
+			log.Info("proxy url fetch", log.WithValue("url", reqUrl))
+
+			resp, err := http.Get(reqUrl)
+			if err != nil {
+				return c.JSON(http.StatusBadRequest, err.Error())
+			}
+			defer resp.Body.Close()
+
+			data, err := ioutil.ReadAll(resp.Body)
+			if err != nil {
+				return c.JSON(http.StatusBadRequest, err.Error())
+			}
+
+			return c.Blob(resp.StatusCode, resp.Header.Get("Content-Type"), data)
+		})
 	})
 
-	api.GET("/ws", func(c echo.Context) error {
-		return s.m.HandleRequest(c.Response().Writer, c.Request())
+	s.protect(auth.GroupContent, func() {
+		api.GET("/ws", func(c echo.Context) error {
+			return s.m.HandleRequest(c.Response().Writer, c.Request())
+		})
 	})
 
 	// Make frontend and static directory public
@@ -326,10 +452,19 @@ func (s *Server) Start(bindAddr string) error {
 				strings.HasPrefix(c.Request().URL.Path, "/static")
 		}, Balancer: middleware.NewRoundRobinBalancer([]*middleware.ProxyTarget{{URL: viteUrl}})}))
 	} else {
-		s.e.Static("/", "./frontend/dist")
+		s.protect(auth.GroupPublic, func() {
+			s.e.Static("/", "./frontend/dist")
+		})
 	}
-	s.e.Static("/static", "./static")
-	s.e.Use(middleware.CORS())
+	s.protect(auth.GroupPublic, func() {
+		s.e.Static("/static", "./static")
+	})
+
+	// Other websites may only use the api if the authentication is disabled
+	s.e.Use(middleware.CORSWithConfig(middleware.CORSConfig{Skipper: func(c echo.Context) bool {
+		return authStore.Enabled()
+	}}))
+	s.e.Use(auth.Middleware(authStore, s.routes))
 
 	s.e.HideBanner = true
 	s.e.HidePort = true
@@ -339,6 +474,17 @@ func (s *Server) Start(bindAddr string) error {
 		_ = s.db.AddLog(e)
 	})
 
+	if s.tls != nil {
+		if len(s.tls.RedirectAddr) > 0 {
+			go redirectHTTPS(s.tls.RedirectAddr, bindAddr)
+		}
+
+		// The fingerprint is needed to trust a self-signed certificate, e.g. in the remote printer
+		log.Info("Server started", log.WithValue("bind", bindAddr), log.WithValue("tls", true), log.WithValue("fingerprint", Fingerprint(cert.Certificate[0])))
+
+		return s.e.StartTLS(bindAddr, certFile, keyFile)
+	}
+
 	log.Info("Server started", log.WithValue("bind", bindAddr))
 
 	return s.e.Start(bindAddr)
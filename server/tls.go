@@ -0,0 +1,215 @@
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/BigJk/snd/local"
+	"github.com/BigJk/snd/log"
+)
+
+const (
+	// selfSignedValidity is how long a generated certificate is valid.
+	selfSignedValidity = time.Hour * 24 * 365
+	// selfSignedRenewal is how long before the expiry a generated certificate is replaced.
+	selfSignedRenewal = time.Hour * 24 * 30
+)
+
+// TLSConfig configures HTTPS.
+type TLSConfig struct {
+	// CertFile and KeyFile are the PEM encoded certificate and private key. If both are empty a
+	// self-signed certificate is generated in the data directory.
+	CertFile string
+	KeyFile  string
+	// RedirectAddr is the address of a plain HTTP listener that redirects to HTTPS, e.g. ":80".
+	// No redirect is served if it is empty.
+	RedirectAddr string
+}
+
+// WithTLS serves the Server over HTTPS instead of HTTP.
+func WithTLS(config TLSConfig) Option {
+	return func(s *Server) error {
+		if (len(config.CertFile) == 0) != (len(config.KeyFile) == 0) {
+			return errors.New("certificate and key file have to be set together")
+		}
+
+		s.tls = &config
+		return nil
+	}
+}
+
+// CertificateFile returns the certificate file that is used with the config. Without a configured
+// file it is the self-signed certificate in the data directory.
+func (c TLSConfig) CertificateFile(dataDir string) string {
+	if len(c.CertFile) > 0 {
+		return c.CertFile
+	}
+	return filepath.Join(dataDir, "tls", "cert.pem")
+}
+
+// ReadCertificate reads the first certificate of a PEM encoded certificate file and returns it DER encoded.
+func ReadCertificate(file string) ([]byte, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, fmt.Errorf("no certificate found in %s", file)
+	}
+
+	return block.Bytes, nil
+}
+
+// Fingerprint returns the SHA-256 fingerprint of a DER encoded certificate. Clients can pin it to
+// trust a self-signed certificate.
+func Fingerprint(der []byte) string {
+	return local.Fingerprint(der)
+}
+
+// certificate returns the certificate and key files of the server. If no files are configured a
+// self-signed certificate is created, or renewed if it expires soon.
+func (s *Server) certificate() (string, string, error) {
+	if len(s.tls.CertFile) > 0 {
+		return s.tls.CertFile, s.tls.KeyFile, nil
+	}
+
+	if len(s.dataDir) == 0 {
+		return "", "", errors.New("a data directory is needed for the self-signed certificate")
+	}
+
+	certFile := s.tls.CertificateFile(s.dataDir)
+	keyFile := filepath.Join(s.dataDir, "tls", "key.pem")
+
+	renewed := false
+	if cert, err := tls.LoadX509KeyPair(certFile, keyFile); err == nil {
+		leaf, err := x509.ParseCertificate(cert.Certificate[0])
+		if err == nil && time.Until(leaf.NotAfter) > selfSignedRenewal {
+			return certFile, keyFile, nil
+		}
+		renewed = true
+	}
+
+	log.Info("generating self-signed certificate", log.WithValue("file", certFile))
+
+	if err := os.MkdirAll(filepath.Dir(certFile), 0700); err != nil {
+		return "", "", err
+	}
+
+	certPEM, keyPEM, err := selfSigned()
+	if err != nil {
+		return "", "", err
+	}
+
+	if err := os.WriteFile(certFile, certPEM, 0644); err != nil {
+		return "", "", err
+	}
+
+	if err := os.WriteFile(keyFile, keyPEM, 0600); err != nil {
+		return "", "", err
+	}
+
+	// Clients that pinned the old certificate reject the new one until they are updated
+	if renewed {
+		if block, _ := pem.Decode(certPEM); block != nil {
+			_ = log.ErrorString("self-signed certificate was renewed, clients that pinned the old certificate have to be updated with the new fingerprint", log.WithValue("file", certFile), log.WithValue("fingerprint", Fingerprint(block.Bytes)))
+		}
+	}
+
+	return certFile, keyFile, nil
+}
+
+// selfSigned creates a certificate for localhost, the hostname and all addresses of the machine,
+// so that it is valid for every address the server can be reached by in the local network. It is a
+// leaf certificate that can't sign other certificates, clients pin it by its fingerprint, its public
+// key or the certificate file instead of trusting it as a CA.
+func selfSigned() ([]byte, []byte, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{Organization: []string{"Sales & Dungeons"}, CommonName: "Sales & Dungeons"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(selfSignedValidity),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  false,
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
+	}
+
+	if hostname, err := os.Hostname(); err == nil && len(hostname) > 0 {
+		template.DNSNames = append(template.DNSNames, hostname)
+	}
+
+	if addrs, err := net.InterfaceAddrs(); err == nil {
+		for _, addr := range addrs {
+			if ipNet, ok := addr.(*net.IPNet); ok && !ipNet.IP.IsLoopback() && !ipNet.IP.IsLinkLocalUnicast() {
+				template.IPAddresses = append(template.IPAddresses, ipNet.IP)
+			}
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), nil
+}
+
+// redirectHTTPS serves a plain HTTP listener that redirects every request to the HTTPS server.
+func redirectHTTPS(redirectAddr string, bindAddr string) {
+	_, port, err := net.SplitHostPort(bindAddr)
+	if err != nil {
+		log.Error(err, log.WithValue("bind", bindAddr))
+		return
+	}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(r.Host); err == nil {
+			host = h
+		}
+		if port != "443" {
+			host = net.JoinHostPort(host, port)
+		}
+
+		http.Redirect(w, r, fmt.Sprintf("https://%s%s", host, r.URL.RequestURI()), http.StatusMovedPermanently)
+	})
+
+	log.Info("redirecting http to https", log.WithValue("bind", redirectAddr))
+
+	if err := http.ListenAndServe(redirectAddr, handler); err != nil {
+		log.Error(err, log.WithValue("bind", redirectAddr))
+	}
+}
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSelfSignedIsLeaf(t *testing.T) {
+	certPEM, _, err := selfSigned()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		t.Fatal("expected a certificate")
+	}
+
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The certificate is pinned by clients, so it must not be able to sign other certificates
+	if cert.IsCA || cert.KeyUsage != x509.KeyUsageDigitalSignature {
+		t.Fatalf("expected a leaf certificate, got ca %v and key usage %d", cert.IsCA, cert.KeyUsage)
+	}
+	if err := cert.VerifyHostname("127.0.0.1"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCertificateRenewal(t *testing.T) {
+	s := &Server{tls: &TLSConfig{}, dataDir: t.TempDir()}
+
+	certFile, keyFile, err := s.certificate()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := ReadCertificate(certFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A valid certificate is reused
+	if _, _, err := s.certificate(); err != nil {
+		t.Fatal(err)
+	}
+	if reused, err := ReadCertificate(certFile); err != nil || Fingerprint(reused) != Fingerprint(first) {
+		t.Fatalf("expected the certificate to be reused, got %v", err)
+	}
+
+	// A certificate that expires soon is replaced
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{SerialNumber: big.NewInt(1), NotBefore: time.Now().Add(-time.Hour), NotAfter: time.Now().Add(selfSignedRenewal / 2)}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := s.certificate(); err != nil {
+		t.Fatal(err)
+	}
+	renewed, err := ReadCertificate(filepath.Join(s.dataDir, "tls", "cert.pem"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if Fingerprint(renewed) == Fingerprint(der) || Fingerprint(renewed) == Fingerprint(first) {
+		t.Fatal("expected a new certificate")
+	}
+}
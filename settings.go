@@ -29,4 +29,22 @@ type Settings struct {
 	AIContextWindow       int      `json:"aiContextWindow"`
 	AIMaxTokens           int      `json:"aiMaxTokens"`
 	AIURL                 string   `json:"aiUrl"`
+	// AIEmbeddingModel is used to find relevant entries for prompts. If empty only full text search is used.
+	AIEmbeddingModel string `json:"aiEmbeddingModel"`
+	// AIDailyBudget is the maximum amount in USD that can be spent on AI per day. Zero means no limit.
+	AIDailyBudget float64 `json:"aiDailyBudget"`
+	// AIDailyTokenLimit is the maximum number of tokens that can be used per day. Zero means no limit.
+	AIDailyTokenLimit int `json:"aiDailyTokenLimit"`
+	// AIRateLimit is the maximum number of AI requests per minute. Zero means no limit.
+	AIRateLimit int `json:"aiRateLimit"`
+	// AITimeout is the time in seconds after which an AI request is cancelled. Zero uses the default of 60 seconds.
+	AITimeout int `json:"aiTimeout"`
+	// AICacheTTL is the time in hours after which cached AI responses expire. Zero means they never expire.
+	AICacheTTL int `json:"aiCacheTtl"`
+	// AICacheMaxSize is the maximum size of the AI cache in MB. Zero means no limit.
+	AICacheMaxSize int `json:"aiCacheMaxSize"`
+	// RenderPoolSize is the maximum number of pages that render at the same time. Zero uses the default.
+	RenderPoolSize int `json:"renderPoolSize"`
+	// HotFolderStrategy is the merge strategy for the entries of files imported from the hot folder. Empty keeps local modifications.
+	HotFolderStrategy string `json:"hotFolderStrategy"`
 }
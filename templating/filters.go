@@ -0,0 +1,124 @@
+package templating
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/rand"
+	"strings"
+
+	"github.com/nikolalohinski/gonja/v2/exec"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/renderer/html"
+)
+
+var markdown = goldmark.New(goldmark.WithRendererOptions(html.WithUnsafe()))
+
+// filters returns the filters that nunjucks has, or S&D adds, but jinja doesn't know.
+func (r *Renderer) filters(rng *rand.Rand) *exec.FilterSet {
+	return exec.NewFilterSet(map[string]exec.FilterFunction{
+		"shuffle": func(e *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+			if in.IsError() || !in.IsList() {
+				return in
+			}
+
+			items := make([]any, 0, in.Len())
+			for i := 0; i < in.Len(); i++ {
+				items = append(items, in.Index(i).Interface())
+			}
+			rng.Shuffle(len(items), func(i, j int) {
+				items[i], items[j] = items[j], items[i]
+			})
+
+			return exec.AsValue(items)
+		},
+		"random": func(e *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+			if in.IsError() || !in.CanSlice() || in.Len() <= 0 {
+				return in
+			}
+			return in.Index(rng.Intn(in.Len()))
+		},
+		"markdown": func(e *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+			if in.IsError() {
+				return in
+			}
+
+			buf := &bytes.Buffer{}
+			if err := markdown.Convert([]byte(in.String()), buf); err != nil {
+				return exec.ValueError(err)
+			}
+
+			return exec.AsSafeValue(buf.String())
+		},
+		"markdowni": func(e *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+			if in.IsError() {
+				return in
+			}
+
+			buf := &bytes.Buffer{}
+			if err := markdown.Convert([]byte(in.String()), buf); err != nil {
+				return exec.ValueError(err)
+			}
+
+			// Strip the paragraph that wraps inline content.
+			res := strings.TrimSpace(buf.String())
+			if strings.HasPrefix(res, "<p>") && strings.HasSuffix(res, "</p>") && strings.Count(res, "<p>") == 1 {
+				res = res[len("<p>") : len(res)-len("</p>")]
+			}
+
+			return exec.AsSafeValue(res)
+		},
+		"json": func(e *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+			if in.IsError() {
+				return in
+			}
+
+			data, err := json.Marshal(in.ToGoSimpleType(false))
+			if err != nil {
+				return exec.ValueError(err)
+			}
+
+			return exec.AsSafeValue(string(data))
+		},
+		"dump": func(e *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+			if in.IsError() {
+				return in
+			}
+
+			data, err := json.Marshal(in.ToGoSimpleType(false))
+			if err != nil {
+				return exec.ValueError(err)
+			}
+
+			return exec.AsValue(string(data))
+		},
+		"nl2br": func(e *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+			if in.IsError() {
+				return in
+			}
+			return exec.AsSafeValue(strings.ReplaceAll(in.Escaped(), "\n", "<br />\n"))
+		},
+		"source": func(e *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+			if in.IsError() {
+				return in
+			}
+
+			entries, err := r.db.GetEntries(in.String())
+			if err != nil {
+				return exec.ValueError(err)
+			}
+
+			// Pass through json so that the entries have the same shape as in the frontend.
+			raw, err := json.Marshal(entries)
+			if err != nil {
+				return exec.ValueError(err)
+			}
+
+			var res []any
+			if err := json.Unmarshal(raw, &res); err != nil {
+				return exec.ValueError(err)
+			}
+
+			return exec.AsValue(normalizeNumbers(res))
+		},
+	})
+}
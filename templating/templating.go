@@ -0,0 +1,322 @@
+// Package templating renders S&D templates on the server without the browser round-trip.
+// Templates are written for Nunjucks, which is mostly compatible with Jinja, so the rendering
+// is done by gonja with the same globals and filters the frontend provides.
+//
+// Templates that make use of features that can only run in the browser (e.g. the {% js %} or
+// {% ai %} tags) return ErrUnsupported, so that the caller can fall back to the browser rendering.
+package templating
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"regexp"
+	"strings"
+
+	"github.com/BigJk/snd"
+	"github.com/BigJk/snd/database"
+	"github.com/BigJk/snd/local"
+	"github.com/nikolalohinski/gonja/v2"
+	"github.com/nikolalohinski/gonja/v2/builtins"
+	"github.com/nikolalohinski/gonja/v2/config"
+	"github.com/nikolalohinski/gonja/v2/exec"
+	"github.com/nikolalohinski/gonja/v2/loaders"
+)
+
+// ErrUnsupported is returned if a template uses features that can't be rendered on the server.
+var ErrUnsupported = errors.New("template uses features that are only supported in the browser")
+
+// unsupportedRegex finds the nunjucks extensions that need the browser to run.
+var unsupportedRegex = regexp.MustCompile(`\{%-?\s*(js|ai)\b`)
+
+// dataRegex finds {% data "name" %}...{% enddata %} blocks.
+var dataRegex = regexp.MustCompile(`(?s)\{%-?\s*data\s+["']?([a-zA-Z_][a-zA-Z0-9_]*)["']?\s*-?%\}(.*?)\{%-?\s*enddata\s*-?%\}`)
+
+// State represents the data that is available in a template. It mirrors the
+// state the frontend passes to the templating worker.
+type State struct {
+	It        map[string]any
+	Config    map[string]any
+	Sources   []string
+	Settings  snd.Settings
+	Images    map[string]string
+	AIEnabled bool
+	AIToken   string
+}
+
+// Options changes what is added around the rendered template.
+type Options struct {
+	// Minimal skips the rng, ai and dither scripts.
+	Minimal bool
+	// Dither appends the dither script.
+	Dither bool
+	// Raw disables the html escaping, for templates that produce plain values instead of html.
+	Raw bool
+}
+
+// Renderer renders templates with access to the database for the source filter.
+type Renderer struct {
+	db database.Database
+}
+
+// New creates a new renderer.
+func New(db database.Database) *Renderer {
+	return &Renderer{db: db}
+}
+
+// IsSupported checks if the template can be rendered on the server.
+func IsSupported(template string) bool {
+	return !unsupportedRegex.MatchString(template)
+}
+
+// Render renders the template string with the given state.
+func (r *Renderer) Render(template string, state State, options Options) (string, error) {
+	if !IsSupported(template) {
+		return "", ErrUnsupported
+	}
+
+	seed := fmt.Sprint(state.Config["seed"])
+	if state.Config["seed"] == nil || seed == "" {
+		seed = fmt.Sprint(rand.Int63n(500000000))
+	}
+
+	ctx, err := r.context(state)
+	if err != nil {
+		return "", err
+	}
+
+	// Extract the static data blocks and add them to the context.
+	var dataErr error
+	template = dataRegex.ReplaceAllStringFunc(template, func(s string) string {
+		match := dataRegex.FindStringSubmatch(s)
+
+		var data any
+		if err := json.Unmarshal([]byte(match[2]), &data); err != nil {
+			dataErr = fmt.Errorf("data block '%s' is not valid json: %w", match[1], err)
+			return ""
+		}
+
+		ctx.Set(match[1], normalizeNumbers(data))
+		return ""
+	})
+	if dataErr != nil {
+		return "", dataErr
+	}
+
+	conf := config.New()
+	conf.AutoEscape = !options.Raw
+
+	env := &exec.Environment{
+		Context:           gonja.DefaultContext,
+		Filters:           exec.NewFilterSet(map[string]exec.FilterFunction{}).Update(builtins.Filters).Update(r.filters(seedRandom(seed))),
+		Tests:             builtins.Tests,
+		ControlStructures: builtins.ControlStructures,
+		Methods:           builtins.Methods,
+	}
+
+	loader, err := loaders.NewMemoryLoader(map[string]string{"/template": template})
+	if err != nil {
+		return "", err
+	}
+
+	tmpl, err := exec.NewTemplate("/template", conf, loader, env)
+	if err != nil {
+		return "", err
+	}
+
+	res, err := tmpl.ExecuteToString(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	if options.Minimal {
+		return res, nil
+	}
+
+	buf := &bytes.Buffer{}
+	buf.WriteString(fmt.Sprintf(rngScriptFormat, jsString(seed)))
+	buf.WriteString(fmt.Sprintf(aiScriptFormat, state.AIEnabled, jsString(state.AIToken), jsString(local.URL(""))))
+	buf.WriteString(res)
+	if options.Dither {
+		buf.WriteString(ditherScript)
+	}
+
+	return buf.String(), nil
+}
+
+// RenderTemplate renders the print template of tmpl for the given entry.
+func (r *Renderer) RenderTemplate(tmpl snd.Template, entry snd.Entry, config map[string]any) (string, error) {
+	state, err := r.templateState(tmpl, entry, config)
+	if err != nil {
+		return "", err
+	}
+
+	return r.Render(tmpl.PrintTemplate, state, Options{Dither: true})
+}
+
+// RenderTemplateList renders the list template of tmpl for the given entry.
+func (r *Renderer) RenderTemplateList(tmpl snd.Template, entry snd.Entry, config map[string]any) (string, error) {
+	state, err := r.templateState(tmpl, entry, config)
+	if err != nil {
+		return "", err
+	}
+
+	state.It = entry.Data
+	state.Images = map[string]string{}
+
+	return r.Render(tmpl.ListTemplate, state, Options{Minimal: true})
+}
+
+// SkeletonEntry returns the entry the frontend uses to preview the skeleton data of a template.
+func SkeletonEntry(tmpl snd.Template) snd.Entry {
+	return snd.Entry{
+		ID:   "skeleton",
+		Name: "Skeleton",
+		Data: tmpl.SkeletonData,
+	}
+}
+
+// SanitizeConfig sets the default values of the template config and removes
+// keys that are not part of the template config anymore.
+func SanitizeConfig(tmpl snd.Template, config map[string]any) map[string]any {
+	res := map[string]any{}
+
+	for i := range tmpl.Config {
+		if val, ok := config[tmpl.Config[i].Key]; ok {
+			res[tmpl.Config[i].Key] = val
+		} else {
+			res[tmpl.Config[i].Key] = tmpl.Config[i].Default
+		}
+	}
+
+	if seed, ok := config["seed"]; ok {
+		res["seed"] = seed
+	}
+
+	return res
+}
+
+func (r *Renderer) templateState(tmpl snd.Template, entry snd.Entry, config map[string]any) (State, error) {
+	settings, err := r.db.GetSettings()
+	if err != nil {
+		return State{}, err
+	}
+
+	it := map[string]any{}
+	for k, v := range entry.Data {
+		it[k] = v
+	}
+	it["snd"] = map[string]any{
+		"id":   entry.ID,
+		"name": entry.Name,
+	}
+
+	images := tmpl.Images
+	if images == nil {
+		images = map[string]string{}
+	}
+
+	return State{
+		It:       it,
+		Config:   SanitizeConfig(tmpl, config),
+		Sources:  tmpl.DataSources,
+		Settings: settings,
+		Images:   images,
+	}, nil
+}
+
+// context converts the state into the context of the template. The values are
+// passed through json, so that the field names match the ones used in the frontend.
+func (r *Renderer) context(state State) (*exec.Context, error) {
+	// Clear sensitive data from state
+	state.Settings.AIApiKey = ""
+	state.Settings.SyncKey = ""
+
+	raw, err := json.Marshal(map[string]any{
+		"it":        state.It,
+		"config":    state.Config,
+		"sources":   state.Sources,
+		"settings":  state.Settings,
+		"images":    state.Images,
+		"aiEnabled": state.AIEnabled,
+		"aiToken":   state.AIToken,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var data map[string]any
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, err
+	}
+
+	cleanupImages(data["it"])
+	cleanupImages(data["config"])
+
+	return exec.NewContext(normalizeNumbers(data).(map[string]any)), nil
+}
+
+// normalizeNumbers converts whole floats to ints. Numbers decoded from json are always
+// floats, which jinja would print as "3.0" while javascript prints "3".
+func normalizeNumbers(data any) any {
+	switch data := data.(type) {
+	case float64:
+		if data == math.Trunc(data) && math.Abs(data) < math.MaxInt64 {
+			return int64(data)
+		}
+	case map[string]any:
+		for k, v := range data {
+			data[k] = normalizeNumbers(v)
+		}
+	case []any:
+		for i, v := range data {
+			data[i] = normalizeNumbers(v)
+		}
+	}
+	return data
+}
+
+// cleanupImages replaces the !IMAGE placeholders with real image urls.
+func cleanupImages(data any) {
+	replace := func(val string) string {
+		if strings.HasPrefix(val, "!IMAGE:") {
+			stripped := strings.TrimPrefix(val, "!IMAGE:")
+			if strings.HasPrefix(stripped, "http") || strings.HasPrefix(stripped, "data:") {
+				return stripped
+			}
+			return "https://dummyimage.com/" + stripped
+		} else if strings.HasPrefix(val, "!IMAGE") {
+			return "https://dummyimage.com/400x3:2"
+		}
+		return val
+	}
+
+	switch data := data.(type) {
+	case map[string]any:
+		for k, v := range data {
+			if s, ok := v.(string); ok {
+				data[k] = replace(s)
+			} else {
+				cleanupImages(v)
+			}
+		}
+	case []any:
+		for i, v := range data {
+			if s, ok := v.(string); ok {
+				data[i] = replace(s)
+			} else {
+				cleanupImages(v)
+			}
+		}
+	}
+}
+
+func seedRandom(seed string) *rand.Rand {
+	hash := fnv.New64a()
+	_, _ = hash.Write([]byte(seed))
+	return rand.New(rand.NewSource(int64(hash.Sum64())))
+}
@@ -0,0 +1,168 @@
+package templating
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/BigJk/snd"
+	"github.com/BigJk/snd/database/badger"
+)
+
+func newTestRenderer(t *testing.T) *Renderer {
+	db, err := badger.New(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	for _, entry := range []snd.Entry{
+		{ID: "1", Name: "Goblin", Data: map[string]any{"hp": 7}},
+		{ID: "2", Name: "Orc", Data: map[string]any{"hp": 15}},
+	} {
+		if err := db.SaveEntry("ds:monsters", entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return New(db)
+}
+
+func TestRender(t *testing.T) {
+	renderer := newTestRenderer(t)
+
+	state := State{
+		It: map[string]any{
+			"name":  "Goblin",
+			"hp":    7.0,
+			"html":  "<b>bold</b>",
+			"text":  "a\nb",
+			"list":  []any{1.0, 2.0},
+			"image": "!IMAGE:http://example.com/a.png",
+		},
+		Config:   map[string]any{"seed": "fixed"},
+		Settings: snd.Settings{AIApiKey: "secret", SyncKey: "secret"},
+	}
+
+	cases := []struct {
+		name     string
+		template string
+		options  Options
+		want     string
+		err      bool
+	}{
+		{name: "variable", template: "{{ it.name }}", want: "Goblin"},
+		{name: "whole numbers", template: "{{ it.hp }}", want: "7"},
+		{name: "escaped", template: "{{ it.html }}", want: "&lt;b&gt;bold&lt;/b&gt;"},
+		{name: "raw", template: "{{ it.html }}", options: Options{Raw: true}, want: "<b>bold</b>"},
+		{name: "images", template: "{{ it.image }}", options: Options{Raw: true}, want: "http://example.com/a.png"},
+		{name: "secrets removed", template: "[{{ settings.aiApiKey }}{{ settings.syncKey }}]", want: "[]"},
+		{name: "markdown", template: `{{ "**a**" | markdown }}`, want: "<p><strong>a</strong></p>\n"},
+		{name: "markdown inline", template: `{{ "**a**" | markdowni }}`, want: "<strong>a</strong>"},
+		{name: "json", template: "{{ it.list | json }}", want: "[1,2]"},
+		{name: "dump", template: "{{ it.list | dump }}", options: Options{Raw: true}, want: "[1,2]"},
+		{name: "nl2br", template: "{{ it.text | nl2br }}", want: "a<br />\nb"},
+		{name: "random", template: "{{ [3] | random }}", want: "3"},
+		{name: "shuffle", template: "{{ [1, 2, 3] | shuffle | sort | join(',') }}", want: "1,2,3"},
+		{name: "source", template: `{% for e in "ds:monsters" | source %}{{ e.name }}={{ e.data.hp }};{% endfor %}`, want: "Goblin=7;Orc=15;"},
+		{name: "data block", template: `{% data "stats" %}{"str": 10, "list": ["a", "b"]}{% enddata %}{{ stats.str }}{{ stats.list | join }}`, want: "10ab"},
+		{name: "data block with single quotes", template: `{% data 'stats' %}{"str": 1.5}{% enddata %}{{ stats.str }}`, want: "1.5"},
+		{name: "invalid data block", template: `{% data "stats" %}{"str": }{% enddata %}`, err: true},
+		{name: "syntax error", template: "{% if %}", err: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			c.options.Minimal = true
+
+			res, err := renderer.Render(c.template, state, c.options)
+			if c.err {
+				if err == nil {
+					t.Fatalf("expected an error, got %q", res)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res != c.want {
+				t.Fatalf("expected %q, got %q", c.want, res)
+			}
+		})
+	}
+}
+
+func TestRenderIsDeterministicWithSeed(t *testing.T) {
+	renderer := newTestRenderer(t)
+	state := State{Config: map[string]any{"seed": "fixed"}}
+
+	template := "{{ range(100) | list | shuffle | join(',') }}"
+	first, err := renderer.Render(template, state, Options{Minimal: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := renderer.Render(template, state, Options{Minimal: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if first != second {
+		t.Fatal("expected the same result for the same seed")
+	}
+}
+
+func TestRenderUnsupported(t *testing.T) {
+	renderer := newTestRenderer(t)
+
+	for _, template := range []string{
+		"{% js %}return 1;{% endjs %}",
+		"{%- ai %}Write a story{% endai %}",
+	} {
+		if IsSupported(template) {
+			t.Fatalf("expected %q to be unsupported", template)
+		}
+		if _, err := renderer.Render(template, State{}, Options{}); !errors.Is(err, ErrUnsupported) {
+			t.Fatalf("expected ErrUnsupported for %q, got %v", template, err)
+		}
+	}
+
+	if !IsSupported("{{ json }}{% if aim %}{% endif %}") {
+		t.Fatal("expected variables that start like the tags to be supported")
+	}
+}
+
+func TestRenderScripts(t *testing.T) {
+	renderer := newTestRenderer(t)
+
+	state := State{
+		Config:  map[string]any{"seed": "a'b</script><script>alert(1)"},
+		AIToken: "token'",
+	}
+
+	res, err := renderer.Render("<p>content</p>", state, Options{Dither: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(res, "</script><script>alert(1)") {
+		t.Fatal("expected the seed to be escaped")
+	}
+	if !strings.Contains(res, `new Math.seedrandom("a'b\u003c/script\u003e\u003cscript\u003ealert(1)")`) {
+		t.Fatal("expected the seed as string literal")
+	}
+	if !strings.Contains(res, `const aiToken = "token'";`) {
+		t.Fatal("expected the token as string literal")
+	}
+	if !strings.Contains(res, "<p>content</p>") || !strings.Contains(res, "floyd_steinberg") {
+		t.Fatal("expected the content and the dither script")
+	}
+
+	minimal, err := renderer.Render("<p>content</p>", state, Options{Minimal: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if minimal != "<p>content</p>" {
+		t.Fatalf("expected no scripts, got %q", minimal)
+	}
+}